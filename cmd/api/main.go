@@ -3,15 +3,26 @@ package main
 import (
 	"context"
 	"log"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
 	"time"
 
+	"tracker-scrapper/internal/core/auth"
 	"tracker-scrapper/internal/core/cache"
+	"tracker-scrapper/internal/core/capture"
 	"tracker-scrapper/internal/core/config"
+	"tracker-scrapper/internal/core/database"
+	"tracker-scrapper/internal/core/health"
+	"tracker-scrapper/internal/core/idempotency"
 	"tracker-scrapper/internal/core/logger"
-	"tracker-scrapper/internal/core/proxy"
+	"tracker-scrapper/internal/core/maintenance"
+	"tracker-scrapper/internal/core/metrics"
 	"tracker-scrapper/internal/core/server"
 	orderadapter "tracker-scrapper/internal/features/orders/adapters"
 	orderhandler "tracker-scrapper/internal/features/orders/handler"
+	orderports "tracker-scrapper/internal/features/orders/ports"
 	orderservice "tracker-scrapper/internal/features/orders/service"
 	trackingadapter "tracker-scrapper/internal/features/tracking/adapters"
 	trackinghandler "tracker-scrapper/internal/features/tracking/handler"
@@ -22,6 +33,7 @@ import (
 	bannerhandler "tracker-scrapper/internal/features/banners/handler"
 	bannerservice "tracker-scrapper/internal/features/banners/service"
 
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
 	"go.uber.org/zap"
 )
 
@@ -39,98 +51,230 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	if err := logger.Init(cfg.Environment, cfg.LogLevel); err != nil {
+	logFile := logger.FileOutput{
+		Path:       cfg.LogFile.Path,
+		MaxSizeMB:  cfg.LogFile.MaxSizeMB,
+		MaxBackups: cfg.LogFile.MaxBackups,
+		MaxAgeDays: cfg.LogFile.MaxAgeDays,
+	}
+	logSampling := logger.Sampling{Initial: cfg.LogSampleInitial, Thereafter: cfg.LogSampleThereafter}
+	if err := logger.Init(cfg.Environment, cfg.LogLevel, cfg.LogFormat, logFile, logSampling); err != nil {
 		log.Fatalf("Failed to init logger: %v", err)
 	}
 	defer logger.Sync()
 
 	l := logger.Get()
+	if err := metrics.Init(); err != nil {
+		l.Fatal("Failed to init metrics", zap.Error(err))
+	}
+
+	if cfg.Capture.Enabled {
+		if err := capture.Init(cfg.Capture.Dir, cfg.Capture.MaxBytes); err != nil {
+			l.Fatal("Failed to init raw payload capture", zap.Error(err))
+		}
+		l.Warn("Raw courier payload capture enabled", zap.String("dir", cfg.Capture.Dir))
+	}
+
 	l.Info("Application starting",
 		zap.String("environment", cfg.Environment),
 		zap.String("log_level", cfg.LogLevel),
+		zap.String("log_format", cfg.LogFormat),
 	)
 
 	// Initialize Order Adapter and run Health Check
-	wcAdapter := orderadapter.NewWooCommerceAdapter(cfg.WooCommerce)
+	wcAdapter, err := orderadapter.NewWooCommerceAdapter(cfg.WooCommerce)
+	if err != nil {
+		l.Fatal("Invalid WooCommerce configuration", zap.Error(err))
+	}
 	if err := wcAdapter.HealthCheck(); err != nil {
-		l.Fatal("WooCommerce Health Check Failed", zap.Error(err))
+		// In maintenance mode WooCommerce is expected to be unavailable; orders are served from cache.
+		// With ORDER_SOURCE=postgres it is only needed by the debug endpoint and the sync command.
+		if !cfg.MaintenanceMode && cfg.OrderSource != "postgres" {
+			l.Fatal("WooCommerce Health Check Failed", zap.Error(err))
+		}
+		l.Warn("WooCommerce Health Check Failed, continuing without it", zap.Error(err))
+	} else {
+		l.Info("WooCommerce connection verified")
 	}
-	l.Info("WooCommerce connection verified")
 
-	// Initialize Redis Cache
-	redisCache, err := cache.NewRedisAdapter(cfg.Cache.RedisURL)
+	// Initialize the cache backend (Redis or Memcached)
+	backendCache, err := cache.NewBackend(cfg.Cache)
 	if err != nil {
-		l.Fatal("Failed to initialize Redis", zap.Error(err))
+		l.Fatal("Failed to initialize cache", zap.String("backend", cfg.Cache.Backend), zap.Error(err))
 	}
-	defer redisCache.Close()
+	defer backendCache.Close()
 
-	// Health Check Redis
+	// Health Check the cache
 	ctx := context.Background()
-	if err := redisCache.Ping(ctx); err != nil {
-		l.Fatal("Redis Health Check Failed", zap.Error(err))
+	if err := backendCache.Ping(ctx); err != nil {
+		l.Fatal("Cache Health Check Failed", zap.String("backend", cfg.Cache.Backend), zap.Error(err))
 	}
-	l.Info("Redis connection verified")
+	l.Info("Cache connection verified", zap.String("backend", cfg.Cache.Backend))
 
-	// Initialize Order Service & Handler with cache
-	orderCacheTTL := time.Duration(cfg.Cache.OrderTTL) * time.Second
-	orderService := orderservice.NewOrderService(wcAdapter, redisCache, orderCacheTTL)
-	orderHandler := orderhandler.NewOrderHandler(orderService)
-
-	// Initialize Tracking Providers with proxy settings
-	coordinadoraProxy := proxy.Settings{
-		Enabled:  cfg.Proxy.Coordinadora,
-		Hostname: cfg.Proxy.Hostname,
-		Port:     cfg.Proxy.Port,
-		Username: cfg.Proxy.Username,
-		Password: cfg.Proxy.Password,
-	}
-	servientregaProxy := proxy.Settings{
-		Enabled:  cfg.Proxy.Servientrega,
-		Hostname: cfg.Proxy.Hostname,
-		Port:     cfg.Proxy.Port,
-		Username: cfg.Proxy.Username,
-		Password: cfg.Proxy.Password,
-	}
-	interrapidisimoProxy := proxy.Settings{
-		Enabled:  cfg.Proxy.Interrapidisimo,
-		Hostname: cfg.Proxy.Hostname,
-		Port:     cfg.Proxy.Port,
-		Username: cfg.Proxy.Username,
-		Password: cfg.Proxy.Password,
-	}
-
-	coordinadoraAdapter := trackingadapter.NewCoordinadoraAdapter(cfg.Couriers.CoordinadoraURL, coordinadoraProxy)
-	servientregaAdapter := trackingadapter.NewServientregaAdapter(cfg.Couriers.ServientregaURL, servientregaProxy)
-	interrapidisimoAdapter := trackingadapter.NewInterrapidisimoAdapter(cfg.Couriers.InterrapidisimoURL, interrapidisimoProxy)
-
-	trackingProviders := []ports.TrackingProvider{
-		coordinadoraAdapter,
-		servientregaAdapter,
-		interrapidisimoAdapter,
+	// Namespace keys so several environments can share the same cache server
+	keyedCache := cache.NewPrefixedCache(backendCache, cfg.Cache.KeyPrefix)
+
+	// Optionally compress values on their way to the backend; the in-process cache keeps them plain
+	var appCache cache.Cache = keyedCache
+	if cfg.Cache.Compression == "gzip" {
+		appCache = cache.NewCodecCache(keyedCache)
+		l.Info("Cache compression enabled", zap.String("codec", cfg.Cache.Compression))
+	}
+
+	// Optionally serve hot orders and tracking from memory before hitting Redis
+	if cfg.Cache.L1Size > 0 {
+		appCache = cache.NewTieredCache(appCache, cfg.Cache.L1Size, time.Duration(cfg.Cache.L1TTL)*time.Second)
+		l.Info("In-process cache enabled", zap.Int("size", cfg.Cache.L1Size), zap.Int("ttl", cfg.Cache.L1TTL))
+	}
+
+	// Spread order and tracking expiries so entries cached in a burst don't lapse together
+	if cfg.Cache.TTLJitterPct > 0 {
+		appCache = cache.NewJitteredCache(appCache, cfg.Cache.TTLJitterPct)
+	}
+
+	// Maintenance mode is shared by the order and tracking services
+	maintenanceMode := maintenance.NewMode(cfg.MaintenanceMode)
+	maintenanceHdl := maintenance.NewHandler(maintenanceMode, cfg.StrictJSON)
+	healthHdl := health.NewHandler(maintenanceMode)
+
+	// Serve orders from the Postgres table kept up to date by cmd/sync instead of calling WooCommerce
+	var orderProvider orderports.OrderProvider = wcAdapter
+	switch cfg.OrderSource {
+	case "woocommerce":
+	case "postgres":
+		pool, err := database.NewPostgresPool(ctx, cfg.Database)
+		if err != nil {
+			l.Fatal("Failed to connect to Postgres", zap.Error(err))
+		}
+		defer pool.Close()
+		orderProvider = orderadapter.NewPostgresOrderAdapter(pool)
+		l.Info("Serving orders from Postgres", zap.String("host", cfg.Database.Host), zap.String("database", cfg.Database.Name))
+	default:
+		l.Fatal("ORDER_SOURCE must be woocommerce or postgres", zap.String("order_source", cfg.OrderSource))
+	}
+
+	// Initialize the courier scraping adapters (proxy, status codes, stealth and timeouts)
+	courierAdapters, err := trackingadapter.NewCourierAdapters(cfg)
+	if err != nil {
+		l.Fatal("Failed to initialize courier adapters", zap.Error(err))
+	}
+
+	// Each courier gets its own circuit breaker so one failing site doesn't hold up the others
+	breakerCooldown := time.Duration(cfg.Couriers.BreakerCooldown) * time.Second
+	withBreaker := func(provider ports.TrackingProvider, courier string) ports.TrackingProvider {
+		b := trackingservice.NewCourierBreaker(courier, cfg.Couriers.BreakerThreshold, breakerCooldown)
+		return trackingservice.WithCircuitBreaker(provider, courier, b)
+	}
+
+	trackingProviders := make([]ports.TrackingProvider, 0, len(trackingadapter.Couriers))
+	for _, courier := range trackingadapter.Couriers {
+		trackingProviders = append(trackingProviders, withBreaker(courierAdapters[courier], courier))
+	}
+
+	// Canned responses let developers exercise the tracking flow without the paid proxy
+	if cfg.Couriers.UseMockCouriers {
+		if cfg.Environment == "development" {
+			l.Warn("Using mock couriers; tracking responses are canned", zap.Strings("couriers", trackingadapter.Couriers))
+			trackingProviders = []ports.TrackingProvider{trackingadapter.NewMockCourierAdapter()}
+		} else {
+			l.Warn("USE_MOCK_COURIERS ignored outside development", zap.String("environment", cfg.Environment))
+		}
 	}
 
 	// Initialize Tracking Service & Handler with cache
 	trackingCacheTTL := time.Duration(cfg.Cache.TrackingTTL) * time.Second
-	trackingSvc := trackingservice.NewTrackingService(trackingProviders, redisCache, trackingCacheTTL)
-	trackingHdl := trackinghandler.NewTrackingHandler(trackingSvc)
+	trackingSvc, err := trackingservice.NewTrackingService(trackingProviders, appCache, trackingCacheTTL, maintenanceMode, cfg.Couriers.BatchWorkers)
+	if err != nil {
+		l.Fatal("Failed to register courier providers", zap.Error(err))
+	}
+	trackingSvc.SetDenylist(cfg.Couriers.Denylist)
+	trackingHdl := trackinghandler.NewTrackingHandler(trackingSvc, cfg.Couriers.OverrideAllowedHosts, cfg.StrictJSON, cfg.Couriers.AutoDetect)
+	trackingHdl.SetWatchTiming(time.Duration(cfg.Couriers.WatchTimeout)*time.Second, time.Duration(cfg.Couriers.WatchInterval)*time.Second)
+
+	// Initialize Order Service & Handler with cache; order shipments are tracked through the tracking service
+	orderCacheTTL := time.Duration(cfg.Cache.OrderTTL) * time.Second
+	webhookNotifier := orderadapter.NewWebhookNotifier(cfg.Webhook)
+	orderTracking := orderadapter.NewTrackingServiceResolver(trackingSvc)
+	orderService := orderservice.NewOrderService(orderProvider, appCache, orderCacheTTL, maintenanceMode, webhookNotifier, orderTracking)
+	orderService.SetStaleTTL(time.Duration(cfg.Cache.OrderStaleTTL) * time.Second)
+	orderHandler := orderhandler.NewOrderHandler(orderService, cfg.StrictJSON)
 
 	// Initialize Banner Feature
-	bannerRepo := banneradapter.NewRedisBannerRepository(redisCache)
+	bannerRepo := banneradapter.NewRedisBannerRepository(keyedCache)
 	bannerSvc := bannerservice.NewBannerService(bannerRepo)
-	bannerHdl := bannerhandler.NewBannerHandler(bannerSvc)
+	bannerHdl := bannerhandler.NewBannerHandler(bannerSvc, cfg.StrictJSON)
+
+	// Reload log level, cache TTLs and the tracking denylist on SIGHUP
+	config.Watch(ctx, ".", cfg, func(next *config.AppConfig) {
+		if err := logger.SetLevel(next.LogLevel); err != nil {
+			l.Warn("Invalid log level on reload", zap.String("log_level", next.LogLevel), zap.Error(err))
+		}
+		orderService.SetCacheTTL(time.Duration(next.Cache.OrderTTL) * time.Second)
+		orderService.SetStaleTTL(time.Duration(next.Cache.OrderStaleTTL) * time.Second)
+		trackingSvc.SetCacheTTL(time.Duration(next.Cache.TrackingTTL) * time.Second)
+		trackingSvc.SetDenylist(next.Couriers.Denylist)
+		l.Info("Configuration reloaded",
+			zap.String("log_level", next.LogLevel),
+			zap.Int("order_ttl", next.Cache.OrderTTL),
+			zap.Int("order_stale_ttl", next.Cache.OrderStaleTTL),
+			zap.Int("tracking_ttl", next.Cache.TrackingTTL),
+			zap.Int("denylisted_numbers", len(next.Couriers.Denylist)),
+		)
+	})
+
+	// Protected routes require a bearer API key unless auth is disabled
+	if !cfg.Auth.Enabled {
+		l.Warn("API key authentication is disabled; do not run like this in production")
+	} else if len(cfg.Auth.APIKeys) == 0 {
+		l.Fatal("AUTH_API_KEYS must be set when AUTH_ENABLED is true")
+	} else if len(cfg.Auth.AdminKeys) == 0 {
+		l.Warn("AUTH_ADMIN_KEYS is empty; admin routes will reject every request")
+	}
+	// Admin keys work on every protected route; ordinary keys never reach /admin
+	requireKey := auth.RequireAPIKey(cfg.Auth.Enabled, slices.Concat(cfg.Auth.APIKeys, cfg.Auth.AdminKeys))
+	requireAdmin := auth.RequireAPIKey(cfg.Auth.Enabled, cfg.Auth.AdminKeys)
+
+	// Writes carrying an Idempotency-Key run once; retries get the stored response
+	idempotent := idempotency.New(keyedCache, time.Duration(cfg.Cache.IdempotencyTTL)*time.Second)
 
 	srv := server.New(cfg)
 
-	// Register Routes
-	srv.App.Get("/orders/:id", orderHandler.GetOrder)
-	srv.App.Get("/tracking/:number", trackingHdl.GetTrackingHistory)
+	// Register Routes (swagger, metrics, health and the public banner routes stay unauthenticated).
+	// Metrics and health stay outside API_BASE_PATH so scrapers and probes keep fixed URLs.
+	srv.App.Get("/metrics", adaptor.HTTPHandler(metrics.Handler()))
+	srv.App.Get(cfg.HealthPath, healthHdl.GetHealth)
+	srv.API.Get("/orders/:id", requireKey, orderHandler.GetOrder)
+	srv.API.Get("/orders/:id/tracking", requireKey, orderHandler.GetOrderTracking)
+	if cfg.DebugEndpoints && cfg.Auth.Enabled {
+		srv.API.Get("/orders/:id/debug", requireKey, orderHandler.GetRawOrder)
+	} else if cfg.DebugEndpoints {
+		l.Warn("DEBUG_ENDPOINTS ignored because API key authentication is disabled")
+	}
+	srv.API.Get("/tracking/:number", requireKey, auth.MarkAdmin(cfg.Auth.Enabled, cfg.Auth.AdminKeys), trackingHdl.GetTrackingHistory)
+	srv.API.Get("/tracking/:number/watch", requireKey, trackingHdl.WatchTrackingHistory)
+	srv.API.Post("/tracking/batch", requireKey, trackingHdl.GetTrackingHistoryBatch)
 
 	// Banner Routes
-	srv.App.Post("/banner", bannerHdl.SetBanner)
-	srv.App.Get("/banner", bannerHdl.GetBanner)
-	srv.App.Delete("/banner", bannerHdl.RemoveBanner)
+	srv.API.Post("/banner", requireKey, idempotent, bannerHdl.SetBanner)
+	srv.API.Get("/banner", bannerHdl.GetBanner)
+	srv.API.Delete("/banner", requireKey, bannerHdl.RemoveBanner)
+	srv.API.Post("/banners/:id/dismiss", server.RateLimit(cfg.DismissRateLimit, time.Minute), bannerHdl.DismissBanner)
 
-	if err := srv.Run(); err != nil {
+	// Admin Routes
+	srv.API.Get("/admin/maintenance", requireAdmin, maintenanceHdl.GetStatus)
+	srv.API.Put("/admin/maintenance", requireAdmin, maintenanceHdl.SetStatus)
+	srv.API.Post("/admin/orders/warm", requireAdmin, orderHandler.WarmCache)
+
+	// Stop serving on SIGINT/SIGTERM, then release the couriers' browsers
+	shutdownCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := srv.Run(shutdownCtx); err != nil {
 		l.Fatal("Server failed to start", zap.Error(err))
 	}
+
+	if err := trackingSvc.Close(); err != nil {
+		l.Warn("Failed to close tracking providers", zap.Error(err))
+	}
+	l.Info("Server stopped")
 }
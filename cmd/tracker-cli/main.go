@@ -0,0 +1,65 @@
+// Command tracker-cli scrapes a courier from the command line with the same adapters the API uses
+// and prints the mapped tracking history as JSON.
+//
+//	tracker-cli coordinadora_co 04333004120
+//
+// It reads the same configuration as the API (.env, config.yaml or environment variables),
+// including proxy, stealth and timeout settings. Logs go to stderr; the exit status is non-zero on error.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+
+	"tracker-scrapper/internal/core/config"
+	"tracker-scrapper/internal/core/logger"
+	trackingadapter "tracker-scrapper/internal/features/tracking/adapters"
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: tracker-cli <courier> <number>\n\nCouriers: %s\n", strings.Join(trackingadapter.Couriers, ", "))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	courier, number := flag.Arg(0), flag.Arg(1)
+	if !slices.Contains(trackingadapter.Couriers, courier) {
+		fmt.Fprintf(os.Stderr, "unsupported courier %q, expected one of: %s\n", courier, strings.Join(trackingadapter.Couriers, ", "))
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load(".")
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	logSampling := logger.Sampling{Initial: cfg.LogSampleInitial, Thereafter: cfg.LogSampleThereafter}
+	if err := logger.Init(cfg.Environment, cfg.LogLevel, cfg.LogFormat, logger.FileOutput{}, logSampling); err != nil {
+		log.Fatalf("Failed to init logger: %v", err)
+	}
+	defer logger.Sync()
+
+	adapters, err := trackingadapter.NewCourierAdapters(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize courier adapters: %v", err)
+	}
+
+	history, err := adapters[courier].GetTrackingHistory(number)
+	if err != nil {
+		log.Fatalf("Tracking lookup failed: %v", err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(history); err != nil {
+		log.Fatalf("Failed to encode tracking history: %v", err)
+	}
+}
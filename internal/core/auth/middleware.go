@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"tracker-scrapper/internal/core/request"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// bearerPrefix precedes the API key in the Authorization header.
+const bearerPrefix = "Bearer "
+
+// adminLocal is the Locals key under which MarkAdmin records an admin caller.
+const adminLocal = "auth.admin"
+
+// ErrorResponse represents an authentication error response with Ray ID.
+type ErrorResponse struct {
+	// Message is the error description.
+	Message string `json:"message"`
+	// RayID is the unique request identifier for tracing.
+	RayID string `json:"ray_id"`
+}
+
+// RequireAPIKey returns middleware that accepts requests carrying "Authorization: Bearer <key>"
+// for one of keys and rejects the rest with 401. Apply it per route to keep others public.
+// When enabled is false every request passes, which is meant for local development only.
+func RequireAPIKey(enabled bool, keys []string) fiber.Handler {
+	if !enabled {
+		return func(c *fiber.Ctx) error {
+			return c.Next()
+		}
+	}
+
+	return func(c *fiber.Ctx) error {
+		header := c.Get(fiber.HeaderAuthorization)
+		if !strings.HasPrefix(header, bearerPrefix) || !validKey(strings.TrimPrefix(header, bearerPrefix), keys) {
+			c.Set(fiber.HeaderWWWAuthenticate, "Bearer")
+			return c.Status(http.StatusUnauthorized).JSON(ErrorResponse{
+				Message: "missing or invalid API key",
+				RayID:   request.RayID(c),
+			})
+		}
+		return c.Next()
+	}
+}
+
+// MarkAdmin returns middleware that records whether the request carries one of adminKeys, so
+// handlers on routes shared with ordinary keys can reserve some options for admins. It never rejects.
+// When enabled is false every request counts as admin, matching RequireAPIKey.
+func MarkAdmin(enabled bool, adminKeys []string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		header := c.Get(fiber.HeaderAuthorization)
+		admin := !enabled || (strings.HasPrefix(header, bearerPrefix) && validKey(strings.TrimPrefix(header, bearerPrefix), adminKeys))
+		c.Locals(adminLocal, admin)
+		return c.Next()
+	}
+}
+
+// IsAdmin reports whether MarkAdmin recorded the request as coming from an admin.
+func IsAdmin(c *fiber.Ctx) bool {
+	admin, _ := c.Locals(adminLocal).(bool)
+	return admin
+}
+
+// validKey reports whether key matches one of keys, comparing in constant time.
+func validKey(key string, keys []string) bool {
+	valid := false
+	for _, k := range keys {
+		if k != "" && subtle.ConstantTimeCompare([]byte(key), []byte(k)) == 1 {
+			valid = true
+		}
+	}
+	return valid
+}
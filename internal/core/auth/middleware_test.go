@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupApp registers a protected and a public route on a fresh Fiber app.
+func setupApp(enabled bool, keys []string) *fiber.App {
+	app := fiber.New()
+	ok := func(c *fiber.Ctx) error { return c.SendString("ok") }
+	app.Get("/protected", RequireAPIKey(enabled, keys), ok)
+	app.Get("/public", ok)
+	return app
+}
+
+// TestRequireAPIKey verifies only requests with a configured bearer key reach protected routes.
+func TestRequireAPIKey(t *testing.T) {
+	app := setupApp(true, []string{"key-1", "key-2"})
+
+	tests := []struct {
+		name          string
+		authorization string
+		status        int
+	}{
+		{"first key", "Bearer key-1", 200},
+		{"second key", "Bearer key-2", 200},
+		{"missing header", "", 401},
+		{"unknown key", "Bearer key-3", 401},
+		{"wrong scheme", "Basic key-1", 401},
+		{"empty bearer", "Bearer ", 401},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/protected", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+		})
+	}
+}
+
+// TestRequireAPIKey_ErrorResponse verifies rejected requests get a JSON error and a challenge header.
+func TestRequireAPIKey_ErrorResponse(t *testing.T) {
+	app := setupApp(true, []string{"key-1"})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/protected", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 401, resp.StatusCode)
+	assert.Equal(t, "Bearer", resp.Header.Get("WWW-Authenticate"))
+
+	var body ErrorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "missing or invalid API key", body.Message)
+	assert.Equal(t, "unknown", body.RayID)
+}
+
+// TestRequireAPIKey_PublicRoute verifies routes without the middleware stay public.
+func TestRequireAPIKey_PublicRoute(t *testing.T) {
+	app := setupApp(true, []string{"key-1"})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/public", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+}
+
+// TestRequireAPIKey_Disabled verifies every request passes when auth is disabled.
+func TestRequireAPIKey_Disabled(t *testing.T) {
+	app := setupApp(false, nil)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/protected", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+}
+
+// TestRequireAPIKey_NoKeys verifies an enabled middleware without keys rejects everything.
+func TestRequireAPIKey_NoKeys(t *testing.T) {
+	app := setupApp(true, nil)
+
+	req := httptest.NewRequest("GET", "/protected", nil)
+	req.Header.Set("Authorization", "Bearer ")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, 401, resp.StatusCode)
+}
+
+// TestMarkAdmin verifies only admin keys are flagged and no request is rejected.
+func TestMarkAdmin(t *testing.T) {
+	tests := []struct {
+		name          string
+		enabled       bool
+		authorization string
+		admin         bool
+	}{
+		{"admin key", true, "Bearer admin-1", true},
+		{"ordinary key", true, "Bearer key-1", false},
+		{"missing header", true, "", false},
+		{"wrong scheme", true, "Basic admin-1", false},
+		{"auth disabled", false, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", MarkAdmin(tt.enabled, []string{"admin-1"}), func(c *fiber.Ctx) error {
+				return c.JSON(IsAdmin(c))
+			})
+
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, 200, resp.StatusCode)
+
+			var admin bool
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&admin))
+			assert.Equal(t, tt.admin, admin)
+		})
+	}
+}
+
+// TestIsAdmin_Unmarked verifies requests that never passed MarkAdmin are not admin.
+func TestIsAdmin_Unmarked(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error { return c.JSON(IsAdmin(c)) })
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+	require.NoError(t, err)
+
+	var admin bool
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&admin))
+	assert.False(t, admin)
+}
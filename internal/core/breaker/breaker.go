@@ -0,0 +1,166 @@
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned by Allow while the breaker is open or a half-open probe is in flight.
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State is the state of a Breaker.
+type State int
+
+// Breaker states.
+const (
+	// StateClosed lets every call through.
+	StateClosed State = iota
+	// StateHalfOpen lets a single probe call through after the cooldown.
+	StateHalfOpen
+	// StateOpen fast-fails every call until the cooldown elapses.
+	StateOpen
+)
+
+// String returns the lowercase name of the state.
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateHalfOpen:
+		return "half-open"
+	case StateOpen:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+// Breaker opens after a number of consecutive failures and fast-fails calls for a cooldown
+// period, then lets a single probe through to decide whether to close again.
+// A nil *Breaker allows every call.
+type Breaker struct {
+	threshold int
+	cooldown  time.Duration
+	// onChange is called with the new state on every transition.
+	onChange func(State)
+	// now returns the current time; replaced in tests.
+	now func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// New creates a closed Breaker that opens after threshold consecutive failures.
+// onChange may be nil.
+func New(threshold int, cooldown time.Duration, onChange func(State)) *Breaker {
+	return &Breaker{
+		threshold: max(threshold, 1),
+		cooldown:  cooldown,
+		onChange:  onChange,
+		now:       time.Now,
+	}
+}
+
+// Allow reports whether a call may proceed. Every allowed call must be followed by Record or Skip.
+func (b *Breaker) Allow() error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return ErrOpen
+		}
+		b.setState(StateHalfOpen)
+		b.probing = true
+		return nil
+	case StateHalfOpen:
+		if b.probing {
+			return ErrOpen
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Record reports the result of a call allowed by Allow.
+func (b *Breaker) Record(err error) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+
+	if err == nil {
+		b.failures = 0
+		if b.state != StateClosed {
+			b.setState(StateClosed)
+		}
+		return
+	}
+
+	b.failures++
+	if b.state == StateHalfOpen || b.failures >= b.threshold {
+		b.openedAt = b.now()
+		b.setState(StateOpen)
+	}
+}
+
+// Skip reports that a call allowed by Allow never reached the dependency.
+// It counts as neither success nor failure and frees a half-open probe slot.
+func (b *Breaker) Skip() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// State returns the current state without triggering a transition.
+func (b *Breaker) State() State {
+	if b == nil {
+		return StateClosed
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// RetryAfter returns how long until an open breaker lets a probe through. It is zero while the
+// breaker is closed, half-open, or open with its cooldown already elapsed.
+func (b *Breaker) RetryAfter() time.Duration {
+	if b == nil {
+		return 0
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state != StateOpen {
+		return 0
+	}
+	return max(b.cooldown-b.now().Sub(b.openedAt), 0)
+}
+
+// setState changes the state and notifies onChange. Callers must hold mu.
+func (b *Breaker) setState(s State) {
+	b.state = s
+	if b.onChange != nil {
+		b.onChange(s)
+	}
+}
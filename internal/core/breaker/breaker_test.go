@@ -0,0 +1,123 @@
+package breaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestBreaker returns a breaker with a controllable clock.
+func newTestBreaker(threshold int, cooldown time.Duration) (*Breaker, *time.Time) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := New(threshold, cooldown, nil)
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+// TestBreaker_OpensAfterThreshold verifies consecutive failures open the breaker.
+func TestBreaker_OpensAfterThreshold(t *testing.T) {
+	b, _ := newTestBreaker(3, time.Minute)
+	failure := errors.New("timeout")
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, b.Allow())
+		b.Record(failure)
+	}
+	assert.Equal(t, StateClosed, b.State())
+
+	require.NoError(t, b.Allow())
+	b.Record(failure)
+
+	assert.Equal(t, StateOpen, b.State())
+	assert.ErrorIs(t, b.Allow(), ErrOpen)
+}
+
+// TestBreaker_SuccessResetsFailures verifies only consecutive failures count.
+func TestBreaker_SuccessResetsFailures(t *testing.T) {
+	b, _ := newTestBreaker(2, time.Minute)
+
+	b.Record(errors.New("boom"))
+	b.Record(nil)
+	b.Record(errors.New("boom"))
+
+	assert.Equal(t, StateClosed, b.State())
+}
+
+// TestBreaker_HalfOpenProbe verifies a single probe is allowed after the cooldown and decides the next state.
+func TestBreaker_HalfOpenProbe(t *testing.T) {
+	b, now := newTestBreaker(1, time.Minute)
+	b.Record(errors.New("boom"))
+	require.Equal(t, StateOpen, b.State())
+
+	*now = now.Add(time.Minute)
+	require.NoError(t, b.Allow())
+	assert.Equal(t, StateHalfOpen, b.State())
+	assert.ErrorIs(t, b.Allow(), ErrOpen, "only one probe may run at a time")
+
+	b.Record(errors.New("still down"))
+	assert.Equal(t, StateOpen, b.State())
+
+	*now = now.Add(time.Minute)
+	require.NoError(t, b.Allow())
+	b.Record(nil)
+	assert.Equal(t, StateClosed, b.State())
+	assert.NoError(t, b.Allow())
+}
+
+// TestBreaker_RetryAfter verifies the remaining cooldown is reported only while the breaker is open.
+func TestBreaker_RetryAfter(t *testing.T) {
+	b, now := newTestBreaker(1, time.Minute)
+	assert.Zero(t, b.RetryAfter())
+
+	b.Record(errors.New("boom"))
+	assert.Equal(t, time.Minute, b.RetryAfter())
+
+	*now = now.Add(45 * time.Second)
+	assert.Equal(t, 15*time.Second, b.RetryAfter())
+
+	*now = now.Add(time.Minute)
+	assert.Zero(t, b.RetryAfter(), "the cooldown has elapsed")
+
+	require.NoError(t, b.Allow())
+	assert.Zero(t, b.RetryAfter(), "a probe is running")
+
+	var nilBreaker *Breaker
+	assert.Zero(t, nilBreaker.RetryAfter())
+}
+
+// TestBreaker_OnChange verifies state transitions are reported.
+func TestBreaker_OnChange(t *testing.T) {
+	var states []State
+	b := New(1, 0, func(s State) { states = append(states, s) })
+
+	b.Record(errors.New("boom"))
+	require.NoError(t, b.Allow())
+	b.Record(nil)
+
+	assert.Equal(t, []State{StateOpen, StateHalfOpen, StateClosed}, states)
+}
+
+// TestBreaker_Nil verifies a nil breaker allows every call.
+func TestBreaker_Nil(t *testing.T) {
+	var b *Breaker
+
+	assert.NoError(t, b.Allow())
+	b.Record(errors.New("boom"))
+	assert.Equal(t, StateClosed, b.State())
+}
+
+// TestBreaker_SkipFreesProbe verifies a skipped probe lets the next call probe without changing the state.
+func TestBreaker_SkipFreesProbe(t *testing.T) {
+	b, now := newTestBreaker(1, time.Minute)
+	b.Record(errors.New("boom"))
+
+	*now = now.Add(time.Minute)
+	require.NoError(t, b.Allow())
+	b.Skip()
+
+	assert.Equal(t, StateHalfOpen, b.State())
+	assert.NoError(t, b.Allow())
+}
@@ -0,0 +1,19 @@
+package cache
+
+import (
+	"fmt"
+
+	"tracker-scrapper/internal/core/config"
+)
+
+// NewBackend creates the cache adapter selected by cfg.Backend (Redis unless set to "memcached").
+func NewBackend(cfg config.CacheConfig) (Cache, error) {
+	switch cfg.Backend {
+	case "", "redis":
+		return NewRedisAdapter(cfg.RedisURL)
+	case "memcached":
+		return NewMemcachedAdapter(cfg.MemcachedServers)
+	default:
+		return nil, fmt.Errorf("unsupported cache backend: %s", cfg.Backend)
+	}
+}
@@ -0,0 +1,26 @@
+package cache
+
+import (
+	"testing"
+
+	"tracker-scrapper/internal/core/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewBackend verifies the configured backend selects the matching adapter.
+func TestNewBackend(t *testing.T) {
+	c, err := NewBackend(config.CacheConfig{RedisURL: "redis://localhost:6379"})
+	require.NoError(t, err)
+	assert.IsType(t, &RedisAdapter{}, c)
+	c.Close()
+
+	c, err = NewBackend(config.CacheConfig{Backend: "memcached", MemcachedServers: []string{"localhost:11211"}})
+	require.NoError(t, err)
+	assert.IsType(t, &MemcachedAdapter{}, c)
+	c.Close()
+
+	_, err = NewBackend(config.CacheConfig{Backend: "valkey"})
+	assert.EqualError(t, err, "unsupported cache backend: valkey")
+}
@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// gzipMagic starts every gzip stream. Cached JSON and plain values never start with it, so
+// values written before compression was enabled are still read as-is.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// minCompressSize is the smallest value worth compressing; gzip adds about 20 bytes of framing.
+const minCompressSize = 256
+
+// CodecCache implements Cache by gzip-compressing values written to another cache and
+// decompressing them on reads. Values are recognized by the gzip magic header, so entries
+// written uncompressed (before it was enabled, or too small to compress) read unchanged.
+type CodecCache struct {
+	next Cache
+}
+
+// NewCodecCache creates a CodecCache that stores compressed values in next.
+func NewCodecCache(next Cache) *CodecCache {
+	return &CodecCache{next: next}
+}
+
+// Get retrieves and decompresses the value from the underlying cache.
+func (c *CodecCache) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := c.next.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return decode(key, value)
+}
+
+// Set compresses the value and stores it in the underlying cache.
+func (c *CodecCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	encoded, err := encode(key, value)
+	if err != nil {
+		return err
+	}
+	return c.next.Set(ctx, key, encoded, ttl)
+}
+
+// GetMulti retrieves and decompresses the values from the underlying cache.
+func (c *CodecCache) GetMulti(ctx context.Context, keys []string) (map[string][]byte, error) {
+	values, err := c.next.GetMulti(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range values {
+		if values[key], err = decode(key, value); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// SetMulti compresses the values and stores them in the underlying cache.
+func (c *CodecCache) SetMulti(ctx context.Context, entries map[string][]byte, ttl time.Duration) error {
+	encoded := make(map[string][]byte, len(entries))
+	for key, value := range entries {
+		var err error
+		if encoded[key], err = encode(key, value); err != nil {
+			return err
+		}
+	}
+	return c.next.SetMulti(ctx, encoded, ttl)
+}
+
+// Update atomically updates the key when the underlying cache supports it. fn sees and returns
+// decompressed values.
+func (c *CodecCache) Update(ctx context.Context, key string, fn UpdateFunc) error {
+	updater, ok := c.next.(Updater)
+	if !ok {
+		return ErrUpdateNotSupported
+	}
+	return updater.Update(ctx, key, func(current []byte) ([]byte, time.Duration, error) {
+		if current != nil {
+			var err error
+			if current, err = decode(key, current); err != nil {
+				return nil, 0, err
+			}
+		}
+		next, ttl, err := fn(current)
+		if err != nil {
+			return nil, 0, err
+		}
+		next, err = encode(key, next)
+		return next, ttl, err
+	})
+}
+
+// Delete removes the key from the underlying cache.
+func (c *CodecCache) Delete(ctx context.Context, key string) error {
+	return c.next.Delete(ctx, key)
+}
+
+// Ping checks the underlying cache.
+func (c *CodecCache) Ping(ctx context.Context) error {
+	return c.next.Ping(ctx)
+}
+
+// Close closes the underlying cache.
+func (c *CodecCache) Close() error {
+	return c.next.Close()
+}
+
+// encode gzip-compresses value, leaving values shorter than minCompressSize as they are.
+func encode(key string, value []byte) ([]byte, error) {
+	if len(value) < minCompressSize {
+		return value, nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(value); err != nil {
+		return nil, fmt.Errorf("failed to compress key %s: %w", key, err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress key %s: %w", key, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// decode decompresses value when it starts with the gzip magic header and returns it unchanged otherwise.
+func decode(key string, value []byte) ([]byte, error) {
+	if !bytes.HasPrefix(value, gzipMagic) {
+		return value, nil
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(value))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress key %s: %w", key, err)
+	}
+	defer zr.Close()
+
+	decoded, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress key %s: %w", key, err)
+	}
+	return decoded, nil
+}
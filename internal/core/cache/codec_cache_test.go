@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCodecCache verifies larger values are stored gzip-compressed and read back unchanged,
+// while small values are stored as they are.
+func TestCodecCache(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisAdapter, err := NewRedisAdapter("redis://" + mr.Addr())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	c := NewCodecCache(redisAdapter)
+	large := []byte(`{"history":"` + strings.Repeat("En transporte ", 100) + `"}`)
+
+	require.NoError(t, c.Set(ctx, "order_1", large, time.Hour))
+	stored, err := mr.Get("order_1")
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix([]byte(stored), gzipMagic))
+	assert.Less(t, len(stored), len(large))
+
+	value, err := c.Get(ctx, "order_1")
+	require.NoError(t, err)
+	assert.Equal(t, large, value)
+
+	require.NoError(t, c.SetMulti(ctx, map[string][]byte{"ts_a": large, "ts_b": []byte("b")}, time.Hour))
+	stored, err = mr.Get("ts_b")
+	require.NoError(t, err)
+	assert.Equal(t, "b", stored)
+
+	values, err := c.GetMulti(ctx, []string{"ts_a", "ts_b"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"ts_a": large, "ts_b": []byte("b")}, values)
+
+	require.NoError(t, c.Update(ctx, "order_1", func(current []byte) ([]byte, time.Duration, error) {
+		assert.Equal(t, large, current)
+		return []byte("updated"), 0, nil
+	}))
+	stored, err = mr.Get("order_1")
+	require.NoError(t, err)
+	assert.Equal(t, "updated", stored)
+}
+
+// TestCodecCache_LegacyValues verifies values written before compression was enabled are read as-is.
+func TestCodecCache_LegacyValues(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisAdapter, err := NewRedisAdapter("redis://" + mr.Addr())
+	require.NoError(t, err)
+
+	legacy := `{"history":"` + strings.Repeat("Entregado ", 100) + `"}`
+	require.NoError(t, mr.Set("order_1", legacy))
+
+	value, err := NewCodecCache(redisAdapter).Get(context.Background(), "order_1")
+
+	require.NoError(t, err)
+	assert.Equal(t, legacy, string(value))
+}
+
+// TestCodecCache_CorruptValue verifies a value with the gzip header that isn't valid gzip is reported.
+func TestCodecCache_CorruptValue(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisAdapter, err := NewRedisAdapter("redis://" + mr.Addr())
+	require.NoError(t, err)
+
+	require.NoError(t, mr.Set("order_1", string(gzipMagic)+"garbage"))
+
+	_, err = NewCodecCache(redisAdapter).Get(context.Background(), "order_1")
+
+	assert.ErrorContains(t, err, "failed to decompress key order_1")
+}
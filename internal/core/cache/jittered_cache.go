@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// Jitter returns ttl shifted by a random amount of up to ±pct percent, so entries written
+// together don't all expire at the same instant. A ttl of 0 (no expiration) or a pct of 0
+// is returned unchanged.
+func Jitter(ttl time.Duration, pct int) time.Duration {
+	if ttl <= 0 || pct <= 0 {
+		return ttl
+	}
+
+	spread := int64(ttl) * int64(pct) / 100
+	if spread == 0 {
+		return ttl
+	}
+	return ttl + time.Duration(rand.Int64N(2*spread+1)-spread)
+}
+
+// JitteredCache implements Cache by applying Jitter to the TTL of every write to another cache,
+// spreading the expiry of entries cached in a burst.
+type JitteredCache struct {
+	next Cache
+	pct  int
+}
+
+// NewJitteredCache creates a JitteredCache that writes to next with TTLs spread by ±pct percent.
+func NewJitteredCache(next Cache, pct int) *JitteredCache {
+	return &JitteredCache{next: next, pct: pct}
+}
+
+// Get retrieves the value from the underlying cache.
+func (j *JitteredCache) Get(ctx context.Context, key string) ([]byte, error) {
+	return j.next.Get(ctx, key)
+}
+
+// Set stores the value with a jittered TTL.
+func (j *JitteredCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return j.next.Set(ctx, key, value, Jitter(ttl, j.pct))
+}
+
+// GetMulti retrieves the values from the underlying cache.
+func (j *JitteredCache) GetMulti(ctx context.Context, keys []string) (map[string][]byte, error) {
+	return j.next.GetMulti(ctx, keys)
+}
+
+// SetMulti stores the values with a TTL jittered per key, so a batch doesn't expire all at once.
+// Keys that end up with the same TTL (all of them when jitter is disabled) are written in one call.
+func (j *JitteredCache) SetMulti(ctx context.Context, entries map[string][]byte, ttl time.Duration) error {
+	groups := make(map[time.Duration]map[string][]byte)
+	for key, value := range entries {
+		jittered := Jitter(ttl, j.pct)
+		if groups[jittered] == nil {
+			groups[jittered] = make(map[string][]byte)
+		}
+		groups[jittered][key] = value
+	}
+
+	for jittered, group := range groups {
+		if err := j.next.SetMulti(ctx, group, jittered); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Update atomically updates the key when the underlying cache supports it, jittering the TTL fn returns.
+func (j *JitteredCache) Update(ctx context.Context, key string, fn UpdateFunc) error {
+	updater, ok := j.next.(Updater)
+	if !ok {
+		return ErrUpdateNotSupported
+	}
+	return updater.Update(ctx, key, func(current []byte) ([]byte, time.Duration, error) {
+		next, ttl, err := fn(current)
+		return next, Jitter(ttl, j.pct), err
+	})
+}
+
+// Delete removes the key from the underlying cache.
+func (j *JitteredCache) Delete(ctx context.Context, key string) error {
+	return j.next.Delete(ctx, key)
+}
+
+// Ping checks the underlying cache.
+func (j *JitteredCache) Ping(ctx context.Context) error {
+	return j.next.Ping(ctx)
+}
+
+// Close closes the underlying cache.
+func (j *JitteredCache) Close() error {
+	return j.next.Close()
+}
@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestJitter verifies TTLs stay within ±pct and that disabled jitter or no expiration is left alone.
+func TestJitter(t *testing.T) {
+	ttl := time.Hour
+	spread := 6 * time.Minute
+
+	seen := map[time.Duration]bool{}
+	for range 200 {
+		got := Jitter(ttl, 10)
+		assert.GreaterOrEqual(t, got, ttl-spread)
+		assert.LessOrEqual(t, got, ttl+spread)
+		seen[got] = true
+	}
+	assert.Greater(t, len(seen), 1, "jitter should vary the TTL")
+
+	assert.Equal(t, ttl, Jitter(ttl, 0))
+	assert.Equal(t, time.Duration(0), Jitter(0, 10))
+}
+
+// TestJitteredCache verifies writes reach the underlying cache with a jittered TTL.
+func TestJitteredCache(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisAdapter, err := NewRedisAdapter("redis://" + mr.Addr())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	c := NewJitteredCache(redisAdapter, 10)
+
+	require.NoError(t, c.Set(ctx, "order_1", []byte("1"), time.Hour))
+	ttl := mr.TTL("order_1")
+	assert.GreaterOrEqual(t, ttl, 54*time.Minute)
+	assert.LessOrEqual(t, ttl, 66*time.Minute)
+
+	value, err := c.Get(ctx, "order_1")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1"), value)
+
+	require.NoError(t, c.SetMulti(ctx, map[string][]byte{"ts_a": []byte("a")}, time.Hour))
+	assert.InDelta(t, float64(time.Hour), float64(mr.TTL("ts_a")), float64(6*time.Minute))
+
+	require.NoError(t, c.Update(ctx, "banner", func([]byte) ([]byte, time.Duration, error) {
+		return []byte("b"), 0, nil
+	}))
+	assert.Equal(t, time.Duration(0), mr.TTL("banner"))
+
+	require.NoError(t, c.Delete(ctx, "order_1"))
+	assert.False(t, mr.Exists("order_1"))
+}
+
+// TestJitteredCache_SetMultiPerKey verifies keys written in one batch get their own jittered TTLs.
+func TestJitteredCache_SetMultiPerKey(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisAdapter, err := NewRedisAdapter("redis://" + mr.Addr())
+	require.NoError(t, err)
+
+	entries := map[string][]byte{}
+	for i := range 20 {
+		entries[fmt.Sprintf("ts_%d", i)] = []byte("v")
+	}
+	require.NoError(t, NewJitteredCache(redisAdapter, 10).SetMulti(context.Background(), entries, time.Hour))
+
+	ttls := map[time.Duration]bool{}
+	for key := range entries {
+		ttl := mr.TTL(key)
+		assert.InDelta(t, float64(time.Hour), float64(ttl), float64(6*time.Minute), key)
+		ttls[ttl] = true
+	}
+	assert.Greater(t, len(ttls), 1, "keys in one batch should not share a TTL")
+}
@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// lruEntry is a value stored in an lru with its expiry.
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// lru is a size-bounded, concurrency-safe in-memory cache with per-entry expiry.
+// The least recently used entry is evicted when the size limit is reached.
+type lru struct {
+	size int
+	// now returns the current time; replaced in tests.
+	now func() time.Time
+
+	mu    sync.Mutex
+	order *list.List
+	items map[string]*list.Element
+}
+
+// newLRU creates an lru holding at most size entries.
+func newLRU(size int) *lru {
+	return &lru{
+		size:  size,
+		now:   time.Now,
+		order: list.New(),
+		items: make(map[string]*list.Element, size),
+	}
+}
+
+// get returns the value for key if present and not expired.
+func (c *lru) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if !c.now().Before(entry.expiresAt) {
+		c.removeElement(elem)
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+	return entry.value, true
+}
+
+// set stores value under key for ttl, evicting the least recently used entry if full.
+func (c *lru) set(key string, value []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.now().Add(ttl)
+
+	if elem, ok := c.items[key]; ok {
+		entry := elem.Value.(*lruEntry)
+		entry.value, entry.expiresAt = value, expiresAt
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	if c.order.Len() >= c.size {
+		c.removeElement(c.order.Back())
+	}
+
+	c.items[key] = c.order.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+}
+
+// remove deletes key if present.
+func (c *lru) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.removeElement(elem)
+	}
+}
+
+// len returns the number of stored entries, including expired ones not yet evicted.
+func (c *lru) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// removeElement unlinks elem. Callers must hold mu.
+func (c *lru) removeElement(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.items, elem.Value.(*lruEntry).key)
+}
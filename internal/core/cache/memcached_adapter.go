@@ -0,0 +1,171 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+)
+
+// maxRelativeExpiration is the longest TTL memcached reads as a number of seconds;
+// longer expirations must be sent as a Unix timestamp.
+const maxRelativeExpiration = 30 * 24 * time.Hour
+
+// memcacheClient is the part of *memcache.Client the adapter uses; replaced in tests.
+type memcacheClient interface {
+	Get(key string) (*memcache.Item, error)
+	GetMulti(keys []string) (map[string]*memcache.Item, error)
+	Set(item *memcache.Item) error
+	Add(item *memcache.Item) error
+	CompareAndSwap(item *memcache.Item) error
+	Delete(key string) error
+	Ping() error
+	Close() error
+}
+
+// MemcachedAdapter implements the Cache interface using Memcached.
+type MemcachedAdapter struct {
+	client memcacheClient
+	// now returns the current time; replaced in tests.
+	now func() time.Time
+}
+
+// NewMemcachedAdapter creates a new Memcached cache adapter.
+// Each server is a host:port address; keys are spread across them.
+func NewMemcachedAdapter(servers []string) (*MemcachedAdapter, error) {
+	if len(servers) == 0 {
+		return nil, errors.New("no memcached servers configured")
+	}
+
+	var selector memcache.ServerList
+	if err := selector.SetServers(servers...); err != nil {
+		return nil, fmt.Errorf("failed to parse memcached servers: %w", err)
+	}
+
+	return &MemcachedAdapter{
+		client: memcache.NewFromSelector(&selector),
+		now:    time.Now,
+	}, nil
+}
+
+// Get retrieves a value from Memcached by key.
+func (m *MemcachedAdapter) Get(ctx context.Context, key string) ([]byte, error) {
+	item, err := m.client.Get(key)
+	if errors.Is(err, memcache.ErrCacheMiss) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get key %s: %w", key, err)
+	}
+	return item.Value, nil
+}
+
+// Set stores a value in Memcached with the specified TTL.
+func (m *MemcachedAdapter) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	err := m.client.Set(&memcache.Item{Key: key, Value: value, Expiration: m.expiration(ttl)})
+	if err != nil {
+		return fmt.Errorf("failed to set key %s: %w", key, err)
+	}
+	return nil
+}
+
+// GetMulti retrieves several values from Memcached with a single multi-key get per server.
+func (m *MemcachedAdapter) GetMulti(ctx context.Context, keys []string) (map[string][]byte, error) {
+	result := make(map[string][]byte, len(keys))
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	items, err := m.client.GetMulti(keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %d keys: %w", len(keys), err)
+	}
+
+	for key, item := range items {
+		result[key] = item.Value
+	}
+	return result, nil
+}
+
+// SetMulti stores several values in Memcached. The protocol has no multi-set, so each key
+// is written with its own SET.
+func (m *MemcachedAdapter) SetMulti(ctx context.Context, entries map[string][]byte, ttl time.Duration) error {
+	expiration := m.expiration(ttl)
+	for key, value := range entries {
+		if err := m.client.Set(&memcache.Item{Key: key, Value: value, Expiration: expiration}); err != nil {
+			return fmt.Errorf("failed to set %d keys: %w", len(entries), err)
+		}
+	}
+	return nil
+}
+
+// Update applies fn to key and writes the result with a compare-and-swap (or an add when key
+// does not exist yet), so the write fails with ErrConflict when key is modified in between.
+func (m *MemcachedAdapter) Update(ctx context.Context, key string, fn UpdateFunc) error {
+	item, err := m.client.Get(key)
+	if err != nil && !errors.Is(err, memcache.ErrCacheMiss) {
+		return fmt.Errorf("failed to get key %s: %w", key, err)
+	}
+
+	var current []byte
+	if item != nil {
+		current = item.Value
+	}
+
+	next, ttl, err := fn(current)
+	if err != nil {
+		return err
+	}
+
+	if item == nil {
+		err = m.client.Add(&memcache.Item{Key: key, Value: next, Expiration: m.expiration(ttl)})
+	} else {
+		item.Value = next
+		item.Expiration = m.expiration(ttl)
+		err = m.client.CompareAndSwap(item)
+	}
+
+	switch {
+	case errors.Is(err, memcache.ErrNotStored), errors.Is(err, memcache.ErrCASConflict), errors.Is(err, memcache.ErrCacheMiss):
+		return fmt.Errorf("%w: %s", ErrConflict, key)
+	case err != nil:
+		return fmt.Errorf("failed to set key %s: %w", key, err)
+	}
+	return nil
+}
+
+// Delete removes a value from Memcached by key. Deleting a missing key is not an error.
+func (m *MemcachedAdapter) Delete(ctx context.Context, key string) error {
+	err := m.client.Delete(key)
+	if err != nil && !errors.Is(err, memcache.ErrCacheMiss) {
+		return fmt.Errorf("failed to delete key %s: %w", key, err)
+	}
+	return nil
+}
+
+// Ping checks if every Memcached server is reachable.
+func (m *MemcachedAdapter) Ping(ctx context.Context) error {
+	if err := m.client.Ping(); err != nil {
+		return fmt.Errorf("memcached ping failed: %w", err)
+	}
+	return nil
+}
+
+// Close closes the idle Memcached connections.
+func (m *MemcachedAdapter) Close() error {
+	return m.client.Close()
+}
+
+// expiration converts ttl to a memcached expiration: 0 for no expiration, whole seconds
+// (rounded up) up to 30 days, and an absolute Unix timestamp beyond that.
+func (m *MemcachedAdapter) expiration(ttl time.Duration) int32 {
+	if ttl <= 0 {
+		return 0
+	}
+	if ttl > maxRelativeExpiration {
+		return int32(m.now().Add(ttl).Unix())
+	}
+	return int32((ttl + time.Second - 1) / time.Second)
+}
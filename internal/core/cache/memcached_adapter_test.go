@@ -0,0 +1,268 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMemcache is an in-memory memcacheClient with compare-and-swap support.
+type fakeMemcache struct {
+	items  map[string]*memcache.Item
+	casIDs map[string]uint64
+	// setErr, when set, is returned by Set.
+	setErr error
+	// beforeWrite, when set, runs before Add and CompareAndSwap to simulate a concurrent writer.
+	beforeWrite func()
+	nextCAS     uint64
+}
+
+func newFakeMemcache() *fakeMemcache {
+	return &fakeMemcache{items: map[string]*memcache.Item{}, casIDs: map[string]uint64{}}
+}
+
+func (f *fakeMemcache) store(item *memcache.Item) {
+	f.nextCAS++
+	stored := *item
+	f.items[item.Key] = &stored
+	f.casIDs[item.Key] = f.nextCAS
+}
+
+func (f *fakeMemcache) Get(key string) (*memcache.Item, error) {
+	item, ok := f.items[key]
+	if !ok {
+		return nil, memcache.ErrCacheMiss
+	}
+	copied := *item
+	return &copied, nil
+}
+
+func (f *fakeMemcache) GetMulti(keys []string) (map[string]*memcache.Item, error) {
+	result := map[string]*memcache.Item{}
+	for _, key := range keys {
+		if item, ok := f.items[key]; ok {
+			result[key] = item
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeMemcache) Set(item *memcache.Item) error {
+	if f.setErr != nil {
+		return f.setErr
+	}
+	f.store(item)
+	return nil
+}
+
+func (f *fakeMemcache) Add(item *memcache.Item) error {
+	if f.beforeWrite != nil {
+		f.beforeWrite()
+	}
+	if _, ok := f.items[item.Key]; ok {
+		return memcache.ErrNotStored
+	}
+	f.store(item)
+	return nil
+}
+
+// CompareAndSwap stores item unless the key is gone or was rewritten by beforeWrite, which stands
+// in for a writer racing between the caller's Get and this call.
+func (f *fakeMemcache) CompareAndSwap(item *memcache.Item) error {
+	seen := f.casIDs[item.Key]
+	if f.beforeWrite != nil {
+		f.beforeWrite()
+	}
+	if _, ok := f.items[item.Key]; !ok {
+		return memcache.ErrNotStored
+	}
+	if f.casIDs[item.Key] != seen {
+		return memcache.ErrCASConflict
+	}
+	f.store(item)
+	return nil
+}
+
+func (f *fakeMemcache) Delete(key string) error {
+	if _, ok := f.items[key]; !ok {
+		return memcache.ErrCacheMiss
+	}
+	delete(f.items, key)
+	return nil
+}
+
+func (f *fakeMemcache) Ping() error {
+	return nil
+}
+
+func (f *fakeMemcache) Close() error {
+	return nil
+}
+
+// newTestMemcachedAdapter returns an adapter backed by a fakeMemcache and a fixed clock.
+func newTestMemcachedAdapter() (*MemcachedAdapter, *fakeMemcache) {
+	fake := newFakeMemcache()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	return &MemcachedAdapter{client: fake, now: func() time.Time { return now }}, fake
+}
+
+// TestMemcachedAdapter_GetSet verifies values round-trip and misses wrap ErrNotFound.
+func TestMemcachedAdapter_GetSet(t *testing.T) {
+	adapter, _ := newTestMemcachedAdapter()
+	ctx := context.Background()
+
+	require.NoError(t, adapter.Set(ctx, "test_key", []byte("test_value"), 10*time.Second))
+
+	value, err := adapter.Get(ctx, "test_key")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("test_value"), value)
+
+	_, err = adapter.Get(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.EqualError(t, err, "key not found: missing")
+}
+
+// TestMemcachedAdapter_Expiration verifies TTLs map to memcached's relative and absolute expirations.
+func TestMemcachedAdapter_Expiration(t *testing.T) {
+	adapter, fake := newTestMemcachedAdapter()
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		ttl  time.Duration
+		want int32
+	}{
+		{"no expiration", 0, 0},
+		{"seconds", 90 * time.Second, 90},
+		{"rounds up", 1500 * time.Millisecond, 2},
+		{"thirty days stays relative", maxRelativeExpiration, int32(maxRelativeExpiration / time.Second)},
+		{"longer becomes a timestamp", 31 * 24 * time.Hour, int32(adapter.now().Add(31 * 24 * time.Hour).Unix())},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, adapter.Set(ctx, "key", []byte("v"), tt.ttl))
+			assert.Equal(t, tt.want, fake.items["key"].Expiration)
+		})
+	}
+}
+
+// TestMemcachedAdapter_Multi verifies GetMulti omits missing keys and SetMulti writes every entry.
+func TestMemcachedAdapter_Multi(t *testing.T) {
+	adapter, fake := newTestMemcachedAdapter()
+	ctx := context.Background()
+
+	require.NoError(t, adapter.SetMulti(ctx, map[string][]byte{"a": []byte("1"), "b": []byte("2")}, time.Minute))
+	assert.Equal(t, int32(60), fake.items["b"].Expiration)
+
+	values, err := adapter.GetMulti(ctx, []string{"a", "b", "c"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"a": []byte("1"), "b": []byte("2")}, values)
+
+	fake.setErr = errors.New("connection reset")
+	assert.ErrorContains(t, adapter.SetMulti(ctx, map[string][]byte{"a": []byte("1")}, 0), "failed to set 1 keys")
+}
+
+// TestMemcachedAdapter_Delete verifies deletes remove the key and ignore missing keys.
+func TestMemcachedAdapter_Delete(t *testing.T) {
+	adapter, _ := newTestMemcachedAdapter()
+	ctx := context.Background()
+
+	require.NoError(t, adapter.Set(ctx, "key", []byte("v"), 0))
+	require.NoError(t, adapter.Delete(ctx, "key"))
+
+	_, err := adapter.Get(ctx, "key")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, adapter.Delete(ctx, "key"))
+}
+
+// TestMemcachedAdapter_Update verifies updates create and replace keys and report concurrent writes as ErrConflict.
+func TestMemcachedAdapter_Update(t *testing.T) {
+	ctx := context.Background()
+	appendX := func(current []byte) ([]byte, time.Duration, error) {
+		return append(current, 'x'), time.Minute, nil
+	}
+
+	t.Run("CreatesAndReplaces", func(t *testing.T) {
+		adapter, _ := newTestMemcachedAdapter()
+
+		require.NoError(t, adapter.Update(ctx, "key", appendX))
+		require.NoError(t, adapter.Update(ctx, "key", appendX))
+
+		value, err := adapter.Get(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("xx"), value)
+	})
+
+	t.Run("ConflictOnCreate", func(t *testing.T) {
+		adapter, fake := newTestMemcachedAdapter()
+		fake.beforeWrite = func() { fake.store(&memcache.Item{Key: "key", Value: []byte("other")}) }
+
+		assert.ErrorIs(t, adapter.Update(ctx, "key", appendX), ErrConflict)
+	})
+
+	t.Run("ConflictOnReplace", func(t *testing.T) {
+		adapter, fake := newTestMemcachedAdapter()
+		require.NoError(t, adapter.Set(ctx, "key", []byte("v"), 0))
+		fake.beforeWrite = func() { fake.store(&memcache.Item{Key: "key", Value: []byte("other")}) }
+
+		assert.ErrorIs(t, adapter.Update(ctx, "key", appendX), ErrConflict)
+	})
+
+	t.Run("FuncErrorAborts", func(t *testing.T) {
+		adapter, _ := newTestMemcachedAdapter()
+		boom := errors.New("boom")
+
+		err := adapter.Update(ctx, "key", func([]byte) ([]byte, time.Duration, error) { return nil, 0, boom })
+
+		assert.ErrorIs(t, err, boom)
+		_, err = adapter.Get(ctx, "key")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+// TestNewMemcachedAdapter_NoServers verifies an empty server list is rejected.
+func TestNewMemcachedAdapter_NoServers(t *testing.T) {
+	_, err := NewMemcachedAdapter(nil)
+	assert.EqualError(t, err, "no memcached servers configured")
+}
+
+// TestMemcachedAdapter_Integration runs the adapter against a real server when MEMCACHED_ADDR is set.
+func TestMemcachedAdapter_Integration(t *testing.T) {
+	addr := os.Getenv("MEMCACHED_ADDR")
+	if addr == "" {
+		t.Skip("MEMCACHED_ADDR not set")
+	}
+
+	adapter, err := NewMemcachedAdapter([]string{addr})
+	require.NoError(t, err)
+	defer adapter.Close()
+
+	ctx := context.Background()
+	require.NoError(t, adapter.Ping(ctx))
+
+	key := "tracker_scrapper_test:" + time.Now().Format("150405.000000")
+	defer adapter.Delete(ctx, key)
+
+	require.NoError(t, adapter.Set(ctx, key, []byte("value"), time.Minute))
+	value, err := adapter.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), value)
+
+	require.NoError(t, adapter.Update(ctx, key, func(current []byte) ([]byte, time.Duration, error) {
+		return append(current, '!'), time.Minute, nil
+	}))
+	value, err = adapter.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value!"), value)
+
+	require.NoError(t, adapter.Delete(ctx, key))
+	_, err = adapter.Get(ctx, key)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
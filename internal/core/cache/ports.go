@@ -2,20 +2,48 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
+// ErrNotFound is returned, wrapped with the key, when a key does not exist in the cache.
+var ErrNotFound = errors.New("key not found")
+
+// ErrConflict is returned, wrapped with the key, when a key changed during an Update.
+var ErrConflict = errors.New("key modified concurrently")
+
+// ErrUpdateNotSupported is returned when Update is called on a cache that can't update atomically.
+var ErrUpdateNotSupported = errors.New("cache does not support atomic updates")
+
+// UpdateFunc computes the new value of a key from its current value, which is nil when the key
+// does not exist. Returning an error aborts the update and is passed to the caller unchanged.
+type UpdateFunc func(current []byte) (next []byte, ttl time.Duration, err error)
+
+// Updater is implemented by caches that can read and write a key atomically.
+type Updater interface {
+	// Update applies fn to the current value of key and stores the result, failing with
+	// ErrConflict when key was written by someone else in between.
+	Update(ctx context.Context, key string, fn UpdateFunc) error
+}
+
 // Cache defines the caching operations interface following hexagonal architecture.
 // This is a port that can be implemented by different cache providers (Redis, Memcached, etc.).
 type Cache interface {
 	// Get retrieves a value from the cache by key.
-	// Returns the cached value or an error if not found or on failure.
+	// Returns the cached value, an error wrapping ErrNotFound if not found, or an error on failure.
 	Get(ctx context.Context, key string) ([]byte, error)
 
 	// Set stores a value in the cache with the specified key and TTL.
 	// TTL of 0 means no expiration.
 	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
 
+	// GetMulti retrieves several values in one round trip.
+	// Missing keys are omitted from the returned map; an error is returned only on failure.
+	GetMulti(ctx context.Context, keys []string) (map[string][]byte, error)
+
+	// SetMulti stores several values in one round trip, all with the same TTL.
+	SetMulti(ctx context.Context, entries map[string][]byte, ttl time.Duration) error
+
 	// Delete removes a value from the cache by key.
 	Delete(ctx context.Context, key string) error
 
@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Key returns name namespaced under prefix as "{prefix}:{name}", or name unchanged when prefix is empty.
+func Key(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + ":" + name
+}
+
+// PrefixedCache implements Cache by namespacing every key of another cache under a prefix,
+// so several environments can share one Redis instance without key collisions.
+type PrefixedCache struct {
+	next   Cache
+	prefix string
+}
+
+// NewPrefixedCache creates a PrefixedCache that stores keys in next under prefix.
+func NewPrefixedCache(next Cache, prefix string) *PrefixedCache {
+	return &PrefixedCache{next: next, prefix: prefix}
+}
+
+// Get retrieves the value stored under the prefixed key.
+func (p *PrefixedCache) Get(ctx context.Context, key string) ([]byte, error) {
+	return p.next.Get(ctx, Key(p.prefix, key))
+}
+
+// Set stores the value under the prefixed key.
+func (p *PrefixedCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return p.next.Set(ctx, Key(p.prefix, key), value, ttl)
+}
+
+// GetMulti retrieves the values stored under the prefixed keys, keyed by the unprefixed keys.
+func (p *PrefixedCache) GetMulti(ctx context.Context, keys []string) (map[string][]byte, error) {
+	prefixed := make([]string, len(keys))
+	names := make(map[string]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = Key(p.prefix, key)
+		names[prefixed[i]] = key
+	}
+
+	values, err := p.next.GetMulti(ctx, prefixed)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]byte, len(values))
+	for key, value := range values {
+		result[names[key]] = value
+	}
+	return result, nil
+}
+
+// SetMulti stores the values under the prefixed keys.
+func (p *PrefixedCache) SetMulti(ctx context.Context, entries map[string][]byte, ttl time.Duration) error {
+	prefixed := make(map[string][]byte, len(entries))
+	for key, value := range entries {
+		prefixed[Key(p.prefix, key)] = value
+	}
+	return p.next.SetMulti(ctx, prefixed, ttl)
+}
+
+// Update atomically updates the prefixed key when the underlying cache supports it.
+func (p *PrefixedCache) Update(ctx context.Context, key string, fn UpdateFunc) error {
+	updater, ok := p.next.(Updater)
+	if !ok {
+		return ErrUpdateNotSupported
+	}
+	return updater.Update(ctx, Key(p.prefix, key), fn)
+}
+
+// Delete removes the prefixed key.
+func (p *PrefixedCache) Delete(ctx context.Context, key string) error {
+	return p.next.Delete(ctx, Key(p.prefix, key))
+}
+
+// Ping checks the underlying cache.
+func (p *PrefixedCache) Ping(ctx context.Context) error {
+	return p.next.Ping(ctx)
+}
+
+// Close closes the underlying cache.
+func (p *PrefixedCache) Close() error {
+	return p.next.Close()
+}
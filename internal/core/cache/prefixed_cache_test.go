@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestKey verifies keys are namespaced only when a prefix is set.
+func TestKey(t *testing.T) {
+	assert.Equal(t, "dev:order_1", Key("dev", "order_1"))
+	assert.Equal(t, "order_1", Key("", "order_1"))
+}
+
+// TestPrefixedCache verifies every operation uses the prefixed key in the underlying cache.
+func TestPrefixedCache(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisAdapter, err := NewRedisAdapter("redis://" + mr.Addr())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	dev := NewPrefixedCache(redisAdapter, "dev")
+	prod := NewPrefixedCache(redisAdapter, "prod")
+
+	require.NoError(t, dev.Set(ctx, "site_banner", []byte("dev"), time.Minute))
+	require.NoError(t, prod.Set(ctx, "site_banner", []byte("prod"), time.Minute))
+	assert.True(t, mr.Exists("dev:site_banner"))
+	assert.True(t, mr.Exists("prod:site_banner"))
+
+	value, err := dev.Get(ctx, "site_banner")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("dev"), value)
+
+	require.NoError(t, dev.SetMulti(ctx, map[string][]byte{"ts_a": []byte("1")}, time.Minute))
+	values, err := dev.GetMulti(ctx, []string{"ts_a", "site_banner", "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"ts_a": []byte("1"), "site_banner": []byte("dev")}, values)
+
+	require.NoError(t, dev.Delete(ctx, "site_banner"))
+	_, err = dev.Get(ctx, "site_banner")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.True(t, mr.Exists("prod:site_banner"))
+}
+
+// TestPrefixedCache_Update verifies updates use the prefixed key and need an Updater underneath.
+func TestPrefixedCache_Update(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisAdapter, err := NewRedisAdapter("redis://" + mr.Addr())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	set := func([]byte) ([]byte, time.Duration, error) { return []byte("v1"), 0, nil }
+	require.NoError(t, NewPrefixedCache(redisAdapter, "dev").Update(ctx, "site_banner", set))
+	assert.True(t, mr.Exists("dev:site_banner"))
+
+	tiered := NewTieredCache(redisAdapter, 10, time.Minute)
+	assert.ErrorIs(t, NewPrefixedCache(tiered, "dev").Update(ctx, "site_banner", set), ErrUpdateNotSupported)
+}
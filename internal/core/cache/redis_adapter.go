@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -30,7 +31,7 @@ func NewRedisAdapter(redisURL string) (*RedisAdapter, error) {
 func (r *RedisAdapter) Get(ctx context.Context, key string) ([]byte, error) {
 	val, err := r.client.Get(ctx, key).Bytes()
 	if err == redis.Nil {
-		return nil, fmt.Errorf("key not found: %s", key)
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get key %s: %w", key, err)
@@ -47,6 +48,72 @@ func (r *RedisAdapter) Set(ctx context.Context, key string, value []byte, ttl ti
 	return nil
 }
 
+// GetMulti retrieves several values from Redis with a single MGET.
+func (r *RedisAdapter) GetMulti(ctx context.Context, keys []string) (map[string][]byte, error) {
+	result := make(map[string][]byte, len(keys))
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %d keys: %w", len(keys), err)
+	}
+
+	for i, value := range values {
+		// MGET returns nil for missing keys and strings otherwise
+		if s, ok := value.(string); ok {
+			result[keys[i]] = []byte(s)
+		}
+	}
+	return result, nil
+}
+
+// SetMulti stores several values in Redis with a single pipelined round trip.
+// MSET cannot set a TTL, so each key is written with its own SET.
+func (r *RedisAdapter) SetMulti(ctx context.Context, entries map[string][]byte, ttl time.Duration) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for key, value := range entries {
+			pipe.Set(ctx, key, value, ttl)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set %d keys: %w", len(entries), err)
+	}
+	return nil
+}
+
+// Update applies fn to key inside a WATCH/MULTI transaction, so the write fails with
+// ErrConflict when key is modified between the read and the write.
+func (r *RedisAdapter) Update(ctx context.Context, key string, fn UpdateFunc) error {
+	err := r.client.Watch(ctx, func(tx *redis.Tx) error {
+		current, err := tx.Get(ctx, key).Bytes()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return fmt.Errorf("failed to get key %s: %w", key, err)
+		}
+
+		next, ttl, err := fn(current)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, next, ttl)
+			return nil
+		})
+		return err
+	}, key)
+	if errors.Is(err, redis.TxFailedErr) {
+		return fmt.Errorf("%w: %s", ErrConflict, key)
+	}
+	return err
+}
+
 // Delete removes a value from Redis by key.
 func (r *RedisAdapter) Delete(ctx context.Context, key string) error {
 	err := r.client.Del(ctx, key).Err()
@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -102,6 +103,42 @@ func TestRedisAdapter_TTL(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestRedisAdapter_GetSetMulti(t *testing.T) {
+	mr := miniredis.RunT(t)
+	defer mr.Close()
+
+	adapter, err := NewRedisAdapter("redis://" + mr.Addr())
+	require.NoError(t, err)
+	defer adapter.Close()
+
+	ctx := context.Background()
+
+	err = adapter.SetMulti(ctx, map[string][]byte{"a": []byte("1"), "b": []byte("2")}, 10*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Second, mr.TTL("a"))
+
+	values, err := adapter.GetMulti(ctx, []string{"a", "missing", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"a": []byte("1"), "b": []byte("2")}, values)
+}
+
+func TestRedisAdapter_MultiEmpty(t *testing.T) {
+	mr := miniredis.RunT(t)
+	defer mr.Close()
+
+	adapter, err := NewRedisAdapter("redis://" + mr.Addr())
+	require.NoError(t, err)
+	defer adapter.Close()
+
+	ctx := context.Background()
+
+	assert.NoError(t, adapter.SetMulti(ctx, nil, time.Second))
+
+	values, err := adapter.GetMulti(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, values)
+}
+
 func TestRedisAdapter_Ping(t *testing.T) {
 	mr := miniredis.RunT(t)
 	defer mr.Close()
@@ -121,3 +158,64 @@ func TestRedisAdapter_InvalidURL(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to parse Redis URL")
 }
+
+// TestRedisAdapter_Update verifies Update passes the current value to fn and stores its result.
+func TestRedisAdapter_Update(t *testing.T) {
+	mr := miniredis.RunT(t)
+	adapter, err := NewRedisAdapter("redis://" + mr.Addr())
+	require.NoError(t, err)
+	defer adapter.Close()
+	ctx := context.Background()
+
+	var seen [][]byte
+	appendX := func(current []byte) ([]byte, time.Duration, error) {
+		seen = append(seen, current)
+		return append(current, 'x'), time.Minute, nil
+	}
+
+	require.NoError(t, adapter.Update(ctx, "counter", appendX))
+	require.NoError(t, adapter.Update(ctx, "counter", appendX))
+
+	assert.Equal(t, [][]byte{nil, []byte("x")}, seen)
+	value, err := adapter.Get(ctx, "counter")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("xx"), value)
+	assert.Equal(t, time.Minute, mr.TTL("counter"))
+}
+
+// TestRedisAdapter_Update_Conflict verifies a write between the read and the write fails with ErrConflict.
+func TestRedisAdapter_Update_Conflict(t *testing.T) {
+	mr := miniredis.RunT(t)
+	adapter, err := NewRedisAdapter("redis://" + mr.Addr())
+	require.NoError(t, err)
+	defer adapter.Close()
+	ctx := context.Background()
+	require.NoError(t, adapter.Set(ctx, "banner", []byte("v1"), 0))
+
+	err = adapter.Update(ctx, "banner", func(current []byte) ([]byte, time.Duration, error) {
+		// Another admin saves in between
+		require.NoError(t, mr.Set("banner", "v2"))
+		return []byte("mine"), 0, nil
+	})
+
+	assert.ErrorIs(t, err, ErrConflict)
+	value, err := adapter.Get(ctx, "banner")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("v2"), value)
+}
+
+// TestRedisAdapter_Update_Abort verifies an error from fn is returned unchanged and nothing is written.
+func TestRedisAdapter_Update_Abort(t *testing.T) {
+	mr := miniredis.RunT(t)
+	adapter, err := NewRedisAdapter("redis://" + mr.Addr())
+	require.NoError(t, err)
+	defer adapter.Close()
+	abort := errors.New("version mismatch")
+
+	err = adapter.Update(context.Background(), "banner", func([]byte) ([]byte, time.Duration, error) {
+		return nil, 0, abort
+	})
+
+	assert.Equal(t, abort, err)
+	assert.False(t, mr.Exists("banner"))
+}
@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"time"
+)
+
+// TieredCache implements Cache with a small in-process LRU (L1) in front of another cache (L2).
+// L1 entries live at most maxL1TTL, so an instance serves data another instance deleted or
+// replaced in L2 for no longer than that.
+type TieredCache struct {
+	l1       *lru
+	l2       Cache
+	maxL1TTL time.Duration
+}
+
+// NewTieredCache creates a TieredCache holding up to size entries in memory for at most maxL1TTL.
+func NewTieredCache(l2 Cache, size int, maxL1TTL time.Duration) *TieredCache {
+	return &TieredCache{
+		l1:       newLRU(max(size, 1)),
+		l2:       l2,
+		maxL1TTL: maxL1TTL,
+	}
+}
+
+// Get returns the value from L1, falling back to L2 and populating L1 on an L2 hit.
+func (t *TieredCache) Get(ctx context.Context, key string) ([]byte, error) {
+	if value, ok := t.l1.get(key); ok {
+		return value, nil
+	}
+
+	value, err := t.l2.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	// The remaining L2 TTL is unknown, so the L1 copy uses the cap
+	t.l1.set(key, value, t.maxL1TTL)
+	return value, nil
+}
+
+// Set writes the value to L2 and, on success, to L1 with its TTL capped to maxL1TTL.
+func (t *TieredCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := t.l2.Set(ctx, key, value, ttl); err != nil {
+		t.l1.remove(key)
+		return err
+	}
+
+	t.l1.set(key, bytes.Clone(value), t.l1TTL(ttl))
+	return nil
+}
+
+// GetMulti returns the values found in L1 and fetches the rest from L2 in one call.
+func (t *TieredCache) GetMulti(ctx context.Context, keys []string) (map[string][]byte, error) {
+	result := make(map[string][]byte, len(keys))
+	var misses []string
+	for _, key := range keys {
+		if value, ok := t.l1.get(key); ok {
+			result[key] = value
+		} else {
+			misses = append(misses, key)
+		}
+	}
+	if len(misses) == 0 {
+		return result, nil
+	}
+
+	values, err := t.l2.GetMulti(ctx, misses)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range values {
+		t.l1.set(key, value, t.maxL1TTL)
+		result[key] = value
+	}
+	return result, nil
+}
+
+// SetMulti writes the values to L2 and, on success, to L1 with their TTL capped to maxL1TTL.
+func (t *TieredCache) SetMulti(ctx context.Context, entries map[string][]byte, ttl time.Duration) error {
+	if err := t.l2.SetMulti(ctx, entries, ttl); err != nil {
+		for key := range entries {
+			t.l1.remove(key)
+		}
+		return err
+	}
+
+	l1TTL := t.l1TTL(ttl)
+	for key, value := range entries {
+		t.l1.set(key, bytes.Clone(value), l1TTL)
+	}
+	return nil
+}
+
+// Delete removes the key from both tiers.
+func (t *TieredCache) Delete(ctx context.Context, key string) error {
+	t.l1.remove(key)
+	return t.l2.Delete(ctx, key)
+}
+
+// Ping checks the L2 cache.
+func (t *TieredCache) Ping(ctx context.Context) error {
+	return t.l2.Ping(ctx)
+}
+
+// Close closes the L2 cache.
+func (t *TieredCache) Close() error {
+	return t.l2.Close()
+}
+
+// l1TTL caps ttl to maxL1TTL; a ttl of 0 (no expiration) uses the cap.
+func (t *TieredCache) l1TTL(ttl time.Duration) time.Duration {
+	if ttl > 0 && ttl < t.maxL1TTL {
+		return ttl
+	}
+	return t.maxL1TTL
+}
@@ -0,0 +1,121 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestTieredCache returns a TieredCache over miniredis with a controllable L1 clock.
+func newTestTieredCache(t *testing.T, size int, maxL1TTL time.Duration) (*TieredCache, *miniredis.Miniredis, *time.Time) {
+	mr := miniredis.RunT(t)
+	redisAdapter, err := NewRedisAdapter("redis://" + mr.Addr())
+	require.NoError(t, err)
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tc := NewTieredCache(redisAdapter, size, maxL1TTL)
+	tc.l1.now = func() time.Time { return now }
+	return tc, mr, &now
+}
+
+// TestTieredCache_ReadsFromL1 verifies values written through the cache are served from memory.
+func TestTieredCache_ReadsFromL1(t *testing.T) {
+	tc, mr, _ := newTestTieredCache(t, 10, time.Minute)
+	ctx := context.Background()
+
+	require.NoError(t, tc.Set(ctx, "order_1", []byte("v1"), time.Hour))
+	assert.True(t, mr.Exists("order_1"), "writes must reach L2")
+
+	mr.FlushAll()
+
+	value, err := tc.Get(ctx, "order_1")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("v1"), value)
+}
+
+// TestTieredCache_L1TTLCapped verifies L1 entries expire after the cap and fall back to L2.
+func TestTieredCache_L1TTLCapped(t *testing.T) {
+	tc, mr, now := newTestTieredCache(t, 10, time.Minute)
+	ctx := context.Background()
+
+	require.NoError(t, tc.Set(ctx, "order_1", []byte("v1"), time.Hour))
+	require.NoError(t, mr.Set("order_1", "v2"))
+
+	*now = now.Add(time.Minute)
+
+	value, err := tc.Get(ctx, "order_1")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("v2"), value)
+}
+
+// TestTieredCache_PopulatesL1FromL2 verifies L2 hits are kept in memory.
+func TestTieredCache_PopulatesL1FromL2(t *testing.T) {
+	tc, mr, _ := newTestTieredCache(t, 10, time.Minute)
+	ctx := context.Background()
+
+	require.NoError(t, mr.Set("ts_1", "history"))
+
+	_, err := tc.Get(ctx, "ts_1")
+	require.NoError(t, err)
+	mr.FlushAll()
+
+	value, err := tc.Get(ctx, "ts_1")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("history"), value)
+}
+
+// TestTieredCache_Delete verifies deletes clear both tiers.
+func TestTieredCache_Delete(t *testing.T) {
+	tc, mr, _ := newTestTieredCache(t, 10, time.Minute)
+	ctx := context.Background()
+
+	require.NoError(t, tc.Set(ctx, "order_1", []byte("v1"), time.Hour))
+	require.NoError(t, tc.Delete(ctx, "order_1"))
+
+	assert.False(t, mr.Exists("order_1"))
+	_, err := tc.Get(ctx, "order_1")
+	assert.Error(t, err)
+}
+
+// TestTieredCache_EvictsLeastRecentlyUsed verifies L1 stays within its size.
+func TestTieredCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	tc, mr, _ := newTestTieredCache(t, 2, time.Minute)
+	ctx := context.Background()
+
+	require.NoError(t, tc.Set(ctx, "a", []byte("1"), time.Hour))
+	require.NoError(t, tc.Set(ctx, "b", []byte("2"), time.Hour))
+	_, err := tc.Get(ctx, "a")
+	require.NoError(t, err)
+	require.NoError(t, tc.Set(ctx, "c", []byte("3"), time.Hour))
+
+	assert.Equal(t, 2, tc.l1.len())
+	mr.FlushAll()
+
+	_, err = tc.Get(ctx, "b")
+	assert.Error(t, err, "b was least recently used and must have been evicted")
+	_, err = tc.Get(ctx, "a")
+	assert.NoError(t, err)
+}
+
+// TestTieredCache_GetMulti verifies L1 hits are combined with a single L2 lookup for the rest.
+func TestTieredCache_GetMulti(t *testing.T) {
+	tc, mr, _ := newTestTieredCache(t, 10, time.Minute)
+	ctx := context.Background()
+
+	require.NoError(t, tc.SetMulti(ctx, map[string][]byte{"a": []byte("1")}, time.Hour))
+	mr.FlushAll()
+	require.NoError(t, mr.Set("b", "2"))
+
+	values, err := tc.GetMulti(ctx, []string{"a", "b", "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"a": []byte("1"), "b": []byte("2")}, values)
+
+	mr.FlushAll()
+	value, err := tc.Get(ctx, "b")
+	require.NoError(t, err, "L2 hits must populate L1")
+	assert.Equal(t, []byte("2"), value)
+}
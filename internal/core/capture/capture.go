@@ -0,0 +1,84 @@
+package capture
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"tracker-scrapper/internal/core/logger"
+
+	"go.uber.org/zap"
+)
+
+// unsafeFileChars matches characters that are not allowed in capture file names.
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// settings holds the active capture configuration.
+type settings struct {
+	dir      string
+	maxBytes int
+}
+
+var globalSettings *settings
+
+// Init enables raw payload capture. With an empty dir payloads are logged instead of written to disk.
+// Until Init is called Save is a no-op, so capture is off unless explicitly configured.
+func Init(dir string, maxBytes int) error {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create capture directory: %w", err)
+		}
+	}
+
+	globalSettings = &settings{dir: dir, maxBytes: maxBytes}
+	return nil
+}
+
+// Disable turns raw payload capture off.
+func Disable() {
+	globalSettings = nil
+}
+
+// Save records a raw courier response body for the given tracking number.
+// Bodies larger than the configured limit are truncated. Failures are logged and never returned.
+func Save(courier, trackingNumber string, body []byte) {
+	s := globalSettings
+	if s == nil {
+		return
+	}
+
+	truncated := false
+	if s.maxBytes > 0 && len(body) > s.maxBytes {
+		body = body[:s.maxBytes]
+		truncated = true
+	}
+
+	if s.dir == "" {
+		logger.Get().Info("Raw courier payload",
+			zap.String("courier", courier),
+			zap.String("tracking_number", trackingNumber),
+			zap.Bool("truncated", truncated),
+			zap.ByteString("body", body),
+		)
+		return
+	}
+
+	name := fmt.Sprintf("%s_%s_%d.json",
+		unsafeFileChars.ReplaceAllString(courier, "_"),
+		unsafeFileChars.ReplaceAllString(trackingNumber, "_"),
+		time.Now().UnixNano(),
+	)
+	path := filepath.Join(s.dir, name)
+	if err := os.WriteFile(path, body, 0o644); err != nil {
+		logger.Get().Warn("Failed to write raw courier payload", zap.String("path", path), zap.Error(err))
+		return
+	}
+
+	logger.Get().Debug("Raw courier payload captured",
+		zap.String("courier", courier),
+		zap.String("path", path),
+		zap.Bool("truncated", truncated),
+	)
+}
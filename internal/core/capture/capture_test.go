@@ -0,0 +1,36 @@
+package capture
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSave_Disabled verifies nothing is written before Init.
+func TestSave_Disabled(t *testing.T) {
+	Disable()
+	assert.NotPanics(t, func() {
+		Save("coordinadora_co", "123", []byte(`{}`))
+	})
+}
+
+// TestSave_WritesTruncatedFile verifies payloads are written to the directory and capped.
+func TestSave_WritesTruncatedFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "captures")
+	require.NoError(t, Init(dir, 4))
+	defer Disable()
+
+	Save("servientrega_co", "../22/59", []byte(`{"Results":[]}`))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Contains(t, entries[0].Name(), "servientrega_co____22_59_")
+
+	data, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	require.NoError(t, err)
+	assert.Equal(t, `{"Re`, string(data))
+}
@@ -0,0 +1,32 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestReal verifies the real clock follows the system time.
+func TestReal(t *testing.T) {
+	before := time.Now()
+	now := Real.Now()
+
+	assert.False(t, now.Before(before))
+	assert.False(t, now.After(time.Now()))
+}
+
+// TestFake verifies the fake clock only moves through Set and Advance.
+func TestFake(t *testing.T) {
+	start := time.Date(2025, 3, 4, 10, 0, 0, 0, time.UTC)
+	c := NewFake(start)
+
+	assert.Equal(t, start, c.Now())
+	assert.Equal(t, start, c.Now())
+
+	c.Advance(time.Hour)
+	assert.Equal(t, start.Add(time.Hour), c.Now())
+
+	c.Set(start)
+	assert.Equal(t, start, c.Now())
+}
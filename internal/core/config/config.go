@@ -1,9 +1,18 @@
 package config
 
 import (
-	"errors"
 	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
 	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	// Embedded so APP_TIMEZONE resolves on images without the system tz database
+	_ "time/tzdata"
 
 	"github.com/spf13/viper"
 )
@@ -13,13 +22,53 @@ import (
 // - mapstructure: used by viper to unmarshal
 // - default: default value to set if missing
 // - required: if "true", error if missing
+// - min/max: inclusive bounds for int fields
+// - oneof: comma-separated allowed values for string fields
 type AppConfig struct {
 	// Environment specifies the runtime environment (e.g., development, production).
 	Environment string `mapstructure:"APP_ENV" default:"development"`
+	// Timezone is the IANA timezone courier dates without a UTC offset are parsed in.
+	Timezone string `mapstructure:"APP_TIMEZONE" default:"America/Bogota"`
 	// LogLevel defines the logging verbosity (e.g., debug, info, error).
 	LogLevel string `mapstructure:"LOG_LEVEL" default:"info"`
+	// LogFormat selects the log output format ("console" or "json"). Empty follows Environment.
+	LogFormat string `mapstructure:"LOG_FORMAT"`
+	// LogFile holds the optional rotated log file configuration.
+	LogFile LogFileConfig `mapstructure:",squash"`
+	// LogSampleInitial is how many identical log entries are written each second in production before
+	// sampling starts. 0 disables sampling. Development never samples.
+	LogSampleInitial int `mapstructure:"LOG_SAMPLE_INITIAL" default:"100" min:"0" max:"100000"`
+	// LogSampleThereafter writes every Nth identical entry past LogSampleInitial within the same second.
+	LogSampleThereafter int `mapstructure:"LOG_SAMPLE_THEREAFTER" default:"100" min:"0" max:"100000"`
 	// ServerPort is the port where the server will listen.
-	ServerPort int `mapstructure:"SERVER_PORT" default:"8080"`
+	ServerPort int `mapstructure:"SERVER_PORT" default:"8080" min:"1" max:"65535"`
+	// BasePath prefixes every API route (e.g., "/api/v1" serves /api/v1/orders/:id). Empty serves them at the root.
+	BasePath string `mapstructure:"API_BASE_PATH"`
+	// SwaggerPath is where the Swagger UI is served, independently of BasePath.
+	SwaggerPath string `mapstructure:"SWAGGER_PATH" default:"/swagger"`
+	// HealthPath is where the health check is served, independently of BasePath, so probes keep a fixed URL.
+	HealthPath string `mapstructure:"HEALTH_PATH" default:"/health"`
+	// RequestTimeout is the number of seconds a request may take before answering 504 (0 disables it).
+	// Scrapes that outlive it keep running and still cache their result.
+	RequestTimeout int `mapstructure:"REQUEST_TIMEOUT" default:"30" min:"0" max:"300"`
+	// DismissRateLimit is the number of banner dismissals accepted per client IP each minute (0 disables the limit).
+	DismissRateLimit int `mapstructure:"BANNER_DISMISS_RATE_LIMIT" default:"10" min:"0" max:"1000"`
+	// StrictJSON rejects request bodies that contain unknown fields.
+	StrictJSON bool `mapstructure:"STRICT_JSON" default:"false"`
+	// MaintenanceMode starts the API serving cached-only responses (can be toggled at runtime).
+	MaintenanceMode bool `mapstructure:"MAINTENANCE_MODE" default:"false"`
+	// DebugEndpoints registers authenticated debugging endpoints such as GET /orders/:id/debug.
+	DebugEndpoints bool `mapstructure:"DEBUG_ENDPOINTS" default:"false"`
+	// OrderSource selects where orders are read from: "woocommerce" (live API) or "postgres" (synced table).
+	OrderSource string `mapstructure:"ORDER_SOURCE" default:"woocommerce"`
+	// Compression compresses responses (brotli, gzip or deflate) for clients that advertise support in Accept-Encoding.
+	Compression bool `mapstructure:"COMPRESSION_ENABLED" default:"true"`
+
+	// Auth holds the API key authentication configuration.
+	Auth AuthConfig `mapstructure:",squash"`
+
+	// CORS holds the cross-origin request configuration.
+	CORS CORSConfig `mapstructure:",squash"`
 
 	// Database holds the database configuration.
 	Database DatabaseConfig `mapstructure:",squash"`
@@ -35,6 +84,24 @@ type AppConfig struct {
 
 	// Cache holds the Redis cache configuration.
 	Cache CacheConfig `mapstructure:",squash"`
+
+	// Webhook holds the outbound webhook configuration.
+	Webhook WebhookConfig `mapstructure:",squash"`
+
+	// Capture holds the raw courier payload capture configuration.
+	Capture CaptureConfig `mapstructure:",squash"`
+}
+
+// LogFileConfig holds the log file output and rotation settings.
+type LogFileConfig struct {
+	// Path is the log file path. Empty logs to stdout.
+	Path string `mapstructure:"LOG_FILE"`
+	// MaxSizeMB is the size in megabytes at which the log file is rotated.
+	MaxSizeMB int `mapstructure:"LOG_MAX_SIZE_MB" default:"100" min:"1" max:"10240"`
+	// MaxBackups is the number of rotated files to keep (0 keeps all).
+	MaxBackups int `mapstructure:"LOG_MAX_BACKUPS" default:"5" min:"0" max:"1000"`
+	// MaxAgeDays is the number of days to keep rotated files (0 keeps all).
+	MaxAgeDays int `mapstructure:"LOG_MAX_AGE_DAYS" default:"28" min:"0" max:"3650"`
 }
 
 // WooCommerceConfig holds the credentials for the WooCommerce Store.
@@ -45,6 +112,20 @@ type WooCommerceConfig struct {
 	ConsumerKey string `mapstructure:"WC_CONSUMER_KEY" required:"true"`
 	// ConsumerSecret is the secret key for API access.
 	ConsumerSecret string `mapstructure:"WC_CONSUMER_SECRET" required:"true"`
+	// FeeLineDelimiter splits a single fee line name into multiple items (e.g., "|"). Empty disables splitting.
+	FeeLineDelimiter string `mapstructure:"WC_FEE_LINE_DELIMITER"`
+	// DefaultCarrier is the courier assumed when an order has a guide number but no carrier (e.g., "servientrega").
+	// Empty leaves such tracking without a provider.
+	DefaultCarrier string `mapstructure:"WC_DEFAULT_CARRIER"`
+	// APIVersion is the WooCommerce REST API version in the endpoint path (/wp-json/wc/<version>/).
+	// Legacy stores may still run v1 or v2.
+	APIVersion string `mapstructure:"WC_API_VERSION" default:"v3" oneof:"v1,v2,v3"`
+	// ExtraOrderParams is a query string added to single order requests, e.g. "dp=2" or a _fields
+	// selection to shrink the response.
+	ExtraOrderParams string `mapstructure:"WC_EXTRA_ORDER_PARAMS"`
+	// InsecureSkipVerify disables TLS certificate verification for staging stores with self-signed
+	// certificates. Not allowed with APP_ENV=production.
+	InsecureSkipVerify bool `mapstructure:"WC_INSECURE_SKIP_VERIFY" default:"false"`
 }
 
 // DatabaseConfig holds database connection details.
@@ -53,16 +134,85 @@ type DatabaseConfig struct {
 	Host string `mapstructure:"DB_HOST" default:"localhost"`
 	// Port is the database connection port.
 	Port int `mapstructure:"DB_PORT" default:"5432"`
+	// User is the database role used to connect.
+	User string `mapstructure:"DB_USER" default:"tracker"`
+	// Password is the password of User.
+	Password string `mapstructure:"DB_PASSWORD"`
+	// Name is the database name.
+	Name string `mapstructure:"DB_NAME" default:"tracker"`
+	// SSLMode is the libpq sslmode (disable, require, verify-full, ...).
+	SSLMode string `mapstructure:"DB_SSLMODE" default:"disable"`
 }
 
 // CourierConfig holds courier tracking API URLs.
 type CourierConfig struct {
 	// CoordinadoraURL is the Coordinadora tracking API base URL.
 	CoordinadoraURL string `mapstructure:"COURIER_COORDINADORA_CO" required:"true"`
-	// ServientregaURL is the Servientrega tracking API base URL.
+	// ServientregaURL is the Servientrega mobile tracking page base URL, tried first.
 	ServientregaURL string `mapstructure:"COURIER_SERVIENTREGA_CO" required:"true"`
+	// ServientregaDesktopURL is the Servientrega desktop tracking page used when the mobile lookup fails. Empty disables the fallback.
+	ServientregaDesktopURL string `mapstructure:"COURIER_SERVIENTREGA_DESKTOP_CO" default:"https://www.servientrega.com/wps/portal/rastreo-envio/detalle?id="`
 	// InterrapidisimoURL is the Interrapidisimo tracking API base URL.
 	InterrapidisimoURL string `mapstructure:"COURIER_INTERRAPIDISIMO_CO" required:"true"`
+	// ServientregaEmptyRetries is how many times the Servientrega page is reloaded on empty results.
+	ServientregaEmptyRetries int `mapstructure:"SERVIENTREGA_EMPTY_RETRIES" default:"2" min:"0" max:"5"`
+	// ServientregaConnectivityCheck selects the reachability check made before opening a Servientrega page:
+	// "direct" sends it without the proxy, "proxy" through the proxy (for when Servientrega blocks
+	// non-proxy IPs) and "off" skips it.
+	ServientregaConnectivityCheck string `mapstructure:"SERVIENTREGA_CONNECTIVITY_CHECK" default:"direct" oneof:"direct,proxy,off"`
+	// BatchWorkers bounds concurrent lookups in a batch tracking request.
+	BatchWorkers int `mapstructure:"TRACKING_BATCH_WORKERS" default:"4" min:"1" max:"32"`
+	// WatchTimeout is how long, in seconds, a tracking watch request is held waiting for a change.
+	WatchTimeout int `mapstructure:"TRACKING_WATCH_TIMEOUT" default:"30" min:"1" max:"120"`
+	// WatchInterval is how often, in seconds, a held watch request looks the tracking up again.
+	WatchInterval int `mapstructure:"TRACKING_WATCH_INTERVAL" default:"5" min:"1" max:"60"`
+	// Denylist lists tracking numbers rejected with a 400 before any lookup (comma-separated, case-insensitive).
+	Denylist []string `mapstructure:"TRACKING_DENYLIST"`
+	// OverrideAllowedHosts lists hosts accepted in the X-Courier-Base-URL header (comma-separated).
+	// Empty disables per-request courier overrides; only admin keys may send them.
+	OverrideAllowedHosts []string `mapstructure:"COURIER_OVERRIDE_ALLOWED_HOSTS"`
+	// StatusCodesFile is an optional JSON file with per-courier status code maps.
+	// Codes listed there take precedence over the built-in ones.
+	StatusCodesFile string `mapstructure:"COURIER_STATUS_CODES_FILE"`
+	// BreakerThreshold is the number of consecutive failures that opens a courier's circuit breaker.
+	BreakerThreshold int `mapstructure:"COURIER_BREAKER_THRESHOLD" default:"5" min:"1" max:"100"`
+	// BreakerCooldown is how long (in seconds) an open breaker fast-fails before probing the courier again.
+	BreakerCooldown int `mapstructure:"COURIER_BREAKER_COOLDOWN" default:"60" min:"1" max:"3600"`
+	// Timeout is the default time budget, in seconds, for a single courier lookup.
+	Timeout int `mapstructure:"COURIER_TIMEOUT" default:"60" min:"1" max:"600"`
+	// CoordinadoraTimeout overrides Timeout for Coordinadora. Zero uses Timeout.
+	CoordinadoraTimeout int `mapstructure:"COURIER_COORDINADORA_TIMEOUT" min:"0" max:"600"`
+	// ServientregaTimeout overrides Timeout for Servientrega. Zero uses Timeout.
+	ServientregaTimeout int `mapstructure:"COURIER_SERVIENTREGA_TIMEOUT" min:"0" max:"600"`
+	// InterrapidisimoTimeout overrides Timeout for Interrapidisimo. Zero uses Timeout.
+	InterrapidisimoTimeout int `mapstructure:"COURIER_INTERRAPIDISIMO_TIMEOUT" min:"0" max:"600"`
+	// UseMockCouriers replaces the real couriers with canned responses. Only honoured when APP_ENV=development.
+	UseMockCouriers bool `mapstructure:"USE_MOCK_COURIERS" default:"false"`
+	// AutoDetect makes the courier query parameter optional; the courier is guessed from the tracking number format.
+	AutoDetect bool `mapstructure:"COURIER_AUTODETECT" default:"false"`
+	// Stealth hides browser automation (webdriver flag, user agent, languages, viewport) on scraped pages.
+	Stealth bool `mapstructure:"SCRAPER_STEALTH" default:"true"`
+	// UserAgent pins a single user agent for every scrape, disabling rotation.
+	UserAgent string `mapstructure:"SCRAPER_USER_AGENT"`
+	// UserAgentsFile lists the user agents rotated across scrapes, one per line. Empty uses built-in desktop browsers.
+	UserAgentsFile string `mapstructure:"SCRAPER_USER_AGENTS_FILE"`
+	// ChromiumBin is the Chromium binary used by every courier. Empty auto-detects an installed browser.
+	ChromiumBin string `mapstructure:"CHROMIUM_BIN"`
+	// MaxBrowsers bounds how many browsers run at once across all couriers.
+	MaxBrowsers int `mapstructure:"SCRAPER_MAX_BROWSERS" default:"4" min:"1" max:"64"`
+	// CoordinadoraMaxBrowsers caps concurrent Coordinadora scrapes below MaxBrowsers. Zero leaves only MaxBrowsers.
+	CoordinadoraMaxBrowsers int `mapstructure:"SCRAPER_COORDINADORA_MAX_BROWSERS" min:"0" max:"64"`
+	// ServientregaMaxBrowsers caps concurrent Servientrega scrapes below MaxBrowsers. Zero leaves only MaxBrowsers.
+	ServientregaMaxBrowsers int `mapstructure:"SCRAPER_SERVIENTREGA_MAX_BROWSERS" min:"0" max:"64"`
+	// InterrapidisimoMaxBrowsers caps concurrent Interrapidisimo scrapes below MaxBrowsers. Zero leaves only MaxBrowsers.
+	InterrapidisimoMaxBrowsers int `mapstructure:"SCRAPER_INTERRAPIDISIMO_MAX_BROWSERS" min:"0" max:"64"`
+	// NavigationRetries is how many times a courier page navigation is attempted before the scrape fails.
+	NavigationRetries int `mapstructure:"SCRAPER_NAVIGATION_RETRIES" default:"3" min:"1" max:"10"`
+	// NavigationRetryDelay is the wait, in seconds, between navigation attempts.
+	NavigationRetryDelay int `mapstructure:"SCRAPER_NAVIGATION_RETRY_DELAY" default:"2" min:"0" max:"60"`
+	// InterceptTimeout is how long, in seconds, a loaded courier page may take to call the courier API
+	// before the scrape fails as a layout change. 0 waits for the whole lookup timeout.
+	InterceptTimeout int `mapstructure:"SCRAPER_INTERCEPT_TIMEOUT" default:"30" min:"0" max:"600"`
 }
 
 // ProxyConfig holds shared proxy configuration with per-courier enable flags.
@@ -75,39 +225,117 @@ type ProxyConfig struct {
 	Username string `mapstructure:"PROXY_USERNAME"`
 	// Password is the proxy authentication password.
 	Password string `mapstructure:"PROXY_PASSWORD"`
+	// Hosts lists several "host:port" proxy endpoints to rotate across (comma-separated).
+	// With more than one entry it replaces Hostname and Port; the credentials are shared.
+	Hosts []string `mapstructure:"PROXY_HOSTS"`
+	// BenchSeconds is how long a proxy endpoint that failed a CONNECT test or tunnel is skipped.
+	BenchSeconds int `mapstructure:"PROXY_BENCH_SECONDS" default:"300" min:"1" max:"86400"`
 	// Coordinadora enables proxy for Coordinadora requests.
 	Coordinadora bool `mapstructure:"PROXY_COORDINADORA" default:"false"`
 	// Servientrega enables proxy for Servientrega requests.
 	Servientrega bool `mapstructure:"PROXY_SERVIENTREGA" default:"false"`
 	// Interrapidisimo enables proxy for Interrapidisimo requests.
 	Interrapidisimo bool `mapstructure:"PROXY_INTERRAPIDISIMO" default:"false"`
+	// CoordinadoraDomains lists the domains tunneled through the proxy for Coordinadora (comma-separated).
+	CoordinadoraDomains []string `mapstructure:"PROXY_COORDINADORA_DOMAINS" default:"coordinadora.com"`
+	// ServientregaDomains lists the domains tunneled through the proxy for Servientrega (comma-separated).
+	ServientregaDomains []string `mapstructure:"PROXY_SERVIENTREGA_DOMAINS" default:"servientrega.com,mobile.servientrega.com"`
+	// InterrapidisimoDomains lists the domains tunneled through the proxy for Interrapidisimo (comma-separated).
+	InterrapidisimoDomains []string `mapstructure:"PROXY_INTERRAPIDISIMO_DOMAINS" default:"interrapidisimo.com"`
+}
+
+// AuthConfig holds the API key authentication for protected routes.
+type AuthConfig struct {
+	// Enabled requires a valid API key on protected routes. May only be disabled with APP_ENV=development.
+	Enabled bool `mapstructure:"AUTH_ENABLED" default:"true"`
+	// APIKeys lists the accepted bearer keys (comma-separated).
+	APIKeys []string `mapstructure:"AUTH_API_KEYS"`
+	// AdminKeys lists the bearer keys accepted on /admin routes (comma-separated). They also work wherever APIKeys do.
+	AdminKeys []string `mapstructure:"AUTH_ADMIN_KEYS"`
+}
+
+// CORSConfig holds the cross-origin resource sharing policy for browser clients.
+type CORSConfig struct {
+	// AllowedOrigins lists the origins allowed to call the API (comma-separated). Empty disallows all cross-origin requests.
+	AllowedOrigins []string `mapstructure:"CORS_ALLOWED_ORIGINS"`
+	// AllowedMethods lists the methods allowed in cross-origin requests (comma-separated).
+	AllowedMethods []string `mapstructure:"CORS_ALLOWED_METHODS" default:"GET,POST,PUT,DELETE"`
+	// AllowedHeaders lists the request headers allowed in cross-origin requests (comma-separated).
+	AllowedHeaders []string `mapstructure:"CORS_ALLOWED_HEADERS" default:"Origin,Content-Type,Accept,Authorization,X-Client-Token,Idempotency-Key"`
 }
 
 // CacheConfig holds Redis cache configuration.
 type CacheConfig struct {
+	// Backend selects the cache server: "redis" or "memcached".
+	Backend string `mapstructure:"CACHE_BACKEND" default:"redis" oneof:"redis,memcached"`
 	// RedisURL is the Redis connection URL (format: redis://[:password@]host[:port][/database]).
-	RedisURL string `mapstructure:"CACHE_REDIS_URL" required:"true"`
+	// Required when Backend is "redis".
+	RedisURL string `mapstructure:"CACHE_REDIS_URL" scheme:"redis,rediss"`
+	// MemcachedServers lists the Memcached host:port addresses (comma-separated). Required when Backend is "memcached".
+	MemcachedServers []string `mapstructure:"CACHE_MEMCACHED_SERVERS"`
+	// KeyPrefix namespaces every cache key as "{prefix}:{key}" so environments can share Redis. Empty disables it.
+	KeyPrefix string `mapstructure:"CACHE_KEY_PREFIX"`
 	// OrderTTL is the TTL in seconds for order cache entries.
-	OrderTTL int `mapstructure:"CACHE_ORDER_TTL" default:"3600"`
+	OrderTTL int `mapstructure:"CACHE_ORDER_TTL" default:"3600" min:"1" max:"604800"`
+	// OrderStaleTTL is how many seconds orders are kept past OrderTTL to be served, flagged stale,
+	// when the order source fails. 0 disables stale serving.
+	OrderStaleTTL int `mapstructure:"CACHE_ORDER_STALE_TTL" default:"0" min:"0" max:"604800"`
 	// TrackingTTL is the TTL in seconds for tracking cache entries.
-	TrackingTTL int `mapstructure:"CACHE_TRACKING_TTL" default:"1800"`
+	TrackingTTL int `mapstructure:"CACHE_TRACKING_TTL" default:"1800" min:"1" max:"604800"`
+	// IdempotencyTTL is how long, in seconds, a response is replayed for a repeated Idempotency-Key.
+	IdempotencyTTL int `mapstructure:"CACHE_IDEMPOTENCY_TTL" default:"86400" min:"60" max:"604800"`
+	// TTLJitterPct randomly spreads order and tracking TTLs by up to ±this percent so entries cached
+	// together don't expire together. 0 disables it.
+	TTLJitterPct int `mapstructure:"CACHE_TTL_JITTER_PCT" default:"10" min:"0" max:"50"`
+	// Compression gzip-compresses larger cached values before they reach the backend. Values written
+	// uncompressed are still read, so it can be turned on without flushing the cache.
+	Compression string `mapstructure:"CACHE_COMPRESSION" default:"none" oneof:"gzip,none"`
+	// L1Size is the number of entries kept in the in-process cache in front of Redis. 0 disables it.
+	L1Size int `mapstructure:"CACHE_L1_SIZE" default:"0" min:"0" max:"100000"`
+	// L1TTL caps, in seconds, how long an entry is served from the in-process cache.
+	L1TTL int `mapstructure:"CACHE_L1_TTL" default:"30" min:"1" max:"3600"`
+}
+
+// WebhookConfig holds the outbound order webhook configuration.
+type WebhookConfig struct {
+	// URL receives a POST with the order JSON when an order becomes SHIPPED. Empty disables the webhook.
+	URL string `mapstructure:"WEBHOOK_URL"`
+	// Secret is the HMAC-SHA256 key used to sign webhook payloads.
+	Secret string `mapstructure:"WEBHOOK_SECRET"`
+	// MaxRetries is the number of retries after a failed delivery.
+	MaxRetries int `mapstructure:"WEBHOOK_MAX_RETRIES" default:"3" min:"0" max:"10"`
 }
 
-// Load loads configuration from .env files and environment variables.
+// CaptureConfig holds the debug capture of raw courier responses.
+type CaptureConfig struct {
+	// Enabled records every intercepted courier response before parsing. Keep off in production.
+	Enabled bool `mapstructure:"DEBUG_RAW_CAPTURE" default:"false"`
+	// Dir is the directory where payloads are written. Empty logs them instead.
+	Dir string `mapstructure:"DEBUG_RAW_CAPTURE_DIR"`
+	// MaxBytes caps the size of each captured payload.
+	MaxBytes int `mapstructure:"DEBUG_RAW_CAPTURE_MAX_BYTES" default:"1048576"`
+}
+
+// configFiles lists the supported config files in lookup order; the first one found is used.
+var configFiles = []struct {
+	name       string
+	configType string
+}{
+	{"config.yaml", "yaml"},
+	{"config.yml", "yaml"},
+	{"config.json", "json"},
+	{".env", "env"},
+}
+
+// Load loads configuration from config.yaml, config.json or .env files and environment variables.
+// Environment variables always take precedence over file values.
 func Load(path string) (*AppConfig, error) {
 	v := viper.New()
 
 	v.AutomaticEnv()
 
-	v.AddConfigPath(path)
-	v.SetConfigName(".env")
-	v.SetConfigType("env")
-
-	if err := v.ReadInConfig(); err != nil {
-		var configFileNotFoundError viper.ConfigFileNotFoundError
-		if !errors.As(err, &configFileNotFoundError) {
-			return nil, fmt.Errorf("error reading config file: %w", err)
-		}
+	if err := readConfigFile(v, path); err != nil {
+		return nil, err
 	}
 
 	var config AppConfig
@@ -120,14 +348,68 @@ func Load(path string) (*AppConfig, error) {
 		return nil, fmt.Errorf("unable to decode into struct: %w", err)
 	}
 
-	if err := validateRequired(&config); err != nil {
+	if err := ValidateConfig(&config); err != nil {
 		return nil, err
 	}
 
 	return &config, nil
 }
 
-// processTags iterates over the struct fields and sets default values in Viper.
+// ValidateConfig checks required fields, int ranges, allowed string values and that every *URL field is a well-formed URL.
+func ValidateConfig(cfg *AppConfig) error {
+	if err := validateRequired(cfg); err != nil {
+		return err
+	}
+	// Allowed values come first so an unknown CACHE_BACKEND isn't reported as missing Redis settings
+	if err := validateOneOf(cfg); err != nil {
+		return err
+	}
+	if err := validateCacheBackend(cfg.Cache); err != nil {
+		return err
+	}
+	if err := validatePaths(cfg); err != nil {
+		return err
+	}
+	if _, err := time.LoadLocation(cfg.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone in configuration APP_TIMEZONE: %w", err)
+	}
+	if cfg.WooCommerce.InsecureSkipVerify && cfg.Environment == "production" {
+		return fmt.Errorf("WC_INSECURE_SKIP_VERIFY is not allowed with APP_ENV=production")
+	}
+	if !cfg.Auth.Enabled && cfg.Environment != "development" {
+		return fmt.Errorf("AUTH_ENABLED=false is only allowed with APP_ENV=development")
+	}
+	if err := validateRanges(cfg); err != nil {
+		return err
+	}
+	return validateURLs(cfg)
+}
+
+// readConfigFile merges the first config file found in path into v.
+// Nested keys are flattened with underscores, so cache: {redis_url: ...} maps to CACHE_REDIS_URL.
+func readConfigFile(v *viper.Viper, path string) error {
+	for _, f := range configFiles {
+		file := filepath.Join(path, f.name)
+		if _, err := os.Stat(file); err != nil {
+			continue
+		}
+
+		fileViper := viper.New()
+		fileViper.SetConfigFile(file)
+		fileViper.SetConfigType(f.configType)
+		if err := fileViper.ReadInConfig(); err != nil {
+			return fmt.Errorf("error reading config file: %w", err)
+		}
+
+		flat := make(map[string]interface{}, len(fileViper.AllKeys()))
+		for _, key := range fileViper.AllKeys() {
+			flat[strings.ReplaceAll(key, ".", "_")] = fileViper.Get(key)
+		}
+		return v.MergeConfigMap(flat)
+	}
+	return nil
+}
+
 // processTags iterates over the struct fields and sets default values in Viper.
 func processTags(v *viper.Viper, config interface{}) error {
 	val := reflect.ValueOf(config)
@@ -192,6 +474,184 @@ func validateRequired(config interface{}) error {
 	return nil
 }
 
+// validateRanges checks int fields against their min and max tags.
+func validateRanges(config interface{}) error {
+	val := reflect.ValueOf(config)
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+
+	t := val.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if field.Type.Kind() == reflect.Struct {
+			if err := validateRanges(val.Field(i).Addr().Interface()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if field.Type.Kind() != reflect.Int {
+			continue
+		}
+
+		minTag, maxTag := field.Tag.Get("min"), field.Tag.Get("max")
+		if minTag == "" && maxTag == "" {
+			continue
+		}
+
+		minVal, maxVal := math.MinInt, math.MaxInt
+		var err error
+		if minTag != "" {
+			if minVal, err = strconv.Atoi(minTag); err != nil {
+				return fmt.Errorf("invalid min tag on %s: %w", field.Name, err)
+			}
+		}
+		if maxTag != "" {
+			if maxVal, err = strconv.Atoi(maxTag); err != nil {
+				return fmt.Errorf("invalid max tag on %s: %w", field.Name, err)
+			}
+		}
+
+		value := int(val.Field(i).Int())
+		if value < minVal || value > maxVal {
+			key := field.Tag.Get("mapstructure")
+			return fmt.Errorf("configuration %s out of range [%s,%s]: %d", key, minTag, maxTag, value)
+		}
+	}
+	return nil
+}
+
+// validatePaths checks that the route paths start with "/" and don't end with one. API_BASE_PATH may be empty.
+func validatePaths(cfg *AppConfig) error {
+	paths := []struct {
+		key, value string
+		optional   bool
+	}{
+		{"API_BASE_PATH", cfg.BasePath, true},
+		{"SWAGGER_PATH", cfg.SwaggerPath, false},
+		{"HEALTH_PATH", cfg.HealthPath, false},
+	}
+	for _, p := range paths {
+		if p.value == "" && p.optional {
+			continue
+		}
+		if !strings.HasPrefix(p.value, "/") || strings.HasSuffix(p.value, "/") {
+			return fmt.Errorf("invalid path in configuration %s: %q must start with / and not end with /", p.key, p.value)
+		}
+	}
+	return nil
+}
+
+// validateCacheBackend checks that the connection settings of the selected cache backend are present.
+func validateCacheBackend(cfg CacheConfig) error {
+	switch {
+	case cfg.Backend == "memcached" && len(cfg.MemcachedServers) == 0:
+		return fmt.Errorf("missing required configuration: CACHE_MEMCACHED_SERVERS")
+	case cfg.Backend != "memcached" && cfg.RedisURL == "":
+		return fmt.Errorf("missing required configuration: CACHE_REDIS_URL")
+	}
+	return nil
+}
+
+// validateOneOf checks non-empty string fields against the comma-separated values of their oneof tag.
+func validateOneOf(config interface{}) error {
+	val := reflect.ValueOf(config)
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+
+	t := val.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if field.Type.Kind() == reflect.Struct {
+			if err := validateOneOf(val.Field(i).Addr().Interface()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		allowed := field.Tag.Get("oneof")
+		if allowed == "" || field.Type.Kind() != reflect.String {
+			continue
+		}
+
+		value := val.Field(i).String()
+		if value == "" {
+			continue
+		}
+
+		if !slices.Contains(strings.Split(allowed, ","), value) {
+			key := field.Tag.Get("mapstructure")
+			return fmt.Errorf("unsupported value for configuration %s: %q (must be one of %s)", key, value, strings.ReplaceAll(allowed, ",", ", "))
+		}
+	}
+	return nil
+}
+
+// validateURLs parses every non-empty string field whose name ends in "URL".
+// The scheme must be http or https unless the field lists its own schemes in a scheme tag.
+func validateURLs(config interface{}) error {
+	val := reflect.ValueOf(config)
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+
+	t := val.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if field.Type.Kind() == reflect.Struct {
+			if err := validateURLs(val.Field(i).Addr().Interface()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if field.Type.Kind() != reflect.String || !strings.HasSuffix(field.Name, "URL") {
+			continue
+		}
+
+		raw := val.Field(i).String()
+		if raw == "" {
+			continue
+		}
+
+		schemes := field.Tag.Get("scheme")
+		if schemes == "" {
+			schemes = "http,https"
+		}
+
+		key := field.Tag.Get("mapstructure")
+		if err := validateURL(raw, strings.Split(schemes, ",")); err != nil {
+			return fmt.Errorf("invalid URL in configuration %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// validateURL checks that raw parses with one of the allowed schemes and a host.
+// Courier URLs may contain a %s placeholder for the tracking number, which is ignored.
+func validateURL(raw string, schemes []string) error {
+	u, err := url.Parse(strings.ReplaceAll(raw, "%s", ""))
+	if err != nil {
+		return err
+	}
+
+	if !slices.Contains(schemes, u.Scheme) {
+		return fmt.Errorf("scheme must be one of %s, got %q", strings.Join(schemes, ", "), u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("missing host")
+	}
+	return nil
+}
+
 // isZero checks if a reflect.Value is the zero value for its type.
 func isZero(v reflect.Value) bool {
 	switch v.Kind() {
@@ -1,7 +1,9 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -36,8 +38,33 @@ func TestLoad_Defaults(t *testing.T) {
 	require.NotNil(t, cfg)
 
 	assert.Equal(t, "development", cfg.Environment)
+	assert.Equal(t, "America/Bogota", cfg.Timezone)
 	assert.Equal(t, "info", cfg.LogLevel)
+	assert.Equal(t, 100, cfg.LogSampleInitial, "production log sampling must be enabled by default")
+	assert.Equal(t, 100, cfg.LogSampleThereafter)
 	assert.Equal(t, 8080, cfg.ServerPort)
+	assert.Equal(t, 30, cfg.RequestTimeout)
+	assert.Equal(t, []string{"servientrega.com", "mobile.servientrega.com"}, cfg.Proxy.ServientregaDomains)
+	assert.Equal(t, []string{"interrapidisimo.com"}, cfg.Proxy.InterrapidisimoDomains)
+	assert.Equal(t, 60, cfg.Couriers.Timeout)
+	assert.Equal(t, 4, cfg.Couriers.MaxBrowsers)
+	assert.Equal(t, 3, cfg.Couriers.NavigationRetries)
+	assert.Equal(t, 2, cfg.Couriers.NavigationRetryDelay)
+	assert.Equal(t, 30, cfg.Couriers.InterceptTimeout)
+	assert.Equal(t, 30, cfg.Couriers.WatchTimeout)
+	assert.Equal(t, 5, cfg.Couriers.WatchInterval)
+	assert.Equal(t, "https://www.servientrega.com/wps/portal/rastreo-envio/detalle?id=", cfg.Couriers.ServientregaDesktopURL)
+	assert.Zero(t, cfg.Couriers.ServientregaTimeout)
+	assert.Equal(t, "v3", cfg.WooCommerce.APIVersion)
+	assert.Equal(t, "redis", cfg.Cache.Backend)
+	assert.Equal(t, 10, cfg.Cache.TTLJitterPct)
+	assert.Equal(t, 86400, cfg.Cache.IdempotencyTTL)
+	assert.Equal(t, "none", cfg.Cache.Compression)
+	assert.Equal(t, 0, cfg.Cache.OrderStaleTTL)
+	assert.False(t, cfg.WooCommerce.InsecureSkipVerify)
+	assert.Empty(t, cfg.BasePath)
+	assert.Equal(t, "/swagger", cfg.SwaggerPath)
+	assert.Equal(t, "/health", cfg.HealthPath)
 }
 
 // TestLoad_EnvVars verifies that environment variables override defaults.
@@ -112,3 +139,244 @@ func TestLoad_ValidationFailure(t *testing.T) {
 	assert.Nil(t, cfg)
 	assert.Contains(t, err.Error(), "missing required configuration")
 }
+
+// TestLoad_YAMLFile verifies nested YAML keys map to the flat configuration keys.
+func TestLoad_YAMLFile(t *testing.T) {
+	content := []byte(`
+app_env: staging
+server_port: 7071
+wc:
+  url: https://yaml.example.com
+  consumer_key: ck_yaml
+  consumer_secret: cs_yaml
+courier:
+  coordinadora_co: https://coordinadora.test
+  servientrega_co: https://servientrega.test
+  interrapidisimo_co: https://interrapidisimo.test
+  override_allowed_hosts:
+    - staging.coordinadora.com
+cache:
+  redis_url: redis://localhost:6379
+`)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), content, 0644))
+
+	os.Setenv("SERVER_PORT", "9091")
+	defer os.Unsetenv("SERVER_PORT")
+
+	cfg, err := Load(dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, "staging", cfg.Environment)
+	assert.Equal(t, 9091, cfg.ServerPort, "env vars must override file values")
+	assert.Equal(t, "https://yaml.example.com", cfg.WooCommerce.URL)
+	assert.Equal(t, "redis://localhost:6379", cfg.Cache.RedisURL)
+	assert.Equal(t, []string{"staging.coordinadora.com"}, cfg.Couriers.OverrideAllowedHosts)
+}
+
+// TestLoad_JSONFile verifies values are loaded from config.json.
+func TestLoad_JSONFile(t *testing.T) {
+	content := []byte(`{
+		"LOG_LEVEL": "warn",
+		"WC_URL": "https://json.example.com",
+		"WC_CONSUMER_KEY": "ck_json",
+		"WC_CONSUMER_SECRET": "cs_json",
+		"COURIER_COORDINADORA_CO": "https://coordinadora.test",
+		"COURIER_SERVIENTREGA_CO": "https://servientrega.test",
+		"COURIER_INTERRAPIDISIMO_CO": "https://interrapidisimo.test",
+		"CACHE": {"REDIS_URL": "redis://localhost:6379", "ORDER_TTL": 60}
+	}`)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.json"), content, 0644))
+
+	cfg, err := Load(dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, "warn", cfg.LogLevel)
+	assert.Equal(t, "https://json.example.com", cfg.WooCommerce.URL)
+	assert.Equal(t, 60, cfg.Cache.OrderTTL)
+}
+
+// validConfig returns an AppConfig that passes ValidateConfig.
+func validConfig() *AppConfig {
+	return &AppConfig{
+		ServerPort:  8080,
+		SwaggerPath: "/swagger",
+		HealthPath:  "/health",
+		LogFile:     LogFileConfig{MaxSizeMB: 100},
+		WooCommerce: WooCommerceConfig{URL: "https://store.example.com", ConsumerKey: "ck", ConsumerSecret: "cs"},
+		Couriers: CourierConfig{
+			CoordinadoraURL:    "https://coordinadora.com/rastreo/?guia=%s",
+			ServientregaURL:    "https://mobile.servientrega.com/WebSitePortal/RastreoEnvioDetalle.html?Guia=",
+			InterrapidisimoURL: "https://www3.interrapidisimo.com/SiguetuEnvio/shipment",
+			BatchWorkers:       4,
+			WatchTimeout:       30,
+			WatchInterval:      5,
+			BreakerThreshold:   5,
+			BreakerCooldown:    60,
+			Timeout:            60,
+			MaxBrowsers:        4,
+			NavigationRetries:  3,
+		},
+		Proxy: ProxyConfig{BenchSeconds: 300},
+		Cache: CacheConfig{RedisURL: "redis://localhost:6379", OrderTTL: 3600, TrackingTTL: 1800, IdempotencyTTL: 86400, L1TTL: 30},
+		Auth:  AuthConfig{Enabled: true},
+	}
+}
+
+// TestValidateConfig_Valid verifies a well-formed configuration passes.
+func TestValidateConfig_Valid(t *testing.T) {
+	assert.NoError(t, ValidateConfig(validConfig()))
+}
+
+// TestValidateConfig_MalformedURLs verifies malformed URLs are reported with their config key.
+func TestValidateConfig_MalformedURLs(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(cfg *AppConfig)
+		key    string
+	}{
+		{"bad scheme", func(cfg *AppConfig) { cfg.WooCommerce.URL = "htp://store" }, "WC_URL"},
+		{"missing host", func(cfg *AppConfig) { cfg.Couriers.ServientregaURL = "https://" }, "COURIER_SERVIENTREGA_CO"},
+		{"no scheme", func(cfg *AppConfig) { cfg.Couriers.InterrapidisimoURL = "www3.interrapidisimo.com/shipment" }, "COURIER_INTERRAPIDISIMO_CO"},
+		{"unparseable", func(cfg *AppConfig) { cfg.Couriers.CoordinadoraURL = "https://coordinadora.com/%zz" }, "COURIER_COORDINADORA_CO"},
+		{"redis with http scheme", func(cfg *AppConfig) { cfg.Cache.RedisURL = "http://localhost:6379" }, "CACHE_REDIS_URL"},
+		{"optional webhook", func(cfg *AppConfig) { cfg.Webhook.URL = "ftp://hooks.example.com" }, "WEBHOOK_URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.mutate(cfg)
+
+			err := ValidateConfig(cfg)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.key)
+		})
+	}
+}
+
+// TestValidateConfig_OutOfRange verifies min/max tags reject out-of-range ints.
+func TestValidateConfig_OutOfRange(t *testing.T) {
+	cfg := validConfig()
+	cfg.ServerPort = 99999
+	err := ValidateConfig(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "configuration SERVER_PORT out of range [1,65535]")
+
+	cfg = validConfig()
+	cfg.Cache.OrderTTL = -1
+	err = ValidateConfig(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "CACHE_ORDER_TTL out of range")
+}
+
+// TestValidateConfig_UnsupportedWooCommerceVersion verifies WC_API_VERSION only accepts known API versions.
+func TestValidateConfig_UnsupportedWooCommerceVersion(t *testing.T) {
+	for _, version := range []string{"v1", "v2", "v3"} {
+		cfg := validConfig()
+		cfg.WooCommerce.APIVersion = version
+		assert.NoError(t, ValidateConfig(cfg), version)
+	}
+
+	cfg := validConfig()
+	cfg.WooCommerce.APIVersion = "v4"
+	err := ValidateConfig(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unsupported value for configuration WC_API_VERSION: "v4" (must be one of v1, v2, v3)`)
+}
+
+// TestValidateConfig_CacheBackend verifies each cache backend requires its own connection settings.
+func TestValidateConfig_CacheBackend(t *testing.T) {
+	cfg := validConfig()
+	cfg.Cache.RedisURL = ""
+	assert.EqualError(t, ValidateConfig(cfg), "missing required configuration: CACHE_REDIS_URL")
+
+	cfg.Cache.Backend = "memcached"
+	assert.EqualError(t, ValidateConfig(cfg), "missing required configuration: CACHE_MEMCACHED_SERVERS")
+
+	cfg.Cache.MemcachedServers = []string{"localhost:11211"}
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.Cache.Backend = "valkey"
+	assert.ErrorContains(t, ValidateConfig(cfg), "unsupported value for configuration CACHE_BACKEND")
+}
+
+// TestValidateConfig_InsecureSkipVerify verifies WC_INSECURE_SKIP_VERIFY is rejected in production.
+func TestValidateConfig_InsecureSkipVerify(t *testing.T) {
+	cfg := validConfig()
+	cfg.WooCommerce.InsecureSkipVerify = true
+	cfg.Environment = "development"
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.Environment = "production"
+	assert.EqualError(t, ValidateConfig(cfg), "WC_INSECURE_SKIP_VERIFY is not allowed with APP_ENV=production")
+}
+
+// TestValidateConfig_AuthDisabled verifies AUTH_ENABLED=false is rejected outside development.
+func TestValidateConfig_AuthDisabled(t *testing.T) {
+	cfg := validConfig()
+	cfg.Auth.Enabled = false
+	cfg.Environment = "development"
+	assert.NoError(t, ValidateConfig(cfg))
+
+	for _, env := range []string{"production", "staging"} {
+		cfg.Environment = env
+		assert.EqualError(t, ValidateConfig(cfg), "AUTH_ENABLED=false is only allowed with APP_ENV=development")
+	}
+}
+
+// TestValidateConfig_Paths verifies route paths must start with a slash and not end with one.
+func TestValidateConfig_Paths(t *testing.T) {
+	cfg := validConfig()
+	cfg.BasePath = "/api/v1"
+	assert.NoError(t, ValidateConfig(cfg))
+
+	for _, basePath := range []string{"api/v1", "/api/v1/", "/"} {
+		cfg.BasePath = basePath
+		assert.EqualError(t, ValidateConfig(cfg),
+			fmt.Sprintf("invalid path in configuration API_BASE_PATH: %q must start with / and not end with /", basePath))
+	}
+
+	cfg = validConfig()
+	cfg.HealthPath = ""
+	assert.ErrorContains(t, ValidateConfig(cfg), "invalid path in configuration HEALTH_PATH")
+}
+
+// TestValidateConfig_Timezone verifies APP_TIMEZONE must name a known timezone.
+func TestValidateConfig_Timezone(t *testing.T) {
+	cfg := validConfig()
+	cfg.Timezone = "America/Bogota"
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.Timezone = "America/Gotham"
+	assert.ErrorContains(t, ValidateConfig(cfg), "invalid timezone in configuration APP_TIMEZONE")
+}
+
+// TestLoad_OutOfRangeEnv verifies range validation runs after unmarshalling env vars.
+func TestLoad_OutOfRangeEnv(t *testing.T) {
+	os.Setenv("WC_URL", "https://example.com")
+	os.Setenv("WC_CONSUMER_KEY", "ck_123")
+	os.Setenv("WC_CONSUMER_SECRET", "cs_123")
+	os.Setenv("COURIER_COORDINADORA_CO", "https://coordinadora.test")
+	os.Setenv("COURIER_SERVIENTREGA_CO", "https://servientrega.test")
+	os.Setenv("COURIER_INTERRAPIDISIMO_CO", "https://interrapidisimo.test")
+	os.Setenv("CACHE_REDIS_URL", "redis://localhost:6379")
+	os.Setenv("CACHE_TRACKING_TTL", "0")
+	defer func() {
+		os.Unsetenv("WC_URL")
+		os.Unsetenv("WC_CONSUMER_KEY")
+		os.Unsetenv("WC_CONSUMER_SECRET")
+		os.Unsetenv("COURIER_COORDINADORA_CO")
+		os.Unsetenv("COURIER_SERVIENTREGA_CO")
+		os.Unsetenv("COURIER_INTERRAPIDISIMO_CO")
+		os.Unsetenv("CACHE_REDIS_URL")
+		os.Unsetenv("CACHE_TRACKING_TTL")
+	}()
+
+	cfg, err := Load(".")
+	require.Error(t, err)
+	assert.Nil(t, cfg)
+	assert.Contains(t, err.Error(), "CACHE_TRACKING_TTL out of range")
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
+
+	"tracker-scrapper/internal/core/logger"
+
+	"go.uber.org/zap"
+)
+
+// reloadableKeys lists the configuration keys applied on reload without a restart.
+var reloadableKeys = map[string]bool{
+	"LOG_LEVEL":             true,
+	"CACHE_ORDER_TTL":       true,
+	"CACHE_ORDER_STALE_TTL": true,
+	"CACHE_TRACKING_TTL":    true,
+	"TRACKING_DENYLIST":     true,
+}
+
+// Watch reloads the configuration from path on every SIGHUP until ctx is done.
+// apply is called with the effective configuration after each successful reload.
+func Watch(ctx context.Context, path string, current *AppConfig, apply func(*AppConfig)) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGHUP)
+
+	go func() {
+		defer signal.Stop(sig)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sig:
+				logger.Get().Info("SIGHUP received, reloading configuration")
+				current = Reload(path, current, apply)
+			}
+		}
+	}()
+}
+
+// Reload loads the configuration from path and applies its reloadable subset.
+// Changes to other keys are logged and ignored until restart. On error current is kept.
+func Reload(path string, current *AppConfig, apply func(*AppConfig)) *AppConfig {
+	next, err := Load(path)
+	if err != nil {
+		logger.Get().Error("Configuration reload failed, keeping current configuration", zap.Error(err))
+		return current
+	}
+
+	for _, key := range changedKeys(current, next) {
+		if !reloadableKeys[key] {
+			logger.Get().Warn("Configuration change requires a restart, ignoring", zap.String("key", key))
+		}
+	}
+
+	effective := *current
+	effective.LogLevel = next.LogLevel
+	effective.Cache.OrderTTL = next.Cache.OrderTTL
+	effective.Cache.OrderStaleTTL = next.Cache.OrderStaleTTL
+	effective.Cache.TrackingTTL = next.Cache.TrackingTTL
+	effective.Couriers.Denylist = next.Couriers.Denylist
+
+	apply(&effective)
+	return &effective
+}
+
+// changedKeys returns the mapstructure keys whose values differ between a and b.
+func changedKeys(a, b interface{}) []string {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Kind() == reflect.Ptr {
+		va, vb = va.Elem(), vb.Elem()
+	}
+
+	t := va.Type()
+
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if field.Type.Kind() == reflect.Struct {
+			keys = append(keys, changedKeys(va.Field(i).Addr().Interface(), vb.Field(i).Addr().Interface())...)
+			continue
+		}
+
+		if !reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			keys = append(keys, field.Tag.Get("mapstructure"))
+		}
+	}
+	return keys
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeEnvFile writes a minimal valid .env file with the given overrides appended.
+func writeEnvFile(t *testing.T, dir, extra string) {
+	t.Helper()
+	content := `
+WC_URL=https://store.example.com
+WC_CONSUMER_KEY=ck
+WC_CONSUMER_SECRET=cs
+COURIER_COORDINADORA_CO=https://coordinadora.test
+COURIER_SERVIENTREGA_CO=https://servientrega.test
+COURIER_INTERRAPIDISIMO_CO=https://interrapidisimo.test
+CACHE_REDIS_URL=redis://localhost:6379
+` + extra
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), []byte(content), 0644))
+}
+
+// TestReload_AppliesReloadableKeys verifies reloadable keys change and immutable ones are kept.
+func TestReload_AppliesReloadableKeys(t *testing.T) {
+	dir := t.TempDir()
+	writeEnvFile(t, dir, "LOG_LEVEL=info\nCACHE_ORDER_TTL=3600\nSERVER_PORT=8080\n")
+
+	current, err := Load(dir)
+	require.NoError(t, err)
+
+	writeEnvFile(t, dir, "LOG_LEVEL=debug\nCACHE_ORDER_TTL=60\nSERVER_PORT=9999\nTRACKING_DENYLIST=00000,test\n")
+
+	var applied *AppConfig
+	next := Reload(dir, current, func(cfg *AppConfig) { applied = cfg })
+
+	require.NotNil(t, applied)
+	assert.Same(t, applied, next)
+	assert.Equal(t, "debug", next.LogLevel)
+	assert.Equal(t, 60, next.Cache.OrderTTL)
+	assert.Equal(t, []string{"00000", "test"}, next.Couriers.Denylist)
+	assert.Equal(t, 8080, next.ServerPort, "immutable keys must be ignored until restart")
+}
+
+// TestReload_InvalidConfigKeepsCurrent verifies a failed reload keeps the current configuration.
+func TestReload_InvalidConfigKeepsCurrent(t *testing.T) {
+	dir := t.TempDir()
+	writeEnvFile(t, dir, "")
+
+	current, err := Load(dir)
+	require.NoError(t, err)
+
+	writeEnvFile(t, dir, "CACHE_ORDER_TTL=-5\n")
+
+	called := false
+	next := Reload(dir, current, func(cfg *AppConfig) { called = true })
+
+	assert.False(t, called)
+	assert.Same(t, current, next)
+}
+
+// TestChangedKeys verifies differing fields are reported by their config key.
+func TestChangedKeys(t *testing.T) {
+	a := AppConfig{ServerPort: 8080, Cache: CacheConfig{OrderTTL: 10}}
+	b := AppConfig{ServerPort: 9090, Cache: CacheConfig{OrderTTL: 10}}
+
+	assert.Equal(t, []string{"SERVER_PORT"}, changedKeys(&a, &b))
+}
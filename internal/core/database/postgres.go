@@ -0,0 +1,41 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+
+	"tracker-scrapper/internal/core/config"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresURL builds a postgres:// connection URL from cfg.
+func PostgresURL(cfg config.DatabaseConfig) string {
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.User, cfg.Password),
+		Host:     net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		Path:     "/" + cfg.Name,
+		RawQuery: url.Values{"sslmode": {cfg.SSLMode}}.Encode(),
+	}
+	if cfg.Password == "" {
+		u.User = url.User(cfg.User)
+	}
+	return u.String()
+}
+
+// NewPostgresPool connects to Postgres and verifies the connection with a ping.
+func NewPostgresPool(ctx context.Context, cfg config.DatabaseConfig) (*pgxpool.Pool, error) {
+	pool, err := pgxpool.New(ctx, PostgresURL(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Postgres pool: %w", err)
+	}
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to connect to Postgres at %s:%d: %w", cfg.Host, cfg.Port, err)
+	}
+	return pool, nil
+}
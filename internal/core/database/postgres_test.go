@@ -0,0 +1,18 @@
+package database
+
+import (
+	"testing"
+
+	"tracker-scrapper/internal/core/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPostgresURL verifies the connection URL is built from the config and escapes credentials.
+func TestPostgresURL(t *testing.T) {
+	cfg := config.DatabaseConfig{Host: "db", Port: 5432, User: "tracker", Password: "p@ss/word", Name: "orders", SSLMode: "require"}
+	assert.Equal(t, "postgres://tracker:p%40ss%2Fword@db:5432/orders?sslmode=require", PostgresURL(cfg))
+
+	cfg.Password = ""
+	assert.Equal(t, "postgres://tracker@db:5432/orders?sslmode=require", PostgresURL(cfg))
+}
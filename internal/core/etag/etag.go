@@ -0,0 +1,19 @@
+package etag
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Compute returns a strong HTTP entity tag for a response body, for bodies sent byte for byte.
+func Compute(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// Weak returns a weak HTTP entity tag for body. Services tag cached values with it, computed from
+// the value without fields that change on every fetch (such as retrieval timestamps), and cache it
+// alongside, so equivalent responses share a tag without the value being re-encoded on cache hits.
+func Weak(body []byte) string {
+	return "W/" + Compute(body)
+}
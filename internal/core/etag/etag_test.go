@@ -0,0 +1,24 @@
+package etag
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCompute verifies the ETag is a quoted, stable hash of the body.
+func TestCompute(t *testing.T) {
+	tag := Compute([]byte(`{"a":1}`))
+
+	assert.Equal(t, tag, Compute([]byte(`{"a":1}`)))
+	assert.NotEqual(t, tag, Compute([]byte(`{"a":2}`)))
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, tag)
+}
+
+// TestWeak verifies weak tags mark the strong tag of the same body as weak.
+func TestWeak(t *testing.T) {
+	body := []byte(`{"a":1}`)
+
+	assert.Equal(t, "W/"+Compute(body), Weak(body))
+	assert.NotEqual(t, Weak(body), Weak([]byte(`{"a":2}`)))
+}
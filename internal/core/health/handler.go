@@ -0,0 +1,52 @@
+package health
+
+import (
+	"net/http"
+
+	"tracker-scrapper/internal/core/maintenance"
+	"tracker-scrapper/internal/core/metrics"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Handler exposes the health check endpoint.
+type Handler struct {
+	mode *maintenance.Mode
+}
+
+// NewHandler creates a new health Handler. A nil maintenance mode is reported as disabled.
+func NewHandler(mode *maintenance.Mode) *Handler {
+	return &Handler{mode: mode}
+}
+
+// Response represents the health check response body.
+type Response struct {
+	// Status is always "ok" while the process is serving requests.
+	Status string `json:"status"`
+	// Maintenance indicates whether maintenance mode is active.
+	Maintenance bool `json:"maintenance"`
+	// Summary is the operational overview, only present in deep mode.
+	Summary *metrics.Summary `json:"summary,omitempty"`
+}
+
+// GetHealth handles GET /health.
+// @Summary Health check
+// @Description Reports liveness. With deep=true it adds cache hit rates, scrape statistics and courier circuit breaker states read from in-memory metrics (no provider calls).
+// @Tags Admin
+// @Produce json
+// @Param deep query bool false "Include operational summary"
+// @Success 200 {object} Response
+// @Router /health [get]
+func (h *Handler) GetHealth(c *fiber.Ctx) error {
+	resp := Response{
+		Status:      "ok",
+		Maintenance: h.mode.Enabled(),
+	}
+
+	if c.QueryBool("deep") {
+		summary := metrics.GetSummary()
+		resp.Summary = &summary
+	}
+
+	return c.Status(http.StatusOK).JSON(resp)
+}
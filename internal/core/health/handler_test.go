@@ -0,0 +1,57 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"tracker-scrapper/internal/core/maintenance"
+	"tracker-scrapper/internal/core/metrics"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupApp registers the health route on a fresh Fiber app.
+func setupApp(mode *maintenance.Mode) *fiber.App {
+	app := fiber.New()
+	app.Get("/health", NewHandler(mode).GetHealth)
+	return app
+}
+
+// TestGetHealth_Shallow verifies the summary is omitted by default.
+func TestGetHealth_Shallow(t *testing.T) {
+	app := setupApp(maintenance.NewMode(true))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/health", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var body map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "ok", body["status"])
+	assert.Equal(t, true, body["maintenance"])
+	assert.NotContains(t, body, "summary")
+}
+
+// TestGetHealth_Deep verifies the summary fields are present in deep mode.
+func TestGetHealth_Deep(t *testing.T) {
+	require.NoError(t, metrics.Init())
+	metrics.CacheHit("tracking")
+	metrics.CacheMiss("tracking")
+
+	app := setupApp(nil)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/health?deep=true", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var body Response
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.NotNil(t, body.Summary)
+	assert.InDelta(t, 0.5, body.Summary.CacheHitRate["tracking"], 1e-9)
+	assert.NotNil(t, body.Summary.AvgScrapeSeconds)
+	assert.NotNil(t, body.Summary.ScrapeOutcomes)
+	assert.NotNil(t, body.Summary.BreakerStates)
+}
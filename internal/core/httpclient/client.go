@@ -1,6 +1,7 @@
 package httpclient
 
 import (
+	"crypto/tls"
 	"net/http"
 	"time"
 
@@ -57,3 +58,16 @@ func NewClient(timeout time.Duration) *http.Client {
 		Timeout: timeout,
 	}
 }
+
+// NewInsecureClient returns an http.Client like NewClient that does not verify TLS certificates.
+// Only meant for staging servers with self-signed certificates.
+func NewInsecureClient(timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	return &http.Client{
+		Transport: &LoggingRoundTripper{
+			Proxied: transport,
+		},
+		Timeout: timeout,
+	}
+}
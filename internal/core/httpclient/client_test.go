@@ -19,7 +19,7 @@ func TestLoggingRoundTripper(t *testing.T) {
 	}))
 	defer ts.Close()
 
-	logger.Init("development", "debug")
+	logger.Init("development", "debug", "", logger.FileOutput{}, logger.Sampling{})
 
 	client := NewClient(1 * time.Second)
 	resp, err := client.Get(ts.URL)
@@ -29,9 +29,27 @@ func TestLoggingRoundTripper(t *testing.T) {
 
 // TestLoggingRoundTripper_Error verifies that failed requests are logged.
 func TestLoggingRoundTripper_Error(t *testing.T) {
-	logger.Init("development", "debug")
+	logger.Init("development", "debug", "", logger.FileOutput{}, logger.Sampling{})
 
 	client := NewClient(1 * time.Second)
 	_, err := client.Get("http://invalid-url-that-does-not-exist.local")
 	require.Error(t, err)
 }
+
+// TestNewInsecureClient verifies self-signed certificates are only accepted by the insecure client.
+func TestNewInsecureClient(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	logger.Init("development", "debug", "", logger.FileOutput{}, logger.Sampling{})
+
+	_, err := NewClient(1 * time.Second).Get(ts.URL)
+	require.Error(t, err)
+
+	resp, err := NewInsecureClient(1 * time.Second).Get(ts.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
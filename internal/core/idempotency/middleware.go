@@ -0,0 +1,176 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"tracker-scrapper/internal/core/cache"
+	"tracker-scrapper/internal/core/logger"
+	"tracker-scrapper/internal/core/request"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// Header carries the client-chosen key identifying one logical write across retries.
+const Header = "Idempotency-Key"
+
+// ReplayedHeader is set to "true" on responses replayed from a stored key.
+const ReplayedHeader = "Idempotent-Replayed"
+
+// maxKeyLength caps the accepted Idempotency-Key length.
+const maxKeyLength = 255
+
+// keyPrefix namespaces idempotency records in the cache.
+const keyPrefix = "idempotency"
+
+// pendingTTL bounds how long a claimed key blocks retries when the process dies mid-request.
+const pendingTTL = time.Minute
+
+// errStored aborts the claim when the key already holds a record.
+var errStored = errors.New("idempotency key already stored")
+
+// Store is the cache the middleware needs: reads, writes and atomic claims of new keys.
+type Store interface {
+	cache.Cache
+	cache.Updater
+}
+
+// ErrorResponse represents an idempotency error response with Ray ID.
+type ErrorResponse struct {
+	// Message is the error description.
+	Message string `json:"message"`
+	// RayID is the unique request identifier for tracing.
+	RayID string `json:"ray_id"`
+}
+
+// record is what is stored under an idempotency key: a pending marker while the first request
+// runs, then its response.
+type record struct {
+	// Pending is true while the first request with the key is still running.
+	Pending bool `json:"pending,omitempty"`
+	// Fingerprint is the SHA-256 of the request body the key was first used with.
+	Fingerprint string `json:"fingerprint"`
+	// Status is the stored response status code.
+	Status int `json:"status,omitempty"`
+	// ContentType is the stored response Content-Type.
+	ContentType string `json:"content_type,omitempty"`
+	// Body is the stored response body.
+	Body []byte `json:"body,omitempty"`
+}
+
+// New returns middleware that runs a request carrying an Idempotency-Key once and answers
+// repeats of the key within ttl with the stored response. Keys are scoped to the method, path
+// and Authorization header. Requests without the header pass through unchanged.
+//
+// A repeat arriving while the first request still runs gets 409, and a key reused with a
+// different body gets 422. Server errors are not stored, so retrying them runs the handler again.
+// When the store is unreachable requests run without idempotency rather than failing.
+func New(store Store, ttl time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(Header)
+		if key == "" {
+			return c.Next()
+		}
+		if len(key) > maxKeyLength {
+			return request.Invalid(c, request.ValidationError{
+				Field:   Header,
+				Message: fmt.Sprintf("must be at most %d characters", maxKeyLength),
+			})
+		}
+
+		ctx := c.UserContext()
+		storeKey := storeKey(c, key)
+		fingerprint := digest(c.Body())
+
+		stored, err := claim(ctx, store, storeKey, fingerprint)
+		switch {
+		case errors.Is(err, cache.ErrConflict):
+			return inProgress(c)
+		case err != nil:
+			logger.With(request.RayID(c)).Warn("Idempotency store unavailable, running request without it", zap.Error(err))
+			return c.Next()
+		case stored != nil && stored.Pending:
+			return inProgress(c)
+		case stored != nil && stored.Fingerprint != fingerprint:
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(ErrorResponse{
+				Message: "Idempotency-Key was already used with a different request body",
+				RayID:   request.RayID(c),
+			})
+		case stored != nil:
+			c.Set(ReplayedHeader, "true")
+			c.Set(fiber.HeaderContentType, stored.ContentType)
+			return c.Status(stored.Status).Send(stored.Body)
+		}
+
+		handlerErr := c.Next()
+		status := c.Response().StatusCode()
+		if handlerErr != nil || status >= fiber.StatusInternalServerError {
+			// Let the client's retry run the request again
+			if err := store.Delete(ctx, storeKey); err != nil {
+				logger.With(request.RayID(c)).Warn("Failed to release idempotency key", zap.Error(err))
+			}
+			return handlerErr
+		}
+
+		data, err := json.Marshal(record{
+			Fingerprint: fingerprint,
+			Status:      status,
+			ContentType: string(c.Response().Header.ContentType()),
+			Body:        c.Response().Body(),
+		})
+		if err == nil {
+			err = store.Set(ctx, storeKey, data, ttl)
+		}
+		if err != nil {
+			logger.With(request.RayID(c)).Warn("Failed to store idempotent response", zap.Error(err))
+		}
+		return nil
+	}
+}
+
+// claim marks key as pending unless it already holds a record, which is returned instead.
+// It fails with cache.ErrConflict when another request claimed key at the same time.
+func claim(ctx context.Context, store Store, key, fingerprint string) (*record, error) {
+	var stored *record
+	err := store.Update(ctx, key, func(current []byte) ([]byte, time.Duration, error) {
+		if current != nil {
+			stored = &record{}
+			if err := json.Unmarshal(current, stored); err != nil {
+				return nil, 0, fmt.Errorf("failed to unmarshal idempotency record: %w", err)
+			}
+			return nil, 0, errStored
+		}
+		next, err := json.Marshal(record{Pending: true, Fingerprint: fingerprint})
+		return next, pendingTTL, err
+	})
+	if errors.Is(err, errStored) {
+		return stored, nil
+	}
+	return nil, err
+}
+
+// inProgress answers a repeat of a key whose first request has not finished.
+func inProgress(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusConflict).JSON(ErrorResponse{
+		Message: "a request with this Idempotency-Key is still in progress",
+		RayID:   request.RayID(c),
+	})
+}
+
+// storeKey scopes key to the route and caller, so clients can't replay each other's responses.
+func storeKey(c *fiber.Ctx, key string) string {
+	scope := digest([]byte(c.Method() + " " + c.Path() + "\n" + c.Get(fiber.HeaderAuthorization) + "\n" + key))
+	return keyPrefix + "_" + scope
+}
+
+// digest returns the hex-encoded SHA-256 of data.
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,143 @@
+package idempotency
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"tracker-scrapper/internal/core/cache"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestApp returns an app serving POST /banner behind the middleware, counting handler runs.
+// The handler answers with status and its run number.
+func newTestApp(t *testing.T, status int) (*fiber.App, *miniredis.Miniredis, *atomic.Int32) {
+	mr := miniredis.RunT(t)
+	redisAdapter, err := cache.NewRedisAdapter("redis://" + mr.Addr())
+	require.NoError(t, err)
+
+	runs := &atomic.Int32{}
+	app := fiber.New()
+	app.Post("/banner", New(cache.NewPrefixedCache(redisAdapter, ""), time.Hour), func(c *fiber.Ctx) error {
+		n := runs.Add(1)
+		return c.Status(status).JSON(fiber.Map{"run": n})
+	})
+	return app, mr, runs
+}
+
+// post sends body to /banner with the given idempotency key and returns the status, replay header and body.
+func post(t *testing.T, app *fiber.App, key, body string) (int, string, string) {
+	req := httptest.NewRequest("POST", "/banner", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(Header, key)
+	}
+	// No test timeout: with the store down, go-redis retries its dials for a few seconds
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err)
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, resp.Header.Get(ReplayedHeader), string(data)
+}
+
+// TestNew_ReplaysStoredResponse verifies a repeated key returns the first response without running the handler again.
+func TestNew_ReplaysStoredResponse(t *testing.T) {
+	app, mr, runs := newTestApp(t, fiber.StatusOK)
+
+	status, replayed, body := post(t, app, "key-1", `{"title":"Sale"}`)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Empty(t, replayed)
+	assert.JSONEq(t, `{"run":1}`, body)
+
+	status, replayed, body = post(t, app, "key-1", `{"title":"Sale"}`)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, "true", replayed)
+	assert.JSONEq(t, `{"run":1}`, body)
+	assert.Equal(t, int32(1), runs.Load())
+
+	for _, key := range mr.Keys() {
+		assert.Equal(t, time.Hour, mr.TTL(key))
+	}
+}
+
+// TestNew_WithoutKey verifies requests without the header always run the handler.
+func TestNew_WithoutKey(t *testing.T) {
+	app, _, runs := newTestApp(t, fiber.StatusOK)
+
+	post(t, app, "", `{}`)
+	_, replayed, body := post(t, app, "", `{}`)
+
+	assert.Empty(t, replayed)
+	assert.JSONEq(t, `{"run":2}`, body)
+	assert.Equal(t, int32(2), runs.Load())
+}
+
+// TestNew_DifferentBody verifies reusing a key with another body is rejected with 422.
+func TestNew_DifferentBody(t *testing.T) {
+	app, _, runs := newTestApp(t, fiber.StatusOK)
+
+	post(t, app, "key-1", `{"title":"Sale"}`)
+	status, _, body := post(t, app, "key-1", `{"title":"Outage"}`)
+
+	assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+	assert.Contains(t, body, "different request body")
+	assert.Equal(t, int32(1), runs.Load())
+}
+
+// TestNew_InProgress verifies a repeat arriving while the first request runs gets 409.
+func TestNew_InProgress(t *testing.T) {
+	app, mr, runs := newTestApp(t, fiber.StatusOK)
+
+	// Stand in for a first request that claimed the key and has not finished
+	claimed := `{"pending":true,"fingerprint":"` + digest([]byte(`{}`)) + `"}`
+	post(t, app, "other", `{}`)
+	for _, key := range mr.Keys() {
+		mr.Set(key, claimed)
+	}
+
+	status, _, body := post(t, app, "other", `{}`)
+	assert.Equal(t, fiber.StatusConflict, status)
+	assert.Contains(t, body, "still in progress")
+	assert.Equal(t, int32(1), runs.Load())
+}
+
+// TestNew_ServerErrorNotStored verifies 5xx responses release the key so a retry runs the handler again.
+func TestNew_ServerErrorNotStored(t *testing.T) {
+	app, mr, runs := newTestApp(t, fiber.StatusInternalServerError)
+
+	post(t, app, "key-1", `{}`)
+	assert.Empty(t, mr.Keys())
+
+	_, replayed, _ := post(t, app, "key-1", `{}`)
+	assert.Empty(t, replayed)
+	assert.Equal(t, int32(2), runs.Load())
+}
+
+// TestNew_KeyTooLong verifies oversized keys are rejected before running the handler.
+func TestNew_KeyTooLong(t *testing.T) {
+	app, _, runs := newTestApp(t, fiber.StatusOK)
+
+	status, _, body := post(t, app, strings.Repeat("k", maxKeyLength+1), `{}`)
+
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	assert.Contains(t, body, Header)
+	assert.Zero(t, runs.Load())
+}
+
+// TestNew_StoreUnavailable verifies requests still run when the store is down.
+func TestNew_StoreUnavailable(t *testing.T) {
+	app, mr, runs := newTestApp(t, fiber.StatusOK)
+	mr.Close()
+
+	status, _, _ := post(t, app, "key-1", `{}`)
+
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, int32(1), runs.Load())
+}
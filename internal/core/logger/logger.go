@@ -1,51 +1,184 @@
 package logger
 
 import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-var globalLogger *zap.Logger
+// global is the logger built by the last Init together with the level controlling it. Both are
+// swapped in one atomic store so Get, SetLevel and Sync never see a logger paired with another
+// Init's level, even while Init runs concurrently.
+var global atomic.Pointer[state]
+
+// state is a built logger and the level it was built with, which can be changed at runtime.
+type state struct {
+	logger *zap.Logger
+	level  zap.AtomicLevel
+}
+
+// FileOutput configures logging to a rotated file instead of stdout.
+type FileOutput struct {
+	// Path is the log file path. Empty keeps logging to stdout.
+	Path string
+	// MaxSizeMB is the size in megabytes at which the file is rotated.
+	MaxSizeMB int
+	// MaxBackups is the number of rotated files to keep (0 keeps all).
+	MaxBackups int
+	// MaxAgeDays is the number of days to keep rotated files (0 keeps all).
+	MaxAgeDays int
+}
+
+// Sampling configures how repeated entries are dropped in production so bursts of identical
+// logs (e.g. from scrapes or the proxy forwarder) don't flood the log pipeline. Entries are
+// counted per level and message over one-second windows.
+type Sampling struct {
+	// Initial is how many identical entries are logged each second before sampling starts. Zero disables sampling.
+	Initial int
+	// Thereafter logs every Nth identical entry after Initial within the same second (0 drops them all).
+	Thereafter int
+}
+
+// Log output formats accepted by Init.
+const (
+	// FormatConsole writes human-readable lines with colored levels.
+	FormatConsole = "console"
+	// FormatJSON writes one JSON object per entry.
+	FormatJSON = "json"
+)
 
 // Init initializes the global logger.
-// For "development" env, it produces pretty console logs.
-// For "production" env, it (usually) produces JSON logs.
-func Init(environment string, level string) error {
+// For "development" env, it produces pretty console logs with debug defaults and no sampling.
+// For "production" env, it produces JSON logs sampled as configured by sampling.
+// format overrides the environment's output format ("console" or "json"); empty keeps it.
+// When file.Path is set, logs are written to that file with rotation.
+// Init may be called again, e.g. from tests or on reload; the previous logger is flushed and replaced,
+// and loggers already handed out by Get keep working.
+func Init(environment string, level string, format string, file FileOutput, sampling Sampling) error {
+	config, err := newConfig(environment, format, sampling)
+	if err != nil {
+		return err
+	}
+
+	if l, err := zapcore.ParseLevel(level); err == nil {
+		config.Level.SetLevel(l)
+	}
+
+	var opts []zap.Option
+	if file.Path != "" {
+		opts = append(opts, zap.WrapCore(func(zapcore.Core) zapcore.Core {
+			return newFileCore(config, file)
+		}))
+	}
+
+	logger, err := config.Build(opts...)
+	if err != nil {
+		return err
+	}
+
+	if previous := global.Swap(&state{logger: logger, level: config.Level}); previous != nil {
+		_ = previous.logger.Sync()
+	}
+	return nil
+}
+
+// newConfig returns the zap configuration for environment, output format and sampling.
+func newConfig(environment, format string, sampling Sampling) (zap.Config, error) {
 	var config zap.Config
 
 	if environment == "production" {
 		config = zap.NewProductionConfig()
+		config.Sampling = nil
+		if sampling.Initial > 0 {
+			config.Sampling = &zap.SamplingConfig{Initial: sampling.Initial, Thereafter: sampling.Thereafter}
+		}
 	} else {
 		config = zap.NewDevelopmentConfig()
 		config.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
 	}
 
-	l, err := zapcore.ParseLevel(level)
-	if err == nil {
-		config.Level = zap.NewAtomicLevelAt(l)
+	switch format {
+	case "":
+	case FormatConsole:
+		if config.Encoding != FormatConsole {
+			config.Encoding = FormatConsole
+			config.EncoderConfig = zap.NewDevelopmentEncoderConfig()
+			config.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		}
+	case FormatJSON:
+		if config.Encoding != FormatJSON {
+			config.Encoding = FormatJSON
+			config.EncoderConfig = zap.NewProductionEncoderConfig()
+		}
+	default:
+		return config, fmt.Errorf("unknown log format %q, expected %q or %q", format, FormatConsole, FormatJSON)
+	}
+
+	return config, nil
+}
+
+// newFileCore builds a core with the encoder and level of config that writes to a rotated file.
+func newFileCore(config zap.Config, file FileOutput) zapcore.Core {
+	var encoder zapcore.Encoder
+	if config.Encoding == FormatJSON {
+		encoder = zapcore.NewJSONEncoder(config.EncoderConfig)
+	} else {
+		// Color codes are noise in a file
+		config.EncoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+		encoder = zapcore.NewConsoleEncoder(config.EncoderConfig)
 	}
 
-	logger, err := config.Build()
+	writer := &lumberjack.Logger{
+		Filename:   file.Path,
+		MaxSize:    file.MaxSizeMB,
+		MaxBackups: file.MaxBackups,
+		MaxAge:     file.MaxAgeDays,
+	}
+
+	core := zapcore.NewCore(encoder, zapcore.AddSync(writer), config.Level)
+	// The file core replaces the one config.Build sampled, so it is sampled the same way
+	if config.Sampling != nil {
+		core = zapcore.NewSamplerWithOptions(core, time.Second, config.Sampling.Initial, config.Sampling.Thereafter)
+	}
+	return core
+}
+
+// SetLevel changes the level of the global logger without rebuilding it.
+// Before Init there is no logger to change, so it only validates level.
+func SetLevel(level string) error {
+	l, err := zapcore.ParseLevel(level)
 	if err != nil {
 		return err
 	}
-
-	globalLogger = logger
+	if s := global.Load(); s != nil {
+		s.level.SetLevel(l)
+	}
 	return nil
 }
 
 // Get returns the global logger instance.
 // If not initialized, it returns a no-op logger to prevent panics.
 func Get() *zap.Logger {
-	if globalLogger == nil {
+	s := global.Load()
+	if s == nil {
 		return zap.NewNop()
 	}
-	return globalLogger
+	return s.logger
+}
+
+// With returns the global logger tagged with rayID, so entries can be correlated with the
+// request's ray_id in access logs and error responses.
+func With(rayID string) *zap.Logger {
+	return Get().With(zap.String("ray_id", rayID))
 }
 
 // Sync flushes any buffered log entries.
 func Sync() {
-	if globalLogger != nil {
-		globalLogger.Sync()
+	if s := global.Load(); s != nil {
+		s.logger.Sync()
 	}
 }
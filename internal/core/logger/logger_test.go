@@ -1,6 +1,10 @@
 package logger
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -11,22 +15,22 @@ import (
 // TestInit verifies logger initialization for different environments.
 func TestInit(t *testing.T) {
 	t.Run("Development", func(t *testing.T) {
-		err := Init("development", "debug")
+		err := Init("development", "debug", "", FileOutput{}, Sampling{})
 		require.NoError(t, err)
-		assert.NotNil(t, globalLogger)
-		assert.True(t, globalLogger.Core().Enabled(zap.DebugLevel))
+		assert.NotNil(t, global.Load())
+		assert.True(t, Get().Core().Enabled(zap.DebugLevel))
 	})
 
 	t.Run("Production", func(t *testing.T) {
-		err := Init("production", "info")
+		err := Init("production", "info", "", FileOutput{}, Sampling{})
 		require.NoError(t, err)
-		assert.NotNil(t, globalLogger)
-		assert.False(t, globalLogger.Core().Enabled(zap.DebugLevel))
-		assert.True(t, globalLogger.Core().Enabled(zap.InfoLevel))
+		assert.NotNil(t, global.Load())
+		assert.False(t, Get().Core().Enabled(zap.DebugLevel))
+		assert.True(t, Get().Core().Enabled(zap.InfoLevel))
 	})
 
 	t.Run("InvalidLevel", func(t *testing.T) {
-		err := Init("development", "invalid_level")
+		err := Init("development", "invalid_level", "", FileOutput{}, Sampling{})
 		require.NoError(t, err)
 	})
 
@@ -34,21 +38,175 @@ func TestInit(t *testing.T) {
 	})
 }
 
+// TestNewConfig_Sampling verifies production samples with the configured values and development never does.
+func TestNewConfig_Sampling(t *testing.T) {
+	t.Run("ProductionDefault", func(t *testing.T) {
+		config, err := newConfig("production", "", Sampling{Initial: 100, Thereafter: 100})
+		require.NoError(t, err)
+		assert.Equal(t, &zap.SamplingConfig{Initial: 100, Thereafter: 100}, config.Sampling)
+	})
+
+	t.Run("ProductionCustom", func(t *testing.T) {
+		config, err := newConfig("production", FormatConsole, Sampling{Initial: 10, Thereafter: 50})
+		require.NoError(t, err)
+		assert.Equal(t, &zap.SamplingConfig{Initial: 10, Thereafter: 50}, config.Sampling)
+	})
+
+	t.Run("ProductionDisabled", func(t *testing.T) {
+		config, err := newConfig("production", "", Sampling{Initial: 0, Thereafter: 100})
+		require.NoError(t, err)
+		assert.Nil(t, config.Sampling)
+	})
+
+	t.Run("Development", func(t *testing.T) {
+		config, err := newConfig("development", "", Sampling{Initial: 100, Thereafter: 100})
+		require.NoError(t, err)
+		assert.Nil(t, config.Sampling)
+	})
+}
+
+// TestInit_FileOutputSampling verifies the rotated file output is sampled like stdout.
+func TestInit_FileOutputSampling(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	require.NoError(t, Init("production", "info", "", FileOutput{Path: path, MaxSizeMB: 1}, Sampling{Initial: 2, Thereafter: 0}))
+	defer func() { global.Store(nil) }()
+
+	for range 5 {
+		Get().Info("repeated")
+	}
+	Sync()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(data), `"msg":"repeated"`))
+}
+
 // TestGet verifies that Get returns the global logger.
 func TestGet(t *testing.T) {
-	globalLogger = nil
+	global.Store(nil)
 	assert.NotNil(t, Get())
 
-	Init("development", "info")
+	Init("development", "info", "", FileOutput{}, Sampling{})
 	assert.NotNil(t, Get())
 	assert.NotEqual(t, zap.NewNop(), Get())
 }
 
 // TestSync verifies that Sync does not panic even if logger is nil.
 func TestSync(t *testing.T) {
-	globalLogger = nil
+	global.Store(nil)
+	Sync()
+
+	Init("development", "info", "", FileOutput{}, Sampling{})
+	Sync()
+}
+
+// TestInit_Concurrent verifies Get, With, SetLevel and Sync can run while Init replaces the logger.
+// Run with -race to catch unsynchronized access to the global logger.
+func TestInit_Concurrent(t *testing.T) {
+	defer func() { global.Store(nil) }()
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				assert.NoError(t, Init("production", "info", "", FileOutput{}, Sampling{}))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range 200 {
+				assert.NotNil(t, Get())
+				With("ray-123").Debug("concurrent")
+				assert.NoError(t, SetLevel("warn"))
+				Sync()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.NotNil(t, global.Load())
+}
+
+// TestSetLevel_BeforeInit verifies SetLevel validates the level even without a logger to change.
+func TestSetLevel_BeforeInit(t *testing.T) {
+	global.Store(nil)
+
+	assert.NoError(t, SetLevel("debug"))
+	assert.Error(t, SetLevel("loud"))
+}
+
+// TestSetLevel verifies the level of the global logger can change at runtime.
+func TestSetLevel(t *testing.T) {
+	require.NoError(t, Init("production", "info", "", FileOutput{}, Sampling{}))
+	assert.False(t, Get().Core().Enabled(zap.DebugLevel))
+
+	require.NoError(t, SetLevel("debug"))
+	assert.True(t, Get().Core().Enabled(zap.DebugLevel))
+
+	assert.Error(t, SetLevel("loud"))
+}
+
+// TestInit_FileOutput verifies logs are written to the configured file.
+func TestInit_FileOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	require.NoError(t, Init("production", "info", "", FileOutput{Path: path, MaxSizeMB: 1, MaxBackups: 1, MaxAgeDays: 1}, Sampling{}))
+	defer func() { global.Store(nil) }()
+
+	Get().Info("written to file")
 	Sync()
 
-	Init("development", "info")
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"msg":"written to file"`)
+}
+
+// TestInit_Format verifies LOG_FORMAT overrides the environment's output format.
+func TestInit_Format(t *testing.T) {
+	defer func() { global.Store(nil) }()
+
+	t.Run("JSONInDevelopment", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		require.NoError(t, Init("development", "debug", FormatJSON, FileOutput{Path: path, MaxSizeMB: 1}, Sampling{}))
+
+		Get().Debug("json while debugging")
+		Sync()
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"msg":"json while debugging"`)
+		assert.Contains(t, string(data), `"level":"debug"`)
+	})
+
+	t.Run("ConsoleInProduction", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		require.NoError(t, Init("production", "info", FormatConsole, FileOutput{Path: path, MaxSizeMB: 1}, Sampling{}))
+
+		Get().Info("console in production")
+		Sync()
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "INFO")
+		assert.NotContains(t, string(data), `"msg"`)
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		assert.Error(t, Init("production", "info", "xml", FileOutput{}, Sampling{}))
+	})
+}
+
+// TestWith verifies the child logger tags every entry with the ray ID.
+func TestWith(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	require.NoError(t, Init("production", "info", "", FileOutput{Path: path, MaxSizeMB: 1}, Sampling{}))
+	defer func() { global.Store(nil) }()
+
+	With("ray-123").Info("correlated")
 	Sync()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"ray_id":"ray-123"`)
 }
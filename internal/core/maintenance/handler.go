@@ -0,0 +1,65 @@
+package maintenance
+
+import (
+	"net/http"
+
+	"tracker-scrapper/internal/core/logger"
+	"tracker-scrapper/internal/core/request"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// Handler exposes admin endpoints to inspect and toggle maintenance mode.
+type Handler struct {
+	mode *Mode
+	// strictJSON rejects request bodies containing unknown fields.
+	strictJSON bool
+}
+
+// NewHandler creates a new maintenance Handler.
+func NewHandler(mode *Mode, strictJSON bool) *Handler {
+	return &Handler{
+		mode:       mode,
+		strictJSON: strictJSON,
+	}
+}
+
+// StatusRequest represents the request and response body for maintenance mode.
+type StatusRequest struct {
+	// Enabled indicates whether maintenance mode is active.
+	Enabled bool `json:"enabled"`
+}
+
+// GetStatus handles GET /admin/maintenance.
+// @Summary Get maintenance mode status
+// @Description Returns whether the API is serving cached-only responses.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} StatusRequest
+// @Router /admin/maintenance [get]
+func (h *Handler) GetStatus(c *fiber.Ctx) error {
+	return c.Status(http.StatusOK).JSON(StatusRequest{Enabled: h.mode.Enabled()})
+}
+
+// SetStatus handles PUT /admin/maintenance.
+// @Summary Toggle maintenance mode
+// @Description Enables or disables cached-only responses for orders and tracking.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param status body StatusRequest true "Maintenance mode state"
+// @Success 200 {object} StatusRequest
+// @Failure 400 {object} request.ValidationErrorResponse
+// @Router /admin/maintenance [put]
+func (h *Handler) SetStatus(c *fiber.Ctx) error {
+	var req StatusRequest
+	if err := request.ParseJSON(c, &req, h.strictJSON); err != nil {
+		return request.Invalid(c, request.BodyError(err))
+	}
+
+	h.mode.Set(req.Enabled)
+	logger.Get().Info("Maintenance mode changed", zap.Bool("enabled", req.Enabled))
+
+	return c.Status(http.StatusOK).JSON(StatusRequest{Enabled: h.mode.Enabled()})
+}
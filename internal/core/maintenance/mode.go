@@ -0,0 +1,36 @@
+package maintenance
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// ErrCacheMiss is returned by services in maintenance mode when the requested data is not cached.
+var ErrCacheMiss = errors.New("service under maintenance: data not available in cache")
+
+// Mode is a process-wide maintenance toggle that can be flipped at runtime.
+// While enabled, services only read from cache and never call external providers.
+// A nil *Mode behaves as permanently disabled.
+type Mode struct {
+	enabled atomic.Bool
+}
+
+// NewMode creates a new Mode with the given initial state.
+func NewMode(enabled bool) *Mode {
+	m := &Mode{}
+	m.enabled.Store(enabled)
+	return m
+}
+
+// Enabled reports whether maintenance mode is active.
+func (m *Mode) Enabled() bool {
+	if m == nil {
+		return false
+	}
+	return m.enabled.Load()
+}
+
+// Set enables or disables maintenance mode.
+func (m *Mode) Set(enabled bool) {
+	m.enabled.Store(enabled)
+}
@@ -0,0 +1,43 @@
+package maintenance
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMode_Toggle verifies enabling and disabling maintenance mode.
+func TestMode_Toggle(t *testing.T) {
+	m := NewMode(false)
+	assert.False(t, m.Enabled())
+
+	m.Set(true)
+	assert.True(t, m.Enabled())
+}
+
+// TestMode_Nil verifies that a nil Mode is treated as disabled.
+func TestMode_Nil(t *testing.T) {
+	var m *Mode
+	assert.False(t, m.Enabled())
+}
+
+// TestHandler_SetStatus verifies the admin endpoint toggles the mode.
+func TestHandler_SetStatus(t *testing.T) {
+	m := NewMode(false)
+	h := NewHandler(m, false)
+
+	app := fiber.New()
+	app.Put("/admin/maintenance", h.SetStatus)
+
+	req := httptest.NewRequest("PUT", "/admin/maintenance", strings.NewReader(`{"enabled":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.True(t, m.Enabled())
+}
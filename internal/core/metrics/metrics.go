@@ -0,0 +1,168 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Scrape outcome label values.
+const (
+	// OutcomeSuccess labels a scrape that returned a tracking history.
+	OutcomeSuccess = "success"
+	// OutcomeTimeout labels a scrape that hit its context deadline.
+	OutcomeTimeout = "timeout"
+	// OutcomeError labels a scrape that failed for any other reason.
+	OutcomeError = "error"
+)
+
+// recorder groups the Prometheus collectors exposed by the application.
+type recorder struct {
+	scrapeDuration *prometheus.HistogramVec
+	scrapeOutcomes *prometheus.CounterVec
+	cacheHits      *prometheus.CounterVec
+	cacheMisses    *prometheus.CounterVec
+	breakerState   *prometheus.GaugeVec
+	proxyBytes     *prometheus.CounterVec
+	layoutChanges  *prometheus.CounterVec
+}
+
+var (
+	globalRecorder *recorder
+	globalRegistry *prometheus.Registry
+)
+
+// Init creates the application registry and registers all collectors.
+// Until Init is called every recording function is a no-op, so tests don't need a registry.
+func Init() error {
+	reg := prometheus.NewRegistry()
+
+	r := &recorder{
+		scrapeDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "tracker_scrape_duration_seconds",
+			Help:    "Duration of courier tracking scrapes in seconds.",
+			Buckets: []float64{1, 2.5, 5, 10, 15, 20, 30, 45, 60},
+		}, []string{"courier"}),
+		scrapeOutcomes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tracker_scrape_total",
+			Help: "Number of courier tracking scrapes by outcome (success, timeout, error).",
+		}, []string{"courier", "outcome"}),
+		cacheHits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tracker_cache_hits_total",
+			Help: "Number of cache hits by service.",
+		}, []string{"service"}),
+		cacheMisses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tracker_cache_misses_total",
+			Help: "Number of cache misses by service.",
+		}, []string{"service"}),
+		breakerState: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "tracker_courier_breaker_state",
+			Help: "Circuit breaker state per courier (0 closed, 1 half-open, 2 open).",
+		}, []string{"courier"}),
+		proxyBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tracker_proxy_bytes_total",
+			Help: "Bytes transferred through the upstream proxy by direction (in, out).",
+		}, []string{"direction"}),
+		layoutChanges: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tracker_courier_layout_changed_total",
+			Help: "Number of scrapes that found a courier page without the expected form or API call.",
+		}, []string{"courier"}),
+	}
+
+	for _, c := range []prometheus.Collector{
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		r.scrapeDuration,
+		r.scrapeOutcomes,
+		r.cacheHits,
+		r.cacheMisses,
+		r.breakerState,
+		r.proxyBytes,
+		r.layoutChanges,
+	} {
+		if err := reg.Register(c); err != nil {
+			return err
+		}
+	}
+
+	globalRecorder = r
+	globalRegistry = reg
+	return nil
+}
+
+// Handler returns the HTTP handler that exposes the registry in the Prometheus text format.
+// If Init has not been called, it serves an empty registry.
+func Handler() http.Handler {
+	reg := globalRegistry
+	if reg == nil {
+		reg = prometheus.NewRegistry()
+	}
+	return promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
+}
+
+// ObserveScrape records the duration and outcome of a courier scrape.
+func ObserveScrape(courier string, duration time.Duration, err error) {
+	if globalRecorder == nil {
+		return
+	}
+	globalRecorder.scrapeDuration.WithLabelValues(courier).Observe(duration.Seconds())
+	globalRecorder.scrapeOutcomes.WithLabelValues(courier, ScrapeOutcome(err)).Inc()
+}
+
+// CacheHit records a cache hit for the given service (e.g., "orders", "tracking").
+func CacheHit(service string) {
+	if globalRecorder == nil {
+		return
+	}
+	globalRecorder.cacheHits.WithLabelValues(service).Inc()
+}
+
+// CacheMiss records a cache miss for the given service (e.g., "orders", "tracking").
+func CacheMiss(service string) {
+	if globalRecorder == nil {
+		return
+	}
+	globalRecorder.cacheMisses.WithLabelValues(service).Inc()
+}
+
+// SetBreakerState records the circuit breaker state of a courier (0 closed, 1 half-open, 2 open).
+func SetBreakerState(courier string, state int) {
+	if globalRecorder == nil {
+		return
+	}
+	globalRecorder.breakerState.WithLabelValues(courier).Set(float64(state))
+}
+
+// AddProxyBytes records bytes received from (in) and sent to (out) the upstream proxy.
+func AddProxyBytes(in, out int64) {
+	if globalRecorder == nil {
+		return
+	}
+	globalRecorder.proxyBytes.WithLabelValues("in").Add(float64(in))
+	globalRecorder.proxyBytes.WithLabelValues("out").Add(float64(out))
+}
+
+// LayoutChanged records a scrape that found the courier page without the expected form or API call.
+func LayoutChanged(courier string) {
+	if globalRecorder == nil {
+		return
+	}
+	globalRecorder.layoutChanges.WithLabelValues(courier).Inc()
+}
+
+// ScrapeOutcome classifies a scrape error into an outcome label value.
+func ScrapeOutcome(err error) string {
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case errors.Is(err, context.DeadlineExceeded):
+		return OutcomeTimeout
+	default:
+		return OutcomeError
+	}
+}
@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestScrapeOutcome verifies error classification into outcome labels.
+func TestScrapeOutcome(t *testing.T) {
+	assert.Equal(t, OutcomeSuccess, ScrapeOutcome(nil))
+	assert.Equal(t, OutcomeTimeout, ScrapeOutcome(fmt.Errorf("timeout waiting for courier response: %w", context.DeadlineExceeded)))
+	assert.Equal(t, OutcomeError, ScrapeOutcome(errors.New("failed to launch browser")))
+}
+
+// TestRecording_WithoutInit verifies that recording is a no-op before Init.
+func TestRecording_WithoutInit(t *testing.T) {
+	globalRecorder = nil
+	globalRegistry = nil
+
+	assert.NotPanics(t, func() {
+		ObserveScrape("coordinadora_co", time.Second, nil)
+		CacheHit("orders")
+		CacheMiss("tracking")
+		SetBreakerState("coordinadora_co", 2)
+		AddProxyBytes(10, 5)
+		LayoutChanged("interrapidisimo_co")
+	})
+}
+
+// TestRecording_WithInit verifies that counters are updated and exposed by the handler.
+func TestRecording_WithInit(t *testing.T) {
+	require.NoError(t, Init())
+	defer func() {
+		globalRecorder = nil
+		globalRegistry = nil
+	}()
+
+	ObserveScrape("servientrega_co", 3*time.Second, nil)
+	ObserveScrape("servientrega_co", 60*time.Second, context.DeadlineExceeded)
+	CacheHit("tracking")
+	CacheMiss("tracking")
+	CacheMiss("tracking")
+	SetBreakerState("servientrega_co", 2)
+	AddProxyBytes(1024, 256)
+	LayoutChanged("interrapidisimo_co")
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(globalRecorder.scrapeOutcomes.WithLabelValues("servientrega_co", OutcomeSuccess)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(globalRecorder.scrapeOutcomes.WithLabelValues("servientrega_co", OutcomeTimeout)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(globalRecorder.cacheHits.WithLabelValues("tracking")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(globalRecorder.cacheMisses.WithLabelValues("tracking")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(globalRecorder.breakerState.WithLabelValues("servientrega_co")))
+	assert.Equal(t, 1024.0, testutil.ToFloat64(globalRecorder.proxyBytes.WithLabelValues("in")))
+	assert.Equal(t, 256.0, testutil.ToFloat64(globalRecorder.proxyBytes.WithLabelValues("out")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(globalRecorder.layoutChanges.WithLabelValues("interrapidisimo_co")))
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, 200, rec.Code)
+	assert.Contains(t, rec.Body.String(), "tracker_scrape_duration_seconds")
+}
@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"tracker-scrapper/internal/core/breaker"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Summary is a point-in-time overview of the recorded counters since startup.
+type Summary struct {
+	// CacheHitRate maps a service (e.g., "orders", "tracking") to its hit ratio in [0, 1].
+	CacheHitRate map[string]float64 `json:"cache_hit_rate"`
+	// AvgScrapeSeconds maps a courier to its mean scrape duration in seconds.
+	AvgScrapeSeconds map[string]float64 `json:"avg_scrape_seconds"`
+	// ScrapeOutcomes maps a courier to its scrape counts by outcome.
+	ScrapeOutcomes map[string]map[string]uint64 `json:"scrape_outcomes"`
+	// BreakerStates maps a courier to its circuit breaker state ("closed", "half-open" or "open").
+	BreakerStates map[string]string `json:"breaker_states"`
+}
+
+// GetSummary computes a Summary from the in-memory collectors.
+// It only reads counters, so it is cheap enough to serve on every health check.
+// Before Init it returns an empty Summary.
+func GetSummary() Summary {
+	summary := Summary{
+		CacheHitRate:     map[string]float64{},
+		AvgScrapeSeconds: map[string]float64{},
+		ScrapeOutcomes:   map[string]map[string]uint64{},
+		BreakerStates:    map[string]string{},
+	}
+	r := globalRecorder
+	if r == nil {
+		return summary
+	}
+
+	hits := map[string]float64{}
+	for _, m := range collect(r.cacheHits) {
+		hits[label(m, "service")] = m.GetCounter().GetValue()
+	}
+	totals := map[string]float64{}
+	for service, v := range hits {
+		totals[service] += v
+	}
+	for _, m := range collect(r.cacheMisses) {
+		totals[label(m, "service")] += m.GetCounter().GetValue()
+	}
+	for service, total := range totals {
+		if total > 0 {
+			summary.CacheHitRate[service] = hits[service] / total
+		}
+	}
+
+	for _, m := range collect(r.scrapeDuration) {
+		h := m.GetHistogram()
+		if h.GetSampleCount() > 0 {
+			summary.AvgScrapeSeconds[label(m, "courier")] = h.GetSampleSum() / float64(h.GetSampleCount())
+		}
+	}
+
+	for _, m := range collect(r.scrapeOutcomes) {
+		courier := label(m, "courier")
+		if summary.ScrapeOutcomes[courier] == nil {
+			summary.ScrapeOutcomes[courier] = map[string]uint64{}
+		}
+		summary.ScrapeOutcomes[courier][label(m, "outcome")] = uint64(m.GetCounter().GetValue())
+	}
+
+	for _, m := range collect(r.breakerState) {
+		summary.BreakerStates[label(m, "courier")] = breaker.State(m.GetGauge().GetValue()).String()
+	}
+
+	return summary
+}
+
+// collect reads the current samples of a collector.
+func collect(c prometheus.Collector) []*dto.Metric {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		c.Collect(ch)
+		close(ch)
+	}()
+
+	var out []*dto.Metric
+	for metric := range ch {
+		m := &dto.Metric{}
+		if err := metric.Write(m); err == nil {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// label returns the value of the named label on a sample.
+func label(m *dto.Metric, name string) string {
+	for _, pair := range m.GetLabel() {
+		if pair.GetName() == name {
+			return pair.GetValue()
+		}
+	}
+	return ""
+}
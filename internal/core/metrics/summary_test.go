@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"tracker-scrapper/internal/core/breaker"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetSummary_WithoutInit verifies an empty summary is returned before Init.
+func TestGetSummary_WithoutInit(t *testing.T) {
+	globalRecorder = nil
+	globalRegistry = nil
+
+	summary := GetSummary()
+	assert.Empty(t, summary.CacheHitRate)
+	assert.Empty(t, summary.AvgScrapeSeconds)
+	assert.Empty(t, summary.ScrapeOutcomes)
+	assert.Empty(t, summary.BreakerStates)
+}
+
+// TestGetSummary verifies hit rates and averages are computed from seeded counters.
+func TestGetSummary(t *testing.T) {
+	require.NoError(t, Init())
+	defer func() {
+		globalRecorder = nil
+		globalRegistry = nil
+	}()
+
+	CacheHit("tracking")
+	CacheHit("tracking")
+	CacheHit("tracking")
+	CacheMiss("tracking")
+	CacheMiss("orders")
+	ObserveScrape("coordinadora_co", 1*time.Second, nil)
+	ObserveScrape("coordinadora_co", 3*time.Second, context.DeadlineExceeded)
+	SetBreakerState("coordinadora_co", int(breaker.StateOpen))
+	SetBreakerState("servientrega_co", int(breaker.StateClosed))
+	SetBreakerState("interrapidisimo_co", int(breaker.StateHalfOpen))
+
+	summary := GetSummary()
+
+	assert.InDelta(t, 0.75, summary.CacheHitRate["tracking"], 1e-9)
+	assert.InDelta(t, 0.0, summary.CacheHitRate["orders"], 1e-9)
+	assert.InDelta(t, 2.0, summary.AvgScrapeSeconds["coordinadora_co"], 1e-9)
+	assert.Equal(t, uint64(1), summary.ScrapeOutcomes["coordinadora_co"][OutcomeSuccess])
+	assert.Equal(t, uint64(1), summary.ScrapeOutcomes["coordinadora_co"][OutcomeTimeout])
+	assert.Equal(t, map[string]string{
+		"coordinadora_co":    "open",
+		"servientrega_co":    "closed",
+		"interrapidisimo_co": "half-open",
+	}, summary.BreakerStates)
+}
@@ -0,0 +1,27 @@
+package proxy
+
+import (
+	"net"
+	"sync/atomic"
+)
+
+// countingConn wraps a tunnel connection and adds the bytes it transfers to shared counters.
+type countingConn struct {
+	net.Conn
+	bytesIn  *atomic.Int64
+	bytesOut *atomic.Int64
+}
+
+// Read implements net.Conn, counting bytes received from the upstream proxy.
+func (c *countingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.bytesIn.Add(int64(n))
+	return n, err
+}
+
+// Write implements net.Conn, counting bytes sent to the upstream proxy.
+func (c *countingConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.bytesOut.Add(int64(n))
+	return n, err
+}
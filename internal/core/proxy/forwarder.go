@@ -10,9 +10,11 @@ import (
 	"net/url"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"tracker-scrapper/internal/core/logger"
+	"tracker-scrapper/internal/core/metrics"
 
 	"github.com/elazarl/goproxy"
 	"go.uber.org/zap"
@@ -29,6 +31,11 @@ type ForwardingProxy struct {
 	mu             sync.Mutex
 	running        bool
 	allowedDomains []string
+	// OnTunnelError, when set, is called every time the upstream proxy fails to open a tunnel.
+	OnTunnelError func(error)
+	// bytesIn and bytesOut count the bytes transferred through all tunnels.
+	bytesIn  atomic.Int64
+	bytesOut atomic.Int64
 }
 
 // RedirectLogger adapts zap logger to goproxy.Logger interface
@@ -63,12 +70,13 @@ func NewForwardingProxy(upstreamURL string, allowedDomains ...string) (*Forwardi
 
 // Start launches the local proxy server on a random available port.
 // Returns the local address (e.g., "127.0.0.1:18080") for Chromium to use.
+// Start is safe to call concurrently; later calls return the address of the running server.
 func (fp *ForwardingProxy) Start(ctx context.Context) (string, error) {
 	fp.mu.Lock()
 	defer fp.mu.Unlock()
 
 	if fp.running {
-		return fp.LocalAddr(), nil
+		return fp.localAddr(), nil
 	}
 
 	// Create goproxy server
@@ -79,10 +87,8 @@ func (fp *ForwardingProxy) Start(ctx context.Context) (string, error) {
 	// Extract credentials from upstream URL
 	var proxyAuth string
 	if fp.upstreamURL.User != nil {
-		username := fp.upstreamURL.User.Username()
 		password, _ := fp.upstreamURL.User.Password()
-		credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
-		proxyAuth = "Basic " + credentials
+		proxyAuth = basicAuth(fp.upstreamURL.User.Username(), password)
 	}
 
 	// Build upstream host for the transport
@@ -120,49 +126,21 @@ func (fp *ForwardingProxy) Start(ctx context.Context) (string, error) {
 			zap.String("upstream", upstreamHost),
 		)
 
-		// Connect to upstream proxy
-		conn, err := net.DialTimeout("tcp", upstreamHost, 30*time.Second)
+		conn, err := dialConnect(context.Background(), upstreamHost, proxyAuth, addr)
 		if err != nil {
-			log.Error("Failed to dial upstream proxy",
+			log.Error("Upstream proxy tunnel failed",
 				zap.String("upstream", upstreamHost),
-				zap.Error(err),
-			)
-			return nil, fmt.Errorf("failed to connect to upstream proxy %s: %w", upstreamHost, err)
-		}
-
-		// Send CONNECT request to upstream proxy
-		connectReq := fmt.Sprintf("CONNECT %s HTTP/1.1\r\nHost: %s\r\n", addr, addr)
-		if proxyAuth != "" {
-			connectReq += fmt.Sprintf("Proxy-Authorization: %s\r\n", proxyAuth)
-		}
-		connectReq += "\r\n"
-
-		log.Debug("Sending CONNECT to upstream", zap.String("target", addr))
-
-		if _, err := conn.Write([]byte(connectReq)); err != nil {
-			conn.Close()
-			return nil, fmt.Errorf("failed to send CONNECT request: %w", err)
-		}
-
-		// Read response from upstream proxy
-		br := bufio.NewReader(conn)
-		resp, err := http.ReadResponse(br, nil)
-		if err != nil {
-			conn.Close()
-			return nil, fmt.Errorf("failed to read CONNECT response: %w", err)
-		}
-
-		if resp.StatusCode != 200 {
-			conn.Close()
-			log.Error("Upstream proxy rejected CONNECT",
-				zap.Int("status", resp.StatusCode),
 				zap.String("target", addr),
+				zap.Error(err),
 			)
-			return nil, fmt.Errorf("upstream proxy CONNECT failed with status: %d", resp.StatusCode)
+			if fp.OnTunnelError != nil {
+				fp.OnTunnelError(err)
+			}
+			return nil, err
 		}
 
 		log.Debug("CONNECT tunnel established", zap.String("target", addr))
-		return conn, nil
+		return &countingConn{Conn: conn, bytesIn: &fp.bytesIn, bytesOut: &fp.bytesOut}, nil
 	}
 
 	// Set ConnectDial for HTTPS CONNECT requests
@@ -209,7 +187,7 @@ func (fp *ForwardingProxy) Start(ctx context.Context) (string, error) {
 	}
 
 	fp.logger.Debug("Starting local proxy forwarder",
-		zap.String("local_addr", fp.LocalAddr()),
+		zap.String("local_addr", fp.localAddr()),
 		zap.String("upstream", upstreamHost),
 		zap.Strings("allowed_domains", fp.allowedDomains),
 	)
@@ -221,12 +199,66 @@ func (fp *ForwardingProxy) Start(ctx context.Context) (string, error) {
 		}
 	}()
 
+	// The listener is already bound, so connections queue even before Serve picks them up;
+	// dial it once to confirm it accepts before handing the address to the browser
+	ready, err := net.DialTimeout("tcp", listener.Addr().String(), time.Second)
+	if err != nil {
+		fp.server.Close()
+		return "", fmt.Errorf("local proxy forwarder not accepting connections: %w", err)
+	}
+	ready.Close()
+
 	fp.running = true
 
-	// Give the server a moment to start
-	time.Sleep(50 * time.Millisecond)
+	return fp.localAddr(), nil
+}
+
+// dialConnect connects to the upstream proxy and opens a CONNECT tunnel to target (host:port).
+// proxyAuth is sent as Proxy-Authorization when non-empty.
+func dialConnect(ctx context.Context, upstreamHost, proxyAuth, target string) (net.Conn, error) {
+	dialer := net.Dialer{Timeout: 30 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", upstreamHost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to upstream proxy %s: %w", upstreamHost, err)
+	}
+
+	// Bound the handshake by ctx; the deadline is cleared once the tunnel is up
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	// Send CONNECT request to upstream proxy
+	connectReq := fmt.Sprintf("CONNECT %s HTTP/1.1\r\nHost: %s\r\n", target, target)
+	if proxyAuth != "" {
+		connectReq += fmt.Sprintf("Proxy-Authorization: %s\r\n", proxyAuth)
+	}
+	connectReq += "\r\n"
 
-	return fp.LocalAddr(), nil
+	if _, err := conn.Write([]byte(connectReq)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send CONNECT request: %w", err)
+	}
+
+	// Read response from upstream proxy
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read CONNECT response: %w", err)
+	}
+
+	if resp.StatusCode != 200 {
+		conn.Close()
+		return nil, fmt.Errorf("upstream proxy CONNECT failed with status: %d", resp.StatusCode)
+	}
+
+	conn.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+// basicAuth returns the Proxy-Authorization value for username and password.
+func basicAuth(username, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
 }
 
 // Stop gracefully shuts down the local proxy server.
@@ -238,7 +270,12 @@ func (fp *ForwardingProxy) Stop() error {
 		return nil
 	}
 
-	fp.logger.Debug("Stopping local proxy forwarder")
+	bytesIn, bytesOut := fp.Stats()
+	fp.logger.Debug("Stopping local proxy forwarder",
+		zap.Int64("bytes_in", bytesIn),
+		zap.Int64("bytes_out", bytesOut),
+	)
+	metrics.AddProxyBytes(bytesIn, bytesOut)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -255,9 +292,21 @@ func (fp *ForwardingProxy) Stop() error {
 // LocalAddr returns the local proxy address for Chromium to connect to.
 // Returns format "http://127.0.0.1:<port>"
 func (fp *ForwardingProxy) LocalAddr() string {
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+	return fp.localAddr()
+}
+
+// localAddr formats the local proxy address. Callers must hold mu.
+func (fp *ForwardingProxy) localAddr() string {
 	return fmt.Sprintf("http://127.0.0.1:%d", fp.localPort)
 }
 
+// Stats returns the bytes received from and sent to the upstream proxy through all tunnels so far.
+func (fp *ForwardingProxy) Stats() (bytesIn, bytesOut int64) {
+	return fp.bytesIn.Load(), fp.bytesOut.Load()
+}
+
 // IsRunning returns whether the proxy server is currently running.
 func (fp *ForwardingProxy) IsRunning() bool {
 	fp.mu.Lock()
@@ -0,0 +1,172 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"tracker-scrapper/internal/core/logger"
+
+	"go.uber.org/zap"
+)
+
+// ErrNoHealthyProxy is returned by Pool.Pick when every endpoint is benched or fails the CONNECT test.
+var ErrNoHealthyProxy = errors.New("no healthy proxy available")
+
+// checkTimeout bounds the CONNECT test run before handing an endpoint to a scrape.
+const checkTimeout = 5 * time.Second
+
+// Endpoint is the address of a single upstream proxy.
+type Endpoint struct {
+	Hostname string
+	Port     int
+}
+
+// ParseEndpoints parses "host:port" entries into endpoints.
+func ParseEndpoints(hosts []string) ([]Endpoint, error) {
+	endpoints := make([]Endpoint, 0, len(hosts))
+	for _, h := range hosts {
+		host, portStr, err := net.SplitHostPort(strings.TrimSpace(h))
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy endpoint %q: %w", h, err)
+		}
+		port, err := strconv.Atoi(portStr)
+		if err != nil || port < 1 || port > 65535 {
+			return nil, fmt.Errorf("invalid proxy endpoint %q: bad port", h)
+		}
+		endpoints = append(endpoints, Endpoint{Hostname: host, Port: port})
+	}
+	return endpoints, nil
+}
+
+// Pool rotates scrapes across several upstream proxies sharing the same credentials.
+// Endpoints that fail the CONNECT test or a tunnel are benched for a while and skipped.
+type Pool struct {
+	endpoints []Endpoint
+	username  string
+	password  string
+	benchFor  time.Duration
+	logger    *zap.Logger
+	// check tests an endpoint before use; replaced in tests.
+	check func(ctx context.Context, s Settings, target string) error
+	// now returns the current time; replaced in tests.
+	now func() time.Time
+
+	mu           sync.Mutex
+	next         int
+	benchedUntil map[Endpoint]time.Time
+}
+
+// NewPool creates a Pool over endpoints. Failing endpoints are benched for benchFor.
+func NewPool(endpoints []Endpoint, username, password string, benchFor time.Duration) *Pool {
+	return &Pool{
+		endpoints:    endpoints,
+		username:     username,
+		password:     password,
+		benchFor:     benchFor,
+		logger:       logger.Get(),
+		check:        CheckConnect,
+		now:          time.Now,
+		benchedUntil: make(map[Endpoint]time.Time),
+	}
+}
+
+// Pick returns settings for the next healthy endpoint in round-robin order.
+// Each candidate must open a CONNECT tunnel to target (host:port); failures are benched.
+func (p *Pool) Pick(ctx context.Context, target string) (Settings, error) {
+	for range p.endpoints {
+		endpoint, ok := p.nextAvailable()
+		if !ok {
+			break
+		}
+
+		s := p.settings(endpoint)
+		checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+		err := p.check(checkCtx, s, target)
+		cancel()
+		if err == nil {
+			return s, nil
+		}
+
+		p.logger.Warn("Proxy failed CONNECT test",
+			zap.String("proxy", s.HostPort()),
+			zap.String("target", target),
+			zap.Error(err),
+		)
+		p.Bench(s)
+	}
+	return Settings{}, ErrNoHealthyProxy
+}
+
+// Bench takes the endpoint used by s out of rotation for the bench period.
+func (p *Pool) Bench(s Settings) {
+	endpoint := Endpoint{Hostname: s.Hostname, Port: s.Port}
+
+	p.mu.Lock()
+	p.benchedUntil[endpoint] = p.now().Add(p.benchFor)
+	p.mu.Unlock()
+
+	p.logger.Warn("Proxy benched",
+		zap.String("proxy", s.HostPort()),
+		zap.Duration("for", p.benchFor),
+	)
+}
+
+// nextAvailable advances the round-robin cursor to the next endpoint that isn't benched.
+func (p *Pool) nextAvailable() (Endpoint, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	for range p.endpoints {
+		endpoint := p.endpoints[p.next]
+		p.next = (p.next + 1) % len(p.endpoints)
+		if until, benched := p.benchedUntil[endpoint]; !benched || !now.Before(until) {
+			return endpoint, true
+		}
+	}
+	return Endpoint{}, false
+}
+
+// settings builds the proxy settings for endpoint.
+func (p *Pool) settings(endpoint Endpoint) Settings {
+	return Settings{
+		Enabled:  true,
+		Hostname: endpoint.Hostname,
+		Port:     endpoint.Port,
+		Username: p.username,
+		Password: p.password,
+		Pool:     p,
+	}
+}
+
+// CheckConnect verifies that s can open a CONNECT tunnel to target (host:port).
+func CheckConnect(ctx context.Context, s Settings, target string) error {
+	upstream := net.JoinHostPort(s.Hostname, strconv.Itoa(s.Port))
+	conn, err := dialConnect(ctx, upstream, s.authorization(), target)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// TargetFromURL returns the host:port a tunnel to rawURL would CONNECT to.
+func TargetFromURL(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid target URL: %w", err)
+	}
+	if u.Port() != "" {
+		return u.Host, nil
+	}
+	if u.Scheme == "http" {
+		return net.JoinHostPort(u.Hostname(), "80"), nil
+	}
+	return net.JoinHostPort(u.Hostname(), "443"), nil
+}
@@ -0,0 +1,177 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestPool returns a pool whose CONNECT test fails for the hosts in down.
+func newTestPool(down map[string]bool, hosts ...string) (*Pool, *time.Time) {
+	endpoints := make([]Endpoint, 0, len(hosts))
+	for _, h := range hosts {
+		endpoints = append(endpoints, Endpoint{Hostname: h, Port: 8080})
+	}
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := NewPool(endpoints, "user", "pass", time.Minute)
+	p.now = func() time.Time { return now }
+	p.check = func(ctx context.Context, s Settings, target string) error {
+		if down[s.Hostname] {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+	return p, &now
+}
+
+// TestPool_Pick_RoundRobin verifies endpoints are used in turn with the shared credentials.
+func TestPool_Pick_RoundRobin(t *testing.T) {
+	p, _ := newTestPool(nil, "a", "b")
+
+	var picked []string
+	for i := 0; i < 3; i++ {
+		s, err := p.Pick(context.Background(), "example.com:443")
+		require.NoError(t, err)
+		picked = append(picked, s.Hostname)
+		assert.Equal(t, "user", s.Username)
+		assert.Same(t, p, s.Pool)
+	}
+
+	assert.Equal(t, []string{"a", "b", "a"}, picked)
+}
+
+// TestPool_Pick_SkipsFailingEndpoints verifies failed CONNECT tests bench an endpoint until it expires.
+func TestPool_Pick_SkipsFailingEndpoints(t *testing.T) {
+	down := map[string]bool{"a": true}
+	p, now := newTestPool(down, "a", "b")
+
+	s, err := p.Pick(context.Background(), "example.com:443")
+	require.NoError(t, err)
+	assert.Equal(t, "b", s.Hostname)
+
+	// "a" stays benched even after recovering
+	down["a"] = false
+	s, err = p.Pick(context.Background(), "example.com:443")
+	require.NoError(t, err)
+	assert.Equal(t, "b", s.Hostname)
+
+	*now = now.Add(time.Minute)
+	s, err = p.Pick(context.Background(), "example.com:443")
+	require.NoError(t, err)
+	assert.Equal(t, "a", s.Hostname)
+}
+
+// TestPool_Pick_NoHealthyProxy verifies an error is returned when every endpoint is down.
+func TestPool_Pick_NoHealthyProxy(t *testing.T) {
+	p, _ := newTestPool(map[string]bool{"a": true, "b": true}, "a", "b")
+
+	_, err := p.Pick(context.Background(), "example.com:443")
+
+	assert.ErrorIs(t, err, ErrNoHealthyProxy)
+}
+
+// TestPool_Bench verifies tunnel failures reported by callers bench the endpoint.
+func TestPool_Bench(t *testing.T) {
+	p, _ := newTestPool(nil, "a", "b")
+
+	p.Bench(Settings{Hostname: "a", Port: 8080})
+
+	for i := 0; i < 2; i++ {
+		s, err := p.Pick(context.Background(), "example.com:443")
+		require.NoError(t, err)
+		assert.Equal(t, "b", s.Hostname)
+	}
+}
+
+// TestSettings_Resolve verifies pooled settings keep the courier's allowed domains.
+func TestSettings_Resolve(t *testing.T) {
+	p, _ := newTestPool(nil, "a", "b")
+
+	single := Settings{Enabled: true, Hostname: "single", Port: 1}
+	s, err := single.Resolve(context.Background(), "https://example.com/track")
+	require.NoError(t, err)
+	assert.Equal(t, single, s)
+
+	pooled := Settings{Enabled: true, Hostname: "a", Port: 8080, AllowedDomains: []string{"example.com"}, Pool: p}
+	s, err = pooled.Resolve(context.Background(), "https://example.com/track")
+	require.NoError(t, err)
+	assert.Equal(t, "a", s.Hostname)
+	assert.Equal(t, []string{"example.com"}, s.AllowedDomains)
+
+	disabled := Settings{Pool: p}
+	s, err = disabled.Resolve(context.Background(), "https://example.com/track")
+	require.NoError(t, err)
+	assert.False(t, s.HasProxy())
+}
+
+// TestParseEndpoints verifies host:port parsing.
+func TestParseEndpoints(t *testing.T) {
+	endpoints, err := ParseEndpoints([]string{"geo.iproyal.com:12321", " 10.0.0.1:3128"})
+	require.NoError(t, err)
+	assert.Equal(t, []Endpoint{{"geo.iproyal.com", 12321}, {"10.0.0.1", 3128}}, endpoints)
+
+	_, err = ParseEndpoints([]string{"missing-port"})
+	assert.Error(t, err)
+
+	_, err = ParseEndpoints([]string{"host:0"})
+	assert.Error(t, err)
+}
+
+// TestTargetFromURL verifies the CONNECT target defaults the port from the scheme.
+func TestTargetFromURL(t *testing.T) {
+	tests := map[string]string{
+		"https://mobile.servientrega.com/x?Guia=": "mobile.servientrega.com:443",
+		"http://localhost/track":                  "localhost:80",
+		"http://127.0.0.1:8081/track":             "127.0.0.1:8081",
+	}
+	for in, want := range tests {
+		got, err := TargetFromURL(in)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+}
+
+// TestCheckConnect verifies the CONNECT test against a fake upstream proxy.
+func TestCheckConnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			req, err := http.ReadRequest(bufio.NewReader(conn))
+			if err == nil && req.Header.Get("Proxy-Authorization") == basicAuth("user", "pass") {
+				conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+			} else {
+				conn.Write([]byte("HTTP/1.1 407 Proxy Authentication Required\r\n\r\n"))
+			}
+			conn.Close()
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	s := Settings{Enabled: true, Hostname: "127.0.0.1", Port: addr.Port, Username: "user", Password: "pass"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	assert.NoError(t, CheckConnect(ctx, s, "example.com:443"))
+
+	s.Password = "wrong"
+	err = CheckConnect(ctx, s, "example.com:443")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), strconv.Itoa(http.StatusProxyAuthRequired))
+}
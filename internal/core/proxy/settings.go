@@ -1,6 +1,9 @@
 package proxy
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 // Settings contains proxy configuration for adapters.
 type Settings struct {
@@ -9,6 +12,31 @@ type Settings struct {
 	Port     int
 	Username string
 	Password string
+	// AllowedDomains restricts the domains tunneled through the proxy. Empty allows all domains.
+	AllowedDomains []string
+	// Pool, when set, supplies the endpoint for each scrape instead of Hostname and Port.
+	Pool *Pool
+}
+
+// Resolve returns the settings to use for a scrape of targetURL.
+// With a pool it picks a healthy endpoint, keeping the courier's flags and allowed domains;
+// otherwise it returns p unchanged.
+func (p Settings) Resolve(ctx context.Context, targetURL string) (Settings, error) {
+	if !p.Enabled || p.Pool == nil {
+		return p, nil
+	}
+
+	target, err := TargetFromURL(targetURL)
+	if err != nil {
+		return Settings{}, err
+	}
+
+	picked, err := p.Pool.Pick(ctx, target)
+	if err != nil {
+		return Settings{}, err
+	}
+	picked.AllowedDomains = p.AllowedDomains
+	return picked, nil
 }
 
 // HasProxy returns true if proxy is enabled and configured.
@@ -34,3 +62,11 @@ func (p Settings) FullURL() string {
 	}
 	return p.HostPort()
 }
+
+// authorization returns the Proxy-Authorization header value, or "" without credentials.
+func (p Settings) authorization() string {
+	if p.Username == "" || p.Password == "" {
+		return ""
+	}
+	return basicAuth(p.Username, p.Password)
+}
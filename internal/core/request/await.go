@@ -0,0 +1,27 @@
+package request
+
+import "context"
+
+// Await runs work in the background and waits for it until ctx is done, so handlers give up
+// when the request timeout expires. Work outliving ctx keeps running, so a lookup that finishes
+// late still fills the cache; it must not touch the Fiber context or memory borrowed from it.
+func Await[T any](ctx context.Context, work func() (T, error)) (T, error) {
+	type outcome struct {
+		value T
+		err   error
+	}
+
+	done := make(chan outcome, 1)
+	go func() {
+		value, err := work()
+		done <- outcome{value, err}
+	}()
+
+	select {
+	case o := <-done:
+		return o.value, o.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
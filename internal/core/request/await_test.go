@@ -0,0 +1,41 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAwait verifies the work's result is returned when it finishes before ctx.
+func TestAwait(t *testing.T) {
+	value, err := Await(context.Background(), func() (string, error) { return "done", nil })
+	assert.NoError(t, err)
+	assert.Equal(t, "done", value)
+
+	_, err = Await(context.Background(), func() (string, error) { return "", errors.New("boom") })
+	assert.EqualError(t, err, "boom")
+}
+
+// TestAwait_ContextDone verifies Await gives up when ctx expires while the work keeps running.
+func TestAwait_ContextDone(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	finished := make(chan struct{})
+	value, err := Await(ctx, func() (string, error) {
+		defer close(finished)
+		time.Sleep(50 * time.Millisecond)
+		return "late", nil
+	})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Empty(t, value)
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("work did not finish after Await returned")
+	}
+}
@@ -0,0 +1,45 @@
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// UnknownFieldError is returned by ParseJSON in strict mode when the body contains a field
+// that does not exist in the target struct.
+type UnknownFieldError struct {
+	// Field is the name of the offending JSON field.
+	Field string
+}
+
+// Error implements the error interface.
+func (e *UnknownFieldError) Error() string {
+	return fmt.Sprintf("unknown field: %s", e.Field)
+}
+
+// ParseJSON decodes the request body into out.
+// When strict is false it behaves like fiber's BodyParser; when true the body must be JSON
+// and unknown fields are rejected with an *UnknownFieldError.
+func ParseJSON(c *fiber.Ctx, out interface{}, strict bool) error {
+	if !strict {
+		return c.BodyParser(out)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(c.Body()))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(out); err != nil {
+		// encoding/json reports unknown fields as: json: unknown field "name"
+		const prefix = "json: unknown field "
+		if msg := err.Error(); strings.HasPrefix(msg, prefix) {
+			return &UnknownFieldError{Field: strings.Trim(strings.TrimPrefix(msg, prefix), `"`)}
+		}
+		return err
+	}
+
+	return nil
+}
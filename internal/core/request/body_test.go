@@ -0,0 +1,60 @@
+package request
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testPayload struct {
+	Title string `json:"title"`
+}
+
+// parseWith runs ParseJSON inside a Fiber handler and returns the decoded payload and error.
+func parseWith(t *testing.T, body string, strict bool) (testPayload, error) {
+	var payload testPayload
+	var parseErr error
+
+	app := fiber.New()
+	app.Post("/", func(c *fiber.Ctx) error {
+		parseErr = ParseJSON(c, &payload, strict)
+		return nil
+	})
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	_, err := app.Test(req)
+	require.NoError(t, err)
+
+	return payload, parseErr
+}
+
+// TestParseJSON_Lenient verifies that unknown fields are ignored when strict mode is off.
+func TestParseJSON_Lenient(t *testing.T) {
+	payload, err := parseWith(t, `{"title":"Hello","titel":"typo"}`, false)
+
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", payload.Title)
+}
+
+// TestParseJSON_StrictUnknownField verifies that unknown fields are rejected in strict mode.
+func TestParseJSON_StrictUnknownField(t *testing.T) {
+	_, err := parseWith(t, `{"titel":"typo"}`, true)
+
+	var unknownErr *UnknownFieldError
+	require.True(t, errors.As(err, &unknownErr))
+	assert.Equal(t, "titel", unknownErr.Field)
+}
+
+// TestParseJSON_StrictValid verifies that a well-formed body decodes in strict mode.
+func TestParseJSON_StrictValid(t *testing.T) {
+	payload, err := parseWith(t, `{"title":"Hello"}`, true)
+
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", payload.Title)
+}
@@ -0,0 +1,53 @@
+package request
+
+import (
+	"encoding/json"
+	"strings"
+
+	"tracker-scrapper/internal/core/etag"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// NotModified reports whether the request's If-None-Match header matches tag.
+// Weak and strong validators match each other, as RFC 9110 requires for If-None-Match.
+func NotModified(c *fiber.Ctx, tag string) bool {
+	header := c.Get(fiber.HeaderIfNoneMatch)
+	if header == "" {
+		return false
+	}
+
+	tag = strings.TrimPrefix(tag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// JSONWithETag sends v as JSON with an ETag header, or an empty 304 when the client already has it.
+// tag is the ETag of v, usually the weak tag cached with it; when empty v is encoded here and
+// tagged with the strong ETag of the result.
+func JSONWithETag(c *fiber.Ctx, v interface{}, tag string) error {
+	var body []byte
+	if tag == "" {
+		var err error
+		if body, err = json.Marshal(v); err != nil {
+			return err
+		}
+		tag = etag.Compute(body)
+	}
+
+	c.Set(fiber.HeaderETag, tag)
+	if NotModified(c, tag) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	if body == nil {
+		return c.JSON(v)
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(body)
+}
@@ -0,0 +1,77 @@
+package request
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"tracker-scrapper/internal/core/etag"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestJSONWithETag verifies the ETag header and the 304 path with and without a precomputed ETag.
+func TestJSONWithETag(t *testing.T) {
+	value := map[string]int{"a": 1}
+	body := []byte(`{"a":1}`)
+
+	tests := []struct {
+		name string
+		tag  string
+	}{
+		{"precomputed", etag.Compute(body)},
+		{"computed", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", func(c *fiber.Ctx) error { return JSONWithETag(c, value, tt.tag) })
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Equal(t, etag.Compute(body), resp.Header.Get("ETag"))
+			got, _ := io.ReadAll(resp.Body)
+			assert.JSONEq(t, string(body), string(got))
+
+			for _, ifNoneMatch := range []string{etag.Compute(body), "W/" + etag.Compute(body), `"other", ` + etag.Compute(body), "*"} {
+				req := httptest.NewRequest("GET", "/", nil)
+				req.Header.Set("If-None-Match", ifNoneMatch)
+				resp, err := app.Test(req)
+				require.NoError(t, err)
+				assert.Equal(t, fiber.StatusNotModified, resp.StatusCode, ifNoneMatch)
+				got, _ := io.ReadAll(resp.Body)
+				assert.Empty(t, got)
+			}
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("If-None-Match", `"stale"`)
+			resp, err = app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		})
+	}
+}
+
+// TestJSONWithETag_Weak verifies a weak tag is sent as is and matched by either form in If-None-Match.
+func TestJSONWithETag_Weak(t *testing.T) {
+	tag := etag.Weak([]byte(`{"a":1}`))
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error { return JSONWithETag(c, map[string]int{"a": 1}, tag) })
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+	require.NoError(t, err)
+	assert.Equal(t, tag, resp.Header.Get("ETag"))
+
+	for _, ifNoneMatch := range []string{tag, strings.TrimPrefix(tag, "W/")} {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("If-None-Match", ifNoneMatch)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusNotModified, resp.StatusCode, ifNoneMatch)
+	}
+}
@@ -0,0 +1,34 @@
+package request
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+)
+
+// RayIDHeader carries the Ray ID on every response. A Ray ID sent by the client on this
+// header is reused instead of generating one.
+const RayIDHeader = "X-Ray-ID"
+
+// rayIDLocal is the fiber local where the requestid middleware stores the Ray ID.
+const rayIDLocal = "requestid"
+
+// unknownRayID is returned when no Ray ID was assigned to the request.
+const unknownRayID = "unknown"
+
+// RayIDMiddleware assigns a Ray ID to every request, echoes it in the X-Ray-ID response
+// header and stores it where RayID reads it, so the header matches ray_id in error bodies.
+func RayIDMiddleware() fiber.Handler {
+	return requestid.New(requestid.Config{
+		Header:     RayIDHeader,
+		ContextKey: rayIDLocal,
+	})
+}
+
+// RayID returns the Ray ID assigned to the request by RayIDMiddleware.
+// It returns "unknown" when the middleware is not installed instead of panicking.
+func RayID(c *fiber.Ctx) string {
+	if rayID, ok := c.Locals(rayIDLocal).(string); ok && rayID != "" {
+		return rayID
+	}
+	return unknownRayID
+}
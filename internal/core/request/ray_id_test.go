@@ -0,0 +1,57 @@
+package request
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRayID verifies the Ray ID is read from the requestid middleware and defaults when missing.
+func TestRayID(t *testing.T) {
+	t.Run("WithMiddleware", func(t *testing.T) {
+		app := fiber.New()
+		app.Use(RayIDMiddleware())
+		app.Get("/", func(c *fiber.Ctx) error { return c.SendString(RayID(c)) })
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Ray-ID", "ray-123")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		body := make([]byte, 16)
+		n, _ := resp.Body.Read(body)
+		assert.Equal(t, "ray-123", string(body[:n]))
+	})
+
+	t.Run("WithoutMiddleware", func(t *testing.T) {
+		app := fiber.New()
+		app.Get("/", func(c *fiber.Ctx) error { return c.SendString(RayID(c)) })
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+		require.NoError(t, err)
+
+		body := make([]byte, 16)
+		n, _ := resp.Body.Read(body)
+		assert.Equal(t, "unknown", string(body[:n]))
+	})
+}
+
+// TestRayIDMiddleware_Header verifies the generated Ray ID is echoed on successful responses.
+func TestRayIDMiddleware_Header(t *testing.T) {
+	app := fiber.New()
+	app.Use(RayIDMiddleware())
+	app.Get("/", func(c *fiber.Ctx) error { return c.SendString(RayID(c)) })
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	body := make([]byte, 64)
+	n, _ := resp.Body.Read(body)
+	rayID := resp.Header.Get(RayIDHeader)
+	assert.NotEmpty(t, rayID)
+	assert.Equal(t, rayID, string(body[:n]))
+}
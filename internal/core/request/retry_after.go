@@ -0,0 +1,15 @@
+package request
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetRetryAfter sets the Retry-After header to d in whole seconds, rounded up and at least 1,
+// so a client told to come back later never retries right away.
+func SetRetryAfter(c *fiber.Ctx, d time.Duration) {
+	seconds := max(int((d+time.Second-1)/time.Second), 1)
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+}
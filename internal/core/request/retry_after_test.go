@@ -0,0 +1,38 @@
+package request
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSetRetryAfter verifies durations are rounded up to whole seconds with a minimum of one.
+func TestSetRetryAfter(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{time.Minute, "60"},
+		{1500 * time.Millisecond, "2"},
+		{time.Millisecond, "1"},
+		{0, "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.d.String(), func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", func(c *fiber.Ctx) error {
+				SetRetryAfter(c, tt.d)
+				return c.SendStatus(fiber.StatusServiceUnavailable)
+			})
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, resp.Header.Get("Retry-After"))
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package request
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// bodyField names the request body in validation errors that aren't about a single field.
+const bodyField = "body"
+
+// ValidationError describes why a request field was rejected.
+type ValidationError struct {
+	// Field is the JSON field, query parameter, path parameter or header that was rejected.
+	Field string `json:"field"`
+	// Message says what is wrong with the field, e.g. "required".
+	Message string `json:"message"`
+}
+
+// ValidationErrorResponse is the body of 400 responses caused by invalid input.
+type ValidationErrorResponse struct {
+	// Errors lists every rejected field; simple cases carry a single entry.
+	Errors []ValidationError `json:"errors"`
+	// RayID is the unique request identifier for tracing.
+	RayID string `json:"ray_id"`
+}
+
+// Invalid responds 400 with the given validation errors and the request's Ray ID.
+func Invalid(c *fiber.Ctx, errs ...ValidationError) error {
+	return c.Status(fiber.StatusBadRequest).JSON(ValidationErrorResponse{
+		Errors: errs,
+		RayID:  RayID(c),
+	})
+}
+
+// BodyError converts a ParseJSON error into a validation error, naming the unknown field in strict mode.
+func BodyError(err error) ValidationError {
+	var unknownErr *UnknownFieldError
+	if errors.As(err, &unknownErr) {
+		return ValidationError{Field: unknownErr.Field, Message: "unknown field"}
+	}
+	return ValidationError{Field: bodyField, Message: "invalid JSON"}
+}
@@ -0,0 +1,44 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestInvalid verifies 400 responses list every validation error with the Ray ID.
+func TestInvalid(t *testing.T) {
+	app := fiber.New()
+	app.Use(RayIDMiddleware())
+	app.Get("/", func(c *fiber.Ctx) error {
+		return Invalid(c,
+			ValidationError{Field: "email", Message: "required"},
+			ValidationError{Field: "id", Message: "must be numeric"},
+		)
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(RayIDHeader, "ray-123")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	var body ValidationErrorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "ray-123", body.RayID)
+	assert.Equal(t, []ValidationError{
+		{Field: "email", Message: "required"},
+		{Field: "id", Message: "must be numeric"},
+	}, body.Errors)
+}
+
+// TestBodyError verifies unknown fields are named and other parse failures point at the body.
+func TestBodyError(t *testing.T) {
+	assert.Equal(t, ValidationError{Field: "titel", Message: "unknown field"}, BodyError(&UnknownFieldError{Field: "titel"}))
+	assert.Equal(t, ValidationError{Field: "body", Message: "invalid JSON"}, BodyError(errors.New("unexpected EOF")))
+}
@@ -0,0 +1,31 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"golang.org/x/sync/semaphore"
+)
+
+// ErrCourierBusy is returned when no browser slot frees up before the lookup deadline.
+var ErrCourierBusy = errors.New("too many concurrent courier lookups")
+
+// Limiter bounds how many browsers run at once across all couriers.
+type Limiter struct {
+	sem *semaphore.Weighted
+}
+
+// NewLimiter creates a Limiter allowing limit concurrent scrapes; limit below 1 is treated as 1.
+func NewLimiter(limit int) *Limiter {
+	return &Limiter{sem: semaphore.NewWeighted(int64(max(limit, 1)))}
+}
+
+// Acquire waits for a free slot until ctx is done, in which case it returns ErrCourierBusy.
+// The returned release function must be called once the scrape finishes.
+func (l *Limiter) Acquire(ctx context.Context) (release func(), err error) {
+	if err := l.sem.Acquire(ctx, 1); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCourierBusy, err)
+	}
+	return func() { l.sem.Release(1) }, nil
+}
@@ -0,0 +1,56 @@
+package scraper
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLimiter_Acquire verifies that with N slots taken an extra acquisition fails with ErrCourierBusy at its deadline,
+// and succeeds once a slot is released.
+func TestLimiter_Acquire(t *testing.T) {
+	const slots = 3
+	l := NewLimiter(slots)
+
+	releases := make([]func(), slots)
+	var wg sync.WaitGroup
+	for i := range slots {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := l.Acquire(context.Background())
+			assert.NoError(t, err)
+			releases[i] = release
+		}()
+	}
+	wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := l.Acquire(ctx)
+	assert.ErrorIs(t, err, ErrCourierBusy)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	releases[0]()
+	release, err := l.Acquire(context.Background())
+	require.NoError(t, err)
+	release()
+}
+
+// TestNewLimiter_MinimumOne verifies a non-positive limit still allows one scrape at a time.
+func TestNewLimiter_MinimumOne(t *testing.T) {
+	l := NewLimiter(0)
+
+	release, err := l.Acquire(context.Background())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = l.Acquire(ctx)
+	assert.ErrorIs(t, err, ErrCourierBusy)
+	release()
+}
@@ -0,0 +1,52 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"tracker-scrapper/internal/core/logger"
+
+	"go.uber.org/zap"
+)
+
+// ErrCourierLayoutChanged is returned when an element a scrape relies on never shows up on the courier
+// page, usually because the page was redesigned or replaced by a bot challenge.
+var ErrCourierLayoutChanged = errors.New("courier page layout changed")
+
+// Navigator is the part of a browser page NavigateWithRetry drives; *rod.Page implements it.
+type Navigator interface {
+	// Navigate opens url in the page.
+	Navigate(url string) error
+	// GetContext returns the context that bounds the page's operations.
+	GetContext() context.Context
+}
+
+// NavigateWithRetry opens url in page, making up to maxRetries attempts (at least one) spaced by delay.
+// Waiting between attempts stops as soon as the page's context is done. It returns the last navigation
+// error, or nil once an attempt succeeds.
+func NavigateWithRetry(page Navigator, url string, maxRetries int, delay time.Duration) error {
+	attempts := max(maxRetries, 1)
+	ctx := page.GetContext()
+
+	var err error
+	for i := 1; i <= attempts; i++ {
+		logger.Get().Debug("Navigating to URL", zap.String("url", url), zap.Int("attempt", i), zap.Int("max_retries", attempts))
+		if err = page.Navigate(url); err == nil {
+			return nil
+		}
+		if i == attempts {
+			break
+		}
+
+		logger.Get().Warn("Navigation failed", zap.Error(err), zap.Duration("retry_in", delay))
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+	}
+	return err
+}
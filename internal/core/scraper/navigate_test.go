@@ -0,0 +1,67 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeNavigator fails the first failures navigations and records every URL it is sent to.
+type fakeNavigator struct {
+	ctx      context.Context
+	failures int
+	visits   []string
+}
+
+func (n *fakeNavigator) Navigate(url string) error {
+	n.visits = append(n.visits, url)
+	if len(n.visits) <= n.failures {
+		return errors.New("net::ERR_CONNECTION_RESET")
+	}
+	return nil
+}
+
+func (n *fakeNavigator) GetContext() context.Context {
+	return n.ctx
+}
+
+// TestNavigateWithRetry verifies transient failures are retried up to the attempt limit.
+func TestNavigateWithRetry(t *testing.T) {
+	t.Run("RecoversFromTransientFailure", func(t *testing.T) {
+		page := &fakeNavigator{ctx: context.Background(), failures: 2}
+
+		assert.NoError(t, NavigateWithRetry(page, "https://example.com", 3, time.Millisecond))
+		assert.Len(t, page.visits, 3)
+	})
+
+	t.Run("ReturnsLastErrorWhenExhausted", func(t *testing.T) {
+		page := &fakeNavigator{ctx: context.Background(), failures: 5}
+
+		assert.EqualError(t, NavigateWithRetry(page, "https://example.com", 3, time.Millisecond), "net::ERR_CONNECTION_RESET")
+		assert.Len(t, page.visits, 3)
+	})
+
+	t.Run("AtLeastOneAttempt", func(t *testing.T) {
+		page := &fakeNavigator{ctx: context.Background()}
+
+		assert.NoError(t, NavigateWithRetry(page, "https://example.com", 0, time.Millisecond))
+		assert.Len(t, page.visits, 1)
+	})
+}
+
+// TestNavigateWithRetry_Cancelled verifies a done page context stops the wait between attempts.
+func TestNavigateWithRetry_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	page := &fakeNavigator{ctx: ctx, failures: 5}
+
+	start := time.Now()
+	err := NavigateWithRetry(page, "https://example.com", 3, time.Minute)
+
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Len(t, page.visits, 1)
+}
@@ -0,0 +1,91 @@
+package scraper
+
+import (
+	"fmt"
+	"math/rand/v2"
+
+	"tracker-scrapper/internal/core/useragent"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// acceptLanguage matches the languages reported by stealthScript.
+const acceptLanguage = "es-CO,es;q=0.9,en;q=0.8"
+
+// stealthScript runs before any page script: it hides the webdriver flag automation sets and
+// reports the languages of a Colombian Spanish browser instead of the headless default.
+const stealthScript = `
+Object.defineProperty(navigator, 'webdriver', {get: () => undefined});
+Object.defineProperty(navigator, 'languages', {get: () => ['es-CO', 'es', 'en']});
+`
+
+// viewports lists common desktop resolutions; one is picked per page.
+var viewports = []struct {
+	width, height int
+}{
+	{1920, 1080},
+	{1536, 864},
+	{1440, 900},
+	{1366, 768},
+	{1280, 720},
+}
+
+// randIntn picks the viewport index. Tests replace it for deterministic results.
+var randIntn = rand.IntN
+
+// Stealth configures how scraped pages hide that they are driven by automation.
+type Stealth struct {
+	// Enabled applies the stealth settings to every page.
+	Enabled bool
+	// UserAgent pins the user agent when non-empty; otherwise each call picks one from the useragent pool.
+	UserAgent string
+}
+
+// EffectiveUserAgent returns the pinned user agent or a random one from the useragent pool.
+// Callers needing the same user agent in several places must call it once and reuse the result.
+func (s Stealth) EffectiveUserAgent() string {
+	if s.UserAgent != "" {
+		return s.UserAgent
+	}
+	return useragent.Random()
+}
+
+// Page is the subset of *rod.Page used to apply stealth settings.
+type Page interface {
+	// EvalOnNewDocument registers js to run before any script of every new document.
+	EvalOnNewDocument(js string) (func() error, error)
+	// SetUserAgent overrides the user agent and Accept-Language of the page.
+	SetUserAgent(req *proto.NetworkSetUserAgentOverride) error
+	// SetViewport overrides the page dimensions.
+	SetViewport(params *proto.EmulationSetDeviceMetricsOverride) error
+}
+
+// ApplyStealth hides the webdriver flag, sets a realistic user agent and languages and picks a
+// random desktop viewport. It must be called before navigating. It does nothing when disabled.
+func ApplyStealth(page Page, s Stealth) error {
+	if !s.Enabled {
+		return nil
+	}
+
+	if _, err := page.EvalOnNewDocument(stealthScript); err != nil {
+		return fmt.Errorf("failed to inject stealth script: %w", err)
+	}
+
+	if err := page.SetUserAgent(&proto.NetworkSetUserAgentOverride{
+		UserAgent:      s.EffectiveUserAgent(),
+		AcceptLanguage: acceptLanguage,
+	}); err != nil {
+		return fmt.Errorf("failed to set user agent: %w", err)
+	}
+
+	viewport := viewports[randIntn(len(viewports))]
+	if err := page.SetViewport(&proto.EmulationSetDeviceMetricsOverride{
+		Width:             viewport.width,
+		Height:            viewport.height,
+		DeviceScaleFactor: 1,
+	}); err != nil {
+		return fmt.Errorf("failed to set viewport: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package scraper
+
+import (
+	"errors"
+	"testing"
+
+	"tracker-scrapper/internal/core/useragent"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePage records the stealth settings applied to it.
+type fakePage struct {
+	scripts   []string
+	userAgent *proto.NetworkSetUserAgentOverride
+	viewport  *proto.EmulationSetDeviceMetricsOverride
+	evalErr   error
+}
+
+// EvalOnNewDocument implements Page.
+func (p *fakePage) EvalOnNewDocument(js string) (func() error, error) {
+	if p.evalErr != nil {
+		return nil, p.evalErr
+	}
+	p.scripts = append(p.scripts, js)
+	return func() error { return nil }, nil
+}
+
+// SetUserAgent implements Page.
+func (p *fakePage) SetUserAgent(req *proto.NetworkSetUserAgentOverride) error {
+	p.userAgent = req
+	return nil
+}
+
+// SetViewport implements Page.
+func (p *fakePage) SetViewport(params *proto.EmulationSetDeviceMetricsOverride) error {
+	p.viewport = params
+	return nil
+}
+
+// TestApplyStealth verifies the new-document script, user agent and viewport are registered.
+func TestApplyStealth(t *testing.T) {
+	orig := randIntn
+	randIntn = func(n int) int { return n - 1 }
+	defer func() { randIntn = orig }()
+
+	page := &fakePage{}
+	require.NoError(t, ApplyStealth(page, Stealth{Enabled: true}))
+
+	require.Len(t, page.scripts, 1)
+	assert.Contains(t, page.scripts[0], "'webdriver'")
+	assert.Contains(t, page.scripts[0], "'languages'")
+
+	require.NotNil(t, page.userAgent)
+	assert.Contains(t, useragent.Pool(), page.userAgent.UserAgent)
+	assert.Equal(t, acceptLanguage, page.userAgent.AcceptLanguage)
+
+	require.NotNil(t, page.viewport)
+	assert.Equal(t, 1280, page.viewport.Width)
+	assert.Equal(t, 720, page.viewport.Height)
+}
+
+// TestApplyStealth_CustomUserAgent verifies a configured user agent replaces the default.
+func TestApplyStealth_CustomUserAgent(t *testing.T) {
+	page := &fakePage{}
+	require.NoError(t, ApplyStealth(page, Stealth{Enabled: true, UserAgent: "custom/1.0"}))
+
+	assert.Equal(t, "custom/1.0", page.userAgent.UserAgent)
+}
+
+// TestApplyStealth_Disabled verifies nothing is applied when stealth is off.
+func TestApplyStealth_Disabled(t *testing.T) {
+	page := &fakePage{}
+	require.NoError(t, ApplyStealth(page, Stealth{}))
+
+	assert.Empty(t, page.scripts)
+	assert.Nil(t, page.userAgent)
+	assert.Nil(t, page.viewport)
+}
+
+// TestApplyStealth_Error verifies a failed script injection is reported.
+func TestApplyStealth_Error(t *testing.T) {
+	page := &fakePage{evalErr: errors.New("target closed")}
+
+	err := ApplyStealth(page, Stealth{Enabled: true})
+
+	assert.ErrorContains(t, err, "failed to inject stealth script")
+	assert.Nil(t, page.userAgent)
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"tracker-scrapper/internal/core/clock"
+	"tracker-scrapper/internal/core/request"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// rateLimiter counts requests per client IP in fixed windows.
+type rateLimiter struct {
+	limit  int
+	window time.Duration
+	clock  clock.Clock
+
+	mu sync.Mutex
+	// windows holds the open window of every client seen since the last sweep.
+	windows map[string]*rateWindow
+	// nextSweep is when windows is next cleared of closed windows.
+	nextSweep time.Time
+}
+
+// rateWindow counts the requests of one client until reset.
+type rateWindow struct {
+	count int
+	reset time.Time
+}
+
+// RateLimit returns middleware that accepts at most limit requests per client IP in each window and
+// answers the rest with 429 and a Retry-After header. Counters live in memory, so each instance
+// keeps its own. A limit of 0 lets every request through.
+func RateLimit(limit int, window time.Duration) fiber.Handler {
+	if limit <= 0 {
+		return func(c *fiber.Ctx) error {
+			return c.Next()
+		}
+	}
+	return newRateLimiter(limit, window, clock.Real).handle
+}
+
+// newRateLimiter creates a rateLimiter reading the time from clk.
+func newRateLimiter(limit int, window time.Duration, clk clock.Clock) *rateLimiter {
+	return &rateLimiter{limit: limit, window: window, clock: clk, windows: make(map[string]*rateWindow)}
+}
+
+// handle counts the request against its client and rejects it once the window's limit is used up.
+func (l *rateLimiter) handle(c *fiber.Ctx) error {
+	wait, ok := l.allow(c.IP())
+	if !ok {
+		request.SetRetryAfter(c, wait)
+		return c.Status(fiber.StatusTooManyRequests).JSON(ErrorResponse{
+			Message: "too many requests",
+			RayID:   request.RayID(c),
+		})
+	}
+	return c.Next()
+}
+
+// allow records a request from client and reports whether it is within the limit, or how long
+// until the client's window resets when it isn't.
+func (l *rateLimiter) allow(client string) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+	if !now.Before(l.nextSweep) {
+		// Forget clients whose window closed, so one-off clients don't pile up
+		for key, w := range l.windows {
+			if !now.Before(w.reset) {
+				delete(l.windows, key)
+			}
+		}
+		l.nextSweep = now.Add(l.window)
+	}
+
+	w, ok := l.windows[client]
+	if !ok || !now.Before(w.reset) {
+		w = &rateWindow{reset: now.Add(l.window)}
+		l.windows[client] = w
+	}
+	if w.count >= l.limit {
+		return w.reset.Sub(now), false
+	}
+	w.count++
+	return 0, true
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"tracker-scrapper/internal/core/clock"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRateLimit verifies requests over the limit get a 429 ErrorResponse with Retry-After.
+func TestRateLimit(t *testing.T) {
+	app := fiber.New()
+	app.Post("/dismiss", RateLimit(2, time.Minute), func(c *fiber.Ctx) error { return c.SendString("ok") })
+
+	for range 2 {
+		resp, err := app.Test(httptest.NewRequest("POST", "/dismiss", nil))
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	}
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/dismiss", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "60", resp.Header.Get("Retry-After"))
+
+	var body ErrorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "too many requests", body.Message)
+}
+
+// TestRateLimit_Disabled verifies a limit of 0 lets every request through.
+func TestRateLimit_Disabled(t *testing.T) {
+	app := fiber.New()
+	app.Post("/dismiss", RateLimit(0, time.Minute), func(c *fiber.Ctx) error { return c.SendString("ok") })
+
+	for range 5 {
+		resp, err := app.Test(httptest.NewRequest("POST", "/dismiss", nil))
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	}
+}
+
+// TestRateLimiter_Window verifies limits are counted per client and reset when the window closes.
+func TestRateLimiter_Window(t *testing.T) {
+	clk := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	l := newRateLimiter(1, time.Minute, clk)
+
+	_, ok := l.allow("10.0.0.1")
+	assert.True(t, ok)
+	_, ok = l.allow("10.0.0.2")
+	assert.True(t, ok)
+
+	clk.Advance(20 * time.Second)
+	wait, ok := l.allow("10.0.0.1")
+	assert.False(t, ok)
+	assert.Equal(t, 40*time.Second, wait)
+
+	clk.Advance(40 * time.Second)
+	_, ok = l.allow("10.0.0.1")
+	assert.True(t, ok)
+	assert.Len(t, l.windows, 1, "closed windows should be swept")
+}
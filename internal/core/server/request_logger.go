@@ -0,0 +1,53 @@
+package server
+
+import (
+	"errors"
+	"time"
+
+	"tracker-scrapper/internal/core/request"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// requestLogger logs one structured entry per request once the handler chain returns.
+// The ray_id field is the same value returned to clients in ErrorResponse.
+func requestLogger(log *zap.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			// The app error handler has not written the response yet
+			status = fiber.StatusInternalServerError
+			var fe *fiber.Error
+			if errors.As(err, &fe) {
+				status = fe.Code
+			}
+		}
+
+		fields := []zap.Field{
+			zap.String("method", c.Method()),
+			zap.String("path", c.Path()),
+			zap.String("route", c.Route().Path),
+			zap.Int("status", status),
+			zap.Duration("latency", time.Since(start)),
+			zap.String("ray_id", request.RayID(c)),
+		}
+		if err != nil {
+			fields = append(fields, zap.Error(err))
+		}
+
+		switch {
+		case status >= fiber.StatusInternalServerError:
+			log.Error("Request completed", fields...)
+		case status >= fiber.StatusBadRequest:
+			log.Warn("Request completed", fields...)
+		default:
+			log.Info("Request completed", fields...)
+		}
+
+		return err
+	}
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"tracker-scrapper/internal/core/request"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// TestRequestLogger verifies the logged ray_id and route match the request and the error body.
+func TestRequestLogger(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+
+	app := fiber.New()
+	app.Use(request.RayIDMiddleware())
+	app.Use(requestLogger(zap.New(core)))
+	app.Get("/orders/:id", func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"ray_id": request.RayID(c)})
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/orders/42", nil))
+	require.NoError(t, err)
+
+	var body struct {
+		RayID string `json:"ray_id"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+	require.Equal(t, 1, logs.Len())
+	entry := logs.All()[0]
+	fields := entry.ContextMap()
+
+	assert.Equal(t, zap.WarnLevel, entry.Level)
+	assert.Equal(t, "GET", fields["method"])
+	assert.Equal(t, "/orders/42", fields["path"])
+	assert.Equal(t, "/orders/:id", fields["route"])
+	assert.EqualValues(t, fiber.StatusNotFound, fields["status"])
+	assert.Equal(t, resp.Header.Get("X-Ray-ID"), fields["ray_id"])
+	assert.Equal(t, body.RayID, fields["ray_id"])
+}
+
+// TestRequestLogger_HandlerError verifies errors returned by handlers are logged with their status.
+func TestRequestLogger_HandlerError(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+
+	app := fiber.New()
+	app.Use(requestLogger(zap.New(core)))
+	app.Get("/", func(c *fiber.Ctx) error {
+		return fiber.ErrServiceUnavailable
+	})
+
+	_, err := app.Test(httptest.NewRequest("GET", "/", nil))
+	require.NoError(t, err)
+
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, zap.ErrorLevel, logs.All()[0].Level)
+	assert.EqualValues(t, fiber.StatusServiceUnavailable, logs.All()[0].ContextMap()["status"])
+	assert.Equal(t, "unknown", logs.All()[0].ContextMap()["ray_id"])
+}
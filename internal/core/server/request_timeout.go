@@ -0,0 +1,40 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"tracker-scrapper/internal/core/request"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ErrorResponse represents a server-level error response with Ray ID.
+type ErrorResponse struct {
+	// Message is the error description.
+	Message string `json:"message"`
+	// RayID is the unique request identifier for tracing.
+	RayID string `json:"ray_id"`
+}
+
+// requestTimeout returns middleware that gives every request a user context expiring after timeout
+// and answers 504 when a handler gives up on it. Fiber contexts cannot outlive their handler, so
+// the ceiling is cooperative: slow handlers must watch c.UserContext(), e.g. with request.Await, and return its error,
+// leaving any work they started (such as a scrape filling the cache) to finish in the background.
+func requestTimeout(timeout time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithTimeout(c.UserContext(), timeout)
+		defer cancel()
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() != nil {
+			return c.Status(fiber.StatusGatewayTimeout).JSON(ErrorResponse{
+				Message: "request timed out",
+				RayID:   request.RayID(c),
+			})
+		}
+		return err
+	}
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"tracker-scrapper/internal/core/request"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowHandler waits for delay unless the request context expires first.
+func slowHandler(delay time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		select {
+		case <-time.After(delay):
+			return c.SendString("done")
+		case <-c.UserContext().Done():
+			return c.UserContext().Err()
+		}
+	}
+}
+
+// TestRequestTimeout_Exceeded verifies a handler outliving the timeout is answered with a 504 ErrorResponse.
+func TestRequestTimeout_Exceeded(t *testing.T) {
+	app := fiber.New()
+	app.Use(request.RayIDMiddleware())
+	app.Use(requestTimeout(50 * time.Millisecond))
+	app.Get("/slow", slowHandler(5*time.Second))
+
+	start := time.Now()
+	resp, err := app.Test(httptest.NewRequest("GET", "/slow", nil), -1)
+	require.NoError(t, err)
+
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, fiber.StatusGatewayTimeout, resp.StatusCode)
+
+	var body ErrorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "request timed out", body.Message)
+	assert.Equal(t, resp.Header.Get(request.RayIDHeader), body.RayID)
+}
+
+// TestRequestTimeout_WithinLimit verifies handlers finishing in time are untouched.
+func TestRequestTimeout_WithinLimit(t *testing.T) {
+	app := fiber.New()
+	app.Use(requestTimeout(time.Second))
+	app.Get("/slow", slowHandler(10*time.Millisecond))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/slow", nil), -1)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
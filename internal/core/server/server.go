@@ -1,24 +1,36 @@
 package server
 
 import (
+	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"tracker-scrapper/internal/core/config"
 	"tracker-scrapper/internal/core/logger"
+	"tracker-scrapper/internal/core/request"
 
-	"github.com/gofiber/contrib/fiberzap/v2"
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/gofiber/fiber/v2/middleware/compress"
+	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/swagger"
 	"go.uber.org/zap"
 
-	_ "tracker-scrapper/docs/swagger"
+	swaggerdocs "tracker-scrapper/docs/swagger"
 )
 
+// shutdownTimeout bounds how long in-flight requests may run once shutdown starts.
+const shutdownTimeout = 30 * time.Second
+
+// defaultSwaggerPath serves the Swagger UI when the configuration doesn't set SWAGGER_PATH.
+const defaultSwaggerPath = "/swagger"
+
 // Server holds the Fiber application and configuration.
 type Server struct {
 	// App is the main Fiber application instance.
 	App *fiber.App
+	// API is where API routes are registered: App itself, or a group under API_BASE_PATH.
+	API fiber.Router
 	// cfg holds the application configuration.
 	cfg *config.AppConfig
 }
@@ -30,25 +42,68 @@ func New(cfg *config.AppConfig) *Server {
 		AppName:               "tracker-scrapper",
 	})
 
-	app.Use(requestid.New(requestid.Config{
-		Header: "X-Ray-ID",
-	}))
+	app.Use(request.RayIDMiddleware())
 
-	app.Use(fiberzap.New(fiberzap.Config{
-		Logger: logger.Get(),
-	}))
+	app.Use(requestLogger(logger.Get()))
 
-	app.Get("/swagger/*", swagger.HandlerDefault)
+	// Registered after the logger so requests cut short are logged with their 504
+	if cfg.RequestTimeout > 0 {
+		app.Use(requestTimeout(time.Duration(cfg.RequestTimeout) * time.Second))
+	}
+
+	// Small bodies are sent as-is; compression only pays off for batches and long histories
+	if cfg.Compression {
+		app.Use(compress.New(compress.Config{Level: compress.LevelDefault}))
+	}
+
+	// Cross-origin requests are rejected unless origins are configured
+	if len(cfg.CORS.AllowedOrigins) > 0 {
+		app.Use(newCORS(cfg.CORS))
+	}
+
+	swaggerPath := cfg.SwaggerPath
+	if swaggerPath == "" {
+		swaggerPath = defaultSwaggerPath
+	}
+	app.Get(swaggerPath+"/*", swagger.HandlerDefault)
+
+	// Documented operations live under the base path, so "Try it out" calls the right URLs
+	var api fiber.Router = app
+	if cfg.BasePath != "" {
+		api = app.Group(cfg.BasePath)
+		swaggerdocs.SwaggerInfo.BasePath = cfg.BasePath
+	}
 
 	return &Server{
 		App: app,
+		API: api,
 		cfg: cfg,
 	}
 }
 
-// Run starts the HTTP server.
-func (s *Server) Run() error {
+// newCORS returns middleware answering preflights and tagging responses for the configured origins.
+func newCORS(cfg config.CORSConfig) fiber.Handler {
+	return cors.New(cors.Config{
+		AllowOrigins:  strings.Join(cfg.AllowedOrigins, ","),
+		AllowMethods:  strings.Join(cfg.AllowedMethods, ","),
+		AllowHeaders:  strings.Join(cfg.AllowedHeaders, ","),
+		ExposeHeaders: "X-Ray-ID,X-Cache,X-Maintenance-Mode,X-Courier,Idempotent-Replayed",
+	})
+}
+
+// Run starts the HTTP server and blocks until it stops. When ctx is done the server stops
+// accepting connections and gives in-flight requests up to shutdownTimeout to finish.
+func (s *Server) Run(ctx context.Context) error {
 	addr := fmt.Sprintf(":%d", s.cfg.ServerPort)
+
+	stop := context.AfterFunc(ctx, func() {
+		logger.Get().Info("Shutting down server", zap.Duration("timeout", shutdownTimeout))
+		if err := s.App.ShutdownWithTimeout(shutdownTimeout); err != nil {
+			logger.Get().Warn("Server shutdown incomplete", zap.Error(err))
+		}
+	})
+	defer stop()
+
 	logger.Get().Info("Starting server", zap.String("address", addr))
 	return s.App.Listen(addr)
 }
@@ -1,14 +1,25 @@
 package server
 
 import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"tracker-scrapper/internal/core/config"
 	"tracker-scrapper/internal/core/logger"
+	"tracker-scrapper/internal/core/request"
 
+	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	swaggerdocs "tracker-scrapper/docs/swagger"
 )
 
 // TestNew verifies that New creates a Server with the correct configuration.
@@ -17,7 +28,7 @@ func TestNew(t *testing.T) {
 		ServerPort: 8080,
 	}
 
-	logger.Init("development", "debug")
+	logger.Init("development", "debug", "", logger.FileOutput{}, logger.Sampling{})
 	srv := New(cfg)
 
 	require.NotNil(t, srv)
@@ -31,13 +42,13 @@ func TestServer_Run_Error(t *testing.T) {
 	cfg := &config.AppConfig{
 		ServerPort: 1,
 	}
-	logger.Init("development", "error")
+	logger.Init("development", "error", "", logger.FileOutput{}, logger.Sampling{})
 
 	srv := New(cfg)
 
 	errCh := make(chan error)
 	go func() {
-		errCh <- srv.Run()
+		errCh <- srv.Run(context.Background())
 	}()
 
 	select {
@@ -48,3 +59,168 @@ func TestServer_Run_Error(t *testing.T) {
 		t.Log("Server unexpectedly started or timed out on Error test")
 	}
 }
+
+// TestServer_Run_Shutdown verifies Run returns cleanly once its context is cancelled.
+func TestServer_Run_Shutdown(t *testing.T) {
+	logger.Init("development", "error", "", logger.FileOutput{}, logger.Sampling{})
+	srv := New(&config.AppConfig{ServerPort: freePort(t)})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	srv.App.Hooks().OnListen(func(fiber.ListenData) error {
+		cancel()
+		return nil
+	})
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.Run(ctx)
+	}()
+
+	select {
+	case err := <-errCh:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not shut down after cancellation")
+	}
+}
+
+// freePort returns a TCP port that is free at the time of the call.
+func freePort(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	defer ln.Close()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+// preflight sends a CORS preflight for GET /orders/1 from origin to srv.
+func preflight(t *testing.T, srv *Server, origin string) string {
+	t.Helper()
+	srv.App.Get("/orders/:id", func(c *fiber.Ctx) error { return c.SendString("ok") })
+
+	req := httptest.NewRequest("OPTIONS", "/orders/1", nil)
+	req.Header.Set("Origin", origin)
+	req.Header.Set("Access-Control-Request-Method", "GET")
+
+	resp, err := srv.App.Test(req)
+	require.NoError(t, err)
+	return resp.Header.Get("Access-Control-Allow-Origin")
+}
+
+// TestNew_CORSDisabledByDefault verifies cross-origin requests are not allowed without configured origins.
+func TestNew_CORSDisabledByDefault(t *testing.T) {
+	logger.Init("development", "error", "", logger.FileOutput{}, logger.Sampling{})
+	srv := New(&config.AppConfig{})
+
+	assert.Empty(t, preflight(t, srv, "https://shop.example.com"))
+}
+
+// TestNew_CORSAllowedOrigins verifies only configured origins pass the preflight.
+func TestNew_CORSAllowedOrigins(t *testing.T) {
+	logger.Init("development", "error", "", logger.FileOutput{}, logger.Sampling{})
+	cfg := &config.AppConfig{CORS: config.CORSConfig{
+		AllowedOrigins: []string{"https://shop.example.com"},
+		AllowedMethods: []string{"GET", "POST"},
+		AllowedHeaders: []string{"Authorization"},
+	}}
+
+	assert.Equal(t, "https://shop.example.com", preflight(t, New(cfg), "https://shop.example.com"))
+	assert.Empty(t, preflight(t, New(cfg), "https://evil.example.com"))
+}
+
+// TestNew_RayIDHeader verifies every response carries X-Ray-ID and it matches ray_id in error bodies.
+func TestNew_RayIDHeader(t *testing.T) {
+	logger.Init("development", "error", "", logger.FileOutput{}, logger.Sampling{})
+	srv := New(&config.AppConfig{})
+	srv.App.Get("/ok", func(c *fiber.Ctx) error { return c.SendString("ok") })
+	srv.App.Get("/fail", func(c *fiber.Ctx) error {
+		return c.Status(500).JSON(fiber.Map{"message": "boom", "ray_id": request.RayID(c)})
+	})
+
+	resp, err := srv.App.Test(httptest.NewRequest("GET", "/ok", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get("X-Ray-ID"))
+
+	resp, err = srv.App.Test(httptest.NewRequest("GET", "/fail", nil))
+	require.NoError(t, err)
+
+	var body map[string]string
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.NotEmpty(t, body["ray_id"])
+	assert.Equal(t, resp.Header.Get("X-Ray-ID"), body["ray_id"])
+}
+
+// TestNew_Compression verifies large responses are gzipped for clients that accept it and sent as-is otherwise.
+func TestNew_Compression(t *testing.T) {
+	logger.Init("development", "error", "", logger.FileOutput{}, logger.Sampling{})
+	srv := New(&config.AppConfig{Compression: true})
+	payload := strings.Repeat(`{"date":"2024-01-02T09:30:00Z","text":"EN TRANSPORTE","city":"BOGOTA"},`, 200)
+	srv.App.Get("/large", func(c *fiber.Ctx) error { return c.SendString(payload) })
+
+	req := httptest.NewRequest("GET", "/large", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := srv.App.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+
+	gz, err := gzip.NewReader(resp.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, payload, string(body))
+
+	resp, err = srv.App.Test(httptest.NewRequest("GET", "/large", nil))
+	require.NoError(t, err)
+	assert.Empty(t, resp.Header.Get("Content-Encoding"))
+}
+
+// TestNew_CompressionSwagger verifies the swagger UI is still served with compression enabled.
+func TestNew_CompressionSwagger(t *testing.T) {
+	logger.Init("development", "error", "", logger.FileOutput{}, logger.Sampling{})
+	srv := New(&config.AppConfig{Compression: true})
+
+	req := httptest.NewRequest("GET", "/swagger/index.html", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := srv.App.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, 200, resp.StatusCode)
+	require.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+
+	gz, err := gzip.NewReader(resp.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "SwaggerUIBundle")
+}
+
+// TestNew_BasePath verifies API routes are served under API_BASE_PATH while the Swagger UI keeps its own path.
+func TestNew_BasePath(t *testing.T) {
+	logger.Init("development", "error", "", logger.FileOutput{}, logger.Sampling{})
+	original := swaggerdocs.SwaggerInfo.BasePath
+	defer func() { swaggerdocs.SwaggerInfo.BasePath = original }()
+
+	srv := New(&config.AppConfig{BasePath: "/api/v1", SwaggerPath: "/docs"})
+	srv.API.Get("/orders/:id", func(c *fiber.Ctx) error { return c.SendString(c.Params("id")) })
+
+	resp, err := srv.App.Test(httptest.NewRequest("GET", "/api/v1/orders/42", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	resp, err = srv.App.Test(httptest.NewRequest("GET", "/orders/42", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 404, resp.StatusCode)
+
+	resp, err = srv.App.Test(httptest.NewRequest("GET", "/docs/index.html", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "/api/v1", swaggerdocs.SwaggerInfo.BasePath)
+}
+
+// TestNew_NoBasePath verifies API routes are registered at the root by default.
+func TestNew_NoBasePath(t *testing.T) {
+	logger.Init("development", "error", "", logger.FileOutput{}, logger.Sampling{})
+	srv := New(&config.AppConfig{})
+
+	assert.Same(t, srv.App, srv.API)
+}
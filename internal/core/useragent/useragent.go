@@ -0,0 +1,86 @@
+package useragent
+
+import (
+	"bufio"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// defaults are recent desktop browsers common among Colombian shoppers.
+var defaults = []string{
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/121.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/122.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/122.0.0.0 Safari/537.36 Edg/122.0.0.0",
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/122.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.3 Safari/605.1.15",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:123.0) Gecko/20100101 Firefox/123.0",
+	"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/121.0.0.0 Safari/537.36",
+}
+
+// pool holds the user agents Random picks from.
+var pool atomic.Pointer[[]string]
+
+// randIntn picks the pool index. Tests replace it for deterministic results.
+var randIntn = rand.IntN
+
+// Defaults returns a copy of the built-in user agents.
+func Defaults() []string {
+	return append([]string(nil), defaults...)
+}
+
+// Set replaces the pool Random picks from. An empty list restores the built-in defaults.
+func Set(agents []string) {
+	if len(agents) == 0 {
+		pool.Store(nil)
+		return
+	}
+	agents = append([]string(nil), agents...)
+	pool.Store(&agents)
+}
+
+// Pool returns the user agents Random currently picks from.
+func Pool() []string {
+	if p := pool.Load(); p != nil {
+		return append([]string(nil), (*p)...)
+	}
+	return Defaults()
+}
+
+// Random returns a user agent picked uniformly from the pool. Safe for concurrent use.
+func Random() string {
+	agents := defaults
+	if p := pool.Load(); p != nil {
+		agents = *p
+	}
+	return agents[randIntn(len(agents))]
+}
+
+// LoadFile reads user agents from a text file, one per line. Blank lines and lines
+// starting with # are ignored. It fails when the file lists no user agent.
+func LoadFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open user agents file: %w", err)
+	}
+	defer f.Close()
+
+	var agents []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		agents = append(agents, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read user agents file: %w", err)
+	}
+	if len(agents) == 0 {
+		return nil, fmt.Errorf("user agents file %s lists no user agent", path)
+	}
+	return agents, nil
+}
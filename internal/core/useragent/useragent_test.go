@@ -0,0 +1,85 @@
+package useragent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRandom_Defaults verifies Random picks from the built-in user agents when none are configured.
+func TestRandom_Defaults(t *testing.T) {
+	Set(nil)
+
+	for range 50 {
+		assert.Contains(t, defaults, Random())
+	}
+}
+
+// TestRandom_ConfiguredPool verifies Random only returns configured user agents and can return each of them.
+func TestRandom_ConfiguredPool(t *testing.T) {
+	configured := []string{"agent/1", "agent/2", "agent/3"}
+	Set(configured)
+	defer Set(nil)
+
+	seen := map[string]bool{}
+	for range 200 {
+		ua := Random()
+		assert.Contains(t, configured, ua)
+		seen[ua] = true
+	}
+	assert.Len(t, seen, len(configured))
+}
+
+// TestRandom_UsesRandIntn verifies the index comes from randIntn over the whole pool.
+func TestRandom_UsesRandIntn(t *testing.T) {
+	original := randIntn
+	defer func() { randIntn = original }()
+	Set([]string{"agent/1", "agent/2"})
+	defer Set(nil)
+
+	var gotN int
+	randIntn = func(n int) int {
+		gotN = n
+		return 1
+	}
+
+	assert.Equal(t, "agent/2", Random())
+	assert.Equal(t, 2, gotN)
+}
+
+// TestSet_CopiesInput verifies later changes to the caller's slice don't affect the pool.
+func TestSet_CopiesInput(t *testing.T) {
+	agents := []string{"agent/1"}
+	Set(agents)
+	defer Set(nil)
+
+	agents[0] = "changed"
+
+	assert.Equal(t, []string{"agent/1"}, Pool())
+}
+
+// TestLoadFile verifies user agents are read one per line, skipping blanks and comments.
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agents.txt")
+	content := "# desktop\nMozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko)\n\n  agent/2  \n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	agents, err := LoadFile(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko)", "agent/2"}, agents)
+}
+
+// TestLoadFile_Errors verifies missing and empty files are rejected.
+func TestLoadFile_Errors(t *testing.T) {
+	_, err := LoadFile(filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "empty.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# nothing here\n"), 0o600))
+	_, err = LoadFile(path)
+	assert.Error(t, err)
+}
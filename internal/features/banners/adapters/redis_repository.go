@@ -3,42 +3,99 @@ package adapters
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"tracker-scrapper/internal/core/cache"
+	"tracker-scrapper/internal/core/clock"
 	"tracker-scrapper/internal/features/banners/domain"
 )
 
 const bannerCacheKey = "site_banner"
 
+// dismissalKeyPrefix namespaces dismissal keys, which are suffixed with the banner ID and client token.
+const dismissalKeyPrefix = "banner_dismissal"
+
+// saveAttempts bounds retries of an unconditional save that raced with another write.
+const saveAttempts = 3
+
+// BannerCache is the cache the repository needs: reads and deletes plus atomic updates.
+type BannerCache interface {
+	cache.Cache
+	cache.Updater
+}
+
 // RedisBannerRepository implements ports.BannerRepository using the cache adaptation.
 type RedisBannerRepository struct {
-	cache cache.Cache
+	cache BannerCache
+	// clock stamps UpdatedAt on saved banners.
+	clock clock.Clock
 }
 
 // NewRedisBannerRepository creates a new RedisBannerRepository.
-func NewRedisBannerRepository(c cache.Cache) *RedisBannerRepository {
+func NewRedisBannerRepository(c BannerCache) *RedisBannerRepository {
 	return &RedisBannerRepository{
 		cache: c,
+		clock: clock.Real,
 	}
 }
 
-// Save stores the banner in the cache.
-func (r *RedisBannerRepository) Save(ctx context.Context, banner *domain.Banner) error {
-	data, err := json.Marshal(banner)
-	if err != nil {
-		return fmt.Errorf("failed to marshal banner: %w", err)
-	}
+// SetClock replaces the clock used for UpdatedAt, for tests.
+func (r *RedisBannerRepository) SetClock(c clock.Clock) {
+	r.clock = c
+}
 
-	ttl := time.Duration(banner.Duration) * time.Second
-	// If duration is 0, it means permanent, so we pass 0 which cache treats as no expiration.
+// Save stores the banner in the cache with a Redis WATCH/MULTI transaction, so the version
+// check and the write are atomic. Unconditional saves that race with another write are retried.
+func (r *RedisBannerRepository) Save(ctx context.Context, banner *domain.Banner, expectedVersion *int64) error {
+	var err error
+	for attempt := 1; attempt <= saveAttempts; attempt++ {
+		err = r.cache.Update(ctx, bannerCacheKey, func(current []byte) ([]byte, time.Duration, error) {
+			return r.nextBanner(current, banner, expectedVersion)
+		})
+		if !errors.Is(err, cache.ErrConflict) || expectedVersion != nil {
+			break
+		}
+	}
 
-	if err := r.cache.Set(ctx, bannerCacheKey, data, ttl); err != nil {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, domain.ErrBannerConflict):
+		return err
+	case errors.Is(err, cache.ErrConflict):
+		// Someone else saved between our read and write, so the expected version is stale
+		return fmt.Errorf("%w: %w", domain.ErrBannerConflict, err)
+	default:
 		return fmt.Errorf("failed to save banner to cache: %w", err)
 	}
+}
 
-	return nil
+// nextBanner checks the stored banner against expectedVersion and encodes banner with the next version.
+func (r *RedisBannerRepository) nextBanner(current []byte, banner *domain.Banner, expectedVersion *int64) ([]byte, time.Duration, error) {
+	var stored int64
+	if current != nil {
+		var existing domain.Banner
+		if err := json.Unmarshal(current, &existing); err != nil {
+			return nil, 0, fmt.Errorf("failed to unmarshal banner: %w", err)
+		}
+		stored = existing.Version
+	}
+	if expectedVersion != nil && *expectedVersion != stored {
+		return nil, 0, fmt.Errorf("%w: expected version %d, stored %d", domain.ErrBannerConflict, *expectedVersion, stored)
+	}
+
+	banner.Version = stored + 1
+	banner.UpdatedAt = r.clock.Now()
+
+	data, err := json.Marshal(banner)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to marshal banner: %w", err)
+	}
+
+	// If duration is 0, it means permanent, so we pass 0 which cache treats as no expiration.
+	return data, time.Duration(banner.Duration) * time.Second, nil
 }
 
 // Get retrieves the banner from the cache.
@@ -46,7 +103,7 @@ func (r *RedisBannerRepository) Get(ctx context.Context) (*domain.Banner, error)
 	data, err := r.cache.Get(ctx, bannerCacheKey)
 	if err != nil {
 		// Check if the error is due to key not found
-		if err.Error() == fmt.Sprintf("key not found: %s", bannerCacheKey) {
+		if errors.Is(err, cache.ErrNotFound) {
 			return nil, nil // Return nil, nil to indicate not found
 		}
 		return nil, fmt.Errorf("failed to get banner from cache: %w", err)
@@ -70,3 +127,27 @@ func (r *RedisBannerRepository) Delete(ctx context.Context) error {
 	}
 	return nil
 }
+
+// Dismiss stores a dismissal marker that expires after ttl.
+func (r *RedisBannerRepository) Dismiss(ctx context.Context, bannerID, clientToken string, ttl time.Duration) error {
+	if err := r.cache.Set(ctx, dismissalKey(bannerID, clientToken), []byte("1"), ttl); err != nil {
+		return fmt.Errorf("failed to save banner dismissal to cache: %w", err)
+	}
+	return nil
+}
+
+// IsDismissed reports whether a dismissal marker exists for the banner and client.
+func (r *RedisBannerRepository) IsDismissed(ctx context.Context, bannerID, clientToken string) (bool, error) {
+	if _, err := r.cache.Get(ctx, dismissalKey(bannerID, clientToken)); err != nil {
+		if errors.Is(err, cache.ErrNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get banner dismissal from cache: %w", err)
+	}
+	return true, nil
+}
+
+// dismissalKey returns the cache key recording that clientToken dismissed bannerID.
+func dismissalKey(bannerID, clientToken string) string {
+	return dismissalKeyPrefix + ":" + bannerID + ":" + clientToken
+}
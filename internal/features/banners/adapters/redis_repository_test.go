@@ -0,0 +1,92 @@
+package adapters
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"tracker-scrapper/internal/core/cache"
+	"tracker-scrapper/internal/core/clock"
+	"tracker-scrapper/internal/features/banners/domain"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRepository returns a repository backed by miniredis with a fixed clock.
+func newTestRepository(t *testing.T) (*RedisBannerRepository, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	redisAdapter, err := cache.NewRedisAdapter("redis://" + mr.Addr())
+	require.NoError(t, err)
+
+	repo := NewRedisBannerRepository(cache.NewPrefixedCache(redisAdapter, ""))
+	repo.SetClock(clock.NewFake(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)))
+	return repo, mr
+}
+
+// TestRedisBannerRepository_Save verifies every save bumps the version and applies the TTL.
+func TestRedisBannerRepository_Save(t *testing.T) {
+	repo, mr := newTestRepository(t)
+	ctx := context.Background()
+
+	first := &domain.Banner{Title: "First", Type: domain.BannerTypeInfo, Duration: 60}
+	require.NoError(t, repo.Save(ctx, first, nil))
+	assert.Equal(t, int64(1), first.Version)
+	assert.Equal(t, 60*time.Second, mr.TTL(bannerCacheKey))
+
+	second := &domain.Banner{Title: "Second", Type: domain.BannerTypeWarning}
+	require.NoError(t, repo.Save(ctx, second, nil))
+	assert.Equal(t, int64(2), second.Version)
+
+	stored, err := repo.Get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "Second", stored.Title)
+	assert.Equal(t, int64(2), stored.Version)
+	assert.Equal(t, repo.clock.Now(), stored.UpdatedAt)
+}
+
+// TestRedisBannerRepository_Save_ExpectedVersion verifies conditional saves only apply on a version match.
+func TestRedisBannerRepository_Save_ExpectedVersion(t *testing.T) {
+	repo, _ := newTestRepository(t)
+	ctx := context.Background()
+	version := func(v int64) *int64 { return &v }
+
+	// No banner is stored yet, so only version 0 matches
+	assert.ErrorIs(t, repo.Save(ctx, &domain.Banner{Title: "A"}, version(1)), domain.ErrBannerConflict)
+	require.NoError(t, repo.Save(ctx, &domain.Banner{Title: "A"}, version(0)))
+
+	require.NoError(t, repo.Save(ctx, &domain.Banner{Title: "B"}, version(1)))
+	assert.ErrorIs(t, repo.Save(ctx, &domain.Banner{Title: "C"}, version(1)), domain.ErrBannerConflict)
+
+	stored, err := repo.Get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "B", stored.Title)
+	assert.Equal(t, int64(2), stored.Version)
+}
+
+// TestRedisBannerRepository_Dismiss verifies dismissals are keyed per banner and client and expire.
+func TestRedisBannerRepository_Dismiss(t *testing.T) {
+	repo, mr := newTestRepository(t)
+	ctx := context.Background()
+
+	require.NoError(t, repo.Dismiss(ctx, "b1", "client", time.Minute))
+	assert.Equal(t, time.Minute, mr.TTL("banner_dismissal:b1:client"))
+
+	dismissed, err := repo.IsDismissed(ctx, "b1", "client")
+	require.NoError(t, err)
+	assert.True(t, dismissed)
+
+	dismissed, err = repo.IsDismissed(ctx, "b2", "client")
+	require.NoError(t, err)
+	assert.False(t, dismissed)
+
+	dismissed, err = repo.IsDismissed(ctx, "b1", "other")
+	require.NoError(t, err)
+	assert.False(t, dismissed)
+
+	mr.FastForward(2 * time.Minute)
+	dismissed, err = repo.IsDismissed(ctx, "b1", "client")
+	require.NoError(t, err)
+	assert.False(t, dismissed)
+}
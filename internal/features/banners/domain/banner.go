@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"time"
 )
@@ -16,28 +18,73 @@ const (
 
 var (
 	ErrInvalidBannerType = errors.New("invalid banner type")
+	// ErrBannerConflict is returned when a conditional save expected a different stored version.
+	ErrBannerConflict = errors.New("banner was modified concurrently")
+	// ErrBannerNotFound is returned when a dismissed banner is not the active one.
+	ErrBannerNotFound = errors.New("banner not found")
+	// ErrInvalidClientToken is returned when a dismissal has no usable client token.
+	ErrInvalidClientToken = errors.New("invalid client token")
 )
 
+// MaxClientTokenLength bounds client tokens, which end up in cache keys.
+const MaxClientTokenLength = 128
+
+// IsValid reports whether t is one of the known banner types.
+func (t BannerType) IsValid() bool {
+	return t == BannerTypeInfo || t == BannerTypeWarning || t == BannerTypeDanger
+}
+
 // Banner represents a site-wide alert.
 type Banner struct {
+	// ID identifies this banner; every new banner gets a fresh one, so dismissals don't carry over.
+	ID        string     `json:"id"`
 	Title     string     `json:"title"`
 	Subtitle  string     `json:"subtitle"`
 	Type      BannerType `json:"type"`
 	Duration  int        `json:"duration,omitempty"` // Duration in seconds. 0 means permanent (until manually deleted).
 	CreatedAt time.Time  `json:"created_at"`
+	// Version increases on every save; send it back as the expected version to detect concurrent updates.
+	Version int64 `json:"version"`
+	// UpdatedAt is when the banner was last saved.
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
-// NewBanner creates a new Banner and validates it.
-func NewBanner(title, subtitle string, bannerType BannerType, duration int) (*Banner, error) {
-	if bannerType != BannerTypeInfo && bannerType != BannerTypeWarning && bannerType != BannerTypeDanger {
+// NewBanner creates a new Banner created at createdAt and validates it.
+func NewBanner(title, subtitle string, bannerType BannerType, duration int, createdAt time.Time) (*Banner, error) {
+	if !bannerType.IsValid() {
 		return nil, ErrInvalidBannerType
 	}
 
 	return &Banner{
+		ID:        newBannerID(),
 		Title:     title,
 		Subtitle:  subtitle,
 		Type:      bannerType,
 		Duration:  duration,
-		CreatedAt: time.Now(),
+		CreatedAt: createdAt,
 	}, nil
 }
+
+// ExpiresIn returns how long the banner stays active after now, or 0 when it is permanent.
+// Banners past their duration return a negative value.
+func (b *Banner) ExpiresIn(now time.Time) time.Duration {
+	if b.Duration <= 0 {
+		return 0
+	}
+	return b.CreatedAt.Add(time.Duration(b.Duration) * time.Second).Sub(now)
+}
+
+// ValidateClientToken checks that a client token is present and short enough to key dismissals.
+func ValidateClientToken(token string) error {
+	if token == "" || len(token) > MaxClientTokenLength {
+		return ErrInvalidClientToken
+	}
+	return nil
+}
+
+// newBannerID returns a random 16-character hex banner ID.
+func newBannerID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -1,7 +1,9 @@
 package domain
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -46,9 +48,10 @@ func TestNewBanner(t *testing.T) {
 		},
 	}
 
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			banner, err := NewBanner(tt.title, tt.subtitle, tt.bannerType, tt.duration)
+			banner, err := NewBanner(tt.title, tt.subtitle, tt.bannerType, tt.duration, createdAt)
 
 			if tt.expectedErr != nil {
 				assert.ErrorIs(t, err, tt.expectedErr)
@@ -60,8 +63,28 @@ func TestNewBanner(t *testing.T) {
 				assert.Equal(t, tt.subtitle, banner.Subtitle)
 				assert.Equal(t, tt.bannerType, banner.Type)
 				assert.Equal(t, tt.duration, banner.Duration)
-				assert.False(t, banner.CreatedAt.IsZero())
+				assert.Equal(t, createdAt, banner.CreatedAt)
+				assert.Len(t, banner.ID, 16)
 			}
 		})
 	}
 }
+
+// TestBanner_ExpiresIn verifies the remaining lifetime of timed and permanent banners.
+func TestBanner_ExpiresIn(t *testing.T) {
+	created := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	timed := &Banner{Duration: 60, CreatedAt: created}
+	assert.Equal(t, 45*time.Second, timed.ExpiresIn(created.Add(15*time.Second)))
+	assert.Negative(t, timed.ExpiresIn(created.Add(2*time.Minute)))
+
+	permanent := &Banner{CreatedAt: created}
+	assert.Zero(t, permanent.ExpiresIn(created.Add(time.Hour)))
+}
+
+// TestValidateClientToken verifies empty and oversized client tokens are rejected.
+func TestValidateClientToken(t *testing.T) {
+	assert.NoError(t, ValidateClientToken("abc123"))
+	assert.ErrorIs(t, ValidateClientToken(""), ErrInvalidClientToken)
+	assert.ErrorIs(t, ValidateClientToken(strings.Repeat("a", MaxClientTokenLength+1)), ErrInvalidClientToken)
+}
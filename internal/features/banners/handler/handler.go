@@ -1,9 +1,12 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 
 	"tracker-scrapper/internal/core/logger"
+	"tracker-scrapper/internal/core/request"
 	"tracker-scrapper/internal/features/banners/domain"
 	"tracker-scrapper/internal/features/banners/ports"
 
@@ -11,15 +14,25 @@ import (
 	"go.uber.org/zap"
 )
 
+const (
+	// clientTokenHeader carries the token identifying a client for banner dismissals.
+	clientTokenHeader = "X-Client-Token"
+	// clientTokenCookie is read when the header is absent, so plain page loads can be filtered too.
+	clientTokenCookie = "banner_client"
+)
+
 // BannerHandler handles HTTP requests for banners.
 type BannerHandler struct {
 	service ports.BannerService
+	// strictJSON rejects request bodies containing unknown fields.
+	strictJSON bool
 }
 
 // NewBannerHandler creates a new BannerHandler.
-func NewBannerHandler(service ports.BannerService) *BannerHandler {
+func NewBannerHandler(service ports.BannerService, strictJSON bool) *BannerHandler {
 	return &BannerHandler{
-		service: service,
+		service:    service,
+		strictJSON: strictJSON,
 	}
 }
 
@@ -29,6 +42,27 @@ type CreateBannerRequest struct {
 	Subtitle string            `json:"subtitle"`
 	Type     domain.BannerType `json:"type"`
 	Duration int               `json:"duration"` // Seconds
+	// ExpectedVersion makes the update conditional: it fails with 409 unless the stored banner has this
+	// version (0 when no banner is set). Omit it to overwrite unconditionally.
+	ExpectedVersion *int64 `json:"expected_version,omitempty"`
+}
+
+// invalidTypeError reports a banner type outside the known values.
+var invalidTypeError = request.ValidationError{Field: "type", Message: "must be INFO, WARNING or DANGER"}
+
+// validate returns an error for every invalid field of the request.
+func (r *CreateBannerRequest) validate() []request.ValidationError {
+	var errs []request.ValidationError
+	if !r.Type.IsValid() {
+		errs = append(errs, invalidTypeError)
+	}
+	if r.Duration < 0 {
+		errs = append(errs, request.ValidationError{Field: "duration", Message: "must not be negative"})
+	}
+	if r.ExpectedVersion != nil && *r.ExpectedVersion < 0 {
+		errs = append(errs, request.ValidationError{Field: "expected_version", Message: "must not be negative"})
+	}
+	return errs
 }
 
 // SetBanner handles POST /banner.
@@ -38,26 +72,35 @@ type CreateBannerRequest struct {
 // @Accept json
 // @Produce json
 // @Param banner body CreateBannerRequest true "Banner details"
-// @Success 200 {object} map[string]string
-// @Failure 400 {object} map[string]string
+// @Param Idempotency-Key header string false "Retries with the same key get the first response instead of saving again"
+// @Success 200 {object} map[string]interface{}
+// @Header 200 {string} Idempotent-Replayed "true when the response was replayed for a repeated Idempotency-Key"
+// @Failure 400 {object} request.ValidationErrorResponse
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /banner [post]
 func (h *BannerHandler) SetBanner(c *fiber.Ctx) error {
 	var req CreateBannerRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+	if err := request.ParseJSON(c, &req, h.strictJSON); err != nil {
+		return request.Invalid(c, request.BodyError(err))
+	}
+	if errs := req.validate(); len(errs) > 0 {
+		return request.Invalid(c, errs...)
 	}
 
-	ctx := c.Context()
-	if err := h.service.SetBanner(ctx, req.Title, req.Subtitle, req.Type, req.Duration); err != nil {
+	ctx := c.UserContext()
+	banner, err := h.service.SetBanner(ctx, req.Title, req.Subtitle, req.Type, req.Duration, req.ExpectedVersion)
+	if err != nil {
 		if err == domain.ErrInvalidBannerType {
-			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-				"error": "Invalid banner type. Must be INFO, WARNING, or DANGER",
+			return request.Invalid(c, invalidTypeError)
+		}
+		if errors.Is(err, domain.ErrBannerConflict) {
+			return c.Status(http.StatusConflict).JSON(fiber.Map{
+				"error": "Banner was modified by someone else; reload it and try again",
 			})
 		}
-		logger.Get().Error("Failed to set banner", zap.Error(err))
+		logger.With(request.RayID(c)).Error("Failed to set banner", zap.Error(err))
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Internal server error",
 		})
@@ -65,6 +108,7 @@ func (h *BannerHandler) SetBanner(c *fiber.Ctx) error {
 
 	return c.Status(http.StatusOK).JSON(fiber.Map{
 		"message": "Banner set successfully",
+		"version": banner.Version,
 	})
 }
 
@@ -73,15 +117,16 @@ func (h *BannerHandler) SetBanner(c *fiber.Ctx) error {
 // @Description Retrieves the active site-wide banner alert.
 // @Tags Banner
 // @Produce json
+// @Param X-Client-Token header string false "Client token; banners this client dismissed are hidden (falls back to the banner_client cookie)"
 // @Success 200 {object} domain.Banner
 // @Failure 404 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /banner [get]
 func (h *BannerHandler) GetBanner(c *fiber.Ctx) error {
-	ctx := c.Context()
-	banner, err := h.service.GetBanner(ctx)
+	ctx := c.UserContext()
+	banner, err := h.service.GetBanner(ctx, clientToken(c))
 	if err != nil {
-		logger.Get().Error("Failed to get banner", zap.Error(err))
+		logger.With(request.RayID(c)).Error("Failed to get banner", zap.Error(err))
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Internal server error",
 		})
@@ -105,9 +150,9 @@ func (h *BannerHandler) GetBanner(c *fiber.Ctx) error {
 // @Failure 500 {object} map[string]string
 // @Router /banner [delete]
 func (h *BannerHandler) RemoveBanner(c *fiber.Ctx) error {
-	ctx := c.Context()
+	ctx := c.UserContext()
 	if err := h.service.RemoveBanner(ctx); err != nil {
-		logger.Get().Error("Failed to remove banner", zap.Error(err))
+		logger.With(request.RayID(c)).Error("Failed to remove banner", zap.Error(err))
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Internal server error",
 		})
@@ -117,3 +162,49 @@ func (h *BannerHandler) RemoveBanner(c *fiber.Ctx) error {
 		"message": "Banner removed successfully",
 	})
 }
+
+// DismissBanner handles POST /banners/:id/dismiss.
+// @Summary Dismiss the current banner
+// @Description Hides the banner from the client identified by the token until the banner expires.
+// @Tags Banner
+// @Produce json
+// @Param id path string true "Banner ID"
+// @Param X-Client-Token header string false "Client token (falls back to the banner_client cookie)"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} request.ValidationErrorResponse
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Router /banners/{id}/dismiss [post]
+func (h *BannerHandler) DismissBanner(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	if err := h.service.DismissBanner(ctx, c.Params("id"), clientToken(c)); err != nil {
+		if errors.Is(err, domain.ErrInvalidClientToken) {
+			return request.Invalid(c, request.ValidationError{
+				Field:   clientTokenHeader,
+				Message: fmt.Sprintf("required, up to %d characters (or the %s cookie)", domain.MaxClientTokenLength, clientTokenCookie),
+			})
+		}
+		if errors.Is(err, domain.ErrBannerNotFound) {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Banner is not active",
+			})
+		}
+		logger.With(request.RayID(c)).Error("Failed to dismiss banner", zap.Error(err))
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Internal server error",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "Banner dismissed successfully",
+	})
+}
+
+// clientToken returns the client token from the header, or from the cookie when the header is absent.
+func clientToken(c *fiber.Ctx) string {
+	if token := c.Get(clientTokenHeader); token != "" {
+		return token
+	}
+	return c.Cookies(clientTokenCookie)
+}
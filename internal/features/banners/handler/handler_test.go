@@ -5,10 +5,12 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"tracker-scrapper/internal/core/request"
 	"tracker-scrapper/internal/features/banners/domain"
 
 	"github.com/gofiber/fiber/v2"
@@ -21,13 +23,16 @@ type MockBannerService struct {
 	mock.Mock
 }
 
-func (m *MockBannerService) SetBanner(ctx context.Context, title, subtitle string, bannerType domain.BannerType, duration int) error {
-	args := m.Called(ctx, title, subtitle, bannerType, duration)
-	return args.Error(0)
+func (m *MockBannerService) SetBanner(ctx context.Context, title, subtitle string, bannerType domain.BannerType, duration int, expectedVersion *int64) (*domain.Banner, error) {
+	args := m.Called(ctx, title, subtitle, bannerType, duration, expectedVersion)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Banner), args.Error(1)
 }
 
-func (m *MockBannerService) GetBanner(ctx context.Context) (*domain.Banner, error) {
-	args := m.Called(ctx)
+func (m *MockBannerService) GetBanner(ctx context.Context, clientToken string) (*domain.Banner, error) {
+	args := m.Called(ctx, clientToken)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -39,12 +44,22 @@ func (m *MockBannerService) RemoveBanner(ctx context.Context) error {
 	return args.Error(0)
 }
 
+func (m *MockBannerService) DismissBanner(ctx context.Context, bannerID, clientToken string) error {
+	args := m.Called(ctx, bannerID, clientToken)
+	return args.Error(0)
+}
+
 func setupApp(service *MockBannerService) *fiber.App {
+	return setupAppWithStrictJSON(service, false)
+}
+
+func setupAppWithStrictJSON(service *MockBannerService, strictJSON bool) *fiber.App {
 	app := fiber.New()
-	handler := NewBannerHandler(service)
+	handler := NewBannerHandler(service, strictJSON)
 	app.Post("/banner", handler.SetBanner)
 	app.Get("/banner", handler.GetBanner)
 	app.Delete("/banner", handler.RemoveBanner)
+	app.Post("/banners/:id/dismiss", handler.DismissBanner)
 	return app
 }
 
@@ -61,7 +76,7 @@ func TestBannerHandler_SetBanner(t *testing.T) {
 		}
 		body, _ := json.Marshal(reqBody)
 
-		mockService.On("SetBanner", mock.Anything, reqBody.Title, reqBody.Subtitle, reqBody.Type, reqBody.Duration).Return(nil).Once()
+		mockService.On("SetBanner", mock.Anything, reqBody.Title, reqBody.Subtitle, reqBody.Type, reqBody.Duration, (*int64)(nil)).Return(&domain.Banner{Version: 1}, nil).Once()
 
 		req := httptest.NewRequest("POST", "/banner", bytes.NewReader(body))
 		req.Header.Set("Content-Type", "application/json")
@@ -82,21 +97,128 @@ func TestBannerHandler_SetBanner(t *testing.T) {
 		}
 		body, _ := json.Marshal(reqBody)
 
-		// The service should return ErrInvalidBannerType
-		mockService.On("SetBanner", mock.Anything, reqBody.Title, "", domain.BannerType("INVALID"), 0).Return(domain.ErrInvalidBannerType).Once()
+		req := httptest.NewRequest("POST", "/banner", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		var respBody request.ValidationErrorResponse
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&respBody))
+		assert.Equal(t, []request.ValidationError{{Field: "type", Message: "must be INFO, WARNING or DANGER"}}, respBody.Errors)
+		mockService.AssertNotCalled(t, "SetBanner")
+	})
+
+	t.Run("InvalidFields", func(t *testing.T) {
+		mockService := new(MockBannerService)
+		app := setupApp(mockService)
+
+		body := []byte(`{"type":"INFO","duration":-5,"expected_version":-1}`)
 
 		req := httptest.NewRequest("POST", "/banner", bytes.NewReader(body))
 		req.Header.Set("Content-Type", "application/json")
 		resp, err := app.Test(req)
 
 		assert.NoError(t, err)
-		if resp.StatusCode != http.StatusBadRequest {
-			// Debug
-			buf := new(bytes.Buffer)
-			buf.ReadFrom(resp.Body)
-			t.Logf("Response Body: %s", buf.String())
-		}
 		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		var respBody request.ValidationErrorResponse
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&respBody))
+		assert.Equal(t, []request.ValidationError{
+			{Field: "duration", Message: "must not be negative"},
+			{Field: "expected_version", Message: "must not be negative"},
+		}, respBody.Errors)
+		mockService.AssertNotCalled(t, "SetBanner")
+	})
+
+	t.Run("NoTitle", func(t *testing.T) {
+		mockService := new(MockBannerService)
+		app := setupApp(mockService)
+
+		mockService.On("SetBanner", mock.Anything, "", "Only a subtitle", domain.BannerTypeInfo, 0, (*int64)(nil)).Return(&domain.Banner{Version: 1}, nil).Once()
+
+		req := httptest.NewRequest("POST", "/banner", bytes.NewReader([]byte(`{"subtitle":"Only a subtitle","type":"INFO"}`)))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("StrictUnknownField", func(t *testing.T) {
+		mockService := new(MockBannerService)
+		app := setupAppWithStrictJSON(mockService, true)
+
+		body := []byte(`{"titel":"Test","type":"INFO"}`)
+
+		req := httptest.NewRequest("POST", "/banner", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		var respBody request.ValidationErrorResponse
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&respBody))
+		assert.Equal(t, []request.ValidationError{{Field: "titel", Message: "unknown field"}}, respBody.Errors)
+		mockService.AssertNotCalled(t, "SetBanner")
+	})
+
+	t.Run("LenientUnknownField", func(t *testing.T) {
+		mockService := new(MockBannerService)
+		app := setupApp(mockService)
+
+		body := []byte(`{"title":"Test","titel":"Typo","type":"INFO"}`)
+
+		mockService.On("SetBanner", mock.Anything, "Test", "", domain.BannerTypeInfo, 0, (*int64)(nil)).Return(&domain.Banner{Version: 1}, nil).Once()
+
+		req := httptest.NewRequest("POST", "/banner", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+}
+
+// TestBannerHandler_SetBanner_Conditional verifies the expected version reaches the service and conflicts map to 409.
+func TestBannerHandler_SetBanner_Conditional(t *testing.T) {
+	t.Run("Saved", func(t *testing.T) {
+		mockService := new(MockBannerService)
+		app := setupApp(mockService)
+
+		expected := int64(2)
+		mockService.On("SetBanner", mock.Anything, "Test", "", domain.BannerTypeInfo, 0, &expected).Return(&domain.Banner{Version: 3}, nil).Once()
+
+		req := httptest.NewRequest("POST", "/banner", bytes.NewReader([]byte(`{"title":"Test","type":"INFO","expected_version":2}`)))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		var respBody map[string]any
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&respBody))
+		assert.Equal(t, float64(3), respBody["version"])
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		mockService := new(MockBannerService)
+		app := setupApp(mockService)
+
+		expected := int64(2)
+		mockService.On("SetBanner", mock.Anything, "Test", "", domain.BannerTypeInfo, 0, &expected).
+			Return(nil, fmt.Errorf("service: failed to save banner: %w", domain.ErrBannerConflict)).Once()
+
+		req := httptest.NewRequest("POST", "/banner", bytes.NewReader([]byte(`{"title":"Test","type":"INFO","expected_version":2}`)))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
 		mockService.AssertExpectations(t)
 	})
 }
@@ -107,7 +229,7 @@ func TestBannerHandler_GetBanner(t *testing.T) {
 		app := setupApp(mockService)
 
 		banner := &domain.Banner{Title: "Test Banner"}
-		mockService.On("GetBanner", mock.Anything).Return(banner, nil).Once()
+		mockService.On("GetBanner", mock.Anything, "").Return(banner, nil).Once()
 
 		req := httptest.NewRequest("GET", "/banner", nil)
 		resp, err := app.Test(req)
@@ -121,7 +243,7 @@ func TestBannerHandler_GetBanner(t *testing.T) {
 		mockService := new(MockBannerService)
 		app := setupApp(mockService)
 
-		mockService.On("GetBanner", mock.Anything).Return(nil, nil).Once()
+		mockService.On("GetBanner", mock.Anything, "").Return(nil, nil).Once()
 
 		req := httptest.NewRequest("GET", "/banner", nil)
 		resp, err := app.Test(req)
@@ -135,7 +257,7 @@ func TestBannerHandler_GetBanner(t *testing.T) {
 		mockService := new(MockBannerService)
 		app := setupApp(mockService)
 
-		mockService.On("GetBanner", mock.Anything).Return(nil, errors.New("db error")).Once()
+		mockService.On("GetBanner", mock.Anything, "").Return(nil, errors.New("db error")).Once()
 
 		req := httptest.NewRequest("GET", "/banner", nil)
 		resp, err := app.Test(req)
@@ -185,3 +307,58 @@ func TestBannerHandler_RemoveBanner(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 }
+
+// TestBannerHandler_GetBanner_ClientToken verifies the client token is read from the header, then the cookie.
+func TestBannerHandler_GetBanner_ClientToken(t *testing.T) {
+	mockService := new(MockBannerService)
+	app := setupApp(mockService)
+
+	mockService.On("GetBanner", mock.Anything, "from-header").Return(nil, nil).Once()
+	mockService.On("GetBanner", mock.Anything, "from-cookie").Return(nil, nil).Once()
+
+	req := httptest.NewRequest("GET", "/banner", nil)
+	req.Header.Set("X-Client-Token", "from-header")
+	req.Header.Set("Cookie", "banner_client=ignored")
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	req = httptest.NewRequest("GET", "/banner", nil)
+	req.Header.Set("Cookie", "banner_client=from-cookie")
+	resp, err = app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	mockService.AssertExpectations(t)
+}
+
+// TestBannerHandler_DismissBanner verifies dismissal outcomes map to the right status codes.
+func TestBannerHandler_DismissBanner(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "Success", wantStatus: http.StatusOK},
+		{name: "InvalidToken", err: domain.ErrInvalidClientToken, wantStatus: http.StatusBadRequest},
+		{name: "NotActive", err: domain.ErrBannerNotFound, wantStatus: http.StatusNotFound},
+		{name: "InternalError", err: errors.New("db error"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockBannerService)
+			app := setupApp(mockService)
+
+			mockService.On("DismissBanner", mock.Anything, "b1", "client").Return(tt.err).Once()
+
+			req := httptest.NewRequest("POST", "/banners/b1/dismiss", nil)
+			req.Header.Set("X-Client-Token", "client")
+			resp, err := app.Test(req)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
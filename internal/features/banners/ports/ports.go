@@ -2,19 +2,34 @@ package ports
 
 import (
 	"context"
+	"time"
+
 	"tracker-scrapper/internal/features/banners/domain"
 )
 
 // BannerService defines the primary port for banner operations.
 type BannerService interface {
-	SetBanner(ctx context.Context, title, subtitle string, bannerType domain.BannerType, duration int) error
-	GetBanner(ctx context.Context) (*domain.Banner, error)
+	// SetBanner saves a new banner and returns it with its new version. When expectedVersion is not nil the
+	// save fails with domain.ErrBannerConflict unless the stored version matches (0 means no banner).
+	SetBanner(ctx context.Context, title, subtitle string, bannerType domain.BannerType, duration int, expectedVersion *int64) (*domain.Banner, error)
+	// GetBanner returns the active banner, or nil when there is none or the client identified by
+	// clientToken already dismissed it. An empty clientToken disables the dismissal check.
+	GetBanner(ctx context.Context, clientToken string) (*domain.Banner, error)
 	RemoveBanner(ctx context.Context) error
+	// DismissBanner hides the active banner from the client identified by clientToken until it expires.
+	// It returns domain.ErrBannerNotFound when bannerID is not the active banner.
+	DismissBanner(ctx context.Context, bannerID, clientToken string) error
 }
 
 // BannerRepository defines the secondary port for banner storage.
 type BannerRepository interface {
-	Save(ctx context.Context, banner *domain.Banner) error
+	// Save stores banner atomically, setting its Version to the stored version plus one and its UpdatedAt.
+	// When expectedVersion is not nil and differs from the stored version (0 when none), it returns domain.ErrBannerConflict.
+	Save(ctx context.Context, banner *domain.Banner, expectedVersion *int64) error
 	Get(ctx context.Context) (*domain.Banner, error)
 	Delete(ctx context.Context) error
+	// Dismiss records that clientToken dismissed bannerID, forgetting it after ttl.
+	Dismiss(ctx context.Context, bannerID, clientToken string, ttl time.Duration) error
+	// IsDismissed reports whether clientToken dismissed bannerID.
+	IsDismissed(ctx context.Context, bannerID, clientToken string) (bool, error)
 }
@@ -3,47 +3,104 @@ package service
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"tracker-scrapper/internal/core/clock"
 	"tracker-scrapper/internal/features/banners/domain"
 	"tracker-scrapper/internal/features/banners/ports"
 )
 
+// permanentDismissalTTL is how long dismissals of permanent banners are remembered,
+// so dismissals of long-gone banners don't pile up in the cache.
+const permanentDismissalTTL = 30 * 24 * time.Hour
+
 // BannerServiceImpl implements ports.BannerService.
 type BannerServiceImpl struct {
 	repo ports.BannerRepository
+	// clock stamps new banners and decides how long dismissals last.
+	clock clock.Clock
 }
 
 // NewBannerService creates a new BannerServiceImpl.
 func NewBannerService(repo ports.BannerRepository) *BannerServiceImpl {
 	return &BannerServiceImpl{
-		repo: repo,
+		repo:  repo,
+		clock: clock.Real,
 	}
 }
 
-// SetBanner creates and saves a new banner.
-func (s *BannerServiceImpl) SetBanner(ctx context.Context, title, subtitle string, bannerType domain.BannerType, duration int) error {
-	banner, err := domain.NewBanner(title, subtitle, bannerType, duration)
+// SetClock replaces the clock used for banner times, for tests.
+func (s *BannerServiceImpl) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// SetBanner creates and saves a new banner. A non-nil expectedVersion makes the save conditional
+// on the stored version; a mismatch returns domain.ErrBannerConflict.
+func (s *BannerServiceImpl) SetBanner(ctx context.Context, title, subtitle string, bannerType domain.BannerType, duration int, expectedVersion *int64) (*domain.Banner, error) {
+	banner, err := domain.NewBanner(title, subtitle, bannerType, duration, s.clock.Now())
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if err := s.repo.Save(ctx, banner); err != nil {
-		return fmt.Errorf("service: failed to save banner: %w", err)
+	if err := s.repo.Save(ctx, banner, expectedVersion); err != nil {
+		return nil, fmt.Errorf("service: failed to save banner: %w", err)
 	}
 
-	return nil
+	return banner, nil
 }
 
-// GetBanner retrieves the current banner.
-func (s *BannerServiceImpl) GetBanner(ctx context.Context) (*domain.Banner, error) {
+// GetBanner retrieves the current banner unless the client already dismissed it.
+func (s *BannerServiceImpl) GetBanner(ctx context.Context, clientToken string) (*domain.Banner, error) {
 	banner, err := s.repo.Get(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("service: failed to get banner: %w", err)
 	}
+	// Unusable tokens can't have dismissed anything, so they see the banner
+	if banner == nil || domain.ValidateClientToken(clientToken) != nil {
+		return banner, nil
+	}
+
+	dismissed, err := s.repo.IsDismissed(ctx, banner.ID, clientToken)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to check banner dismissal: %w", err)
+	}
+	if dismissed {
+		return nil, nil
+	}
 
 	return banner, nil
 }
 
+// DismissBanner records the dismissal for as long as the banner stays active.
+func (s *BannerServiceImpl) DismissBanner(ctx context.Context, bannerID, clientToken string) error {
+	if err := domain.ValidateClientToken(clientToken); err != nil {
+		return err
+	}
+
+	banner, err := s.repo.Get(ctx)
+	if err != nil {
+		return fmt.Errorf("service: failed to get banner: %w", err)
+	}
+	if banner == nil || banner.ID != bannerID {
+		return domain.ErrBannerNotFound
+	}
+
+	ttl := banner.ExpiresIn(s.clock.Now())
+	switch {
+	case ttl < 0:
+		// Expired but not yet evicted from the cache
+		return domain.ErrBannerNotFound
+	case ttl == 0:
+		ttl = permanentDismissalTTL
+	}
+
+	if err := s.repo.Dismiss(ctx, bannerID, clientToken, ttl); err != nil {
+		return fmt.Errorf("service: failed to dismiss banner: %w", err)
+	}
+
+	return nil
+}
+
 // RemoveBanner deletes the current banner.
 func (s *BannerServiceImpl) RemoveBanner(ctx context.Context) error {
 	if err := s.repo.Delete(ctx); err != nil {
@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
+
+	"tracker-scrapper/internal/core/clock"
 	"tracker-scrapper/internal/features/banners/domain"
 
 	"github.com/stretchr/testify/assert"
@@ -15,8 +18,8 @@ type MockBannerRepository struct {
 	mock.Mock
 }
 
-func (m *MockBannerRepository) Save(ctx context.Context, banner *domain.Banner) error {
-	args := m.Called(ctx, banner)
+func (m *MockBannerRepository) Save(ctx context.Context, banner *domain.Banner, expectedVersion *int64) error {
+	args := m.Called(ctx, banner, expectedVersion)
 	return args.Error(0)
 }
 
@@ -33,31 +36,52 @@ func (m *MockBannerRepository) Delete(ctx context.Context) error {
 	return args.Error(0)
 }
 
+func (m *MockBannerRepository) Dismiss(ctx context.Context, bannerID, clientToken string, ttl time.Duration) error {
+	args := m.Called(ctx, bannerID, clientToken, ttl)
+	return args.Error(0)
+}
+
+func (m *MockBannerRepository) IsDismissed(ctx context.Context, bannerID, clientToken string) (bool, error) {
+	args := m.Called(ctx, bannerID, clientToken)
+	return args.Bool(0), args.Error(1)
+}
+
 func TestBannerService_SetBanner(t *testing.T) {
 	mockRepo := new(MockBannerRepository)
 	service := NewBannerService(mockRepo)
 	ctx := context.Background()
 
 	t.Run("Success", func(t *testing.T) {
-		mockRepo.On("Save", ctx, mock.AnythingOfType("*domain.Banner")).Return(nil).Once()
+		mockRepo.On("Save", ctx, mock.AnythingOfType("*domain.Banner"), (*int64)(nil)).Return(nil).Once()
 
-		err := service.SetBanner(ctx, "Title", "Subtitle", domain.BannerTypeInfo, 60)
+		banner, err := service.SetBanner(ctx, "Title", "Subtitle", domain.BannerTypeInfo, 60, nil)
 		assert.NoError(t, err)
+		assert.Equal(t, "Title", banner.Title)
 		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("InvalidType", func(t *testing.T) {
-		err := service.SetBanner(ctx, "Title", "Subtitle", "INVALID", 60)
+		_, err := service.SetBanner(ctx, "Title", "Subtitle", "INVALID", 60, nil)
 		assert.ErrorIs(t, err, domain.ErrInvalidBannerType)
 	})
 
 	t.Run("RepoError", func(t *testing.T) {
-		mockRepo.On("Save", ctx, mock.AnythingOfType("*domain.Banner")).Return(errors.New("db error")).Once()
+		mockRepo.On("Save", ctx, mock.AnythingOfType("*domain.Banner"), (*int64)(nil)).Return(errors.New("db error")).Once()
 
-		err := service.SetBanner(ctx, "Title", "Subtitle", domain.BannerTypeInfo, 60)
+		_, err := service.SetBanner(ctx, "Title", "Subtitle", domain.BannerTypeInfo, 60, nil)
 		assert.Error(t, err)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		expected := int64(3)
+		mockRepo.On("Save", ctx, mock.AnythingOfType("*domain.Banner"), &expected).Return(domain.ErrBannerConflict).Once()
+
+		banner, err := service.SetBanner(ctx, "Title", "Subtitle", domain.BannerTypeInfo, 60, &expected)
+		assert.ErrorIs(t, err, domain.ErrBannerConflict)
+		assert.Nil(t, banner)
+		mockRepo.AssertExpectations(t)
+	})
 }
 
 func TestBannerService_GetBanner(t *testing.T) {
@@ -69,7 +93,7 @@ func TestBannerService_GetBanner(t *testing.T) {
 		expectedBanner := &domain.Banner{Title: "Test"}
 		mockRepo.On("Get", ctx).Return(expectedBanner, nil).Once()
 
-		banner, err := service.GetBanner(ctx)
+		banner, err := service.GetBanner(ctx, "")
 		assert.NoError(t, err)
 		assert.Equal(t, expectedBanner, banner)
 		mockRepo.AssertExpectations(t)
@@ -78,7 +102,7 @@ func TestBannerService_GetBanner(t *testing.T) {
 	t.Run("RepoError", func(t *testing.T) {
 		mockRepo.On("Get", ctx).Return(nil, errors.New("db error")).Once()
 
-		banner, err := service.GetBanner(ctx)
+		banner, err := service.GetBanner(ctx, "")
 		assert.Error(t, err)
 		assert.Nil(t, banner)
 		mockRepo.AssertExpectations(t)
@@ -106,3 +130,90 @@ func TestBannerService_RemoveBanner(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+// TestBannerService_GetBanner_Dismissed verifies banners the client dismissed are hidden from it.
+func TestBannerService_GetBanner_Dismissed(t *testing.T) {
+	mockRepo := new(MockBannerRepository)
+	service := NewBannerService(mockRepo)
+	ctx := context.Background()
+	active := &domain.Banner{ID: "b1", Title: "Test"}
+
+	t.Run("Dismissed", func(t *testing.T) {
+		mockRepo.On("Get", ctx).Return(active, nil).Once()
+		mockRepo.On("IsDismissed", ctx, "b1", "client").Return(true, nil).Once()
+
+		banner, err := service.GetBanner(ctx, "client")
+		assert.NoError(t, err)
+		assert.Nil(t, banner)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("NotDismissed", func(t *testing.T) {
+		mockRepo.On("Get", ctx).Return(active, nil).Once()
+		mockRepo.On("IsDismissed", ctx, "b1", "client").Return(false, nil).Once()
+
+		banner, err := service.GetBanner(ctx, "client")
+		assert.NoError(t, err)
+		assert.Equal(t, active, banner)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("RepoError", func(t *testing.T) {
+		mockRepo.On("Get", ctx).Return(active, nil).Once()
+		mockRepo.On("IsDismissed", ctx, "b1", "client").Return(false, errors.New("db error")).Once()
+
+		banner, err := service.GetBanner(ctx, "client")
+		assert.Error(t, err)
+		assert.Nil(t, banner)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+// TestBannerService_DismissBanner verifies dismissals last as long as the banner and only apply to the active one.
+func TestBannerService_DismissBanner(t *testing.T) {
+	mockRepo := new(MockBannerRepository)
+	service := NewBannerService(mockRepo)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	service.SetClock(clock.NewFake(now))
+	ctx := context.Background()
+
+	t.Run("Timed", func(t *testing.T) {
+		banner := &domain.Banner{ID: "b1", Duration: 600, CreatedAt: now.Add(-time.Minute)}
+		mockRepo.On("Get", ctx).Return(banner, nil).Once()
+		mockRepo.On("Dismiss", ctx, "b1", "client", 9*time.Minute).Return(nil).Once()
+
+		assert.NoError(t, service.DismissBanner(ctx, "b1", "client"))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Permanent", func(t *testing.T) {
+		banner := &domain.Banner{ID: "b1", CreatedAt: now.Add(-time.Minute)}
+		mockRepo.On("Get", ctx).Return(banner, nil).Once()
+		mockRepo.On("Dismiss", ctx, "b1", "client", permanentDismissalTTL).Return(nil).Once()
+
+		assert.NoError(t, service.DismissBanner(ctx, "b1", "client"))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("NotActive", func(t *testing.T) {
+		mockRepo.On("Get", ctx).Return(&domain.Banner{ID: "b2"}, nil).Once()
+		assert.ErrorIs(t, service.DismissBanner(ctx, "b1", "client"), domain.ErrBannerNotFound)
+
+		mockRepo.On("Get", ctx).Return(nil, nil).Once()
+		assert.ErrorIs(t, service.DismissBanner(ctx, "b1", "client"), domain.ErrBannerNotFound)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		banner := &domain.Banner{ID: "b1", Duration: 60, CreatedAt: now.Add(-time.Hour)}
+		mockRepo.On("Get", ctx).Return(banner, nil).Once()
+
+		assert.ErrorIs(t, service.DismissBanner(ctx, "b1", "client"), domain.ErrBannerNotFound)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("MissingToken", func(t *testing.T) {
+		assert.ErrorIs(t, service.DismissBanner(ctx, "b1", ""), domain.ErrInvalidClientToken)
+		mockRepo.AssertNotCalled(t, "Dismiss", ctx, "b1", "", mock.Anything)
+	})
+}
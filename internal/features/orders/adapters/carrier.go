@@ -0,0 +1,125 @@
+package adapter
+
+import (
+	"strings"
+	"unicode"
+)
+
+// carrierAliases maps each recognized carrier to the spellings customers use in order notes.
+// Aliases are compact: lowercase, without accents, spaces or punctuation.
+var carrierAliases = []struct {
+	carrier string
+	aliases []string
+}{
+	{"servientrega_co", []string{"servientrega", "servi", "servientrga", "serventrega"}},
+	{"coordinadora_co", []string{"coordinadora", "coordi", "coord", "cordinadora"}},
+	{"interrapidisimo_co", []string{"interrapidisimo", "inter", "interrapidismo", "interapidisimo"}},
+	{"tcc_co", []string{"tcc", "transportadoratcc"}},
+	{"envia_co", []string{"envia", "enviacolvanes", "colvanes"}},
+	{"cuatro72_co", []string{"cuatro72", "472", "cuatrosetentaydos", "serviciospostales", "serviciospostalesnacionales"}},
+}
+
+// accentReplacer strips the Spanish accents customers type inconsistently.
+var accentReplacer = strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "ñ", "n")
+
+// normalizeCarrierName converts various carrier name formats to standardized format.
+// It tries, in order, an exact alias, the longest alias contained in the name, and the
+// closest alias within a small edit distance. Unknown names are returned with the "_co" suffix.
+func normalizeCarrierName(carrier string) string {
+	carrier = strings.ToLower(strings.TrimSpace(carrier))
+
+	key := compactCarrierName(carrier)
+	if key == "" {
+		return ""
+	}
+
+	if match := matchCarrierAlias(key); match != "" {
+		return match
+	}
+
+	// Return as-is if already in correct format or unknown
+	if strings.HasSuffix(carrier, "_co") {
+		return carrier
+	}
+	return carrier + "_co"
+}
+
+// compactCarrierName lowercases name, strips accents, the "_co" suffix and anything that is
+// not a letter or digit, so "Inter Rapidísimo" and "interrapidisimo_co" compare equal.
+func compactCarrierName(name string) string {
+	name = strings.TrimSuffix(accentReplacer.Replace(strings.ToLower(name)), "_co")
+
+	var b strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// matchCarrierAlias returns the carrier whose alias best matches key, or "" when none does.
+func matchCarrierAlias(key string) string {
+	for _, entry := range carrierAliases {
+		for _, alias := range entry.aliases {
+			if key == alias {
+				return entry.carrier
+			}
+		}
+	}
+
+	// The longest contained alias wins, so "serviciospostales" is not taken for "servi"
+	best, bestLen := "", 0
+	for _, entry := range carrierAliases {
+		for _, alias := range entry.aliases {
+			if len(alias) > bestLen && strings.Contains(key, alias) {
+				best, bestLen = entry.carrier, len(alias)
+			}
+		}
+	}
+	if best != "" {
+		return best
+	}
+
+	// Tolerate typos in longer names: one edit from 5 letters, two from 9
+	bestDistance := 0
+	for _, entry := range carrierAliases {
+		for _, alias := range entry.aliases {
+			if len(alias) < 5 {
+				continue
+			}
+			maxDistance := 1
+			if len(alias) >= 9 {
+				maxDistance = 2
+			}
+			d := levenshtein(key, alias)
+			if d <= maxDistance && (best == "" || d < bestDistance) {
+				best, bestDistance = entry.carrier, d
+			}
+		}
+	}
+	return best
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,79 @@
+package adapter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNormalizeCarrierName_Aliases verifies each alias and common misspelling maps to its carrier.
+func TestNormalizeCarrierName_Aliases(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		// Servientrega
+		{"servi", "servientrega_co"},
+		{"Servientrga", "servientrega_co"},
+		{"serventrega", "servientrega_co"},
+		{"servientregaa", "servientrega_co"},
+		// Coordinadora
+		{"coordi", "coordinadora_co"},
+		{"Coord", "coordinadora_co"},
+		{"cordinadora", "coordinadora_co"},
+		{"coordinadra", "coordinadora_co"},
+		// Interrapidisimo
+		{"inter rapidisimo", "interrapidisimo_co"},
+		{"Inter Rapidísimo", "interrapidisimo_co"},
+		{"inter-rapidisimo", "interrapidisimo_co"},
+		{"interapidisimo", "interrapidisimo_co"},
+		{"intrrapidisimo", "interrapidisimo_co"},
+		// TCC
+		{"tcc", "tcc_co"},
+		{"TCC_co", "tcc_co"},
+		{"Transportadora TCC", "tcc_co"},
+		// Envia
+		{"envia", "envia_co"},
+		{"Envía", "envia_co"},
+		{"envia colvanes", "envia_co"},
+		{"colvanes", "envia_co"},
+		// 4-72
+		{"472", "cuatro72_co"},
+		{"4-72", "cuatro72_co"},
+		{"cuatro72_co", "cuatro72_co"},
+		{"Servicios Postales Nacionales", "cuatro72_co"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			assert.Equal(t, tc.expected, normalizeCarrierName(tc.input))
+		})
+	}
+}
+
+// TestNormalizeCarrierName_Unknown verifies names matching no alias keep the "_co" suffix convention.
+func TestNormalizeCarrierName_Unknown(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"deprisa", "deprisa_co"},
+		{"Saferbo", "saferbo_co"},
+		{"redetrans_co", "redetrans_co"},
+		{"", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			assert.Equal(t, tc.expected, normalizeCarrierName(tc.input))
+		})
+	}
+}
+
+// TestLevenshtein verifies the edit distance used for typo tolerance.
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, 0, levenshtein("tcc", "tcc"))
+	assert.Equal(t, 1, levenshtein("servientrga", "servientrega"))
+	assert.Equal(t, 2, levenshtein("cordinadra", "coordinadora"))
+	assert.Equal(t, 3, levenshtein("", "abc"))
+}
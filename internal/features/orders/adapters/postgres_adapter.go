@@ -0,0 +1,177 @@
+package adapter
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"time"
+
+	"tracker-scrapper/internal/features/orders/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// postgresSchema creates the orders, order_items and order_tracking tables.
+//
+//go:embed postgres_schema.sql
+var postgresSchema string
+
+// postgresQueryTimeout bounds a single GetOrder lookup.
+const postgresQueryTimeout = 5 * time.Second
+
+// PostgresOrderAdapter implements the OrderProvider interface from orders synced into Postgres.
+// Rows are written by SaveOrder, normally from the sync command.
+type PostgresOrderAdapter struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresOrderAdapter creates a new PostgresOrderAdapter using pool.
+func NewPostgresOrderAdapter(pool *pgxpool.Pool) *PostgresOrderAdapter {
+	return &PostgresOrderAdapter{pool: pool}
+}
+
+// EnsureSchema creates the order tables when they don't exist.
+func (a *PostgresOrderAdapter) EnsureSchema(ctx context.Context) error {
+	if _, err := a.pool.Exec(ctx, postgresSchema); err != nil {
+		return fmt.Errorf("failed to apply order schema: %w", err)
+	}
+	return nil
+}
+
+// GetOrder reads an order with its items and tracking. It returns nil without error when the order isn't synced.
+func (a *PostgresOrderAdapter) GetOrder(orderID string) (*domain.Order, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), postgresQueryTimeout)
+	defer cancel()
+
+	var order domain.Order
+	var status string
+	err := a.pool.QueryRow(ctx, `
+		SELECT id, status, first_name, last_name, address, city, state, email, payment_method, created_at
+		FROM orders WHERE id = $1`, orderID,
+	).Scan(&order.ID, &status, &order.FirstName, &order.LastName, &order.Address,
+		&order.City, &order.State, &order.Email, &order.PaymentMethod, &order.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order %s: %w", orderID, err)
+	}
+	order.Status = domain.OrderStatus(status)
+
+	if order.Items, err = a.getItems(ctx, orderID); err != nil {
+		return nil, err
+	}
+	if order.Tracking, err = a.getTracking(ctx, orderID); err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// getItems reads the items of an order in their original order.
+func (a *PostgresOrderAdapter) getItems(ctx context.Context, orderID string) ([]domain.OrderItem, error) {
+	rows, err := a.pool.Query(ctx, `
+		SELECT quantity, sku, name, picture
+		FROM order_items WHERE order_id = $1 ORDER BY position`, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query items of order %s: %w", orderID, err)
+	}
+
+	items, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.OrderItem, error) {
+		var item domain.OrderItem
+		err := row.Scan(&item.Quantity, &item.SKU, &item.Name, &item.Picture)
+		return item, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read items of order %s: %w", orderID, err)
+	}
+	return items, nil
+}
+
+// getTracking reads the tracking entries of an order in their original order.
+func (a *PostgresOrderAdapter) getTracking(ctx context.Context, orderID string) ([]domain.TrackingInfo, error) {
+	rows, err := a.pool.Query(ctx, `
+		SELECT provider, tracking_number, date_shipped
+		FROM order_tracking WHERE order_id = $1 ORDER BY position`, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tracking of order %s: %w", orderID, err)
+	}
+
+	tracking, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.TrackingInfo, error) {
+		var info domain.TrackingInfo
+		var shipped *time.Time
+		if err := row.Scan(&info.TrackingProvider, &info.TrackingNumber, &shipped); err != nil {
+			return info, err
+		}
+		if shipped != nil {
+			info.DateShipped = *shipped
+		}
+		return info, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tracking of order %s: %w", orderID, err)
+	}
+	return tracking, nil
+}
+
+// SaveOrder inserts or replaces an order, its items and its tracking in a single transaction.
+func (a *PostgresOrderAdapter) SaveOrder(ctx context.Context, order *domain.Order) error {
+	tx, err := a.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	// Rollback is a no-op once the transaction is committed
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO orders (id, status, first_name, last_name, address, city, state, email, payment_method, created_at, synced_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, now())
+		ON CONFLICT (id) DO UPDATE SET
+			status = EXCLUDED.status,
+			first_name = EXCLUDED.first_name,
+			last_name = EXCLUDED.last_name,
+			address = EXCLUDED.address,
+			city = EXCLUDED.city,
+			state = EXCLUDED.state,
+			email = EXCLUDED.email,
+			payment_method = EXCLUDED.payment_method,
+			created_at = EXCLUDED.created_at,
+			synced_at = now()`,
+		order.ID, string(order.Status), order.FirstName, order.LastName, order.Address,
+		order.City, order.State, order.Email, order.PaymentMethod, order.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to upsert order %s: %w", order.ID, err)
+	}
+
+	// Items and tracking are replaced wholesale; WooCommerce has no stable IDs for fee-line items
+	if _, err := tx.Exec(ctx, `DELETE FROM order_items WHERE order_id = $1`, order.ID); err != nil {
+		return fmt.Errorf("failed to clear items of order %s: %w", order.ID, err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM order_tracking WHERE order_id = $1`, order.ID); err != nil {
+		return fmt.Errorf("failed to clear tracking of order %s: %w", order.ID, err)
+	}
+
+	batch := &pgx.Batch{}
+	for i, item := range order.Items {
+		batch.Queue(`INSERT INTO order_items (order_id, position, quantity, sku, name, picture) VALUES ($1, $2, $3, $4, $5, $6)`,
+			order.ID, i, item.Quantity, item.SKU, item.Name, item.Picture)
+	}
+	for i, info := range order.Tracking {
+		var shipped *time.Time
+		if !info.DateShipped.IsZero() {
+			shipped = &info.DateShipped
+		}
+		batch.Queue(`INSERT INTO order_tracking (order_id, position, provider, tracking_number, date_shipped) VALUES ($1, $2, $3, $4, $5)`,
+			order.ID, i, info.TrackingProvider, info.TrackingNumber, shipped)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to insert items and tracking of order %s: %w", order.ID, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit order %s: %w", order.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package adapter
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"tracker-scrapper/internal/features/orders/domain"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestPostgresAdapter connects to TEST_DATABASE_URL and applies the schema, skipping the test when it is unset.
+func newTestPostgresAdapter(t *testing.T) *PostgresOrderAdapter {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	pool, err := pgxpool.New(context.Background(), dsn)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	adapter := NewPostgresOrderAdapter(pool)
+	require.NoError(t, adapter.EnsureSchema(context.Background()))
+	return adapter
+}
+
+// TestPostgresOrderAdapter_SaveAndGet verifies an order round-trips, and a re-sync replaces items and tracking.
+func TestPostgresOrderAdapter_SaveAndGet(t *testing.T) {
+	adapter := newTestPostgresAdapter(t)
+	ctx := context.Background()
+
+	order := &domain.Order{
+		ID:        "pg-test-1",
+		Status:    domain.OrderStatusShipped,
+		FirstName: "Ana",
+		Email:     "ana@example.com",
+		CreatedAt: time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC),
+		Items:     []domain.OrderItem{{Quantity: 2, SKU: "SKU-1", Name: "Item 1"}, {Quantity: 1, SKU: "SKU-2", Name: "Item 2"}},
+		Tracking: []domain.TrackingInfo{
+			{TrackingProvider: "coordinadora_co", TrackingNumber: "04333004120", DateShipped: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+	require.NoError(t, adapter.SaveOrder(ctx, order))
+
+	got, err := adapter.GetOrder("pg-test-1")
+	require.NoError(t, err)
+	assert.Equal(t, order.Status, got.Status)
+	assert.Equal(t, order.Items, got.Items)
+	assert.Equal(t, order.Tracking[0].TrackingNumber, got.Tracking[0].TrackingNumber)
+	assert.True(t, order.Tracking[0].DateShipped.Equal(got.Tracking[0].DateShipped))
+
+	order.Items = order.Items[:1]
+	order.Tracking = nil
+	require.NoError(t, adapter.SaveOrder(ctx, order))
+
+	got, err = adapter.GetOrder("pg-test-1")
+	require.NoError(t, err)
+	assert.Len(t, got.Items, 1)
+	assert.Empty(t, got.Tracking)
+}
+
+// TestPostgresOrderAdapter_GetOrder_NotFound verifies an unsynced order is reported as nil.
+func TestPostgresOrderAdapter_GetOrder_NotFound(t *testing.T) {
+	adapter := newTestPostgresAdapter(t)
+
+	got, err := adapter.GetOrder("does-not-exist")
+
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
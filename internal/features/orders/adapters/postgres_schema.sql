@@ -0,0 +1,33 @@
+-- Orders synced from WooCommerce by cmd/sync. Safe to apply repeatedly.
+CREATE TABLE IF NOT EXISTS orders (
+    id             TEXT PRIMARY KEY,
+    status         TEXT        NOT NULL,
+    first_name     TEXT        NOT NULL DEFAULT '',
+    last_name      TEXT        NOT NULL DEFAULT '',
+    address        TEXT        NOT NULL DEFAULT '',
+    city           TEXT        NOT NULL DEFAULT '',
+    state          TEXT        NOT NULL DEFAULT '',
+    email          TEXT        NOT NULL DEFAULT '',
+    payment_method TEXT        NOT NULL DEFAULT '',
+    created_at     TIMESTAMPTZ NOT NULL,
+    synced_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS order_items (
+    order_id TEXT    NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    quantity INTEGER NOT NULL,
+    sku      TEXT    NOT NULL DEFAULT '',
+    name     TEXT    NOT NULL DEFAULT '',
+    picture  TEXT    NOT NULL DEFAULT '',
+    PRIMARY KEY (order_id, position)
+);
+
+CREATE TABLE IF NOT EXISTS order_tracking (
+    order_id        TEXT        NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    position        INTEGER     NOT NULL,
+    provider        TEXT        NOT NULL DEFAULT '',
+    tracking_number TEXT        NOT NULL,
+    date_shipped    TIMESTAMPTZ,
+    PRIMARY KEY (order_id, position)
+);
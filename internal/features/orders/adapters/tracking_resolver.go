@@ -0,0 +1,38 @@
+package adapter
+
+import (
+	"fmt"
+
+	trackingdomain "tracker-scrapper/internal/features/tracking/domain"
+	trackingservice "tracker-scrapper/internal/features/tracking/service"
+)
+
+// TrackingServiceResolver implements the TrackingResolver interface with the tracking feature's
+// service, so order shipments share its cache, breakers and browser limits.
+type TrackingServiceResolver struct {
+	// service looks up and caches tracking histories.
+	service *trackingservice.TrackingService
+}
+
+// NewTrackingServiceResolver creates a new TrackingServiceResolver.
+func NewTrackingServiceResolver(service *trackingservice.TrackingService) *TrackingServiceResolver {
+	return &TrackingServiceResolver{service: service}
+}
+
+// GetTrackingHistory normalizes carrier as typed on the order and looks the shipment up.
+// Numbers failing the tracking sanity checks are rejected without a scrape.
+func (r *TrackingServiceResolver) GetTrackingHistory(trackingNumber, carrier string) (*trackingdomain.TrackingHistory, error) {
+	courier := normalizeCarrierName(carrier)
+	if !r.service.SupportsCourier(courier) {
+		return nil, fmt.Errorf("%w: %s", trackingservice.ErrCourierNotSupported, courier)
+	}
+	if err := r.service.CheckTrackingNumber(trackingNumber, courier); err != nil {
+		return nil, fmt.Errorf("invalid tracking number: %w", err)
+	}
+
+	result, err := r.service.GetTrackingHistory(trackingNumber, courier)
+	if err != nil {
+		return nil, err
+	}
+	return result.History, nil
+}
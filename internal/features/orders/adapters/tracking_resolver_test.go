@@ -0,0 +1,75 @@
+package adapter
+
+import (
+	"testing"
+	"time"
+
+	"tracker-scrapper/internal/core/cache"
+	trackingdomain "tracker-scrapper/internal/features/tracking/domain"
+	trackingports "tracker-scrapper/internal/features/tracking/ports"
+	trackingservice "tracker-scrapper/internal/features/tracking/service"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixedTrackingProvider answers every lookup for courier with an empty processing history.
+type fixedTrackingProvider struct {
+	courier string
+	lookups []string
+}
+
+// GetTrackingHistory implements TrackingProvider.
+func (p *fixedTrackingProvider) GetTrackingHistory(trackingNumber string) (*trackingdomain.TrackingHistory, error) {
+	p.lookups = append(p.lookups, trackingNumber)
+	return &trackingdomain.TrackingHistory{GlobalStatus: trackingdomain.TrackingStatusProcessing}, nil
+}
+
+// SupportsCourier implements TrackingProvider.
+func (p *fixedTrackingProvider) SupportsCourier(courier string) bool {
+	return courier == p.courier
+}
+
+// Couriers implements TrackingProvider.
+func (p *fixedTrackingProvider) Couriers() []string {
+	return []string{p.courier}
+}
+
+// newTestResolver returns a resolver over a tracking service backed by miniredis and provider.
+func newTestResolver(t *testing.T, provider *fixedTrackingProvider) *TrackingServiceResolver {
+	mr := miniredis.RunT(t)
+	redisAdapter, err := cache.NewRedisAdapter("redis://" + mr.Addr())
+	require.NoError(t, err)
+
+	svc, err := trackingservice.NewTrackingService([]trackingports.TrackingProvider{provider}, redisAdapter, time.Minute, nil, 1)
+	require.NoError(t, err)
+	return NewTrackingServiceResolver(svc)
+}
+
+// TestTrackingServiceResolver_GetTrackingHistory verifies carrier names from orders are normalized before the lookup.
+func TestTrackingServiceResolver_GetTrackingHistory(t *testing.T) {
+	provider := &fixedTrackingProvider{courier: "coordinadora_co"}
+	resolver := newTestResolver(t, provider)
+
+	history, err := resolver.GetTrackingHistory("04333004120", "Coordinadora")
+
+	require.NoError(t, err)
+	assert.Equal(t, "coordinadora_co", history.Courier)
+	assert.Equal(t, []string{"04333004120"}, provider.lookups)
+}
+
+// TestTrackingServiceResolver_Rejected verifies unsupported carriers and implausible numbers are rejected without a scrape.
+func TestTrackingServiceResolver_Rejected(t *testing.T) {
+	provider := &fixedTrackingProvider{courier: "coordinadora_co"}
+	resolver := newTestResolver(t, provider)
+
+	_, err := resolver.GetTrackingHistory("04333004120", "TCC")
+	assert.ErrorIs(t, err, trackingservice.ErrCourierNotSupported)
+	assert.EqualError(t, err, "courier not supported: tcc_co")
+
+	_, err = resolver.GetTrackingHistory("0000000000", "Coordinadora")
+	assert.EqualError(t, err, "invalid tracking number: must not be all zeros")
+
+	assert.Empty(t, provider.lookups)
+}
@@ -0,0 +1,125 @@
+package adapter
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"tracker-scrapper/internal/core/config"
+	"tracker-scrapper/internal/core/httpclient"
+	"tracker-scrapper/internal/core/logger"
+	"tracker-scrapper/internal/features/orders/domain"
+
+	"go.uber.org/zap"
+)
+
+// SignatureHeader carries the hex-encoded HMAC-SHA256 of the webhook body.
+const SignatureHeader = "X-Webhook-Signature"
+
+// IdempotencyKeyHeader carries a key that stays the same across retries of one delivery, so
+// receivers can drop duplicates when an attempt succeeded but its response was lost.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// WebhookNotifier implements the OrderNotifier interface by POSTing orders to a webhook URL.
+type WebhookNotifier struct {
+	// client is the HTTP client used for webhook deliveries.
+	client *http.Client
+	// config holds the webhook URL, secret and retry settings.
+	config config.WebhookConfig
+	// backoff is the delay before the first retry; it doubles on each attempt.
+	backoff time.Duration
+}
+
+// NewWebhookNotifier creates a new instance of WebhookNotifier.
+func NewWebhookNotifier(cfg config.WebhookConfig) *WebhookNotifier {
+	return &WebhookNotifier{
+		client:  httpclient.NewClient(10 * time.Second),
+		config:  cfg,
+		backoff: time.Second,
+	}
+}
+
+// NotifyShipped delivers the order in the background. It is a no-op when no URL is configured.
+func (n *WebhookNotifier) NotifyShipped(order *domain.Order) {
+	if n.config.URL == "" || order == nil {
+		return
+	}
+
+	body, err := json.Marshal(order)
+	if err != nil {
+		logger.Get().Error("Failed to encode webhook payload", zap.String("order_id", order.ID), zap.Error(err))
+		return
+	}
+
+	go n.deliver(order.ID, body)
+}
+
+// deliver sends the payload, retrying with exponential backoff until it succeeds or retries run out.
+func (n *WebhookNotifier) deliver(orderID string, body []byte) {
+	backoff := n.backoff
+	key := deliveryKey(orderID, body)
+	var err error
+
+	for attempt := 0; attempt <= n.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		if err = n.post(body, key); err == nil {
+			logger.Get().Info("Order webhook delivered", zap.String("order_id", orderID), zap.Int("attempt", attempt+1))
+			return
+		}
+
+		logger.Get().Warn("Order webhook attempt failed",
+			zap.String("order_id", orderID),
+			zap.Int("attempt", attempt+1),
+			zap.Error(err),
+		)
+	}
+
+	logger.Get().Error("Order webhook delivery gave up", zap.String("order_id", orderID), zap.Error(err))
+}
+
+// post performs a single signed webhook request.
+func (n *WebhookNotifier) post(body []byte, key string) error {
+	req, err := http.NewRequest("POST", n.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(IdempotencyKeyHeader, key)
+	if n.config.Secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(body, n.config.Secret))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// deliveryKey identifies a delivery by order and payload, so a redelivery of the same
+// notification (even after a restart) carries the same key.
+func deliveryKey(orderID string, body []byte) string {
+	sum := sha256.Sum256(body)
+	return "order-shipped-" + orderID + "-" + hex.EncodeToString(sum[:8])
+}
+
+// Sign returns the hex-encoded HMAC-SHA256 of body using secret.
+func Sign(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
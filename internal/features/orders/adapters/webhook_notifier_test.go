@@ -0,0 +1,71 @@
+package adapter
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"tracker-scrapper/internal/core/config"
+	"tracker-scrapper/internal/features/orders/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestWebhookNotifier_NotifyShipped_SignsAndRetries verifies a signed delivery succeeds after a failed attempt,
+// sending the same Idempotency-Key on every attempt.
+func TestWebhookNotifier_NotifyShipped_SignsAndRetries(t *testing.T) {
+	var attempts atomic.Int32
+	delivered := make(chan string, 1)
+	keys := make(chan string, 2)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys <- r.Header.Get(IdempotencyKeyHeader)
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "sha256="+Sign(body, "s3cret"), r.Header.Get(SignatureHeader))
+		w.WriteHeader(http.StatusOK)
+		delivered <- string(body)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(config.WebhookConfig{URL: server.URL, Secret: "s3cret", MaxRetries: 2})
+	notifier.backoff = time.Millisecond
+
+	notifier.NotifyShipped(&domain.Order{ID: "123", Status: domain.OrderStatusShipped})
+
+	select {
+	case body := <-delivered:
+		assert.Contains(t, body, `"order_id":"123"`)
+		assert.Equal(t, int32(2), attempts.Load())
+
+		first, second := <-keys, <-keys
+		assert.Equal(t, deliveryKey("123", []byte(body)), first)
+		assert.Equal(t, first, second)
+	case <-time.After(2 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+}
+
+// TestWebhookNotifier_NotifyShipped_NoURL verifies the notifier is a no-op without a URL.
+func TestWebhookNotifier_NotifyShipped_NoURL(t *testing.T) {
+	notifier := NewWebhookNotifier(config.WebhookConfig{})
+	assert.NotPanics(t, func() {
+		notifier.NotifyShipped(&domain.Order{ID: "123"})
+	})
+}
+
+// TestDeliveryKey verifies keys are stable for a payload and differ between orders and payloads.
+func TestDeliveryKey(t *testing.T) {
+	key := deliveryKey("123", []byte(`{"status":"SHIPPED"}`))
+
+	assert.Regexp(t, `^order-shipped-123-[0-9a-f]{16}$`, key)
+	assert.Equal(t, key, deliveryKey("123", []byte(`{"status":"SHIPPED"}`)))
+	assert.NotEqual(t, key, deliveryKey("124", []byte(`{"status":"SHIPPED"}`)))
+	assert.NotEqual(t, key, deliveryKey("123", []byte(`{"status":"SHIPPED","tracking":"1"}`)))
+}
@@ -1,15 +1,18 @@
 package adapter
 
 import (
-	"encoding/base64"
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
+	"tracker-scrapper/internal/core/clock"
 	"tracker-scrapper/internal/core/config"
 	"tracker-scrapper/internal/core/httpclient"
 	"tracker-scrapper/internal/core/logger"
@@ -24,31 +27,128 @@ type WooCommerceAdapter struct {
 	client *http.Client
 	// config holds the WooCommerce connection details.
 	config config.WooCommerceConfig
+	// defaultCarrier is the normalized WC_DEFAULT_CARRIER, assigned to guide numbers found without a carrier.
+	defaultCarrier string
+	// apiVersion is the REST API version used in endpoint paths (v1, v2 or v3).
+	apiVersion string
+	// orderParams are the WC_EXTRA_ORDER_PARAMS added to single order requests.
+	orderParams url.Values
+	// clock timestamps OAuth 1.0a signed requests.
+	clock clock.Clock
+}
+
+// defaultAPIVersion is used when WC_API_VERSION is not set.
+const defaultAPIVersion = "v3"
+
+// requiredOrderFields are the order fields mapToDomain reads. A _fields parameter must keep all of them.
+var requiredOrderFields = []string{
+	"id", "status", "date_created", "payment_method_title", "billing", "shipping",
+	"line_items", "fee_lines", "shipping_lines", "meta_data", "customer_note",
 }
 
 // NewWooCommerceAdapter creates a new instance of WooCommerceAdapter.
-func NewWooCommerceAdapter(cfg config.WooCommerceConfig) *WooCommerceAdapter {
+// It fails when cfg.ExtraOrderParams is not a valid query string or its _fields drops a mapped field.
+// With cfg.InsecureSkipVerify the store's TLS certificate is not verified and a warning is logged.
+func NewWooCommerceAdapter(cfg config.WooCommerceConfig) (*WooCommerceAdapter, error) {
+	apiVersion := cfg.APIVersion
+	if apiVersion == "" {
+		apiVersion = defaultAPIVersion
+	}
+
+	orderParams, err := parseOrderParams(cfg.ExtraOrderParams)
+	if err != nil {
+		return nil, err
+	}
+
+	client := httpclient.NewClient(10 * time.Second)
+	if cfg.InsecureSkipVerify {
+		logger.Get().Warn("TLS certificate verification is DISABLED for WooCommerce (WC_INSECURE_SKIP_VERIFY); never use this in production",
+			zap.String("url", cfg.URL))
+		client = httpclient.NewInsecureClient(10 * time.Second)
+	}
+
 	return &WooCommerceAdapter{
-		client: httpclient.NewClient(10 * time.Second),
-		config: cfg,
+		client:         client,
+		config:         cfg,
+		defaultCarrier: normalizeCarrierName(cfg.DefaultCarrier),
+		apiVersion:     apiVersion,
+		orderParams:    orderParams,
+		clock:          clock.Real,
+	}, nil
+}
+
+// SetClock replaces the clock used for OAuth timestamps, for tests.
+func (a *WooCommerceAdapter) SetClock(c clock.Clock) {
+	a.clock = c
+}
+
+// parseOrderParams parses WC_EXTRA_ORDER_PARAMS (e.g., "dp=2&_fields=id,status,...") and checks that a
+// _fields parameter lists every field in requiredOrderFields. Nested selections such as line_items.name
+// count as keeping their top-level field.
+func parseOrderParams(raw string) (url.Values, error) {
+	params, err := url.ParseQuery(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid WC_EXTRA_ORDER_PARAMS: %w", err)
+	}
+
+	fields, ok := params["_fields"]
+	if !ok {
+		return params, nil
+	}
+	requested := map[string]bool{}
+	for _, value := range fields {
+		for _, field := range strings.Split(value, ",") {
+			top, _, _ := strings.Cut(strings.TrimSpace(field), ".")
+			requested[top] = true
+		}
+	}
+
+	var missing []string
+	for _, field := range requiredOrderFields {
+		if !requested[field] {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("WC_EXTRA_ORDER_PARAMS _fields leaves out fields orders are mapped from: %s", strings.Join(missing, ","))
 	}
+	return params, nil
+}
+
+// endpoint returns the URL of path (e.g., "orders/123") under the configured REST API version.
+func (a *WooCommerceAdapter) endpoint(path string) string {
+	return fmt.Sprintf("%s/wp-json/wc/%s/%s", a.config.URL, a.apiVersion, path)
 }
 
 // GetOrder fetches an order from WooCommerce and maps it to the domain entity.
 func (a *WooCommerceAdapter) GetOrder(orderID string) (*domain.Order, error) {
-	url := fmt.Sprintf("%s/wp-json/wc/v3/orders/%s", a.config.URL, orderID)
+	raw, err := a.GetRawOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	var wcOrder woocommerceOrder
+	if err := json.Unmarshal(raw, &wcOrder); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return a.mapToDomain(wcOrder, orderID), nil
+}
+
+// GetRawOrder fetches an order from WooCommerce and returns the unmapped JSON,
+// including all meta_data and shipping_lines, for debugging tracking extraction.
+func (a *WooCommerceAdapter) GetRawOrder(orderID string) (json.RawMessage, error) {
+	url := a.endpoint("orders/" + orderID)
+	if len(a.orderParams) > 0 {
+		url += "?" + a.orderParams.Encode()
+	}
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	// Basic Auth using optimized string building
-	authVal := make([]byte, 0, len(a.config.ConsumerKey)+len(a.config.ConsumerSecret)+1)
-	authVal = fmt.Appendf(authVal, "%s:%s", a.config.ConsumerKey, a.config.ConsumerSecret)
-
-	encoded := base64.StdEncoding.EncodeToString(authVal)
-	req.Header.Add("Authorization", "Basic "+encoded)
+	a.authorize(req)
 
 	resp, err := a.client.Do(req)
 	if err != nil {
@@ -63,28 +163,69 @@ func (a *WooCommerceAdapter) GetOrder(orderID string) (*domain.Order, error) {
 		return nil, fmt.Errorf("woocommerce API returned status: %d", resp.StatusCode)
 	}
 
-	var wcOrder woocommerceOrder
-	if err := json.NewDecoder(resp.Body).Decode(&wcOrder); err != nil {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if !json.Valid(body) {
+		return nil, fmt.Errorf("failed to decode response: invalid JSON")
+	}
+
+	return json.RawMessage(body), nil
+}
+
+// ListOrders fetches one page of orders, oldest modification first, and maps them to domain entities.
+// A non-zero modifiedAfter restricts the page to orders changed since then. An empty page means no more orders.
+func (a *WooCommerceAdapter) ListOrders(page, perPage int, modifiedAfter time.Time) ([]*domain.Order, error) {
+	query := url.Values{
+		"page":     {strconv.Itoa(page)},
+		"per_page": {strconv.Itoa(perPage)},
+		"orderby":  {"modified"},
+		"order":    {"asc"},
+	}
+	if !modifiedAfter.IsZero() {
+		query.Set("modified_after", modifiedAfter.UTC().Format("2006-01-02T15:04:05"))
+	}
+	req, err := http.NewRequest("GET", a.endpoint("orders?"+query.Encode()), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	a.authorize(req)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("woocommerce API returned status: %d", resp.StatusCode)
+	}
+
+	var wcOrders []woocommerceOrder
+	if err := json.NewDecoder(resp.Body).Decode(&wcOrders); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	return a.mapToDomain(wcOrder, orderID), nil
+	orders := make([]*domain.Order, 0, len(wcOrders))
+	for _, wcOrder := range wcOrders {
+		orders = append(orders, a.mapToDomain(wcOrder, strconv.Itoa(wcOrder.ID)))
+	}
+	return orders, nil
 }
 
 // HealthCheck verifies that the WooCommerce API is reachable and credentials are valid.
 func (a *WooCommerceAdapter) HealthCheck() error {
 	// Check orders endpoint with per_page=1 to verify auth and reachability
-	url := fmt.Sprintf("%s/wp-json/wc/v3/orders?per_page=1", a.config.URL)
+	url := a.endpoint("orders?per_page=1")
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return fmt.Errorf("health check failed to create request: %w", err)
 	}
 
-	authVal := make([]byte, 0, len(a.config.ConsumerKey)+len(a.config.ConsumerSecret)+1)
-	authVal = fmt.Appendf(authVal, "%s:%s", a.config.ConsumerKey, a.config.ConsumerSecret)
-	encoded := base64.StdEncoding.EncodeToString(authVal)
-	req.Header.Add("Authorization", "Basic "+encoded)
+	a.authorize(req)
 
 	resp, err := a.client.Do(req)
 	if err != nil {
@@ -99,8 +240,52 @@ func (a *WooCommerceAdapter) HealthCheck() error {
 	return nil
 }
 
+// AddOrderNote creates a note on a WooCommerce order and returns the created note ID.
+// Customer-visible notes are also emailed to the customer by WooCommerce.
+func (a *WooCommerceAdapter) AddOrderNote(orderID, note string, customerVisible bool) (int, error) {
+	url := a.endpoint("orders/" + orderID + "/notes")
+
+	body, err := json.Marshal(wcOrderNoteRequest{Note: note, CustomerNote: customerVisible})
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode note: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	a.authorize(req)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		var apiErr wcErrorResponse
+		if json.NewDecoder(resp.Body).Decode(&apiErr) == nil && apiErr.Message != "" {
+			return 0, fmt.Errorf("woocommerce API returned status %d: %s", resp.StatusCode, apiErr.Message)
+		}
+		return 0, fmt.Errorf("woocommerce API returned status: %d", resp.StatusCode)
+	}
+
+	var created wcOrderNote
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return 0, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return created.ID, nil
+}
+
 // mapToDomain converts a raw WooCommerce order response into a domain Order entity.
 func (a *WooCommerceAdapter) mapToDomain(wcOrder woocommerceOrder, orderID string) *domain.Order {
+	if a.apiVersion != defaultAPIVersion {
+		wcOrder = wcOrder.withLegacyAddresses()
+	}
+
 	tracking := a.extractTrackingInfo(wcOrder, orderID)
 	status := mapStatus(wcOrder.Status, tracking)
 
@@ -116,7 +301,7 @@ func (a *WooCommerceAdapter) mapToDomain(wcOrder woocommerceOrder, orderID strin
 		PaymentMethod: wcOrder.PaymentMethodTitle,
 		Tracking:      tracking,
 		CreatedAt:     time.Time(wcOrder.DateCreated),
-		Items:         mapItems(wcOrder.LineItems, wcOrder.FeeLines),
+		Items:         mapItems(wcOrder.LineItems, wcOrder.FeeLines, a.config.FeeLineDelimiter),
 	}
 }
 
@@ -141,11 +326,18 @@ func mapStatus(status string, tracking []domain.TrackingInfo) domain.OrderStatus
 }
 
 // extractTrackingInfo attempts to find tracking information from order metadata.
+// Guide numbers found without a carrier get the store's default carrier, if one is configured.
 func (a *WooCommerceAdapter) extractTrackingInfo(order woocommerceOrder, orderID string) []domain.TrackingInfo {
+	return withDefaultCarrier(a.findTrackingInfo(order, orderID), a.defaultCarrier)
+}
+
+// findTrackingInfo returns tracking information as stored in the order metadata or notes.
+func (a *WooCommerceAdapter) findTrackingInfo(order woocommerceOrder, orderID string) []domain.TrackingInfo {
 	var tracking []domain.TrackingInfo
 
 	for _, shippingLine := range order.ShippingLines {
 		var trackingNum, trackingProvider string
+		var dateShipped time.Time
 
 		for _, meta := range shippingLine.MetaData {
 			switch meta.Key {
@@ -157,6 +349,10 @@ func (a *WooCommerceAdapter) extractTrackingInfo(order woocommerceOrder, orderID
 				if val, ok := meta.Value.(string); ok && val != "" {
 					trackingProvider = val
 				}
+			case "Date Shipped", "date_shipped", "_date_shipped":
+				if val, ok := parseDateShipped(meta.Value); ok {
+					dateShipped = val
+				}
 			}
 		}
 
@@ -164,6 +360,7 @@ func (a *WooCommerceAdapter) extractTrackingInfo(order woocommerceOrder, orderID
 			tracking = append(tracking, domain.TrackingInfo{
 				TrackingNumber:   trackingNum,
 				TrackingProvider: trackingProvider,
+				DateShipped:      dateShipped,
 			})
 		}
 	}
@@ -201,6 +398,11 @@ func (a *WooCommerceAdapter) extractTrackingInfo(order woocommerceOrder, orderID
 		})
 	}
 
+	// The order's own note is already loaded, so check it before fetching the notes list
+	if len(tracking) == 0 {
+		tracking = extractTrackingFromNotes(order.CustomerNote, a.defaultCarrier)
+	}
+
 	// Final fallback: fetch and parse order notes
 	if len(tracking) == 0 {
 		tracking = a.getTrackingFromNotes(orderID)
@@ -209,6 +411,20 @@ func (a *WooCommerceAdapter) extractTrackingInfo(order woocommerceOrder, orderID
 	return tracking
 }
 
+// withDefaultCarrier sets carrier as the provider of entries that have a guide number but no provider.
+// Entries without a guide number are left alone, so no tracking is made up.
+func withDefaultCarrier(tracking []domain.TrackingInfo, carrier string) []domain.TrackingInfo {
+	if carrier == "" {
+		return tracking
+	}
+	for i := range tracking {
+		if tracking[i].TrackingNumber != "" && tracking[i].TrackingProvider == "" {
+			tracking[i].TrackingProvider = carrier
+		}
+	}
+	return tracking
+}
+
 // parseTrackingItems parses the WooCommerce tracking items JSON structure.
 func parseTrackingItems(value interface{}) ([]domain.TrackingInfo, error) {
 	jsonBytes, err := json.Marshal(value)
@@ -232,109 +448,144 @@ func parseTrackingItems(value interface{}) ([]domain.TrackingInfo, error) {
 	return tracking, nil
 }
 
+// parseDateShipped parses a ship date stored as "YYYY-MM-DD" or as a unix timestamp,
+// either as a string or a JSON number. Unparseable values report false.
+func parseDateShipped(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case string:
+		v = strings.TrimSpace(v)
+		if v == "" {
+			return time.Time{}, false
+		}
+		if t, err := time.Parse("2006-01-02", v); err == nil {
+			return t, true
+		}
+		if secs, err := strconv.ParseInt(v, 10, 64); err == nil && secs > 0 {
+			return time.Unix(secs, 0).UTC(), true
+		}
+	case float64:
+		if v > 0 {
+			return time.Unix(int64(v), 0).UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Order notes are requested newest first, notesPerPage at a time, reading at most maxNotesPages pages.
+const (
+	notesPerPage  = 50
+	maxNotesPages = 4
+)
+
 // getTrackingFromNotes fetches order notes from WooCommerce API and extracts tracking information.
+// Notes are read newest first, so the latest customer note carrying a guide number wins; further
+// pages are only requested while the previous one came back full.
 func (a *WooCommerceAdapter) getTrackingFromNotes(orderID string) []domain.TrackingInfo {
-	url := fmt.Sprintf("%s/wp-json/wc/v3/orders/%s/notes", a.config.URL, orderID)
+	for page := 1; page <= maxNotesPages; page++ {
+		notes, err := a.getOrderNotes(orderID, page)
+		if err != nil {
+			logger.Get().Warn("Failed to fetch order notes", zap.String("order_id", orderID), zap.Int("page", page), zap.Error(err))
+			return nil
+		}
 
-	req, err := http.NewRequest("GET", url, nil)
+		// Search for tracking info in customer notes
+		for _, note := range notes {
+			if note.CustomerNote && note.Note != "" {
+				if tracking := extractTrackingFromNotes(note.Note, a.defaultCarrier); len(tracking) > 0 {
+					return tracking
+				}
+			}
+		}
+
+		// A short page is the last one; a longer one means the store ignored per_page and sent everything
+		if len(notes) != notesPerPage {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// getOrderNotes fetches one page of an order's notes, newest first.
+func (a *WooCommerceAdapter) getOrderNotes(orderID string, page int) ([]wcOrderNote, error) {
+	query := url.Values{
+		"per_page": {strconv.Itoa(notesPerPage)},
+		"page":     {strconv.Itoa(page)},
+		"orderby":  {"date"},
+		"order":    {"desc"},
+	}
+
+	req, err := http.NewRequest("GET", a.endpoint("orders/"+orderID+"/notes?"+query.Encode()), nil)
 	if err != nil {
-		logger.Get().Warn("Failed to create notes request", zap.String("order_id", orderID), zap.Error(err))
-		return nil
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	// Basic Auth
-	authVal := make([]byte, 0, len(a.config.ConsumerKey)+len(a.config.ConsumerSecret)+1)
-	authVal = fmt.Appendf(authVal, "%s:%s", a.config.ConsumerKey, a.config.ConsumerSecret)
-	encoded := base64.StdEncoding.EncodeToString(authVal)
-	req.Header.Add("Authorization", "Basic "+encoded)
+	a.authorize(req)
 
 	resp, err := a.client.Do(req)
 	if err != nil {
-		logger.Get().Warn("Failed to fetch order notes", zap.String("order_id", orderID), zap.Error(err))
-		return nil
+		return nil, fmt.Errorf("failed to execute request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		logger.Get().Warn("Order notes request failed", zap.String("order_id", orderID), zap.Int("status", resp.StatusCode))
-		return nil
+		return nil, fmt.Errorf("woocommerce API returned status: %d", resp.StatusCode)
 	}
 
 	var notes []wcOrderNote
 	if err := json.NewDecoder(resp.Body).Decode(&notes); err != nil {
-		logger.Get().Warn("Failed to decode order notes", zap.String("order_id", orderID), zap.Error(err))
-		return nil
+		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
+	return notes, nil
+}
 
-	// Search for tracking info in customer notes
-	for _, note := range notes {
-		if note.CustomerNote && note.Note != "" {
-			if tracking := extractTrackingFromNotes(note.Note); len(tracking) > 0 {
-				return tracking
-			}
-		}
-	}
+// notesTrackingPattern matches "No de guía: {number} Paquetería: {carrier}" in customer notes.
+// Case-insensitive, handles accents (guía/guia), flexible whitespace.
+var notesTrackingPattern = regexp.MustCompile(`(?i)no\s+de\s+gu[ií]a:\s*(\S+).*?paqueter[ií]a:\s*(\S+)`)
 
-	return nil
-}
+// notesGuidePattern matches "No de guía: {number}" in notes that don't name a carrier.
+var notesGuidePattern = regexp.MustCompile(`(?i)no\s+de\s+gu[ií]a:\s*(\S+)`)
 
 // extractTrackingFromNotes parses customer notes to extract tracking information.
 // Matches patterns like: "No de guía: 2259176774 Paquetería: servientrega_co"
-func extractTrackingFromNotes(notes string) []domain.TrackingInfo {
+// Notes for orders split into several shipments yield one entry per guide number.
+// When no carrier is named, guide numbers are attributed to defaultCarrier, if it is set.
+func extractTrackingFromNotes(notes, defaultCarrier string) []domain.TrackingInfo {
 	if notes == "" {
 		return nil
 	}
 
-	// Pattern matches: "No de guía: {number} Paquetería: {carrier}"
-	// Case-insensitive, handles accents (guía/guia), flexible whitespace
-	pattern := regexp.MustCompile(`(?i)no\s+de\s+gu[ií]a:\s*(\S+).*?paqueter[ií]a:\s*(\S+)`)
-	matches := pattern.FindStringSubmatch(notes)
-
-	if len(matches) < 3 {
-		return nil
+	found := notesTrackingPattern.FindAllStringSubmatch(notes, -1)
+	if len(found) == 0 && defaultCarrier != "" {
+		for _, m := range notesGuidePattern.FindAllStringSubmatch(notes, -1) {
+			found = append(found, []string{m[0], m[1], defaultCarrier})
+		}
 	}
 
-	trackingNumber := strings.TrimSpace(matches[1])
-	carrier := strings.TrimSpace(matches[2])
+	var tracking []domain.TrackingInfo
+	for _, matches := range found {
+		trackingNumber := strings.TrimSpace(matches[1])
+		carrier := strings.TrimSpace(matches[2])
 
-	// Normalize carrier name to standard format
-	normalizedCarrier := normalizeCarrierName(carrier)
+		// Normalize carrier name to standard format
+		normalizedCarrier := normalizeCarrierName(carrier)
 
-	if trackingNumber == "" || normalizedCarrier == "" {
-		return nil
-	}
+		if trackingNumber == "" || normalizedCarrier == "" {
+			continue
+		}
 
-	return []domain.TrackingInfo{
-		{
+		tracking = append(tracking, domain.TrackingInfo{
 			TrackingNumber:   trackingNumber,
 			TrackingProvider: normalizedCarrier,
-		},
+		})
 	}
-}
-
-// normalizeCarrierName converts various carrier name formats to standardized format.
-func normalizeCarrierName(carrier string) string {
-	carrier = strings.ToLower(strings.TrimSpace(carrier))
 
-	// Map common variations to standard format
-	switch {
-	case strings.Contains(carrier, "servientrega"):
-		return "servientrega_co"
-	case strings.Contains(carrier, "coordinadora"):
-		return "coordinadora_co"
-	case strings.Contains(carrier, "interrapidisimo") || strings.Contains(carrier, "inter"):
-		return "interrapidisimo_co"
-	default:
-		// Return as-is if already in correct format or unknown
-		if strings.HasSuffix(carrier, "_co") {
-			return carrier
-		}
-		return carrier + "_co"
-	}
+	return tracking
 }
 
 // mapItems converts WooCommerce line items and fee lines to domain OrderItems.
-func mapItems(wcItems []wcLineItem, feeLines []wcFeeLine) []domain.OrderItem {
+// When feeDelimiter is non-empty, each fee line name is split on it into separate items.
+func mapItems(wcItems []wcLineItem, feeLines []wcFeeLine, feeDelimiter string) []domain.OrderItem {
 	items := make([]domain.OrderItem, 0, len(wcItems)+len(feeLines))
 
 	for _, item := range wcItems {
@@ -351,17 +602,39 @@ func mapItems(wcItems []wcLineItem, feeLines []wcFeeLine) []domain.OrderItem {
 	}
 
 	for _, fee := range feeLines {
-		items = append(items, domain.OrderItem{
-			Quantity: 1,
-			SKU:      "",
-			Name:     fee.Name,
-			Picture:  "",
-		})
+		for _, name := range splitFeeName(fee.Name, feeDelimiter) {
+			items = append(items, domain.OrderItem{
+				Quantity: 1,
+				SKU:      "",
+				Name:     name,
+				Picture:  "",
+			})
+		}
 	}
 
 	return items
 }
 
+// splitFeeName splits a combined fee line name into product names, dropping empty parts.
+// If delimiter is empty the name is returned unchanged.
+func splitFeeName(name, delimiter string) []string {
+	if delimiter == "" {
+		return []string{name}
+	}
+
+	var names []string
+	for _, part := range strings.Split(name, delimiter) {
+		if part = strings.TrimSpace(part); part != "" {
+			names = append(names, part)
+		}
+	}
+
+	if len(names) == 0 {
+		return []string{name}
+	}
+	return names
+}
+
 // internal structs for mapping
 
 // woocommerceOrder represents the JSON structure of an order from WooCommerce API.
@@ -386,6 +659,24 @@ type woocommerceOrder struct {
 	ShippingLines []wcShippingLine `json:"shipping_lines"`
 	// MetaData contains extra fields.
 	MetaData []wcMetaData `json:"meta_data"`
+	// CustomerNote is the note attached to the order itself; some stores write the guide number there.
+	CustomerNote string `json:"customer_note"`
+	// BillingAddress is the billing address as named by legacy (v1/v2) stores.
+	BillingAddress *wcBilling `json:"billing_address"`
+	// ShippingAddress is the shipping address as named by legacy (v1/v2) stores.
+	ShippingAddress *wcShipping `json:"shipping_address"`
+}
+
+// withLegacyAddresses returns the order with billing and shipping taken from the legacy
+// billing_address and shipping_address fields when the current names are absent.
+func (o woocommerceOrder) withLegacyAddresses() woocommerceOrder {
+	if o.Billing == (wcBilling{}) && o.BillingAddress != nil {
+		o.Billing = *o.BillingAddress
+	}
+	if o.Shipping == (wcShipping{}) && o.ShippingAddress != nil {
+		o.Shipping = *o.ShippingAddress
+	}
+	return o
 }
 
 // wcMetaData represents a key-value pair in WooCommerce metadata.
@@ -410,6 +701,22 @@ type wcOrderNote struct {
 	DateCreated string `json:"date_created"`
 }
 
+// wcOrderNoteRequest is the body sent to create an order note.
+type wcOrderNoteRequest struct {
+	// Note is the note content.
+	Note string `json:"note"`
+	// CustomerNote makes the note visible to (and emailed to) the customer.
+	CustomerNote bool `json:"customer_note"`
+}
+
+// wcErrorResponse is the error body returned by the WooCommerce REST API.
+type wcErrorResponse struct {
+	// Code is the machine-readable error code (e.g., woocommerce_rest_shop_order_invalid_id).
+	Code string `json:"code"`
+	// Message is the human-readable error description.
+	Message string `json:"message"`
+}
+
 // wcTrackingItem represents a single tracking entry from WooCommerce Shipment Tracking plugin.
 type wcTrackingItem struct {
 	// TrackingProvider is the carrier name.
@@ -2,8 +2,10 @@ package adapter
 
 import (
 	"encoding/base64"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
@@ -47,7 +49,8 @@ func TestWooCommerceAdapter_GetOrder_Success(t *testing.T) {
 		"meta_data": []
 	}`
 
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	// Basic auth is only used over HTTPS
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		expectedAuth := "Basic " + base64.StdEncoding.EncodeToString([]byte("ck_test:cs_test"))
 		assert.Equal(t, expectedAuth, r.Header.Get("Authorization"))
 
@@ -72,7 +75,9 @@ func TestWooCommerceAdapter_GetOrder_Success(t *testing.T) {
 		ConsumerSecret: "cs_test",
 	}
 
-	adapter := NewWooCommerceAdapter(cfg)
+	adapter, err := NewWooCommerceAdapter(cfg)
+	require.NoError(t, err)
+	adapter.client = server.Client()
 	order, err := adapter.GetOrder("123")
 
 	require.NoError(t, err)
@@ -142,7 +147,8 @@ func TestWooCommerceAdapter_GetOrder_WithShippingLineTracking(t *testing.T) {
 	}))
 	defer server.Close()
 
-	adapter := NewWooCommerceAdapter(config.WooCommerceConfig{URL: server.URL})
+	adapter, err := NewWooCommerceAdapter(config.WooCommerceConfig{URL: server.URL})
+	require.NoError(t, err)
 	order, err := adapter.GetOrder("456")
 
 	require.NoError(t, err)
@@ -153,6 +159,109 @@ func TestWooCommerceAdapter_GetOrder_WithShippingLineTracking(t *testing.T) {
 
 	assert.Equal(t, "coordinadora_co", order.Tracking[0].TrackingProvider)
 	assert.Equal(t, "93202303516", order.Tracking[0].TrackingNumber)
+	assert.True(t, order.Tracking[0].DateShipped.IsZero(), "no ship date in metadata")
+}
+
+// TestExtractTrackingInfo_DateShipped verifies ship dates are read from shipping line metadata in both formats.
+func TestExtractTrackingInfo_DateShipped(t *testing.T) {
+	order := woocommerceOrder{
+		ShippingLines: []wcShippingLine{
+			{MetaData: []wcMetaData{
+				{Key: "Tracking Number", Value: "93202303516"},
+				{Key: "Date Shipped", Value: "2024-05-02"},
+			}},
+			{MetaData: []wcMetaData{
+				{Key: "_tracking_number", Value: "2259176774"},
+				{Key: "_date_shipped", Value: "1714608000"},
+			}},
+			{MetaData: []wcMetaData{
+				{Key: "tracking_number", Value: "36000123456"},
+				{Key: "date_shipped", Value: "not a date"},
+			}},
+		},
+	}
+
+	tracking := (&WooCommerceAdapter{}).extractTrackingInfo(order, "1")
+
+	require.Len(t, tracking, 3)
+	assert.Equal(t, time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC), tracking[0].DateShipped)
+	assert.Equal(t, time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC), tracking[1].DateShipped)
+	assert.True(t, tracking[2].DateShipped.IsZero())
+}
+
+// TestExtractTrackingInfo_DefaultCarrier verifies the default carrier only fills in guide numbers without a carrier.
+func TestExtractTrackingInfo_DefaultCarrier(t *testing.T) {
+	order := woocommerceOrder{
+		ShippingLines: []wcShippingLine{
+			{MetaData: []wcMetaData{{Key: "Tracking Number", Value: "2259176774"}}},
+			{MetaData: []wcMetaData{
+				{Key: "Tracking Number", Value: "36000123456"},
+				{Key: "Tracking Company", Value: "coordinadora_co"},
+			}},
+			{MetaData: []wcMetaData{{Key: "Tracking Company", Value: "interrapidisimo_co"}}},
+		},
+	}
+
+	adapter, err := NewWooCommerceAdapter(config.WooCommerceConfig{DefaultCarrier: "Servientrega"})
+	require.NoError(t, err)
+	tracking := adapter.extractTrackingInfo(order, "1")
+
+	require.Len(t, tracking, 3)
+	assert.Equal(t, "servientrega_co", tracking[0].TrackingProvider)
+	assert.Equal(t, "coordinadora_co", tracking[1].TrackingProvider)
+	assert.Equal(t, domain.TrackingInfo{TrackingProvider: "interrapidisimo_co"}, tracking[2])
+
+	tracking = (&WooCommerceAdapter{}).extractTrackingInfo(order, "1")
+	assert.Empty(t, tracking[0].TrackingProvider, "no default configured")
+}
+
+// TestExtractTrackingInfo_CustomerNote verifies tracking in the order's customer note is used without fetching notes.
+func TestExtractTrackingInfo_CustomerNote(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Unexpected request: %s", r.URL.Path)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	adapter, err := NewWooCommerceAdapter(config.WooCommerceConfig{URL: server.URL, DefaultCarrier: "servientrega"})
+	require.NoError(t, err)
+	adapter.client = server.Client()
+
+	order := woocommerceOrder{CustomerNote: "No de guía: 36000123456 Paquetería: coordinadora"}
+	tracking := adapter.extractTrackingInfo(order, "1")
+	require.Len(t, tracking, 1)
+	assert.Equal(t, domain.TrackingInfo{TrackingNumber: "36000123456", TrackingProvider: "coordinadora_co"}, tracking[0])
+
+	// Without a carrier, the default applies
+	order = woocommerceOrder{CustomerNote: "No de guia: 2259176774"}
+	tracking = adapter.extractTrackingInfo(order, "1")
+	require.Len(t, tracking, 1)
+	assert.Equal(t, "servientrega_co", tracking[0].TrackingProvider)
+}
+
+// TestParseDateShipped verifies the accepted ship date formats.
+func TestParseDateShipped(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    interface{}
+		expected time.Time
+		ok       bool
+	}{
+		{"date", "2024-05-02", time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC), true},
+		{"unix string", "1714608000", time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC), true},
+		{"unix number", float64(1714608000), time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC), true},
+		{"empty", "", time.Time{}, false},
+		{"garbage", "yesterday", time.Time{}, false},
+		{"unsupported type", true, time.Time{}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := parseDateShipped(tc.value)
+			assert.Equal(t, tc.ok, ok)
+			assert.True(t, tc.expected.Equal(got))
+		})
+	}
 }
 
 // TestWooCommerceAdapter_GetOrder_WithFeeLines verifies fee_lines are included as items.
@@ -184,7 +293,8 @@ func TestWooCommerceAdapter_GetOrder_WithFeeLines(t *testing.T) {
 	}))
 	defer server.Close()
 
-	adapter := NewWooCommerceAdapter(config.WooCommerceConfig{URL: server.URL})
+	adapter, err := NewWooCommerceAdapter(config.WooCommerceConfig{URL: server.URL})
+	require.NoError(t, err)
 	order, err := adapter.GetOrder("789")
 
 	require.NoError(t, err)
@@ -198,6 +308,47 @@ func TestWooCommerceAdapter_GetOrder_WithFeeLines(t *testing.T) {
 	assert.Equal(t, "", order.Items[1].Picture)
 }
 
+// TestWooCommerceAdapter_GetOrder_SplitFeeLines verifies delimited fee lines produce multiple items.
+func TestWooCommerceAdapter_GetOrder_SplitFeeLines(t *testing.T) {
+	mockResponse := `{
+		"id": 790,
+		"status": "processing",
+		"date_created": "2023-10-27T15:00:00",
+		"billing": {"first_name": "Bob", "last_name": "Brown", "email": "bob@example.com"},
+		"shipping": {"address_1": "789 Oak St", "city": "Town", "state": "TN"},
+		"line_items": [],
+		"fee_lines": [
+			{"name": "Journey Camo Blanco | Extra Strap |"}
+		],
+		"shipping_lines": [],
+		"meta_data": []
+	}`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(mockResponse))
+	}))
+	defer server.Close()
+
+	adapter, err := NewWooCommerceAdapter(config.WooCommerceConfig{URL: server.URL, FeeLineDelimiter: "|"})
+	require.NoError(t, err)
+	order, err := adapter.GetOrder("790")
+
+	require.NoError(t, err)
+	require.Len(t, order.Items, 2)
+	assert.Equal(t, "Journey Camo Blanco", order.Items[0].Name)
+	assert.Equal(t, 1, order.Items[0].Quantity)
+	assert.Equal(t, "Extra Strap", order.Items[1].Name)
+}
+
+// TestSplitFeeName verifies fee line splitting with and without a delimiter.
+func TestSplitFeeName(t *testing.T) {
+	assert.Equal(t, []string{"A | B"}, splitFeeName("A | B", ""))
+	assert.Equal(t, []string{"A", "B"}, splitFeeName("A | B", "|"))
+	assert.Equal(t, []string{"A + B"}, splitFeeName("A + B", "|"))
+	assert.Equal(t, []string{" | "}, splitFeeName(" | ", "|"))
+}
+
 // TestWooCommerceAdapter_GetOrder_LegacyTracking verifies fallback to legacy metadata.
 func TestWooCommerceAdapter_GetOrder_LegacyTracking(t *testing.T) {
 	mockResponse := `{
@@ -221,7 +372,8 @@ func TestWooCommerceAdapter_GetOrder_LegacyTracking(t *testing.T) {
 	}))
 	defer server.Close()
 
-	adapter := NewWooCommerceAdapter(config.WooCommerceConfig{URL: server.URL})
+	adapter, err := NewWooCommerceAdapter(config.WooCommerceConfig{URL: server.URL})
+	require.NoError(t, err)
 	order, err := adapter.GetOrder("890")
 
 	require.NoError(t, err)
@@ -242,7 +394,8 @@ func TestWooCommerceAdapter_GetOrder_NotFound(t *testing.T) {
 	cfg := config.WooCommerceConfig{
 		URL: server.URL,
 	}
-	adapter := NewWooCommerceAdapter(cfg)
+	adapter, err := NewWooCommerceAdapter(cfg)
+	require.NoError(t, err)
 
 	order, err := adapter.GetOrder("999")
 	require.Error(t, err)
@@ -284,6 +437,118 @@ func TestWooCommerceAdapter_GetOrder_MappedStatus(t *testing.T) {
 	}
 }
 
+// TestWooCommerceAdapter_GetRawOrder verifies the unmapped order JSON is returned untouched.
+func TestWooCommerceAdapter_GetRawOrder(t *testing.T) {
+	mockResponse := `{"id":456,"meta_data":[{"key":"_custom","value":{"nested":true}}],"shipping_lines":[]}`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/wp-json/wc/v3/orders/456", r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(mockResponse))
+	}))
+	defer server.Close()
+
+	adapter, err := NewWooCommerceAdapter(config.WooCommerceConfig{URL: server.URL})
+	require.NoError(t, err)
+	raw, err := adapter.GetRawOrder("456")
+
+	require.NoError(t, err)
+	assert.JSONEq(t, mockResponse, string(raw))
+}
+
+// TestWooCommerceAdapter_ExtraOrderParams verifies WC_EXTRA_ORDER_PARAMS is sent on the order request only.
+func TestWooCommerceAdapter_ExtraOrderParams(t *testing.T) {
+	fields := "id,status,date_created,payment_method_title,billing,shipping,line_items.name,line_items.quantity,fee_lines,shipping_lines,meta_data,customer_note"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/wp-json/wc/v3/orders/123":
+			assert.Equal(t, "2", r.URL.Query().Get("dp"))
+			assert.Equal(t, fields, r.URL.Query().Get("_fields"))
+			w.Write([]byte(`{"id": 123, "status": "processing"}`))
+		case "/wp-json/wc/v3/orders/123/notes":
+			assert.Empty(t, r.URL.Query().Get("_fields"))
+			w.Write([]byte(`[]`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	adapter, err := NewWooCommerceAdapter(config.WooCommerceConfig{URL: server.URL, ExtraOrderParams: "dp=2&_fields=" + fields})
+	require.NoError(t, err)
+	order, err := adapter.GetOrder("123")
+
+	require.NoError(t, err)
+	assert.Equal(t, "123", order.ID)
+}
+
+// TestNewWooCommerceAdapter_ExtraOrderParams verifies invalid params and _fields dropping mapped fields are rejected.
+func TestNewWooCommerceAdapter_ExtraOrderParams(t *testing.T) {
+	_, err := NewWooCommerceAdapter(config.WooCommerceConfig{ExtraOrderParams: "dp=2&_fields=id,status,billing"})
+	assert.EqualError(t, err, "WC_EXTRA_ORDER_PARAMS _fields leaves out fields orders are mapped from: date_created,payment_method_title,shipping,line_items,fee_lines,shipping_lines,meta_data,customer_note")
+
+	_, err = NewWooCommerceAdapter(config.WooCommerceConfig{ExtraOrderParams: "dp=%zz"})
+	assert.ErrorContains(t, err, "invalid WC_EXTRA_ORDER_PARAMS")
+
+	_, err = NewWooCommerceAdapter(config.WooCommerceConfig{ExtraOrderParams: "dp=2"})
+	assert.NoError(t, err)
+}
+
+// TestWooCommerceAdapter_APIVersion verifies legacy API versions use their own endpoint path
+// and that v2 billing_address/shipping_address fields are mapped like billing/shipping.
+func TestWooCommerceAdapter_APIVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/wp-json/wc/v2/orders/789":
+			w.Write([]byte(`{
+				"id": 789,
+				"status": "processing",
+				"billing_address": {"first_name": "Ana", "last_name": "Gómez", "email": "ana@example.com"},
+				"shipping_address": {"address_1": "Calle 1", "city": "Bogotá", "state": "DC"}
+			}`))
+		case "/wp-json/wc/v2/orders/789/notes":
+			w.Write([]byte(`[]`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	adapter, err := NewWooCommerceAdapter(config.WooCommerceConfig{URL: server.URL, APIVersion: "v2"})
+	require.NoError(t, err)
+	order, err := adapter.GetOrder("789")
+
+	require.NoError(t, err)
+	assert.Equal(t, "Ana", order.FirstName)
+	assert.Equal(t, "Gómez", order.LastName)
+	assert.Equal(t, "ana@example.com", order.Email)
+	assert.Equal(t, "Calle 1", order.Address)
+	assert.Equal(t, "Bogotá", order.City)
+	assert.Equal(t, "DC", order.State)
+}
+
+// TestWooCommerceAdapter_GetRawOrder_Errors verifies not found and invalid JSON responses fail.
+func TestWooCommerceAdapter_GetRawOrder_Errors(t *testing.T) {
+	status, body := http.StatusNotFound, ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	adapter, err := NewWooCommerceAdapter(config.WooCommerceConfig{URL: server.URL})
+	require.NoError(t, err)
+
+	_, err = adapter.GetRawOrder("1")
+	assert.EqualError(t, err, "order not found: 1")
+
+	status, body = http.StatusOK, "<html>"
+	_, err = adapter.GetRawOrder("1")
+	assert.ErrorContains(t, err, "invalid JSON")
+}
+
 // TestWooCommerceAdapter_HealthCheck tests the HealthCheck logic.
 func TestWooCommerceAdapter_HealthCheck(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
@@ -295,9 +560,10 @@ func TestWooCommerceAdapter_HealthCheck(t *testing.T) {
 		defer server.Close()
 
 		cfg := config.WooCommerceConfig{URL: server.URL}
-		adapter := NewWooCommerceAdapter(cfg)
+		adapter, err := NewWooCommerceAdapter(cfg)
+		require.NoError(t, err)
 
-		err := adapter.HealthCheck()
+		err = adapter.HealthCheck()
 		assert.NoError(t, err)
 	})
 
@@ -308,26 +574,144 @@ func TestWooCommerceAdapter_HealthCheck(t *testing.T) {
 		defer server.Close()
 
 		cfg := config.WooCommerceConfig{URL: server.URL}
-		adapter := NewWooCommerceAdapter(cfg)
+		adapter, err := NewWooCommerceAdapter(cfg)
+		require.NoError(t, err)
 
-		err := adapter.HealthCheck()
+		err = adapter.HealthCheck()
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "status: 500")
 	})
 
 	t.Run("Failure_Network", func(t *testing.T) {
 		cfg := config.WooCommerceConfig{URL: "http://invalid-url.local"}
-		adapter := NewWooCommerceAdapter(cfg)
-		err := adapter.HealthCheck()
+		adapter, err := NewWooCommerceAdapter(cfg)
+		require.NoError(t, err)
+		err = adapter.HealthCheck()
 		assert.Error(t, err)
 	})
 }
 
+// TestWooCommerceAdapter_AddOrderNote verifies the note request body, auth header and returned ID.
+func TestWooCommerceAdapter_AddOrderNote(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/wp-json/wc/v3/orders/123/notes", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		expectedAuth := "Basic " + base64.StdEncoding.EncodeToString([]byte("ck_test:cs_test"))
+		assert.Equal(t, expectedAuth, r.Header.Get("Authorization"))
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "Tu pedido fue entregado", body["note"])
+		assert.Equal(t, true, body["customer_note"])
+
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id": 281, "note": "Tu pedido fue entregado", "customer_note": true}`))
+	}))
+	defer server.Close()
+
+	adapter, err := NewWooCommerceAdapter(config.WooCommerceConfig{URL: server.URL, ConsumerKey: "ck_test", ConsumerSecret: "cs_test"})
+	require.NoError(t, err)
+	adapter.client = server.Client()
+	noteID, err := adapter.AddOrderNote("123", "Tu pedido fue entregado", true)
+
+	require.NoError(t, err)
+	assert.Equal(t, 281, noteID)
+}
+
+// TestWooCommerceAdapter_AddOrderNote_Error verifies non-201 responses are returned as errors.
+func TestWooCommerceAdapter_AddOrderNote_Error(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		contains string
+	}{
+		{"InvalidOrder", http.StatusNotFound, `{"code": "woocommerce_rest_shop_order_invalid_id", "message": "Invalid ID."}`, "Invalid ID."},
+		{"NoBody", http.StatusUnauthorized, "", "status: 401"},
+		{"UnexpectedOK", http.StatusOK, `{"id": 1}`, "status: 200"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			adapter, err := NewWooCommerceAdapter(config.WooCommerceConfig{URL: server.URL})
+			require.NoError(t, err)
+			_, err = adapter.AddOrderNote("999", "note", false)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.contains)
+		})
+	}
+}
+
+// TestWooCommerceAdapter_getTrackingFromNotes_Paged verifies notes are requested newest first and
+// that a tracking note past other notes, and past the first full page, is still found.
+func TestWooCommerceAdapter_getTrackingFromNotes_Paged(t *testing.T) {
+	var pages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		assert.Equal(t, "50", query.Get("per_page"))
+		assert.Equal(t, "date", query.Get("orderby"))
+		assert.Equal(t, "desc", query.Get("order"))
+		pages = append(pages, query.Get("page"))
+
+		var notes []wcOrderNote
+		switch query.Get("page") {
+		case "1":
+			for i := range notesPerPage {
+				notes = append(notes, wcOrderNote{ID: 100 - i, Note: "Estado cambiado", CustomerNote: i%2 == 0})
+			}
+		case "2":
+			notes = []wcOrderNote{
+				{ID: 50, Note: "Pedido en preparación", CustomerNote: true},
+				{ID: 49, Note: "No de guía: 2259176774 Paquetería: servientrega_co", CustomerNote: false},
+				{ID: 48, Note: "No de guía: 1234567890 Paquetería: coordinadora_co", CustomerNote: true},
+			}
+		}
+		json.NewEncoder(w).Encode(notes)
+	}))
+	defer server.Close()
+
+	adapter, err := NewWooCommerceAdapter(config.WooCommerceConfig{URL: server.URL})
+	require.NoError(t, err)
+	tracking := adapter.getTrackingFromNotes("123")
+
+	require.Len(t, tracking, 1)
+	assert.Equal(t, "1234567890", tracking[0].TrackingNumber)
+	assert.Equal(t, "coordinadora_co", tracking[0].TrackingProvider)
+	assert.Equal(t, []string{"1", "2"}, pages)
+}
+
+// TestWooCommerceAdapter_getTrackingFromNotes_UnpagedStore verifies a store that ignores per_page
+// and returns every note at once is not asked for further pages.
+func TestWooCommerceAdapter_getTrackingFromNotes_UnpagedStore(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		notes := make([]wcOrderNote, notesPerPage+10)
+		json.NewEncoder(w).Encode(notes)
+	}))
+	defer server.Close()
+
+	adapter, err := NewWooCommerceAdapter(config.WooCommerceConfig{URL: server.URL})
+	require.NoError(t, err)
+
+	assert.Nil(t, adapter.getTrackingFromNotes("123"))
+	assert.Equal(t, 1, requests)
+}
+
 // TestExtractTrackingFromNotes_Success verifies successful extraction from valid notes.
 func TestExtractTrackingFromNotes_Success(t *testing.T) {
 	notes := "Datos de rastreo: No de guía: 2259176774 Paquetería: servientrega_co URL de seguimiento: https://www.servientrega.com/..."
 
-	tracking := extractTrackingFromNotes(notes)
+	tracking := extractTrackingFromNotes(notes, "")
 
 	require.Len(t, tracking, 1)
 	assert.Equal(t, "2259176774", tracking[0].TrackingNumber)
@@ -338,7 +722,7 @@ func TestExtractTrackingFromNotes_Success(t *testing.T) {
 func TestExtractTrackingFromNotes_WithoutAccent(t *testing.T) {
 	notes := "No de guia: 1234567890 Paqueteria: coordinadora_co"
 
-	tracking := extractTrackingFromNotes(notes)
+	tracking := extractTrackingFromNotes(notes, "")
 
 	require.Len(t, tracking, 1)
 	assert.Equal(t, "1234567890", tracking[0].TrackingNumber)
@@ -349,7 +733,7 @@ func TestExtractTrackingFromNotes_WithoutAccent(t *testing.T) {
 func TestExtractTrackingFromNotes_DifferentSpacing(t *testing.T) {
 	notes := "No   de   guía:    9876543210    Paquetería:    interrapidisimo_co"
 
-	tracking := extractTrackingFromNotes(notes)
+	tracking := extractTrackingFromNotes(notes, "")
 
 	require.Len(t, tracking, 1)
 	assert.Equal(t, "9876543210", tracking[0].TrackingNumber)
@@ -382,7 +766,7 @@ func TestExtractTrackingFromNotes_CarrierNormalization(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			tracking := extractTrackingFromNotes(tc.notes)
+			tracking := extractTrackingFromNotes(tc.notes, "")
 			require.Len(t, tracking, 1)
 			assert.Equal(t, tc.expectedCarrier, tracking[0].TrackingProvider)
 		})
@@ -393,14 +777,46 @@ func TestExtractTrackingFromNotes_CarrierNormalization(t *testing.T) {
 func TestExtractTrackingFromNotes_NoMatch(t *testing.T) {
 	notes := "This is just a regular customer note without tracking info."
 
-	tracking := extractTrackingFromNotes(notes)
+	tracking := extractTrackingFromNotes(notes, "")
 
 	assert.Nil(t, tracking)
 }
 
+// TestExtractTrackingFromNotes_MultipleShipments verifies every guide number in a note is returned.
+func TestExtractTrackingFromNotes_MultipleShipments(t *testing.T) {
+	notes := "Pedido enviado en dos paquetes.\n" +
+		"No de guía: 2259176774 Paquetería: servientrega\n" +
+		"No de guia: 36000123456 Paquetería: Coordinadora"
+
+	tracking := extractTrackingFromNotes(notes, "")
+
+	require.Len(t, tracking, 2)
+	assert.Equal(t, domain.TrackingInfo{TrackingNumber: "2259176774", TrackingProvider: "servientrega_co"}, tracking[0])
+	assert.Equal(t, domain.TrackingInfo{TrackingNumber: "36000123456", TrackingProvider: "coordinadora_co"}, tracking[1])
+}
+
+// TestExtractTrackingFromNotes_DefaultCarrier verifies guide numbers without a carrier use the default carrier.
+func TestExtractTrackingFromNotes_DefaultCarrier(t *testing.T) {
+	notes := "Tu pedido fue enviado. No de guía: 2259176774"
+
+	tracking := extractTrackingFromNotes(notes, "servientrega_co")
+	require.Len(t, tracking, 1)
+	assert.Equal(t, domain.TrackingInfo{TrackingNumber: "2259176774", TrackingProvider: "servientrega_co"}, tracking[0])
+
+	assert.Nil(t, extractTrackingFromNotes(notes, ""), "no default configured")
+
+	// A named carrier wins over the default
+	tracking = extractTrackingFromNotes("No de guía: 36000123456 Paquetería: coordinadora", "servientrega_co")
+	require.Len(t, tracking, 1)
+	assert.Equal(t, "coordinadora_co", tracking[0].TrackingProvider)
+
+	// No guide number, no tracking
+	assert.Nil(t, extractTrackingFromNotes("Gracias por tu compra", "servientrega_co"))
+}
+
 // TestExtractTrackingFromNotes_EmptyNote verifies empty result for empty notes.
 func TestExtractTrackingFromNotes_EmptyNote(t *testing.T) {
-	tracking := extractTrackingFromNotes("")
+	tracking := extractTrackingFromNotes("", "")
 
 	assert.Nil(t, tracking)
 }
@@ -430,3 +846,51 @@ func TestNormalizeCarrierName(t *testing.T) {
 		})
 	}
 }
+
+// TestWooCommerceAdapter_ListOrders verifies paging parameters are sent and every order is mapped.
+func TestWooCommerceAdapter_ListOrders(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/wp-json/wc/v3/orders" {
+			// Orders without tracking metadata fall back to their notes
+			w.Write([]byte(`[]`))
+			return
+		}
+		query = r.URL.Query()
+		w.Write([]byte(`[
+			{"id": 101, "status": "completed", "billing": {"email": "a@example.com"}, "shipping_lines": [], "meta_data": []},
+			{"id": 102, "status": "cancelled", "billing": {"email": "b@example.com"}, "shipping_lines": [], "meta_data": []}
+		]`))
+	}))
+	defer server.Close()
+
+	adapter, err := NewWooCommerceAdapter(config.WooCommerceConfig{URL: server.URL})
+	require.NoError(t, err)
+	orders, err := adapter.ListOrders(2, 50, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+
+	require.NoError(t, err)
+	require.Len(t, orders, 2)
+	assert.Equal(t, "101", orders[0].ID)
+	assert.Equal(t, domain.OrderStatusShipped, orders[0].Status)
+	assert.Equal(t, domain.OrderStatusCancelled, orders[1].Status)
+	assert.Equal(t, "2", query.Get("page"))
+	assert.Equal(t, "50", query.Get("per_page"))
+	assert.Equal(t, "2024-05-01T12:00:00", query.Get("modified_after"))
+}
+
+// TestWooCommerceAdapter_InsecureSkipVerify verifies stores with self-signed certificates are reachable only when opted in.
+func TestWooCommerceAdapter_InsecureSkipVerify(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	verified, err := NewWooCommerceAdapter(config.WooCommerceConfig{URL: server.URL})
+	require.NoError(t, err)
+	assert.Error(t, verified.HealthCheck())
+
+	insecure, err := NewWooCommerceAdapter(config.WooCommerceConfig{URL: server.URL, InsecureSkipVerify: true})
+	require.NoError(t, err)
+	assert.NoError(t, insecure.HealthCheck())
+}
@@ -0,0 +1,78 @@
+package adapter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"tracker-scrapper/internal/core/clock"
+	"tracker-scrapper/internal/core/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWooCommerceAdapter_OAuth1OverHTTP verifies plain HTTP stores get signed OAuth 1.0a parameters instead of Basic auth.
+func TestWooCommerceAdapter_OAuth1OverHTTP(t *testing.T) {
+	oauthNonce = func() string { return "abc123" }
+	defer func() { oauthNonce = defaultOAuthNonce }()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Authorization"))
+
+		q := r.URL.Query()
+		assert.Equal(t, "ck_test", q.Get("oauth_consumer_key"))
+		assert.Equal(t, "abc123", q.Get("oauth_nonce"))
+		assert.Equal(t, "HMAC-SHA1", q.Get("oauth_signature_method"))
+		assert.Equal(t, "1700000000", q.Get("oauth_timestamp"))
+		assert.Equal(t, "1", q.Get("per_page"), "existing query parameters must be kept")
+
+		expected := oauthSignature("GET", "http://"+r.Host+r.URL.Path, q, "cs_test")
+		assert.Equal(t, expected, q.Get("oauth_signature"))
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	adapter, err := NewWooCommerceAdapter(config.WooCommerceConfig{URL: server.URL, ConsumerKey: "ck_test", ConsumerSecret: "cs_test"})
+	require.NoError(t, err)
+	adapter.SetClock(clock.NewFake(time.Unix(1700000000, 0)))
+
+	require.NoError(t, adapter.HealthCheck())
+}
+
+// TestWooCommerceAdapter_BasicAuthOverHTTPS verifies HTTPS stores keep using Basic auth.
+func TestWooCommerceAdapter_BasicAuthOverHTTPS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "ck_test", username)
+		assert.Equal(t, "cs_test", password)
+		assert.Empty(t, r.URL.Query().Get("oauth_signature"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	adapter, err := NewWooCommerceAdapter(config.WooCommerceConfig{URL: server.URL, ConsumerKey: "ck_test", ConsumerSecret: "cs_test"})
+	require.NoError(t, err)
+	adapter.client = server.Client()
+
+	require.NoError(t, adapter.HealthCheck())
+}
+
+// TestOAuthSignature verifies the signature against an independently computed HMAC-SHA1 value.
+func TestOAuthSignature(t *testing.T) {
+	params := map[string][]string{
+		"oauth_consumer_key":     {"ck_key"},
+		"oauth_nonce":            {"nonce"},
+		"oauth_signature_method": {"HMAC-SHA1"},
+		"oauth_timestamp":        {"1"},
+		"per_page":               {"1"},
+	}
+
+	sig := oauthSignature("get", "http://store.test/wp-json/wc/v3/orders", params, "cs_secret")
+
+	assert.Equal(t, "x8u2mFZriFyYbWmGcFYhqp45qq0=", sig)
+	assert.Equal(t, "a%20b~c%2A", oauthEscape("a b~c*"))
+}
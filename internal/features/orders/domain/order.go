@@ -24,6 +24,8 @@ type TrackingInfo struct {
 	TrackingProvider string `json:"tracking_provider"`
 	// TrackingNumber is the unique tracking identifier provided by the carrier.
 	TrackingNumber string `json:"tracking_number"`
+	// DateShipped is when the shipment was handed to the carrier, zero when unknown.
+	DateShipped time.Time `json:"date_shipped,omitzero"`
 }
 
 // Order represents a customer order in the system.
@@ -52,6 +54,29 @@ type Order struct {
 	CreatedAt time.Time `json:"create_date"`
 	// Items contains the list of products included in the order.
 	Items []OrderItem `json:"items"`
+	// RetrievedAt is when the order was fetched from the store; cached copies keep the original time.
+	RetrievedAt time.Time `json:"retrieved_at,omitzero"`
+}
+
+// IsShipped reports whether the order has been shipped.
+func (o *Order) IsShipped() bool {
+	return o.Status == OrderStatusShipped
+}
+
+// HasTracking reports whether any of the order's tracking entries has a tracking number.
+func (o *Order) HasTracking() bool {
+	return o.PrimaryTracking() != nil
+}
+
+// PrimaryTracking returns the first tracking entry with a tracking number, or nil when there is none.
+// Entries without a number are placeholders left by store plugins and are skipped.
+func (o *Order) PrimaryTracking() *TrackingInfo {
+	for i := range o.Tracking {
+		if o.Tracking[i].TrackingNumber != "" {
+			return &o.Tracking[i]
+		}
+	}
+	return nil
 }
 
 // OrderItem represents an individual item within an order.
@@ -51,3 +51,39 @@ func TestOrderStatus_Values(t *testing.T) {
 	assert.Equal(t, OrderStatus("CANCELLED"), OrderStatusCancelled)
 	assert.Equal(t, OrderStatus("PENDING"), OrderStatusPending)
 }
+
+// TestOrder_IsShipped verifies only the SHIPPED status counts as shipped.
+func TestOrder_IsShipped(t *testing.T) {
+	assert.True(t, (&Order{Status: OrderStatusShipped}).IsShipped())
+	assert.False(t, (&Order{Status: OrderStatusCreated}).IsShipped())
+	assert.False(t, (&Order{}).IsShipped())
+}
+
+// TestOrder_PrimaryTracking verifies the first entry with a tracking number is returned and placeholders are skipped.
+func TestOrder_PrimaryTracking(t *testing.T) {
+	order := &Order{Tracking: []TrackingInfo{
+		{TrackingProvider: "servientrega_co"},
+		{TrackingProvider: "coordinadora_co", TrackingNumber: "111"},
+		{TrackingProvider: "interrapidisimo_co", TrackingNumber: "222"},
+	}}
+
+	primary := order.PrimaryTracking()
+
+	assert.True(t, order.HasTracking())
+	if assert.NotNil(t, primary) {
+		assert.Equal(t, "111", primary.TrackingNumber)
+		assert.Same(t, &order.Tracking[1], primary)
+	}
+}
+
+// TestOrder_PrimaryTracking_Empty verifies orders without tracking numbers have no primary tracking.
+func TestOrder_PrimaryTracking_Empty(t *testing.T) {
+	for _, order := range []*Order{
+		{},
+		{Tracking: []TrackingInfo{}},
+		{Tracking: []TrackingInfo{{TrackingProvider: "servientrega_co"}}},
+	} {
+		assert.Nil(t, order.PrimaryTracking())
+		assert.False(t, order.HasTracking())
+	}
+}
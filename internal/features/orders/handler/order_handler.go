@@ -1,29 +1,46 @@
 package handler
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"tracker-scrapper/internal/core/logger"
+	"tracker-scrapper/internal/core/maintenance"
+	"tracker-scrapper/internal/core/request"
 	"tracker-scrapper/internal/features/orders/service"
 
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
 )
 
+// maxWarmIDs caps the number of orders a single cache warming request may list.
+const maxWarmIDs = 100
+
 // OrderHandler handles HTTP requests related to orders.
 type OrderHandler struct {
 	// service is the OrderService instance.
 	service *service.OrderService
+	// strictJSON rejects request bodies containing unknown fields.
+	strictJSON bool
 }
 
 // NewOrderHandler creates a new instance of OrderHandler.
-func NewOrderHandler(s *service.OrderService) *OrderHandler {
+func NewOrderHandler(s *service.OrderService, strictJSON bool) *OrderHandler {
 	return &OrderHandler{
-		service: s,
+		service:    s,
+		strictJSON: strictJSON,
 	}
 }
 
+// WarmRequest is the body of POST /admin/orders/warm.
+type WarmRequest struct {
+	// IDs lists the orders to fetch and cache.
+	IDs []string `json:"ids"`
+}
+
 // GetOrder handles the request to retrieve an order context-aware error handling.
 // @Summary Get Order by ID
 // @Description Fetch order details using Order ID and Email.
@@ -32,60 +49,221 @@ func NewOrderHandler(s *service.OrderService) *OrderHandler {
 // @Param id path string true "Order ID"
 // @Param email query string true "Customer Email"
 // @Success 200 {object} domain.Order
-// @Failure 400 {object} ErrorResponse
+// @Header 200 {string} X-Cache "HIT when served from cache, STALE when an expired copy is served because the store failed, MISS otherwise"
+// @Header 200 {string} X-Maintenance-Mode "true when served in maintenance mode"
+// @Header 200 {string} ETag "Entity tag of the body; send it back in If-None-Match to get a 304"
+// @Success 304 "Not modified since the ETag in If-None-Match"
+// @Failure 400 {object} request.ValidationErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Failure 504 {object} ErrorResponse
 // @Router /orders/{id} [get]
 func (h *OrderHandler) GetOrder(c *fiber.Ctx) error {
 	orderID := c.Params("id")
 	email := c.Query("email")
 
-	rayID, ok := c.Locals("requestid").(string)
-	if !ok {
-		rayID = "unknown"
+	if errs := validateOrderQuery(orderID, email); len(errs) > 0 {
+		return request.Invalid(c, errs...)
 	}
 
-	if orderID == "" {
-		return c.Status(http.StatusBadRequest).JSON(ErrorResponse{
-			Message: "Order ID is required",
-			RayID:   rayID,
-		})
+	// The lookup may outlive the request, so it must not share memory with the Fiber context
+	id, addr := strings.Clone(orderID), strings.Clone(email)
+	result, err := request.Await(c.UserContext(), func() (*service.OrderResult, error) {
+		return h.service.GetOrder(id, addr)
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		// Answered with 504 by the request timeout middleware
+		return err
+	}
+	if err != nil {
+		return orderError(c, err, orderID)
+	}
+
+	c.Set("X-Cache", cacheStatus(result))
+	if result.Maintenance {
+		c.Set("X-Maintenance-Mode", "true")
+	}
+	return request.JSONWithETag(c, result.Order, result.ETag)
+}
+
+// GetOrderTracking handles the request to track every shipment of an order.
+// @Summary Get the tracking of an order's shipments
+// @Description Looks up every tracking number on the order and merges their events into one timeline, oldest first. Shipments that could not be looked up carry an error instead of a history.
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param email query string true "Customer Email"
+// @Success 200 {object} service.OrderTracking
+// @Failure 400 {object} request.ValidationErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Failure 504 {object} ErrorResponse
+// @Router /orders/{id}/tracking [get]
+func (h *OrderHandler) GetOrderTracking(c *fiber.Ctx) error {
+	orderID := c.Params("id")
+	email := c.Query("email")
+
+	if errs := validateOrderQuery(orderID, email); len(errs) > 0 {
+		return request.Invalid(c, errs...)
+	}
+
+	// The lookup may outlive the request, so it must not share memory with the Fiber context
+	id, addr := strings.Clone(orderID), strings.Clone(email)
+	tracking, err := request.Await(c.UserContext(), func() (*service.OrderTracking, error) {
+		return h.service.GetOrderTracking(id, addr)
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		// Answered with 504 by the request timeout middleware
+		return err
+	}
+	if err != nil {
+		return orderError(c, err, orderID)
+	}
+
+	for _, shipment := range tracking.Shipments {
+		if shipment.Error != "" {
+			logger.With(request.RayID(c)).Warn("Failed to track order shipment",
+				zap.String("order_id", orderID),
+				zap.String("tracking_number", shipment.TrackingNumber),
+				zap.String("carrier", shipment.Carrier),
+				zap.String("error", shipment.Error),
+			)
+		}
 	}
 
+	return c.Status(http.StatusOK).JSON(tracking)
+}
+
+// validateOrderQuery returns an error for every invalid parameter of an order lookup.
+func validateOrderQuery(orderID, email string) []request.ValidationError {
+	var errs []request.ValidationError
+	switch {
+	case orderID == "":
+		errs = append(errs, request.ValidationError{Field: "id", Message: "required"})
+	case !isNumeric(orderID):
+		errs = append(errs, request.ValidationError{Field: "id", Message: "must be numeric"})
+	}
 	if email == "" {
-		return c.Status(http.StatusBadRequest).JSON(ErrorResponse{
-			Message: "Email is required",
-			RayID:   rayID,
-		})
+		errs = append(errs, request.ValidationError{Field: "email", Message: "required"})
 	}
+	return errs
+}
+
+// orderError logs a failed order lookup and answers it with the status matching err.
+func orderError(c *fiber.Ctx, err error, orderID string) error {
+	rayID := request.RayID(c)
+	logger.With(rayID).Error("Failed to fetch order",
+		zap.String("order_id", orderID),
+		zap.Error(err),
+	)
 
-	order, err := h.service.GetOrder(orderID, email)
+	status := http.StatusInternalServerError
+	msg := "Internal Server Error"
+	inMaintenance := false
+
+	if errors.Is(err, maintenance.ErrCacheMiss) {
+		status = http.StatusServiceUnavailable
+		msg = "Service under maintenance: order not available in cache"
+		inMaintenance = true
+	} else if errors.Is(err, service.ErrOrderNotFound) {
+		status = http.StatusNotFound
+		msg = "Order not found"
+	} else if errors.Is(err, service.ErrEmailMismatch) {
+		status = http.StatusUnauthorized
+		msg = "Email mismatch"
+	} else {
+		msg = err.Error()
+	}
+
+	return c.Status(status).JSON(ErrorResponse{
+		Message:     msg,
+		RayID:       rayID,
+		Maintenance: inMaintenance,
+	})
+}
+
+// GetRawOrder handles the request to inspect the unmapped WooCommerce order.
+// @Summary Get raw WooCommerce order (debug)
+// @Description Returns the unmapped WooCommerce order JSON, including meta_data and shipping_lines. Only registered when DEBUG_ENDPOINTS is enabled.
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} request.ValidationErrorResponse
+// @Failure 502 {object} ErrorResponse
+// @Router /orders/{id}/debug [get]
+func (h *OrderHandler) GetRawOrder(c *fiber.Ctx) error {
+	orderID := c.Params("id")
+	rayID := request.RayID(c)
+
+	if !isNumeric(orderID) {
+		return request.Invalid(c, request.ValidationError{Field: "id", Message: "must be numeric"})
+	}
+
+	raw, err := h.service.GetRawOrder(orderID)
 	if err != nil {
-		logger.Get().Error("Failed to fetch order",
+		logger.With(rayID).Error("Failed to fetch raw order",
 			zap.String("order_id", orderID),
-			zap.String("ray_id", rayID),
 			zap.Error(err),
 		)
+		return c.Status(http.StatusBadGateway).JSON(ErrorResponse{
+			Message: err.Error(),
+			RayID:   rayID,
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Status(http.StatusOK).Send(raw)
+}
+
+// WarmCache handles the request to pre-populate the order cache.
+// @Summary Warm the order cache
+// @Description Fetches each listed order, bypassing the email check, and caches it under the order's email. Each ID reports whether it was cached.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body WarmRequest true "Orders to cache"
+// @Success 200 {array} service.WarmResult
+// @Failure 400 {object} request.ValidationErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /admin/orders/warm [post]
+func (h *OrderHandler) WarmCache(c *fiber.Ctx) error {
+	var req WarmRequest
+	if err := request.ParseJSON(c, &req, h.strictJSON); err != nil {
+		return request.Invalid(c, request.BodyError(err))
+	}
 
-		status := http.StatusInternalServerError
-		msg := "Internal Server Error"
-
-		if errors.Is(err, service.ErrOrderNotFound) {
-			status = http.StatusNotFound
-			msg = "Order not found"
-		} else if errors.Is(err, service.ErrEmailMismatch) {
-			status = http.StatusUnauthorized
-			msg = "Email mismatch"
-		} else {
-			msg = err.Error()
+	if len(req.IDs) == 0 || len(req.IDs) > maxWarmIDs {
+		return request.Invalid(c, request.ValidationError{
+			Field:   "ids",
+			Message: fmt.Sprintf("must contain between 1 and %d entries", maxWarmIDs),
+		})
+	}
+
+	var errs []request.ValidationError
+	for i, id := range req.IDs {
+		if !isNumeric(id) {
+			errs = append(errs, request.ValidationError{Field: fmt.Sprintf("ids[%d]", i), Message: "must be numeric"})
 		}
+	}
+	if len(errs) > 0 {
+		return request.Invalid(c, errs...)
+	}
 
-		return c.Status(status).JSON(ErrorResponse{
-			Message: msg,
-			RayID:   rayID,
+	results, err := h.service.WarmCache(c.UserContext(), req.IDs)
+	if errors.Is(err, service.ErrWarmInMaintenance) {
+		return c.Status(http.StatusServiceUnavailable).JSON(ErrorResponse{
+			Message:     err.Error(),
+			RayID:       request.RayID(c),
+			Maintenance: true,
 		})
 	}
+	if err != nil {
+		return err
+	}
 
-	return c.Status(http.StatusOK).JSON(order)
+	return c.JSON(results)
 }
 
 // ErrorResponse represents the structure of an error response.
@@ -94,4 +272,30 @@ type ErrorResponse struct {
 	Message string `json:"message"`
 	// RayID is the unique request identifier for debugging.
 	RayID string `json:"ray_id"`
+	// Maintenance is true when the error was caused by maintenance mode.
+	Maintenance bool `json:"maintenance,omitempty"`
+}
+
+// cacheStatus returns the X-Cache header value for a response.
+func cacheStatus(result *service.OrderResult) string {
+	if result.Stale {
+		return "STALE"
+	}
+	if result.FromCache {
+		return "HIT"
+	}
+	return "MISS"
+}
+
+// isNumeric reports whether s is a non-empty string of ASCII digits.
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
 }
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"tracker-scrapper/internal/core/cache"
+	"tracker-scrapper/internal/features/orders/domain"
+	"tracker-scrapper/internal/features/orders/service"
+	trackingdomain "tracker-scrapper/internal/features/tracking/domain"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// missCache is a cache that never holds anything.
+type missCache struct{}
+
+func (m *missCache) Get(ctx context.Context, key string) ([]byte, error) {
+	return nil, cache.ErrNotFound
+}
+func (m *missCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return nil
+}
+func (m *missCache) GetMulti(ctx context.Context, keys []string) (map[string][]byte, error) {
+	return map[string][]byte{}, nil
+}
+func (m *missCache) SetMulti(ctx context.Context, entries map[string][]byte, ttl time.Duration) error {
+	return nil
+}
+func (m *missCache) Delete(ctx context.Context, key string) error { return nil }
+func (m *missCache) Ping(ctx context.Context) error               { return nil }
+func (m *missCache) Close() error                                 { return nil }
+
+// slowOrderProvider returns order after delay.
+type slowOrderProvider struct {
+	order *domain.Order
+	delay time.Duration
+}
+
+// GetOrder implements OrderProvider.
+func (m *slowOrderProvider) GetOrder(orderID string) (*domain.Order, error) {
+	time.Sleep(m.delay)
+	return m.order, nil
+}
+
+// stubTrackingResolver resolves every shipment to an empty history.
+type stubTrackingResolver struct{}
+
+// GetTrackingHistory implements TrackingResolver.
+func (m *stubTrackingResolver) GetTrackingHistory(trackingNumber, carrier string) (*trackingdomain.TrackingHistory, error) {
+	return &trackingdomain.TrackingHistory{}, nil
+}
+
+// withRequestTimeout mirrors the server's request timeout middleware: handlers get a user context
+// expiring after timeout, and giving up on it is answered with 504.
+func withRequestTimeout(timeout time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithTimeout(c.UserContext(), timeout)
+		defer cancel()
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+		if errors.Is(err, context.DeadlineExceeded) {
+			return c.Status(fiber.StatusGatewayTimeout).JSON(ErrorResponse{Message: "request timed out"})
+		}
+		return err
+	}
+}
+
+// setupApp registers the order routes behind a request timeout, served by a provider taking delay.
+func setupApp(timeout, delay time.Duration) *fiber.App {
+	provider := &slowOrderProvider{
+		order: &domain.Order{ID: "123", Email: "ana@example.com", Status: domain.OrderStatusCreated},
+		delay: delay,
+	}
+	svc := service.NewOrderService(provider, &missCache{}, time.Minute, nil, nil, &stubTrackingResolver{})
+	h := NewOrderHandler(svc, false)
+
+	app := fiber.New()
+	app.Use(withRequestTimeout(timeout))
+	app.Get("/orders/:id", h.GetOrder)
+	app.Get("/orders/:id/tracking", h.GetOrderTracking)
+	return app
+}
+
+// TestOrderHandler_GetOrder verifies an order answered within the timeout is returned.
+func TestOrderHandler_GetOrder(t *testing.T) {
+	app := setupApp(time.Second, 0)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/orders/123?email=ana@example.com", nil), -1)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var order domain.Order
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&order))
+	assert.Equal(t, "123", order.ID)
+}
+
+// TestOrderHandler_GetOrder_RequestTimeout verifies a store slower than the request timeout is answered with 504.
+func TestOrderHandler_GetOrder_RequestTimeout(t *testing.T) {
+	app := setupApp(20*time.Millisecond, 200*time.Millisecond)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/orders/123?email=ana@example.com", nil), -1)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusGatewayTimeout, resp.StatusCode)
+}
+
+// TestOrderHandler_GetOrderTracking_RequestTimeout verifies a store slower than the request timeout is answered with 504.
+func TestOrderHandler_GetOrderTracking_RequestTimeout(t *testing.T) {
+	app := setupApp(20*time.Millisecond, 200*time.Millisecond)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/orders/123/tracking?email=ana@example.com", nil), -1)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusGatewayTimeout, resp.StatusCode)
+}
@@ -0,0 +1,11 @@
+package ports
+
+import "tracker-scrapper/internal/features/orders/domain"
+
+// OrderNotifier defines the interface for notifying downstream systems about order changes.
+// This is a Secondary Port (Driven Port).
+type OrderNotifier interface {
+	// NotifyShipped reports that an order has transitioned to SHIPPED.
+	// Implementations must not block the caller.
+	NotifyShipped(order *domain.Order)
+}
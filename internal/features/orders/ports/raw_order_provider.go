@@ -0,0 +1,11 @@
+package ports
+
+import "encoding/json"
+
+// RawOrderProvider defines the interface for retrieving the unmapped order as returned by the
+// external system, used to debug tracking extraction.
+// This is a Secondary Port (Driven Port).
+type RawOrderProvider interface {
+	// GetRawOrder retrieves the raw order JSON by its unique identifier.
+	GetRawOrder(orderID string) (json.RawMessage, error)
+}
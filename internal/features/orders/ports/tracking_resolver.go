@@ -0,0 +1,11 @@
+package ports
+
+import trackingdomain "tracker-scrapper/internal/features/tracking/domain"
+
+// TrackingResolver defines the interface for looking up the tracking history of an order's shipments.
+// This is a Secondary Port (Driven Port).
+type TrackingResolver interface {
+	// GetTrackingHistory retrieves the history of trackingNumber as reported by carrier, the
+	// carrier name as stored on the order.
+	GetTrackingHistory(trackingNumber, carrier string) (*trackingdomain.TrackingHistory, error)
+}
@@ -6,11 +6,20 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"tracker-scrapper/internal/core/cache"
+	"tracker-scrapper/internal/core/clock"
+	"tracker-scrapper/internal/core/etag"
+	"tracker-scrapper/internal/core/logger"
+	"tracker-scrapper/internal/core/maintenance"
+	"tracker-scrapper/internal/core/metrics"
 	"tracker-scrapper/internal/features/orders/domain"
 	"tracker-scrapper/internal/features/orders/ports"
+
+	"go.uber.org/zap"
 )
 
 // ErrOrderNotFound is returned when the order does not exist.
@@ -19,44 +28,151 @@ var ErrOrderNotFound = errors.New("order not found")
 // ErrEmailMismatch is returned when the provided email does not match the order's email.
 var ErrEmailMismatch = errors.New("email does not match order record")
 
+// ErrRawOrderUnsupported is returned when the order provider cannot return raw orders.
+var ErrRawOrderUnsupported = errors.New("order provider does not support raw orders")
+
+// ErrWarmInMaintenance is returned by WarmCache while maintenance mode keeps the provider off limits.
+var ErrWarmInMaintenance = errors.New("cache warming is unavailable in maintenance mode")
+
+// orderStateTTL bounds how long the last seen status of an order is remembered for shipped detection.
+const orderStateTTL = 30 * 24 * time.Hour
+
+// warmWorkers bounds how many orders WarmCache fetches at once.
+const warmWorkers = 4
+
+// WarmResult holds the outcome of warming the cache for one order. Error is empty when Cached is true.
+type WarmResult struct {
+	// ID is the order ID.
+	ID string `json:"id"`
+	// Cached is true when the order was fetched and written to the cache.
+	Cached bool `json:"cached"`
+	// Error describes why the order could not be cached.
+	Error string `json:"error,omitempty"`
+}
+
+// OrderResult wraps an order with metadata about how it was obtained.
+type OrderResult struct {
+	// Order is the validated order.
+	Order *domain.Order
+	// FromCache is true when Order was served from the cache instead of a live fetch.
+	FromCache bool
+	// Maintenance is true when the result was produced while maintenance mode was active.
+	Maintenance bool
+	// ETag identifies Order's content, leaving out RetrievedAt. Empty when unknown.
+	ETag string
+	// Stale is true when Order is a cached copy past the order TTL, served because the provider
+	// could not be reached or maintenance mode is active. FromCache is also true.
+	Stale bool
+}
+
 // OrderService handles the business logic for retrieving and validating orders.
 type OrderService struct {
 	// provider is the interface for fetching order data from external sources.
 	provider ports.OrderProvider
 	// cache is the caching layer for storing retrieved orders.
 	cache cache.Cache
-	// cacheTTL is the duration for which orders are cached.
-	cacheTTL time.Duration
+	// cacheTTL is the duration (in nanoseconds) for which orders are cached. Updated on config reload.
+	cacheTTL atomic.Int64
+	// staleTTL is how long (in nanoseconds) orders are kept past cacheTTL to be served when the
+	// provider fails. Zero disables stale serving. Updated on config reload.
+	staleTTL atomic.Int64
+	// maintenance restricts lookups to the cache while enabled.
+	maintenance *maintenance.Mode
+	// notifier is informed when an order transitions to SHIPPED. May be nil.
+	notifier ports.OrderNotifier
+	// tracking looks up the shipments of an order. May be nil.
+	tracking ports.TrackingResolver
+	// clock stamps RetrievedAt and decides when cached orders expire.
+	clock clock.Clock
 }
 
 // NewOrderService creates a new instance of OrderService with cache support.
-func NewOrderService(provider ports.OrderProvider, cache cache.Cache, cacheTTL time.Duration) *OrderService {
-	return &OrderService{
-		provider: provider,
-		cache:    cache,
-		cacheTTL: cacheTTL,
+// A nil maintenance mode is treated as disabled, a nil notifier disables shipped notifications
+// and a nil tracking resolver disables GetOrderTracking.
+func NewOrderService(provider ports.OrderProvider, cache cache.Cache, cacheTTL time.Duration, maintenance *maintenance.Mode, notifier ports.OrderNotifier, tracking ports.TrackingResolver) *OrderService {
+	s := &OrderService{
+		provider:    provider,
+		cache:       cache,
+		maintenance: maintenance,
+		notifier:    notifier,
+		tracking:    tracking,
+		clock:       clock.Real,
 	}
+	s.SetCacheTTL(cacheTTL)
+	return s
+}
+
+// SetCacheTTL changes the TTL used for orders cached from now on.
+func (s *OrderService) SetCacheTTL(ttl time.Duration) {
+	s.cacheTTL.Store(int64(ttl))
+}
+
+// SetClock replaces the clock used for retrieval times and expiry, for tests.
+func (s *OrderService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// SetStaleTTL changes how long orders cached from now on are kept past the cache TTL, so an
+// expired copy can be served when the provider fails. Zero disables stale serving.
+func (s *OrderService) SetStaleTTL(ttl time.Duration) {
+	s.staleTTL.Store(int64(ttl))
 }
 
 // GetOrder retrieves an order by ID and validates that the provided email matches the order's email.
 // Uses cache with key format: order_{orderID}_{email}
-func (s *OrderService) GetOrder(orderID, email string) (*domain.Order, error) {
+// In maintenance mode only the cache is consulted and maintenance.ErrCacheMiss is returned on a miss.
+// With a stale TTL set, an expired cached copy is refetched but served, flagged Stale, when the
+// provider fails or maintenance mode is active.
+func (s *OrderService) GetOrder(orderID, email string) (*OrderResult, error) {
 	ctx := context.Background()
-	cacheKey := fmt.Sprintf("order_%s_%s", orderID, email)
+	cacheKey := orderCacheKey(orderID, email)
+	inMaintenance := s.maintenance.Enabled()
+
+	// stale holds an expired cached copy to fall back on when the order can't be fetched
+	var stale *OrderResult
 
-	// Try to get from cache first
+	// Try to get from cache first; a miss wraps cache.ErrNotFound, anything else is a cache failure
 	cachedData, err := s.cache.Get(ctx, cacheKey)
+	if err != nil && !errors.Is(err, cache.ErrNotFound) {
+		logger.Get().Warn("Order cache read failed", zap.String("order_id", orderID), zap.Error(err))
+	}
 	if err == nil {
-		var order domain.Order
-		if err := json.Unmarshal(cachedData, &order); err == nil {
-			return &order, nil
+		var entry cachedOrder
+		if err := json.Unmarshal(cachedData, &entry); err == nil {
+			order := &entry.Order
+			// Entries cached before the ETag was recorded still get one
+			if entry.ETag == "" {
+				entry.ETag = orderETag(order)
+			}
+			result := &OrderResult{Order: order, FromCache: true, Maintenance: inMaintenance, ETag: entry.ETag}
+			if !s.expired(order) {
+				metrics.CacheHit("orders")
+				logger.Get().Debug("Order cache hit", zap.String("order_id", orderID))
+				return result, nil
+			}
+			result.Stale = true
+			stale = result
 		}
 		// If unmarshal fails, continue to fetch from provider
 	}
+	metrics.CacheMiss("orders")
+	logger.Get().Debug("Order cache miss", zap.String("order_id", orderID))
+
+	if inMaintenance {
+		if stale != nil {
+			return stale, nil
+		}
+		return nil, maintenance.ErrCacheMiss
+	}
 
 	// Cache miss or error - fetch from provider
 	order, err := s.provider.GetOrder(orderID)
 	if err != nil {
+		if stale != nil {
+			logger.Get().Warn("Order provider failed, serving expired cached order",
+				zap.String("order_id", orderID), zap.Time("retrieved_at", stale.Order.RetrievedAt), zap.Error(err))
+			return stale, nil
+		}
 		return nil, err
 	}
 
@@ -69,12 +185,141 @@ func (s *OrderService) GetOrder(orderID, email string) (*domain.Order, error) {
 		return nil, ErrEmailMismatch
 	}
 
-	// Cache the validated order
-	orderData, err := json.Marshal(order)
-	if err == nil {
-		// Fire and forget - don't fail if cache write fails
-		_ = s.cache.Set(ctx, cacheKey, orderData, s.cacheTTL)
+	return &OrderResult{Order: order, ETag: s.store(ctx, cacheKey, order)}, nil
+}
+
+// WarmCache fetches the orders with the given IDs and caches each one under its own email, so
+// customer lookups are served from the cache. The email check is skipped, so it is meant for
+// admins preparing for traffic peaks. At most warmWorkers orders are fetched at once; orders not
+// started before ctx is done fail with its error. Results are in the order of ids.
+func (s *OrderService) WarmCache(ctx context.Context, ids []string) ([]WarmResult, error) {
+	if s.maintenance.Enabled() {
+		return nil, ErrWarmInMaintenance
+	}
+
+	results := make([]WarmResult, len(ids))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(warmWorkers, len(ids)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = s.warmOrder(ctx, ids[i])
+			}
+		}()
+	}
+
+	for i := range ids {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results, nil
+}
+
+// warmOrder fetches and caches a single order, honoring cancellation before starting the fetch.
+func (s *OrderService) warmOrder(ctx context.Context, orderID string) WarmResult {
+	res := WarmResult{ID: orderID}
+
+	if err := ctx.Err(); err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	order, err := s.provider.GetOrder(orderID)
+	if err == nil && order == nil {
+		err = ErrOrderNotFound
+	}
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	s.store(ctx, orderCacheKey(orderID, order.Email), order)
+	res.Cached = true
+	return res
+}
+
+// store records a live order's status for shipped detection, stamps its retrieval time and
+// caches it under cacheKey. It returns the order's ETag, or "" when encoding fails.
+func (s *OrderService) store(ctx context.Context, cacheKey string, order *domain.Order) string {
+	s.detectShipped(ctx, order)
+	order.RetrievedAt = s.clock.Now().UTC()
+
+	tag := orderETag(order)
+	orderData, err := json.Marshal(cachedOrder{Order: *order, ETag: tag})
+	if err != nil {
+		return ""
+	}
+	// Fire and forget - don't fail if cache write fails
+	// Kept for the stale TTL past the order TTL; expired decides when the copy is no longer fresh
+	_ = s.cache.Set(ctx, cacheKey, orderData, time.Duration(s.cacheTTL.Load()+s.staleTTL.Load()))
+	return tag
+}
+
+// cachedOrder is the cache entry of an order, holding the ETag computed when it was cached.
+type cachedOrder struct {
+	domain.Order
+	// ETag is orderETag of the order. Empty in entries cached before it was recorded.
+	ETag string `json:"etag,omitempty"`
+}
+
+// orderETag returns the weak ETag of order's JSON encoding without RetrievedAt, which changes on every
+// fetch, so a refetched order that didn't change keeps its ETag. It returns "" when order can't be encoded.
+func orderETag(order *domain.Order) string {
+	unstamped := *order
+	unstamped.RetrievedAt = time.Time{}
+	data, err := json.Marshal(unstamped)
+	if err != nil {
+		return ""
+	}
+	return etag.Weak(data)
+}
+
+// expired reports whether a cached order is past the order TTL. Only orders kept for stale
+// serving outlive it, so without a stale TTL, or a retrieval time, cached orders are always fresh.
+func (s *OrderService) expired(order *domain.Order) bool {
+	if s.staleTTL.Load() == 0 || order.RetrievedAt.IsZero() {
+		return false
+	}
+	return s.clock.Now().Sub(order.RetrievedAt) >= time.Duration(s.cacheTTL.Load())
+}
+
+// orderCacheKey returns the cache key of an order looked up with email: order_{orderID}_{email}.
+// The email is lowercased so lookups and warmed entries share a key regardless of case.
+// It embeds the customer's email, so logs name the order ID instead.
+func orderCacheKey(orderID, email string) string {
+	return fmt.Sprintf("order_%s_%s", orderID, strings.ToLower(email))
+}
+
+// GetRawOrder returns the unmapped order from the provider for debugging.
+// It always fetches live, bypassing the cache, maintenance mode and email validation.
+func (s *OrderService) GetRawOrder(orderID string) (json.RawMessage, error) {
+	raw, ok := s.provider.(ports.RawOrderProvider)
+	if !ok {
+		return nil, ErrRawOrderUnsupported
+	}
+	return raw.GetRawOrder(orderID)
+}
+
+// detectShipped records the order's status and notifies when it changed from a non-shipped state to SHIPPED.
+// Uses cache key format: order_state_{orderID}
+func (s *OrderService) detectShipped(ctx context.Context, order *domain.Order) {
+	stateKey := fmt.Sprintf("order_state_%s", order.ID)
+
+	previous, err := s.cache.Get(ctx, stateKey)
+	if err == nil && s.notifier != nil &&
+		domain.OrderStatus(previous) != domain.OrderStatusShipped &&
+		order.IsShipped() {
+		logger.Get().Info("Order transitioned to shipped",
+			zap.String("order_id", order.ID),
+			zap.String("previous_status", string(previous)),
+		)
+		s.notifier.NotifyShipped(order)
 	}
 
-	return order, nil
+	_ = s.cache.Set(ctx, stateKey, []byte(order.Status), orderStateTTL)
 }
@@ -0,0 +1,385 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"tracker-scrapper/internal/core/cache"
+	"tracker-scrapper/internal/core/clock"
+	"tracker-scrapper/internal/core/maintenance"
+	"tracker-scrapper/internal/features/orders/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockCache is an in-memory Cache for testing.
+type mockCache struct {
+	data map[string][]byte
+	ttls map[string]time.Duration
+	// getErr, when set, is returned by Get to simulate an unreachable cache.
+	getErr error
+}
+
+// Get implements Cache.
+func (m *mockCache) Get(ctx context.Context, key string) ([]byte, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	if v, ok := m.data[key]; ok {
+		return v, nil
+	}
+	return nil, fmt.Errorf("%w: %s", cache.ErrNotFound, key)
+}
+
+// Set implements Cache.
+func (m *mockCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.data[key] = value
+	if m.ttls != nil {
+		m.ttls[key] = ttl
+	}
+	return nil
+}
+
+// GetMulti implements Cache.
+func (m *mockCache) GetMulti(ctx context.Context, keys []string) (map[string][]byte, error) {
+	result := make(map[string][]byte, len(keys))
+	for _, key := range keys {
+		if v, ok := m.data[key]; ok {
+			result[key] = v
+		}
+	}
+	return result, nil
+}
+
+// SetMulti implements Cache.
+func (m *mockCache) SetMulti(ctx context.Context, entries map[string][]byte, ttl time.Duration) error {
+	for key, value := range entries {
+		if err := m.Set(ctx, key, value, ttl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete implements Cache.
+func (m *mockCache) Delete(ctx context.Context, key string) error {
+	delete(m.data, key)
+	return nil
+}
+
+// Ping implements Cache.
+func (m *mockCache) Ping(ctx context.Context) error { return nil }
+
+// Close implements Cache.
+func (m *mockCache) Close() error { return nil }
+
+// mockOrderProvider returns a fixed order.
+type mockOrderProvider struct {
+	order *domain.Order
+	// err, when set, is returned by GetOrder to simulate an unreachable store.
+	err error
+}
+
+// GetOrder implements OrderProvider.
+func (m *mockOrderProvider) GetOrder(orderID string) (*domain.Order, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.order, nil
+}
+
+// mockRawOrderProvider also returns the raw order.
+type mockRawOrderProvider struct {
+	mockOrderProvider
+	raw json.RawMessage
+}
+
+// GetRawOrder implements RawOrderProvider.
+func (m *mockRawOrderProvider) GetRawOrder(orderID string) (json.RawMessage, error) {
+	return m.raw, nil
+}
+
+// mockNotifier records shipped notifications.
+type mockNotifier struct {
+	notified []string
+}
+
+// NotifyShipped implements OrderNotifier.
+func (m *mockNotifier) NotifyShipped(order *domain.Order) {
+	m.notified = append(m.notified, order.ID)
+}
+
+// TestOrderService_GetOrder_NotifiesOnShippedTransition verifies the notifier fires once on CREATED -> SHIPPED.
+func TestOrderService_GetOrder_NotifiesOnShippedTransition(t *testing.T) {
+	c := &mockCache{data: map[string][]byte{}}
+	provider := &mockOrderProvider{order: &domain.Order{ID: "1", Email: "a@b.co", Status: domain.OrderStatusCreated}}
+	notifier := &mockNotifier{}
+	svc := NewOrderService(provider, c, time.Hour, nil, notifier, nil)
+
+	_, err := svc.GetOrder("1", "a@b.co")
+	require.NoError(t, err)
+	assert.Empty(t, notifier.notified)
+
+	// Expire the order cache so the next call refetches
+	delete(c.data, "order_1_a@b.co")
+	provider.order = &domain.Order{ID: "1", Email: "a@b.co", Status: domain.OrderStatusShipped}
+
+	_, err = svc.GetOrder("1", "a@b.co")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1"}, notifier.notified)
+
+	delete(c.data, "order_1_a@b.co")
+	_, err = svc.GetOrder("1", "a@b.co")
+	require.NoError(t, err)
+	assert.Len(t, notifier.notified, 1)
+}
+
+// TestOrderService_GetOrder_NoNotifyWithoutPreviousState verifies orders first seen as shipped are not notified.
+func TestOrderService_GetOrder_NoNotifyWithoutPreviousState(t *testing.T) {
+	c := &mockCache{data: map[string][]byte{}}
+	provider := &mockOrderProvider{order: &domain.Order{ID: "2", Email: "a@b.co", Status: domain.OrderStatusShipped}}
+	notifier := &mockNotifier{}
+	svc := NewOrderService(provider, c, time.Hour, nil, notifier, nil)
+
+	_, err := svc.GetOrder("2", "a@b.co")
+	require.NoError(t, err)
+	assert.Empty(t, notifier.notified)
+	assert.Equal(t, []byte(domain.OrderStatusShipped), c.data["order_state_2"])
+}
+
+// TestOrderService_SetCacheTTL verifies a reloaded TTL applies to subsequent cache writes.
+func TestOrderService_SetCacheTTL(t *testing.T) {
+	c := &mockCache{data: map[string][]byte{}, ttls: map[string]time.Duration{}}
+	provider := &mockOrderProvider{order: &domain.Order{ID: "3", Email: "a@b.co", Status: domain.OrderStatusCreated}}
+	svc := NewOrderService(provider, c, time.Hour, nil, nil, nil)
+
+	svc.SetCacheTTL(time.Minute)
+	_, err := svc.GetOrder("3", "a@b.co")
+
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, c.ttls["order_3_a@b.co"])
+}
+
+// TestOrderService_GetOrder_RetrievedAt verifies cache hits report when the order was originally fetched.
+func TestOrderService_GetOrder_RetrievedAt(t *testing.T) {
+	fetchedAt := time.Date(2025, 3, 4, 10, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(fetchedAt)
+
+	c := &mockCache{data: map[string][]byte{}}
+	provider := &mockOrderProvider{order: &domain.Order{ID: "4", Email: "a@b.co", Status: domain.OrderStatusCreated}}
+	svc := NewOrderService(provider, c, time.Hour, nil, nil, nil)
+	svc.SetClock(clk)
+
+	live, err := svc.GetOrder("4", "a@b.co")
+	require.NoError(t, err)
+	assert.Equal(t, fetchedAt, live.Order.RetrievedAt)
+
+	clk.Advance(time.Hour)
+	cached, err := svc.GetOrder("4", "a@b.co")
+	require.NoError(t, err)
+	assert.True(t, cached.FromCache)
+	assert.Equal(t, fetchedAt, cached.Order.RetrievedAt)
+}
+
+// TestOrderService_GetOrder_ETagIgnoresRetrievedAt verifies a later fetch of an unchanged order keeps its ETag.
+func TestOrderService_GetOrder_ETagIgnoresRetrievedAt(t *testing.T) {
+	fetchedAt := time.Date(2025, 3, 4, 10, 0, 0, 0, time.UTC)
+	provider := &mockOrderProvider{order: &domain.Order{ID: "4", Email: "a@b.co", Status: domain.OrderStatusCreated}}
+
+	lookup := func(at time.Time) *OrderResult {
+		svc := NewOrderService(provider, &mockCache{data: map[string][]byte{}}, time.Hour, nil, nil, nil)
+		svc.SetClock(clock.NewFake(at))
+		result, err := svc.GetOrder("4", "a@b.co")
+		require.NoError(t, err)
+		return result
+	}
+	firstETag := lookup(fetchedAt).ETag
+	later := lookup(fetchedAt.Add(2 * time.Hour))
+
+	assert.False(t, later.FromCache)
+	assert.Equal(t, fetchedAt.Add(2*time.Hour), later.Order.RetrievedAt)
+	assert.NotEmpty(t, firstETag)
+	assert.Equal(t, firstETag, later.ETag)
+}
+
+// TestOrderService_GetOrder_CachedETag verifies the weak ETag is cached with the order and served on hits.
+func TestOrderService_GetOrder_CachedETag(t *testing.T) {
+	c := &mockCache{data: map[string][]byte{}}
+	provider := &mockOrderProvider{order: &domain.Order{ID: "4", Email: "a@b.co", Status: domain.OrderStatusCreated}}
+	svc := NewOrderService(provider, c, time.Hour, nil, nil, nil)
+
+	live, err := svc.GetOrder("4", "a@b.co")
+	require.NoError(t, err)
+	assert.Regexp(t, `^W/"[0-9a-f]{32}"$`, live.ETag)
+
+	var entry struct {
+		ETag string `json:"etag"`
+	}
+	require.NoError(t, json.Unmarshal(c.data["order_4_a@b.co"], &entry))
+	assert.Equal(t, live.ETag, entry.ETag)
+
+	c.data["order_4_a@b.co"] = []byte(`{"order_id":"4","email":"a@b.co","status":"CREATED","etag":"W/\"stored\""}`)
+	cached, err := svc.GetOrder("4", "a@b.co")
+	require.NoError(t, err)
+	assert.True(t, cached.FromCache)
+	assert.Equal(t, `W/"stored"`, cached.ETag)
+}
+
+// TestOrderService_GetOrder_Stale verifies expired orders are kept for the stale TTL, refetched
+// while the provider works and served flagged stale when it fails.
+func TestOrderService_GetOrder_Stale(t *testing.T) {
+	fetchedAt := time.Date(2025, 3, 4, 10, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(fetchedAt)
+
+	c := &mockCache{data: map[string][]byte{}, ttls: map[string]time.Duration{}}
+	provider := &mockOrderProvider{order: &domain.Order{ID: "5", Email: "a@b.co", Status: domain.OrderStatusCreated}}
+	svc := NewOrderService(provider, c, time.Hour, nil, nil, nil)
+	svc.SetClock(clk)
+	svc.SetStaleTTL(24 * time.Hour)
+
+	_, err := svc.GetOrder("5", "a@b.co")
+	require.NoError(t, err)
+	assert.Equal(t, 25*time.Hour, c.ttls["order_5_a@b.co"])
+
+	// Still fresh: served from the cache
+	clk.Set(fetchedAt.Add(30 * time.Minute))
+	fresh, err := svc.GetOrder("5", "a@b.co")
+	require.NoError(t, err)
+	assert.True(t, fresh.FromCache)
+	assert.False(t, fresh.Stale)
+
+	// Expired and the provider fails: the expired copy is served
+	clk.Set(fetchedAt.Add(2 * time.Hour))
+	provider.err = errors.New("connection refused")
+	stale, err := svc.GetOrder("5", "a@b.co")
+	require.NoError(t, err)
+	assert.True(t, stale.FromCache)
+	assert.True(t, stale.Stale)
+	assert.Equal(t, fetchedAt, stale.Order.RetrievedAt)
+
+	// Expired and the provider works: refetched
+	provider.err = nil
+	live, err := svc.GetOrder("5", "a@b.co")
+	require.NoError(t, err)
+	assert.False(t, live.FromCache)
+	assert.False(t, live.Stale)
+	assert.Equal(t, fetchedAt.Add(2*time.Hour), live.Order.RetrievedAt)
+}
+
+// TestOrderService_GetOrder_StaleDisabled verifies provider errors are returned without a stale TTL.
+func TestOrderService_GetOrder_StaleDisabled(t *testing.T) {
+	c := &mockCache{data: map[string][]byte{}}
+	provider := &mockOrderProvider{err: errors.New("connection refused")}
+	svc := NewOrderService(provider, c, time.Hour, nil, nil, nil)
+
+	_, err := svc.GetOrder("5", "a@b.co")
+
+	assert.EqualError(t, err, "connection refused")
+}
+
+// TestOrderService_GetOrder_CacheFailure verifies a failing cache read falls back to the provider.
+func TestOrderService_GetOrder_CacheFailure(t *testing.T) {
+	c := &mockCache{data: map[string][]byte{}, getErr: errors.New("connection refused")}
+	provider := &mockOrderProvider{order: &domain.Order{ID: "5", Email: "a@b.co", Status: domain.OrderStatusCreated}}
+	svc := NewOrderService(provider, c, time.Hour, nil, nil, nil)
+
+	result, err := svc.GetOrder("5", "a@b.co")
+
+	require.NoError(t, err)
+	assert.False(t, result.FromCache)
+	assert.Equal(t, "5", result.Order.ID)
+}
+
+// TestOrderService_WarmCache verifies each order is cached under its own email and failures are reported per ID.
+func TestOrderService_WarmCache(t *testing.T) {
+	c := &mockCache{data: map[string][]byte{}}
+	provider := &mockOrderProvider{order: &domain.Order{ID: "6", Email: "a@b.co", Status: domain.OrderStatusCreated}}
+	svc := NewOrderService(provider, c, time.Hour, nil, nil, nil)
+
+	results, err := svc.WarmCache(context.Background(), []string{"6"})
+
+	require.NoError(t, err)
+	assert.Equal(t, []WarmResult{{ID: "6", Cached: true}}, results)
+	assert.Contains(t, c.data, "order_6_a@b.co")
+
+	cached, err := svc.GetOrder("6", "a@b.co")
+	require.NoError(t, err)
+	assert.True(t, cached.FromCache)
+
+	provider.order = nil
+	results, err = svc.WarmCache(context.Background(), []string{"7"})
+	require.NoError(t, err)
+	assert.Equal(t, []WarmResult{{ID: "7", Error: ErrOrderNotFound.Error()}}, results)
+}
+
+// TestOrderService_WarmCache_EmailCase verifies warmed orders are found whatever the case of the email.
+func TestOrderService_WarmCache_EmailCase(t *testing.T) {
+	c := &mockCache{data: map[string][]byte{}}
+	provider := &mockOrderProvider{order: &domain.Order{ID: "6", Email: "Ana@B.co", Status: domain.OrderStatusCreated}}
+	svc := NewOrderService(provider, c, time.Hour, nil, nil, nil)
+
+	_, err := svc.WarmCache(context.Background(), []string{"6"})
+	require.NoError(t, err)
+	assert.Contains(t, c.data, "order_6_ana@b.co")
+
+	provider.err = errors.New("store down")
+	cached, err := svc.GetOrder("6", "ANA@b.co")
+	require.NoError(t, err)
+	assert.True(t, cached.FromCache)
+}
+
+// TestOrderService_WarmCache_Cancelled verifies orders are not fetched once the context is done.
+func TestOrderService_WarmCache_Cancelled(t *testing.T) {
+	c := &mockCache{data: map[string][]byte{}}
+	provider := &mockOrderProvider{order: &domain.Order{ID: "8", Email: "a@b.co"}}
+	svc := NewOrderService(provider, c, time.Hour, nil, nil, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err := svc.WarmCache(ctx, []string{"8", "9"})
+
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	for _, res := range results {
+		assert.False(t, res.Cached)
+		assert.Equal(t, context.Canceled.Error(), res.Error)
+	}
+	assert.Empty(t, c.data)
+}
+
+// TestOrderService_WarmCache_Maintenance verifies warming is refused while maintenance mode is on.
+func TestOrderService_WarmCache_Maintenance(t *testing.T) {
+	c := &mockCache{data: map[string][]byte{}}
+	svc := NewOrderService(&mockOrderProvider{}, c, time.Hour, maintenance.NewMode(true), nil, nil)
+
+	_, err := svc.WarmCache(context.Background(), []string{"1"})
+
+	assert.ErrorIs(t, err, ErrWarmInMaintenance)
+}
+
+// TestOrderService_GetRawOrder verifies raw orders are fetched live when the provider supports them.
+func TestOrderService_GetRawOrder(t *testing.T) {
+	c := &mockCache{data: map[string][]byte{}}
+	provider := &mockRawOrderProvider{raw: json.RawMessage(`{"id":4}`)}
+	svc := NewOrderService(provider, c, time.Hour, nil, nil, nil)
+
+	raw, err := svc.GetRawOrder("4")
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":4}`, string(raw))
+	assert.Empty(t, c.data, "raw orders must not be cached")
+}
+
+// TestOrderService_GetRawOrder_Unsupported verifies providers without raw access report ErrRawOrderUnsupported.
+func TestOrderService_GetRawOrder_Unsupported(t *testing.T) {
+	svc := NewOrderService(&mockOrderProvider{}, &mockCache{data: map[string][]byte{}}, time.Hour, nil, nil, nil)
+
+	_, err := svc.GetRawOrder("4")
+
+	assert.ErrorIs(t, err, ErrRawOrderUnsupported)
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"sync"
+
+	trackingdomain "tracker-scrapper/internal/features/tracking/domain"
+)
+
+// ErrTrackingUnavailable is returned by GetOrderTracking when no tracking resolver is configured.
+var ErrTrackingUnavailable = errors.New("order tracking is not available")
+
+// OrderTracking holds the tracking of every shipment of an order.
+type OrderTracking struct {
+	// Shipments has one entry per tracking number on the order, in the order's order.
+	Shipments []ShipmentTracking `json:"shipments"`
+	// Timeline merges the events of every shipment that could be looked up, oldest first.
+	Timeline []TimelineEvent `json:"timeline"`
+}
+
+// ShipmentTracking is the outcome of looking up one shipment of an order. Error is empty when
+// History is set.
+type ShipmentTracking struct {
+	// TrackingNumber is the guide number as stored on the order.
+	TrackingNumber string `json:"tracking_number"`
+	// Carrier is the carrier as stored on the order.
+	Carrier string `json:"carrier"`
+	// History is the shipment's tracking history.
+	History *trackingdomain.TrackingHistory `json:"history,omitempty"`
+	// Error describes why the shipment could not be looked up.
+	Error string `json:"error,omitempty"`
+}
+
+// TimelineEvent is a tracking event tagged with the shipment it belongs to.
+type TimelineEvent struct {
+	trackingdomain.TrackingEvent
+	// TrackingNumber is the guide number of the shipment the event belongs to.
+	TrackingNumber string `json:"tracking_number"`
+	// Courier is the normalized courier of the shipment the event belongs to.
+	Courier string `json:"courier"`
+}
+
+// GetOrderTracking validates the order like GetOrder and looks up every tracking number on it
+// concurrently. Shipments that fail are reported with their error, so one carrier being down
+// still returns the others; only order lookup failures are returned as an error.
+func (s *OrderService) GetOrderTracking(orderID, email string) (*OrderTracking, error) {
+	if s.tracking == nil {
+		return nil, ErrTrackingUnavailable
+	}
+
+	result, err := s.GetOrder(orderID, email)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := []ShipmentTracking{}
+	for _, info := range result.Order.Tracking {
+		if info.TrackingNumber != "" {
+			infos = append(infos, ShipmentTracking{TrackingNumber: info.TrackingNumber, Carrier: info.TrackingProvider})
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := range infos {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			history, err := s.tracking.GetTrackingHistory(infos[i].TrackingNumber, infos[i].Carrier)
+			if err != nil {
+				infos[i].Error = err.Error()
+				return
+			}
+			infos[i].History = history
+		}()
+	}
+	wg.Wait()
+
+	return &OrderTracking{Shipments: infos, Timeline: mergeTimeline(infos)}, nil
+}
+
+// mergeTimeline returns the events of every looked up shipment sorted by date, oldest first.
+// Events on the same date keep the order of the shipments and of their histories.
+func mergeTimeline(shipments []ShipmentTracking) []TimelineEvent {
+	timeline := []TimelineEvent{}
+	for _, shipment := range shipments {
+		if shipment.History == nil {
+			continue
+		}
+		for _, event := range shipment.History.History {
+			timeline = append(timeline, TimelineEvent{
+				TrackingEvent:  event,
+				TrackingNumber: shipment.TrackingNumber,
+				Courier:        shipment.History.Courier,
+			})
+		}
+	}
+
+	slices.SortStableFunc(timeline, func(a, b TimelineEvent) int {
+		return a.Date.Compare(b.Date)
+	})
+	return timeline
+}
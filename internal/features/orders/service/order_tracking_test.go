@@ -0,0 +1,98 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"tracker-scrapper/internal/features/orders/domain"
+	trackingdomain "tracker-scrapper/internal/features/tracking/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockTrackingResolver returns fixed histories and errors by tracking number.
+type mockTrackingResolver struct {
+	histories map[string]*trackingdomain.TrackingHistory
+	errs      map[string]error
+}
+
+// GetTrackingHistory implements TrackingResolver.
+func (m *mockTrackingResolver) GetTrackingHistory(trackingNumber, carrier string) (*trackingdomain.TrackingHistory, error) {
+	if err := m.errs[trackingNumber]; err != nil {
+		return nil, err
+	}
+	return m.histories[trackingNumber], nil
+}
+
+// at returns 2025-03-day hour:00 UTC.
+func at(day, hour int) time.Time {
+	return time.Date(2025, 3, day, hour, 0, 0, 0, time.UTC)
+}
+
+// TestOrderService_GetOrderTracking verifies every shipment is looked up and their events are merged by date,
+// with failed shipments reported alongside the ones that succeeded.
+func TestOrderService_GetOrderTracking(t *testing.T) {
+	provider := &mockOrderProvider{order: &domain.Order{ID: "7", Email: "a@b.co", Tracking: []domain.TrackingInfo{
+		{TrackingProvider: "Coordinadora", TrackingNumber: "111"},
+		{TrackingProvider: "Servientrega", TrackingNumber: "222"},
+		{TrackingProvider: "Inter Rapidisimo", TrackingNumber: "333"},
+		{TrackingProvider: "TCC"},
+	}}}
+	resolver := &mockTrackingResolver{
+		histories: map[string]*trackingdomain.TrackingHistory{
+			"111": {Courier: "coordinadora_co", History: []trackingdomain.TrackingEvent{
+				{Date: at(1, 9), Text: "Recibido"},
+				{Date: at(3, 9), Text: "Entregado"},
+			}},
+			"222": {Courier: "servientrega_co", History: []trackingdomain.TrackingEvent{
+				{Date: at(2, 9), Text: "En transporte"},
+			}},
+		},
+		errs: map[string]error{"333": errors.New("courier temporarily unavailable: interrapidisimo_co")},
+	}
+	svc := NewOrderService(provider, &mockCache{data: map[string][]byte{}}, time.Hour, nil, nil, resolver)
+
+	tracking, err := svc.GetOrderTracking("7", "a@b.co")
+	require.NoError(t, err)
+
+	require.Len(t, tracking.Shipments, 3, "entries without a guide number are skipped")
+	assert.Equal(t, "111", tracking.Shipments[0].TrackingNumber)
+	assert.NotNil(t, tracking.Shipments[0].History)
+	assert.Equal(t, "Inter Rapidisimo", tracking.Shipments[2].Carrier)
+	assert.Nil(t, tracking.Shipments[2].History)
+	assert.Equal(t, "courier temporarily unavailable: interrapidisimo_co", tracking.Shipments[2].Error)
+
+	var texts, numbers []string
+	for _, event := range tracking.Timeline {
+		texts = append(texts, event.Text)
+		numbers = append(numbers, event.TrackingNumber)
+	}
+	assert.Equal(t, []string{"Recibido", "En transporte", "Entregado"}, texts)
+	assert.Equal(t, []string{"111", "222", "111"}, numbers)
+	assert.Equal(t, "servientrega_co", tracking.Timeline[1].Courier)
+}
+
+// TestOrderService_GetOrderTracking_OrderErrors verifies order lookup failures are returned before any tracking lookup.
+func TestOrderService_GetOrderTracking_OrderErrors(t *testing.T) {
+	provider := &mockOrderProvider{order: &domain.Order{ID: "7", Email: "a@b.co"}}
+	svc := NewOrderService(provider, &mockCache{data: map[string][]byte{}}, time.Hour, nil, nil, &mockTrackingResolver{})
+
+	_, err := svc.GetOrderTracking("7", "other@b.co")
+	assert.ErrorIs(t, err, ErrEmailMismatch)
+
+	tracking, err := svc.GetOrderTracking("7", "a@b.co")
+	require.NoError(t, err)
+	assert.Empty(t, tracking.Shipments)
+	assert.Empty(t, tracking.Timeline)
+}
+
+// TestOrderService_GetOrderTracking_NoResolver verifies the lookup is unavailable without a tracking resolver.
+func TestOrderService_GetOrderTracking_NoResolver(t *testing.T) {
+	svc := NewOrderService(&mockOrderProvider{}, &mockCache{data: map[string][]byte{}}, time.Hour, nil, nil, nil)
+
+	_, err := svc.GetOrderTracking("7", "a@b.co")
+
+	assert.ErrorIs(t, err, ErrTrackingUnavailable)
+}
@@ -4,17 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
-	"net/url"
+	"maps"
+	"slices"
 	"strings"
 	"time"
 
+	"tracker-scrapper/internal/core/capture"
 	"tracker-scrapper/internal/core/logger"
+	"tracker-scrapper/internal/core/metrics"
 	"tracker-scrapper/internal/core/proxy"
 	"tracker-scrapper/internal/features/tracking/domain"
+	"tracker-scrapper/internal/features/tracking/ports"
 
-	"github.com/go-rod/rod"
-	"github.com/go-rod/rod/lib/launcher"
 	"go.uber.org/zap"
 )
 
@@ -23,169 +24,205 @@ type CoordinadoraAdapter struct {
 	baseURL string
 	proxy   proxy.Settings
 	logger  *zap.Logger
+	// authorization is sent on courier API requests when set through per-call overrides.
+	authorization string
+	// statusCodes holds configured codes that take precedence over coordDefaultCodes.
+	statusCodes StatusCodes
+	// timeout bounds a single lookup, from proxy selection to the parsed response.
+	timeout time.Duration
+	// location is the timezone the courier's dates are parsed in. Nil uses America/Bogota.
+	location *time.Location
+	// fetcher opens the tracking page and intercepts the courier API response.
+	fetcher PageFetcher
 }
 
-var coordKnownCodes = map[string]bool{
-	"2": true, // EN TERMINAL ORIGEN
-	"3": true, // EN TRANSPORTE
-	"4": true, // EN TERMINAL DESTINO
-	"5": true, // EN REPARTO
-	"6": true, // ENTREGADA
-	"8": true, // CERRADO POR INCIDENCIA / RETURN
+// coordDefaultCodes are the built-in Coordinadora codes; configured codes take precedence.
+var coordDefaultCodes = StatusCodes{
+	"2": "",                             // EN TERMINAL ORIGEN
+	"3": "",                             // EN TRANSPORTE
+	"4": "",                             // EN TERMINAL DESTINO
+	"5": "",                             // EN REPARTO
+	"6": domain.TrackingStatusCompleted, // ENTREGADA
+	"8": domain.TrackingStatusReturn,    // CERRADO POR INCIDENCIA / RETURN
 	// Incidence variations (7xx)
-	"700":    true, // Incidence
-	"701":    true, // Visita no entrega
-	"701_4":  true, // Novedad tiene solución
-	"701_10": true, // Novedad tiene solución
-	"728":    true, // Destinatario no cancela
-	"733":    true, // Afectacion tiempo entrega
+	"700":    domain.TrackingStatusIncidence, // Incidence
+	"701":    domain.TrackingStatusIncidence, // Visita no entrega
+	"701_4":  domain.TrackingStatusIncidence, // Novedad tiene solución
+	"701_10": domain.TrackingStatusIncidence, // Novedad tiene solución
+	"728":    domain.TrackingStatusIncidence, // Destinatario no cancela
+	"733":    domain.TrackingStatusIncidence, // Afectacion tiempo entrega
 	// Other
-	"post_binded": true, // Nueva guia generada
+	"post_binded": "", // Nueva guia generada
+}
+
+const (
+	// coordDetailPattern matches the API call returning the shipment's history.
+	coordDetailPattern = "*/wp-json/rgc/v1/detail_tracking*"
+	// coordStatusPattern matches the status call the page makes for some guides, whose events may be
+	// missing from the detail response.
+	coordStatusPattern = "*/wp-json/rgc/v1/*status*"
+	// coordStatusWait bounds the wait for the status response once the detail response arrived,
+	// since not every guide triggers it.
+	coordStatusWait = 5 * time.Second
+)
+
+// coordProgressStages maps Coordinadora event codes to the shipment's progress.
+var coordProgressStages = progressStages{
+	"2": 20,  // EN TERMINAL ORIGEN
+	"3": 50,  // EN TRANSPORTE
+	"4": 65,  // EN TERMINAL DESTINO
+	"5": 80,  // EN REPARTO
+	"6": 100, // ENTREGADA
 }
 
 // NewCoordinadoraAdapter creates a new CoordinadoraAdapter with the given base URL and proxy settings.
-func NewCoordinadoraAdapter(baseURL string, proxySettings proxy.Settings) *CoordinadoraAdapter {
+// statusCodes may be nil to use only the built-in codes. A zero timeout uses DefaultTimeout.
+func NewCoordinadoraAdapter(baseURL string, proxySettings proxy.Settings, statusCodes StatusCodes, timeout time.Duration, fetcher PageFetcher) *CoordinadoraAdapter {
 	return &CoordinadoraAdapter{
-		baseURL: baseURL,
-		proxy:   proxySettings,
-		logger:  logger.Get(),
+		baseURL:     baseURL,
+		proxy:       proxySettings,
+		logger:      logger.Get(),
+		statusCodes: statusCodes,
+		timeout:     effectiveTimeout(timeout),
+		fetcher:     fetcher,
 	}
 }
 
 // coordinadoraResponse represents the JSON structure from Coordinadora API.
 type coordinadoraResponse struct {
-	TrackingNumber string `json:"tracking_number"`
-	History        []struct {
-		Code        string `json:"code"`
-		Date        string `json:"date"`
-		Description string `json:"description"`
-	} `json:"history"`
+	TrackingNumber string              `json:"tracking_number"`
+	History        []coordinadoraEvent `json:"history"`
 }
 
-// GetTrackingHistory retrieves tracking history from Coordinadora using browser automation.
-func (a *CoordinadoraAdapter) GetTrackingHistory(trackingNumber string) (*domain.TrackingHistory, error) {
-	// Create a master context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-	defer cancel()
+// coordinadoraEvent is a history entry of a Coordinadora response.
+type coordinadoraEvent struct {
+	Code        string `json:"code"`
+	Date        string `json:"date"`
+	Description string `json:"description"`
+	// EvidenceURL and ReceivedBy are only present on the delivered (code 6) event.
+	EvidenceURL string `json:"evidence_url"`
+	ReceivedBy  string `json:"received_by"`
+}
 
-	pageURL := fmt.Sprintf(a.baseURL, trackingNumber)
-	if !strings.Contains(a.baseURL, "%s") {
-		// Fallback: Check if it ends with =, implying a query param is ready
-		if strings.HasSuffix(a.baseURL, "=") {
-			pageURL = a.baseURL + trackingNumber
-		} else {
-			pageURL = fmt.Sprintf("%s?guia=%s", a.baseURL, trackingNumber)
-		}
-	}
+// SetLocation sets the timezone Coordinadora's dates are parsed in, since they carry no offset.
+func (a *CoordinadoraAdapter) SetLocation(loc *time.Location) {
+	a.location = loc
+}
 
-	// Start local proxy forwarder if proxy is configured with credentials
-	var localProxyAddr string
-	var proxyForwarder *proxy.ForwardingProxy
-	if a.proxy.HasProxy() && a.proxy.Username != "" && a.proxy.Password != "" {
-		var err error
-		// Whitelist only Coordinadora domains to save bandwidth
-		proxyForwarder, err = proxy.NewForwardingProxy(a.proxy.FullURL(), "coordinadora.com")
-		if err != nil {
-			return nil, fmt.Errorf("failed to create proxy forwarder: %w", err)
-		}
-		localProxyAddr, err = proxyForwarder.Start(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to start proxy forwarder: %w", err)
-		}
-		defer proxyForwarder.Stop()
-		a.logger.Debug("Local proxy forwarder started", zap.String("local_addr", localProxyAddr))
-	} else if a.proxy.HasProxy() {
-		localProxyAddr = a.proxy.HostPort()
-	}
+// Close releases the page fetcher. Adapters share it, so closing it more than once is harmless.
+func (a *CoordinadoraAdapter) Close() error {
+	return closeIfCloser(a.fetcher)
+}
 
-	a.logger.Debug("Launching browser...",
-		zap.Bool("proxy_enabled", a.proxy.HasProxy()),
-		zap.String("proxy_addr", localProxyAddr),
-	)
+// GetTrackingHistory retrieves tracking history from Coordinadora and records scrape metrics.
+func (a *CoordinadoraAdapter) GetTrackingHistory(trackingNumber string) (*domain.TrackingHistory, error) {
+	start := time.Now()
+	history, err := a.scrape(a.normalizeTrackingNumber(trackingNumber))
+	err = timeoutError("coordinadora_co", a.timeout, err)
+	metrics.ObserveScrape("coordinadora_co", time.Since(start), err)
+	return history, err
+}
 
-	// Configure launcher
-	l := launcher.New().
-		Context(ctx).
-		Headless(true).
-		NoSandbox(true)
+// normalizeTrackingNumber keeps only the digits of raw. Coordinadora guides may start with
+// zeros that are part of the number, so they are preserved.
+func (a *CoordinadoraAdapter) normalizeTrackingNumber(raw string) string {
+	return digitsOnly(raw)
+}
 
-	// Configure proxy - use local forwarder address (no auth needed)
-	if localProxyAddr != "" {
-		l = l.Proxy(localProxyAddr)
-		a.logger.Debug("Browser configured with proxy", zap.String("proxy", localProxyAddr))
-	}
+// scrape retrieves tracking history from Coordinadora using browser automation.
+func (a *CoordinadoraAdapter) scrape(trackingNumber string) (*domain.TrackingHistory, error) {
+	// Create a master context with the courier's timeout
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
 
-	u, err := l.Launch()
+	pageURL := a.pageURL(trackingNumber)
+
+	// Pick a healthy proxy endpoint when a pool is configured
+	proxySettings, err := a.proxy.Resolve(ctx, pageURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to launch browser: %w", err)
+		return nil, err
 	}
 
-	browser := rod.New().Context(ctx).ControlURL(u)
-	if err := browser.Connect(); err != nil {
-		return nil, fmt.Errorf("failed to connect to browser: %w", err)
+	// Responses keyed by the pattern they matched; the status call only happens for some guides
+	responses := map[string][]byte{}
+	body, err := a.fetcher.Fetch(ctx, FetchRequest{
+		Courier: "coordinadora_co",
+		URL:     pageURL,
+		Pattern: coordDetailPattern,
+		Proxy:   proxySettings,
+		// Tunnel only the configured Coordinadora domains to save bandwidth
+		ProxyDomains:  proxySettings.AllowedDomains,
+		Authorization: a.authorization,
+		ExtraPatterns: []string{coordStatusPattern},
+		ExtraWait:     coordStatusWait,
+		OnExtras: func(extras map[string][]byte) {
+			maps.Copy(responses, extras)
+		},
+	})
+	if err != nil {
+		return nil, err
 	}
-	defer browser.Close()
-
-	page := browser.MustPage(pageURL)
-
-	router := page.HijackRequests()
-	defer router.MustStop()
-
-	done := make(chan []byte)
 
-	// Pattern from user example: */wp-json/rgc/v1/detail_tracking*
-	router.MustAdd("*/wp-json/rgc/v1/detail_tracking*", func(ctx *rod.Hijack) {
-		// Create proxy-aware client if proxy is used
-		client := http.DefaultClient
-		if localProxyAddr != "" {
-			proxyURL, err := url.Parse(localProxyAddr)
-			if err != nil {
-				a.logger.Error("Failed to parse local proxy URL", zap.Error(err))
-			} else {
-				client = &http.Client{
-					Transport: &http.Transport{
-						Proxy: http.ProxyURL(proxyURL),
-					},
-					Timeout: 30 * time.Second,
-				}
-			}
-		}
+	capture.Save("coordinadora_co", trackingNumber, body)
+	var resp coordinadoraResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse courier response: %w", err)
+	}
 
-		if err := ctx.LoadResponse(client, true); err != nil {
-			a.logger.Error("Failed to load response", zap.Error(err))
-			return
+	// A status response that can't be parsed only loses the events it would have added
+	var extra []coordinadoraResponse
+	if status, ok := responses[coordStatusPattern]; ok {
+		var statusResp coordinadoraResponse
+		if err := json.Unmarshal(status, &statusResp); err != nil {
+			a.logger.Warn("Failed to parse Coordinadora status response", zap.Error(err))
+		} else {
+			extra = append(extra, statusResp)
 		}
-		done <- []byte(ctx.Response.Body())
-	})
-
-	go router.Run()
+	}
 
-	// Wait for response
-	select {
-	case body := <-done:
-		var resp coordinadoraResponse
-		if err := json.Unmarshal(body, &resp); err != nil {
-			return nil, fmt.Errorf("failed to parse courier response: %w", err)
-		}
-		return a.mapResponseToDomain(resp)
+	history, err := a.mapResponseToDomain(resp, extra...)
+	if err != nil {
+		return nil, err
+	}
+	history.PublicURL = pageURL
+	return history, nil
+}
 
-	case <-ctx.Done():
-		return nil, fmt.Errorf("timeout waiting for courier response: %w", ctx.Err())
+// pageURL builds the Coordinadora tracking page URL for trackingNumber from the base URL,
+// which may hold a %s placeholder, end in "=" or be a bare page taking a guia parameter.
+func (a *CoordinadoraAdapter) pageURL(trackingNumber string) string {
+	if strings.Contains(a.baseURL, "%s") {
+		return fmt.Sprintf(a.baseURL, trackingNumber)
 	}
+	if strings.HasSuffix(a.baseURL, "=") {
+		return a.baseURL + trackingNumber
+	}
+	return fmt.Sprintf("%s?guia=%s", a.baseURL, trackingNumber)
 }
 
-// mapResponseToDomain converts Coordinadora response to domain structure.
-func (a *CoordinadoraAdapter) mapResponseToDomain(resp coordinadoraResponse) (*domain.TrackingHistory, error) {
+// mapResponseToDomain converts Coordinadora response to domain structure, merging in the events of
+// extra responses (e.g., the status call) that the detail response is missing.
+func (a *CoordinadoraAdapter) mapResponseToDomain(resp coordinadoraResponse, extra ...coordinadoraResponse) (*domain.TrackingHistory, error) {
+	resp = mergeCoordinadoraResponses(resp, extra)
+
 	history := &domain.TrackingHistory{
 		GlobalStatus: domain.TrackingStatusProcessing, // Default
 		History:      make([]domain.TrackingEvent, 0),
 	}
 
-	// Layout: "2023-12-28 10:50:44"
+	// Layout: "2023-12-28 10:50:44", local time without an offset
 	const dateLayout = "2006-01-02 15:04:05"
+	location := effectiveLocation(a.location)
 
-	for _, item := range resp.History {
-		date, _ := time.Parse(dateLayout, item.Date)
+	lastKnown := true
+	for i, item := range resp.History {
+		date, err := time.ParseInLocation(dateLayout, item.Date, location)
+		if err != nil {
+			history.Warnings = append(history.Warnings, fmt.Sprintf("event %d: invalid date %q", i, item.Date))
+		}
+		if item.Code == "" {
+			history.Warnings = append(history.Warnings, fmt.Sprintf("event %d: missing status code", i))
+		}
 
 		event := domain.TrackingEvent{
 			Date: date,
@@ -193,37 +230,107 @@ func (a *CoordinadoraAdapter) mapResponseToDomain(resp coordinadoraResponse) (*d
 			City: "", // Coordinadora history items don't strictly have city
 			Code: item.Code,
 		}
+		if item.Code == "6" {
+			event.ProofURL = item.EvidenceURL
+			event.SignedBy = item.ReceivedBy
+		}
 		history.History = append(history.History, event)
+		// The latest event's description is the status Coordinadora shows
+		history.RawStatus = strings.TrimSpace(item.Description)
 
 		// Status Mapping Logic
-		// 6 -> COMPLETED
-		// 8 -> RETURN
-		// 7... -> INCIDENCE
-		switch {
-		case item.Code == "6":
-			history.GlobalStatus = domain.TrackingStatusCompleted
-		case item.Code == "8":
-			history.GlobalStatus = domain.TrackingStatusReturn
-		case strings.HasPrefix(item.Code, "7"):
-			history.GlobalStatus = domain.TrackingStatusIncidence
+		// For Coordinadora, 7xx codes are virtually infinite variations of incidence.
+		// We treat any unlisted prefix "7" as known incidence category.
+		status, isKnown := a.statusCodes.lookup(coordDefaultCodes, item.Code)
+		if !isKnown && strings.HasPrefix(item.Code, "7") {
+			status, isKnown = domain.TrackingStatusIncidence, true
+		}
+		if status != "" {
+			history.GlobalStatus = status
 		}
 
-		// Check known codes.
-		// For Coordinadora, 7xx codes are virtually infinite variations of incidence.
-		// We treat any prefix "7" as known incidence category.
-		isKnown := coordKnownCodes[item.Code] || strings.HasPrefix(item.Code, "7")
+		lastKnown = isKnown
+
 		if !isKnown {
 			a.logger.Warn("Unknown Coordinadora status code encountered",
+				zap.String("courier", "coordinadora_co"),
 				zap.String("code", item.Code),
 				zap.String("description", item.Description),
 			)
 		}
 	}
 
+	// An unmapped latest event means the real status is unknown, not processing
+	if !lastKnown {
+		history.GlobalStatus = domain.TrackingStatusUnknown
+	}
+
+	history.ProgressPct = progressPct(history, coordProgressStages)
+
+	if len(history.Warnings) > 0 {
+		a.logger.Warn("Coordinadora response partially parsed",
+			zap.String("courier", "coordinadora_co"),
+			zap.Strings("warnings", history.Warnings),
+		)
+	}
+
 	return history, nil
 }
 
+// mergeCoordinadoraResponses adds the events of extra that resp doesn't already have, matched by code
+// and date. When any were added the history is put back in chronological order, which the
+// "2006-01-02 15:04:05" dates give as plain string order.
+func mergeCoordinadoraResponses(resp coordinadoraResponse, extra []coordinadoraResponse) coordinadoraResponse {
+	type eventKey struct{ code, date string }
+	seen := make(map[eventKey]bool, len(resp.History))
+	for _, item := range resp.History {
+		seen[eventKey{item.Code, item.Date}] = true
+	}
+
+	merged := false
+	for _, other := range extra {
+		if resp.TrackingNumber == "" {
+			resp.TrackingNumber = other.TrackingNumber
+		}
+		for _, item := range other.History {
+			key := eventKey{item.Code, item.Date}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			// Copy before appending so the caller's history isn't modified
+			if !merged {
+				resp.History = slices.Clone(resp.History)
+				merged = true
+			}
+			resp.History = append(resp.History, item)
+		}
+	}
+
+	if merged {
+		slices.SortStableFunc(resp.History, func(x, y coordinadoraEvent) int {
+			return strings.Compare(x.Date, y.Date)
+		})
+	}
+	return resp
+}
+
+// WithOverrides returns a copy of the adapter using the given per-call overrides.
+func (a *CoordinadoraAdapter) WithOverrides(overrides ports.Overrides) ports.TrackingProvider {
+	clone := *a
+	if overrides.BaseURL != "" {
+		clone.baseURL = overrides.BaseURL
+	}
+	clone.authorization = overrides.Authorization
+	return &clone
+}
+
 // SupportsCourier returns true if this adapter supports coordinadora_co.
 func (a *CoordinadoraAdapter) SupportsCourier(courierName string) bool {
 	return courierName == "coordinadora_co"
 }
+
+// Couriers returns coordinadora_co.
+func (a *CoordinadoraAdapter) Couriers() []string {
+	return []string{"coordinadora_co"}
+}
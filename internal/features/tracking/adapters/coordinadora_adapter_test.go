@@ -2,9 +2,12 @@ package adapter
 
 import (
 	"encoding/json"
+	"net/url"
 	"testing"
+	"time"
 
 	"tracker-scrapper/internal/features/tracking/domain"
+	"tracker-scrapper/internal/features/tracking/ports"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -41,6 +44,7 @@ func TestCoordinadoraAdapter_mapResponseToDomain_Success(t *testing.T) {
 	assert.Equal(t, domain.TrackingStatusCompleted, history.GlobalStatus)
 	require.Len(t, history.History, 2)
 	assert.Equal(t, "6", history.History[1].Code)
+	assert.Empty(t, history.Warnings)
 }
 
 // TestCoordinadoraAdapter_mapResponseToDomain_Return verifies return mapping (Code 8).
@@ -123,3 +127,198 @@ func TestCoordinadoraAdapter_mapResponseToDomain_IncidenceVariations(t *testing.
 	assert.Equal(t, "700", history.History[0].Code)
 	assert.Equal(t, "701", history.History[1].Code)
 }
+
+// TestCoordinadoraAdapter_mapResponseToDomain_DeliveryProof verifies proof fields on the delivered event.
+func TestCoordinadoraAdapter_mapResponseToDomain_DeliveryProof(t *testing.T) {
+	jsonContent := `{
+    "history": [
+        {
+            "code": "5",
+            "date": "2024-01-03 08:12:00",
+            "description": "EN REPARTO"
+        },
+        {
+            "code": "6",
+            "date": "2024-01-03 13:58:00",
+            "description": "ENTREGADA",
+            "evidence_url": "https://coordinadora.com/evidencias/04333004120.jpg",
+            "received_by": "MARIA PEREZ"
+        }
+    ]
+}`
+	var resp coordinadoraResponse
+	err := json.Unmarshal([]byte(jsonContent), &resp)
+	require.NoError(t, err)
+
+	adapter := &CoordinadoraAdapter{
+		logger: zap.NewNop(),
+	}
+	history, err := adapter.mapResponseToDomain(resp)
+
+	require.NoError(t, err)
+	require.Len(t, history.History, 2)
+	assert.Empty(t, history.History[0].ProofURL)
+	assert.Empty(t, history.History[0].SignedBy)
+	assert.Equal(t, "https://coordinadora.com/evidencias/04333004120.jpg", history.History[1].ProofURL)
+	assert.Equal(t, "MARIA PEREZ", history.History[1].SignedBy)
+}
+
+// TestCoordinadoraAdapter_WithOverrides verifies overrides apply to a copy only.
+func TestCoordinadoraAdapter_WithOverrides(t *testing.T) {
+	adapter := &CoordinadoraAdapter{
+		baseURL: "https://coordinadora.com/rastreo?guia=",
+		logger:  zap.NewNop(),
+	}
+
+	overridden := adapter.WithOverrides(ports.Overrides{
+		BaseURL:       "https://staging.coordinadora.com/rastreo?guia=",
+		Authorization: "Bearer test",
+	}).(*CoordinadoraAdapter)
+
+	assert.Equal(t, "https://staging.coordinadora.com/rastreo?guia=", overridden.baseURL)
+	assert.Equal(t, "Bearer test", overridden.authorization)
+	assert.Equal(t, "https://coordinadora.com/rastreo?guia=", adapter.baseURL)
+	assert.Empty(t, adapter.authorization)
+}
+
+// TestCoordinadoraAdapter_mapResponseToDomain_UnknownLatestCode verifies an unmapped latest code yields UNKNOWN.
+func TestCoordinadoraAdapter_mapResponseToDomain_UnknownLatestCode(t *testing.T) {
+	jsonContent := `{
+    "history": [
+        {"code": "2", "date": "2024-01-01 10:00:00", "description": "EN TERMINAL ORIGEN"},
+        {"code": "99", "date": "2024-01-02 10:00:00", "description": "ESTADO NUEVO"}
+    ]
+}`
+	var resp coordinadoraResponse
+	require.NoError(t, json.Unmarshal([]byte(jsonContent), &resp))
+
+	adapter := &CoordinadoraAdapter{
+		logger: zap.NewNop(),
+	}
+	history, err := adapter.mapResponseToDomain(resp)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.TrackingStatusUnknown, history.GlobalStatus)
+	require.Len(t, history.History, 2)
+	assert.Equal(t, "99", history.History[1].Code)
+}
+
+// TestCoordinadoraAdapter_mapResponseToDomain_PartialParse verifies malformed events are kept and reported as warnings.
+func TestCoordinadoraAdapter_mapResponseToDomain_PartialParse(t *testing.T) {
+	jsonContent := `{
+    "history": [
+        {"code": "2", "date": "28/12/2023", "description": "EN TERMINAL ORIGEN"},
+        {"code": "", "date": "2024-01-02 10:00:00", "description": "SIN CODIGO"},
+        {"code": "6", "date": "2024-01-03 13:58:00", "description": "ENTREGADA"}
+    ]
+}`
+	var resp coordinadoraResponse
+	require.NoError(t, json.Unmarshal([]byte(jsonContent), &resp))
+
+	adapter := &CoordinadoraAdapter{
+		logger: zap.NewNop(),
+	}
+	history, err := adapter.mapResponseToDomain(resp)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.TrackingStatusCompleted, history.GlobalStatus)
+	require.Len(t, history.History, 3)
+	assert.True(t, history.History[0].Date.IsZero())
+	assert.Equal(t, []string{
+		`event 0: invalid date "28/12/2023"`,
+		"event 1: missing status code",
+	}, history.Warnings)
+}
+
+// TestCoordinadoraAdapter_normalizeTrackingNumber verifies pasted numbers keep only their digits, leading zeros included.
+func TestCoordinadoraAdapter_normalizeTrackingNumber(t *testing.T) {
+	adapter := &CoordinadoraAdapter{}
+
+	tests := map[string]string{
+		"04333004120":     "04333004120",
+		"  04333004120\n": "04333004120",
+		"0433 3004 120":   "04333004120",
+		"0433-3004-120":   "04333004120",
+		"guia":            "guia",
+	}
+	for raw, want := range tests {
+		assert.Equal(t, want, adapter.normalizeTrackingNumber(raw), raw)
+	}
+}
+
+// TestCoordinadoraAdapter_pageURL verifies every supported base URL form yields a well-formed page URL carrying the guide.
+func TestCoordinadoraAdapter_pageURL(t *testing.T) {
+	tests := map[string]string{
+		"https://coordinadora.com/rastreo/?guia=%s":         "https://coordinadora.com/rastreo/?guia=04333004120",
+		"https://coordinadora.com/rastreo/?guia=":           "https://coordinadora.com/rastreo/?guia=04333004120",
+		"https://coordinadora.com/rastreo/rastreo-de-guia/": "https://coordinadora.com/rastreo/rastreo-de-guia/?guia=04333004120",
+	}
+	for baseURL, want := range tests {
+		adapter := &CoordinadoraAdapter{baseURL: baseURL}
+
+		got := adapter.pageURL("04333004120")
+
+		assert.Equal(t, want, got, baseURL)
+		parsed, err := url.Parse(got)
+		require.NoError(t, err)
+		assert.Equal(t, "04333004120", parsed.Query().Get("guia"))
+	}
+}
+
+// TestCoordinadoraAdapter_mapResponseToDomain_RawStatus verifies the latest description is kept as the raw status.
+func TestCoordinadoraAdapter_mapResponseToDomain_RawStatus(t *testing.T) {
+	adapter := &CoordinadoraAdapter{logger: zap.NewNop()}
+
+	var resp coordinadoraResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"history": [
+		{"code": "3", "date": "2023-12-28 10:50:44", "description": "EN TRANSPORTE"},
+		{"code": "5", "date": "2023-12-29 07:12:00", "description": " EN REPARTO "}
+	]}`), &resp))
+
+	history, err := adapter.mapResponseToDomain(resp)
+	require.NoError(t, err)
+	assert.Equal(t, "EN REPARTO", history.RawStatus)
+
+	history, err = adapter.mapResponseToDomain(coordinadoraResponse{})
+	require.NoError(t, err)
+	assert.Empty(t, history.RawStatus)
+}
+
+// TestCoordinadoraAdapter_mapResponseToDomain_MergeExtra verifies extra responses add only missing events, in date order.
+func TestCoordinadoraAdapter_mapResponseToDomain_MergeExtra(t *testing.T) {
+	detail := coordinadoraResponse{History: []coordinadoraEvent{
+		{Code: "2", Date: "2024-01-01 08:00:00", Description: "EN TERMINAL ORIGEN"},
+		{Code: "5", Date: "2024-01-03 07:00:00", Description: "EN REPARTO"},
+	}}
+	status := coordinadoraResponse{TrackingNumber: "04333004120", History: []coordinadoraEvent{
+		{Code: "3", Date: "2024-01-02 09:00:00", Description: "EN TRANSPORTE"},
+		{Code: "5", Date: "2024-01-03 07:00:00", Description: "EN REPARTO"},
+	}}
+
+	adapter := &CoordinadoraAdapter{logger: zap.NewNop()}
+	history, err := adapter.mapResponseToDomain(detail, status)
+
+	require.NoError(t, err)
+	require.Len(t, history.History, 3)
+	assert.Equal(t, "2", history.History[0].Code)
+	assert.Equal(t, "3", history.History[1].Code)
+	assert.Equal(t, "5", history.History[2].Code)
+	assert.Equal(t, "EN REPARTO", history.RawStatus)
+	assert.Len(t, detail.History, 2, "the detail response must not be modified")
+}
+
+// TestCoordinadoraAdapter_mapResponseToDomain_Timezone verifies dates are read as Colombia time by default
+// and in the configured location once one is set.
+func TestCoordinadoraAdapter_mapResponseToDomain_Timezone(t *testing.T) {
+	resp := coordinadoraResponse{History: []coordinadoraEvent{{Code: "2", Date: "2023-12-28 10:50:44"}}}
+	adapter := &CoordinadoraAdapter{logger: zap.NewNop()}
+
+	history, err := adapter.mapResponseToDomain(resp)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 12, 28, 15, 50, 44, 0, time.UTC), history.History[0].Date.UTC())
+
+	adapter.SetLocation(time.FixedZone("UTC-3", -3*60*60))
+	history, err = adapter.mapResponseToDomain(resp)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 12, 28, 13, 50, 44, 0, time.UTC), history.History[0].Date.UTC())
+}
@@ -0,0 +1,135 @@
+package adapter
+
+import (
+	"fmt"
+	"time"
+
+	"tracker-scrapper/internal/core/config"
+	"tracker-scrapper/internal/core/logger"
+	"tracker-scrapper/internal/core/proxy"
+	"tracker-scrapper/internal/core/scraper"
+	"tracker-scrapper/internal/core/useragent"
+	"tracker-scrapper/internal/features/tracking/ports"
+
+	"go.uber.org/zap"
+)
+
+// Couriers lists the supported couriers in the order their providers are registered.
+var Couriers = []string{"coordinadora_co", "servientrega_co", "interrapidisimo_co"}
+
+// NewCourierAdapters builds the scraping adapter of every courier in Couriers from cfg, keyed by courier name.
+// The API and the CLI both use it so a command-line scrape follows the same code path as a request.
+func NewCourierAdapters(cfg *config.AppConfig) (map[string]ports.TrackingProvider, error) {
+	l := logger.Get()
+
+	// Rotate across a pool when several proxy endpoints are configured
+	proxyHostname, proxyPort := cfg.Proxy.Hostname, cfg.Proxy.Port
+	var proxyPool *proxy.Pool
+	if len(cfg.Proxy.Hosts) > 0 {
+		endpoints, err := proxy.ParseEndpoints(cfg.Proxy.Hosts)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy hosts: %w", err)
+		}
+		// With a single endpoint this is plain single-proxy mode; with a pool each scrape picks its own endpoint
+		proxyHostname, proxyPort = endpoints[0].Hostname, endpoints[0].Port
+		if len(endpoints) > 1 {
+			benchFor := time.Duration(cfg.Proxy.BenchSeconds) * time.Second
+			proxyPool = proxy.NewPool(endpoints, cfg.Proxy.Username, cfg.Proxy.Password, benchFor)
+			l.Info("Proxy pool configured", zap.Int("endpoints", len(endpoints)))
+		}
+	}
+
+	proxyFor := func(enabled bool, domains []string) proxy.Settings {
+		return proxy.Settings{
+			Enabled:        enabled,
+			Hostname:       proxyHostname,
+			Port:           proxyPort,
+			Username:       cfg.Proxy.Username,
+			Password:       cfg.Proxy.Password,
+			AllowedDomains: domains,
+			Pool:           proxyPool,
+		}
+	}
+
+	// Load optional status code overrides; adapters fall back to their built-in codes
+	var statusCodes map[string]StatusCodes
+	if cfg.Couriers.StatusCodesFile != "" {
+		var err error
+		statusCodes, err = LoadStatusCodes(cfg.Couriers.StatusCodesFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load courier status codes: %w", err)
+		}
+		l.Info("Courier status codes loaded", zap.String("file", cfg.Couriers.StatusCodesFile))
+	}
+
+	// Each scrape picks its user agent from the pool; a pinned user agent is a pool of one
+	switch {
+	case cfg.Couriers.UserAgent != "":
+		useragent.Set([]string{cfg.Couriers.UserAgent})
+	case cfg.Couriers.UserAgentsFile != "":
+		agents, err := useragent.LoadFile(cfg.Couriers.UserAgentsFile)
+		if err != nil {
+			return nil, err
+		}
+		useragent.Set(agents)
+		l.Info("User agents loaded", zap.String("file", cfg.Couriers.UserAgentsFile), zap.Int("count", len(agents)))
+	default:
+		useragent.Set(nil)
+	}
+
+	// All scraping adapters share the rod-backed page fetcher, and with it the cap on running browsers
+	fetcher := WithLimiter(NewRodFetcher(scraper.Stealth{
+		Enabled: cfg.Couriers.Stealth,
+	}, cfg.Couriers.ChromiumBin, cfg.Couriers.NavigationRetries, time.Duration(cfg.Couriers.NavigationRetryDelay)*time.Second,
+		time.Duration(cfg.Couriers.InterceptTimeout)*time.Second),
+		scraper.NewLimiter(cfg.Couriers.MaxBrowsers))
+
+	// Courier dates carry no UTC offset; they are all read as APP_TIMEZONE
+	location, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone: %w", err)
+	}
+
+	// Per-courier timeouts fall back to COURIER_TIMEOUT when unset
+	timeout := func(seconds int) time.Duration {
+		if seconds == 0 {
+			seconds = cfg.Couriers.Timeout
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	coordinadora := NewCoordinadoraAdapter(cfg.Couriers.CoordinadoraURL,
+		proxyFor(cfg.Proxy.Coordinadora, cfg.Proxy.CoordinadoraDomains),
+		statusCodes["coordinadora_co"], timeout(cfg.Couriers.CoordinadoraTimeout),
+		courierFetcher(fetcher, cfg.Couriers.CoordinadoraMaxBrowsers))
+	coordinadora.SetLocation(location)
+
+	servientrega := NewServientregaAdapter(cfg.Couriers.ServientregaURL, cfg.Couriers.ServientregaDesktopURL,
+		proxyFor(cfg.Proxy.Servientrega, cfg.Proxy.ServientregaDomains),
+		statusCodes["servientrega_co"], cfg.Couriers.ServientregaEmptyRetries,
+		ConnectivityCheck(cfg.Couriers.ServientregaConnectivityCheck), timeout(cfg.Couriers.ServientregaTimeout),
+		courierFetcher(fetcher, cfg.Couriers.ServientregaMaxBrowsers))
+	servientrega.SetLocation(location)
+
+	interrapidisimo := NewInterrapidisimoAdapter(cfg.Couriers.InterrapidisimoURL,
+		proxyFor(cfg.Proxy.Interrapidisimo, cfg.Proxy.InterrapidisimoDomains),
+		statusCodes["interrapidisimo_co"], timeout(cfg.Couriers.InterrapidisimoTimeout),
+		courierFetcher(fetcher, cfg.Couriers.InterrapidisimoMaxBrowsers))
+	interrapidisimo.SetLocation(location)
+
+	return map[string]ports.TrackingProvider{
+		"coordinadora_co":    coordinadora,
+		"servientrega_co":    servientrega,
+		"interrapidisimo_co": interrapidisimo,
+	}, nil
+}
+
+// courierFetcher caps one courier's scrapes at limit on top of the shared fetcher's browser cap,
+// since some couriers ban an IP after a few parallel requests. The courier slot is taken first, so
+// lookups queued for a busy courier don't hold shared browsers. A limit of 0 returns shared as is.
+func courierFetcher(shared PageFetcher, limit int) PageFetcher {
+	if limit == 0 {
+		return shared
+	}
+	return WithLimiter(shared, scraper.NewLimiter(limit))
+}
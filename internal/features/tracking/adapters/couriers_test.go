@@ -0,0 +1,75 @@
+package adapter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"tracker-scrapper/internal/core/config"
+	"tracker-scrapper/internal/core/useragent"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewCourierAdapters verifies an adapter is built for every courier from the configuration.
+func TestNewCourierAdapters(t *testing.T) {
+	cfg := &config.AppConfig{Timezone: "America/Lima", Couriers: config.CourierConfig{
+		CoordinadoraURL:     "https://coordinadora.com/?guia=",
+		ServientregaURL:     "https://mobile.servientrega.com/?Guia=",
+		InterrapidisimoURL:  "https://www3.interrapidisimo.com/SiguetuEnvio/shipment",
+		Timeout:             60,
+		ServientregaTimeout: 90,
+	}}
+
+	adapters, err := NewCourierAdapters(cfg)
+
+	require.NoError(t, err)
+	require.Len(t, adapters, len(Couriers))
+	for _, courier := range Couriers {
+		assert.True(t, adapters[courier].SupportsCourier(courier), courier)
+	}
+	assert.Equal(t, "https://coordinadora.com/?guia=", adapters["coordinadora_co"].(*CoordinadoraAdapter).baseURL)
+	assert.Equal(t, 90*time.Second, adapters["servientrega_co"].(*ServientregaAdapter).timeout)
+	assert.Equal(t, 60*time.Second, adapters["interrapidisimo_co"].(*InterrapidisimoAdapter).timeout)
+	assert.Equal(t, "America/Lima", adapters["coordinadora_co"].(*CoordinadoraAdapter).location.String())
+	assert.Equal(t, "America/Lima", adapters["servientrega_co"].(*ServientregaAdapter).location.String())
+	assert.Equal(t, "America/Lima", adapters["interrapidisimo_co"].(*InterrapidisimoAdapter).location.String())
+}
+
+// TestNewCourierAdapters_InvalidConfig verifies bad proxy hosts, status code files and timezones are reported.
+func TestNewCourierAdapters_InvalidConfig(t *testing.T) {
+	_, err := NewCourierAdapters(&config.AppConfig{Proxy: config.ProxyConfig{Hosts: []string{"no-port"}}})
+	assert.ErrorContains(t, err, "invalid proxy hosts")
+
+	missing := filepath.Join(t.TempDir(), "missing.json")
+	_, err = NewCourierAdapters(&config.AppConfig{Couriers: config.CourierConfig{StatusCodesFile: missing}})
+	assert.ErrorContains(t, err, "failed to load courier status codes")
+
+	_, err = NewCourierAdapters(&config.AppConfig{Timezone: "America/Gotham"})
+	assert.ErrorContains(t, err, "invalid timezone")
+}
+
+// TestNewCourierAdapters_UserAgents verifies the user agent pool comes from the pinned user agent or the file.
+func TestNewCourierAdapters_UserAgents(t *testing.T) {
+	defer useragent.Set(nil)
+
+	path := filepath.Join(t.TempDir(), "agents.txt")
+	require.NoError(t, os.WriteFile(path, []byte("agent/1\nagent/2\n"), 0o600))
+
+	_, err := NewCourierAdapters(&config.AppConfig{Couriers: config.CourierConfig{UserAgentsFile: path}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"agent/1", "agent/2"}, useragent.Pool())
+
+	_, err = NewCourierAdapters(&config.AppConfig{Couriers: config.CourierConfig{UserAgent: "pinned/1.0", UserAgentsFile: path}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pinned/1.0"}, useragent.Pool())
+
+	_, err = NewCourierAdapters(&config.AppConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, useragent.Defaults(), useragent.Pool())
+
+	_, err = NewCourierAdapters(&config.AppConfig{Couriers: config.CourierConfig{UserAgentsFile: filepath.Join(t.TempDir(), "missing.txt")}})
+	assert.Error(t, err)
+}
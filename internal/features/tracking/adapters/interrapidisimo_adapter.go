@@ -4,17 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
-	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
+	"tracker-scrapper/internal/core/capture"
 	"tracker-scrapper/internal/core/logger"
+	"tracker-scrapper/internal/core/metrics"
 	"tracker-scrapper/internal/core/proxy"
 	"tracker-scrapper/internal/features/tracking/domain"
+	"tracker-scrapper/internal/features/tracking/ports"
 
-	"github.com/go-rod/rod"
-	"github.com/go-rod/rod/lib/launcher"
 	"go.uber.org/zap"
 )
 
@@ -23,26 +23,51 @@ type InterrapidisimoAdapter struct {
 	baseURL string
 	proxy   proxy.Settings
 	logger  *zap.Logger
+	// authorization is sent on courier API requests when set through per-call overrides.
+	authorization string
+	// statusCodes holds configured codes that take precedence over interDefaultCodes.
+	statusCodes StatusCodes
+	// timeout bounds a single lookup, from proxy selection to the parsed response.
+	timeout time.Duration
+	// location is the timezone the courier's dates are parsed in. Nil uses America/Bogota.
+	location *time.Location
+	// fetcher opens the tracking page and intercepts the courier API response.
+	fetcher PageFetcher
 }
 
-var interKnownCodes = map[int]bool{
-	1:  true, // Recibimos tu envío
-	2:  true, // En Centro Logístico Origen / Destino / Tránsito
-	3:  true, // Viajando a tu destino
-	4:  true, // Viajando a tu destino (variation)
-	6:  true, // En camino hacia ti
-	7:  true, // No logramos hacer la entrega (Incidence)
-	10: true, // Tu envío fue devuelto (Return)
-	11: true, // Tu envío fue entregado (Delivered)
-	16: true, // Archivada
+// interDefaultCodes are the built-in Interrapidisimo codes; configured codes take precedence.
+var interDefaultCodes = StatusCodes{
+	"1":  "",                             // Recibimos tu envío
+	"2":  "",                             // En Centro Logístico Origen / Destino / Tránsito
+	"3":  "",                             // Viajando a tu destino
+	"4":  "",                             // Viajando a tu destino (variation)
+	"6":  "",                             // En camino hacia ti
+	"7":  domain.TrackingStatusIncidence, // No logramos hacer la entrega (Incidence)
+	"10": domain.TrackingStatusReturn,    // Tu envío fue devuelto (Return)
+	"11": domain.TrackingStatusCompleted, // Tu envío fue entregado (Delivered)
+	"16": "",                             // Archivada
+}
+
+// interProgressStages maps Interrapidisimo event codes to the shipment's progress.
+var interProgressStages = progressStages{
+	"1":  10,  // Recibimos tu envío
+	"2":  35,  // En Centro Logístico Origen / Destino / Tránsito
+	"3":  50,  // Viajando a tu destino
+	"4":  50,  // Viajando a tu destino (variation)
+	"6":  80,  // En camino hacia ti
+	"11": 100, // Tu envío fue entregado
 }
 
 // NewInterrapidisimoAdapter creates a new InterrapidisimoAdapter with the given base URL and proxy settings.
-func NewInterrapidisimoAdapter(baseURL string, proxySettings proxy.Settings) *InterrapidisimoAdapter {
+// statusCodes may be nil to use only the built-in codes. A zero timeout uses DefaultTimeout.
+func NewInterrapidisimoAdapter(baseURL string, proxySettings proxy.Settings, statusCodes StatusCodes, timeout time.Duration, fetcher PageFetcher) *InterrapidisimoAdapter {
 	return &InterrapidisimoAdapter{
-		baseURL: baseURL,
-		proxy:   proxySettings,
-		logger:  logger.Get(),
+		baseURL:     baseURL,
+		proxy:       proxySettings,
+		logger:      logger.Get(),
+		statusCodes: statusCodes,
+		timeout:     effectiveTimeout(timeout),
+		fetcher:     fetcher,
 	}
 }
 
@@ -58,126 +83,91 @@ type interResponse struct {
 	} `json:"EstadosGuia"`
 	Guia struct {
 		NumeroGuia int64 `json:"NumeroGuia"`
+		// RutaImagenEntrega and NombreRecibe are only filled once the shipment is delivered.
+		RutaImagenEntrega string `json:"RutaImagenEntrega"`
+		NombreRecibe      string `json:"NombreRecibe"`
 	} `json:"Guia"`
 	Success bool   `json:"Success"`
 	Message string `json:"Message"`
 }
 
-// GetTrackingHistory retrieves tracking history from Interrapidisimo using browser automation.
+// SetLocation sets the timezone Interrapidisimo's dates are parsed in, since they carry no offset.
+func (a *InterrapidisimoAdapter) SetLocation(loc *time.Location) {
+	a.location = loc
+}
+
+// Close releases the page fetcher. Adapters share it, so closing it more than once is harmless.
+func (a *InterrapidisimoAdapter) Close() error {
+	return closeIfCloser(a.fetcher)
+}
+
+// GetTrackingHistory retrieves tracking history from Interrapidisimo and records scrape metrics.
 func (a *InterrapidisimoAdapter) GetTrackingHistory(trackingNumber string) (*domain.TrackingHistory, error) {
-	// Create a master context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-	defer cancel()
+	start := time.Now()
+	history, err := a.scrape(a.normalizeTrackingNumber(trackingNumber))
+	err = timeoutError("interrapidisimo_co", a.timeout, err)
+	metrics.ObserveScrape("interrapidisimo_co", time.Since(start), err)
+	return history, err
+}
 
-	// Start local proxy forwarder if proxy is configured with credentials
-	var localProxyAddr string
-	var proxyForwarder *proxy.ForwardingProxy
-	if a.proxy.HasProxy() && a.proxy.Username != "" && a.proxy.Password != "" {
-		var err error
-		// Whitelist only Interrapidisimo domains
-		proxyForwarder, err = proxy.NewForwardingProxy(a.proxy.FullURL(), "interrapidisimo.com")
-		if err != nil {
-			return nil, fmt.Errorf("failed to create proxy forwarder: %w", err)
-		}
-		localProxyAddr, err = proxyForwarder.Start(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to start proxy forwarder: %w", err)
-		}
-		defer proxyForwarder.Stop()
-		a.logger.Debug("Local proxy forwarder started", zap.String("local_addr", localProxyAddr))
-	} else if a.proxy.HasProxy() {
-		localProxyAddr = a.proxy.HostPort()
+// normalizeTrackingNumber keeps only the digits of raw and drops leading zeros: Interrapidisimo
+// stores guides as an int64 (NumeroGuia), so "000240041234567" and "240041234567" are the same guide.
+func (a *InterrapidisimoAdapter) normalizeTrackingNumber(raw string) string {
+	digits := digitsOnly(raw)
+	if trimmed := strings.TrimLeft(digits, "0"); trimmed != "" {
+		return trimmed
 	}
+	return digits
+}
 
-	a.logger.Debug("Launching browser...",
-		zap.Bool("proxy_enabled", a.proxy.HasProxy()),
-		zap.String("proxy_addr", localProxyAddr),
-	)
-
-	// Configure launcher
-	l := launcher.New().
-		Context(ctx).
-		Headless(true).
-		NoSandbox(true)
-
-	// Configure proxy - use local forwarder address (no auth needed)
-	if localProxyAddr != "" {
-		l = l.Proxy(localProxyAddr)
-		a.logger.Debug("Browser configured with proxy", zap.String("proxy", localProxyAddr))
-	}
+// scrape retrieves tracking history from Interrapidisimo using browser automation.
+func (a *InterrapidisimoAdapter) scrape(trackingNumber string) (*domain.TrackingHistory, error) {
+	// Create a master context with the courier's timeout
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
 
-	u, err := l.Launch()
+	// Pick a healthy proxy endpoint when a pool is configured
+	proxySettings, err := a.proxy.Resolve(ctx, a.baseURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to launch browser: %w", err)
+		return nil, err
 	}
 
-	browser := rod.New().Context(ctx).ControlURL(u)
-	if err := browser.Connect(); err != nil {
-		return nil, fmt.Errorf("failed to connect to browser: %w", err)
-	}
-	defer browser.Close()
-
-	// Open the page
-	page := browser.MustPage(a.baseURL)
-
-	// Wait for input field
-	page.MustElement("#inputGuide").MustWaitVisible()
-
-	// Setup request hijacking
-	router := page.HijackRequests()
-	defer router.MustStop()
-
-	done := make(chan []byte)
-
-	// Intercept the API call
-	router.MustAdd("*/ObtenerRastreoGuiasClientePost", func(ctx *rod.Hijack) {
-		// Create proxy-aware client if proxy is used
-		client := http.DefaultClient
-		if localProxyAddr != "" {
-			proxyURL, err := url.Parse(localProxyAddr)
-			if err != nil {
-				a.logger.Error("Failed to parse local proxy URL", zap.Error(err))
-			} else {
-				client = &http.Client{
-					Transport: &http.Transport{
-						Proxy: http.ProxyURL(proxyURL),
-					},
-					Timeout: 30 * time.Second,
-				}
-			}
-		}
-
-		if err := ctx.LoadResponse(client, true); err != nil {
-			a.logger.Error("Failed to load response", zap.Error(err))
-			return
-		}
-		done <- []byte(ctx.Response.Body())
+	body, err := a.fetcher.Fetch(ctx, FetchRequest{
+		Courier: "interrapidisimo_co",
+		URL:     a.baseURL,
+		// Intercept the API call triggered by the search form
+		Pattern: "*/ObtenerRastreoGuiasClientePost",
+		Proxy:   proxySettings,
+		// Tunnel only the configured Interrapidisimo domains
+		ProxyDomains:  proxySettings.AllowedDomains,
+		Authorization: a.authorization,
+		Form: &FormInput{
+			InputSelector:  "#inputGuide",
+			Value:          trackingNumber,
+			SubmitSelector: ".search-button",
+		},
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	go router.Run()
-
-	// Interact with the page
-	page.MustElement("#inputGuide").MustInput(trackingNumber)
-	page.MustElement(".search-button").MustClick()
-
-	// Wait for response with timeout
-	select {
-	case body := <-done:
-		// Attempt to unmarshal
-		var resp interResponse
-		if err := json.Unmarshal(body, &resp); err != nil {
-			return nil, fmt.Errorf("failed to parse courier response: %w", err)
-		}
-
-		if !resp.Success {
-			return nil, fmt.Errorf("courier error: %s", resp.Message)
-		}
+	capture.Save("interrapidisimo_co", trackingNumber, body)
+	var resp interResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse courier response: %w", err)
+	}
 
-		return a.mapResponseToDomain(resp)
+	if !resp.Success {
+		return nil, fmt.Errorf("%w: courier error: %s", ports.ErrShipmentNotFound, resp.Message)
+	}
 
-	case <-ctx.Done():
-		return nil, fmt.Errorf("timeout waiting for courier response: %w", ctx.Err())
+	history, err := a.mapResponseToDomain(resp)
+	if err != nil {
+		return nil, err
 	}
+	// The search page takes no guide parameter, so customers are sent to it to enter the number
+	history.PublicURL = a.baseURL
+	return history, nil
 }
 
 // mapResponseToDomain converts Interrapidisimo response to domain structure.
@@ -187,13 +177,18 @@ func (a *InterrapidisimoAdapter) mapResponseToDomain(resp interResponse) (*domai
 		History:      make([]domain.TrackingEvent, 0),
 	}
 
-	for _, item := range resp.EstadosGuia {
+	location := effectiveLocation(a.location)
+	lastKnown := true
+	for i, item := range resp.EstadosGuia {
 		state := item.EstadoGuia
 
 		// Parse date
 		// Format example: "2025-05-10T13:06:23.02" or "2025-04-30T18:53:15.917"
-		// We try standard RFC3339-like layouts
-		date, _ := time.Parse("2006-01-02T15:04:05", state.FechaGrabacion) // Simplification, might need robust parsing
+		// Local time without an offset; the fractional seconds are accepted by the layout when parsing
+		date, err := time.ParseInLocation("2006-01-02T15:04:05", state.FechaGrabacion, location)
+		if err != nil {
+			history.Warnings = append(history.Warnings, fmt.Sprintf("event %d: invalid date %q", i, state.FechaGrabacion))
+		}
 
 		event := domain.TrackingEvent{
 			Date: date,
@@ -201,32 +196,64 @@ func (a *InterrapidisimoAdapter) mapResponseToDomain(resp interResponse) (*domai
 			City: state.Ciudad,
 			Code: strconv.Itoa(state.IdEstadoGuia),
 		}
+		if state.IdEstadoGuia == 11 {
+			event.ProofURL = resp.Guia.RutaImagenEntrega
+			event.SignedBy = resp.Guia.NombreRecibe
+		}
 		history.History = append(history.History, event)
+		// The latest state's description is the status Interrapidisimo shows
+		history.RawStatus = strings.TrimSpace(state.DescripcionEstadoGuia)
 
 		// Determine Global Status based on latest event or specific codes
-		// Code 10: RETURN
-		// Code 11: DELIVERED
-		switch state.IdEstadoGuia {
-		case 10:
-			history.GlobalStatus = domain.TrackingStatusReturn
-		case 11:
-			history.GlobalStatus = domain.TrackingStatusCompleted
-		case 7:
-			history.GlobalStatus = domain.TrackingStatusIncidence
+		status, isKnown := a.statusCodes.lookup(interDefaultCodes, event.Code)
+		if status != "" {
+			history.GlobalStatus = status
 		}
 
-		if !interKnownCodes[state.IdEstadoGuia] {
+		lastKnown = isKnown
+
+		if !isKnown {
 			a.logger.Warn("Unknown Interrapidisimo status code encountered",
+				zap.String("courier", "interrapidisimo_co"),
 				zap.Int("code", state.IdEstadoGuia),
 				zap.String("description", state.DescripcionEstadoGuia),
 			)
 		}
 	}
 
+	// An unmapped latest event means the real status is unknown, not processing
+	if !lastKnown {
+		history.GlobalStatus = domain.TrackingStatusUnknown
+	}
+
+	history.ProgressPct = progressPct(history, interProgressStages)
+
+	if len(history.Warnings) > 0 {
+		a.logger.Warn("Interrapidisimo response partially parsed",
+			zap.String("courier", "interrapidisimo_co"),
+			zap.Strings("warnings", history.Warnings),
+		)
+	}
+
 	return history, nil
 }
 
+// WithOverrides returns a copy of the adapter using the given per-call overrides.
+func (a *InterrapidisimoAdapter) WithOverrides(overrides ports.Overrides) ports.TrackingProvider {
+	clone := *a
+	if overrides.BaseURL != "" {
+		clone.baseURL = overrides.BaseURL
+	}
+	clone.authorization = overrides.Authorization
+	return &clone
+}
+
 // SupportsCourier returns true if this adapter supports interrapidisimo_co.
 func (a *InterrapidisimoAdapter) SupportsCourier(courierName string) bool {
 	return courierName == "interrapidisimo_co"
 }
+
+// Couriers returns interrapidisimo_co.
+func (a *InterrapidisimoAdapter) Couriers() []string {
+	return []string{"interrapidisimo_co"}
+}
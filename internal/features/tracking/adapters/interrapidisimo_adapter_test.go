@@ -61,9 +61,9 @@ func TestInterrapidisimoAdapter_mapResponseToDomain_Success(t *testing.T) {
 	assert.Equal(t, "Recibimos tú envío", history.History[0].Text)
 	assert.Equal(t, "BOGOTA\\CUND\\COL", history.History[0].City)
 
-	// Verify date parsing (approximate check due to time zones if not handled strictly)
-	expectedDate, _ := time.Parse("2006-01-02T15:04:05", "2025-04-30T18:53:15.917")
-	assert.WithinDuration(t, expectedDate, history.History[0].Date, time.Second)
+	// Dates carry no offset and are read as Colombia local time by default
+	expectedDate, _ := time.ParseInLocation("2006-01-02T15:04:05", "2025-04-30T18:53:15.917", bogotaLocation)
+	assert.True(t, expectedDate.Equal(history.History[0].Date))
 
 	// Verify delivery event
 	assert.Equal(t, "11", history.History[1].Code)
@@ -113,3 +113,151 @@ func TestInterrapidisimoAdapter_mapResponseToDomain_Return(t *testing.T) {
 	assert.Equal(t, "10", history.History[1].Code)
 	assert.Equal(t, "Tu envío Fue devuelto", history.History[1].Text)
 }
+
+// TestInterrapidisimoAdapter_mapResponseToDomain_DeliveryProof verifies proof fields on the delivered event.
+func TestInterrapidisimoAdapter_mapResponseToDomain_DeliveryProof(t *testing.T) {
+	jsonContent := `{
+    "EstadosGuia": [
+        {
+            "EstadoGuia": {
+                "IdEstadoGuia": 6,
+                "DescripcionEstadoGuia": "En camino hacia ti",
+                "Ciudad": "MEDELLIN",
+                "FechaGrabacion": "2025-05-02T08:10:00.12"
+            }
+        },
+        {
+            "EstadoGuia": {
+                "IdEstadoGuia": 11,
+                "DescripcionEstadoGuia": "Tú envío fue entregado",
+                "Ciudad": "MEDELLIN",
+                "FechaGrabacion": "2025-05-02T15:44:21.5"
+            }
+        }
+    ],
+    "Guia": {
+        "NumeroGuia": 240041234567,
+        "RutaImagenEntrega": "https://www3.interrapidisimo.com/pruebas/240041234567.png",
+        "NombreRecibe": "CARLOS GOMEZ"
+    },
+    "Success": true,
+    "Message": "Consulta exitosa"
+}`
+
+	var resp interResponse
+	err := json.Unmarshal([]byte(jsonContent), &resp)
+	require.NoError(t, err)
+
+	adapter := &InterrapidisimoAdapter{
+		logger: zap.NewNop(),
+	}
+	history, err := adapter.mapResponseToDomain(resp)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.TrackingStatusCompleted, history.GlobalStatus)
+	require.Len(t, history.History, 2)
+	assert.Empty(t, history.History[0].ProofURL)
+	assert.Equal(t, "https://www3.interrapidisimo.com/pruebas/240041234567.png", history.History[1].ProofURL)
+	assert.Equal(t, "CARLOS GOMEZ", history.History[1].SignedBy)
+}
+
+// TestInterrapidisimoAdapter_mapResponseToDomain_UnknownLatestCode verifies only the latest event decides UNKNOWN.
+func TestInterrapidisimoAdapter_mapResponseToDomain_UnknownLatestCode(t *testing.T) {
+	adapter := &InterrapidisimoAdapter{
+		logger: zap.NewNop(),
+	}
+
+	var unknownLast interResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"EstadosGuia": [
+        {"EstadoGuia": {"IdEstadoGuia": 1}},
+        {"EstadoGuia": {"IdEstadoGuia": 42}}
+    ]}`), &unknownLast))
+	history, err := adapter.mapResponseToDomain(unknownLast)
+	require.NoError(t, err)
+	assert.Equal(t, domain.TrackingStatusUnknown, history.GlobalStatus)
+	assert.Len(t, history.History, 2)
+
+	var unknownEarlier interResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"EstadosGuia": [
+        {"EstadoGuia": {"IdEstadoGuia": 42}},
+        {"EstadoGuia": {"IdEstadoGuia": 6}}
+    ]}`), &unknownEarlier))
+	history, err = adapter.mapResponseToDomain(unknownEarlier)
+	require.NoError(t, err)
+	assert.Equal(t, domain.TrackingStatusProcessing, history.GlobalStatus)
+}
+
+// TestInterrapidisimoAdapter_mapResponseToDomain_PartialParse verifies malformed dates are kept and reported as warnings.
+func TestInterrapidisimoAdapter_mapResponseToDomain_PartialParse(t *testing.T) {
+	adapter := &InterrapidisimoAdapter{
+		logger: zap.NewNop(),
+	}
+
+	var resp interResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"EstadosGuia": [
+        {"EstadoGuia": {"IdEstadoGuia": 1, "FechaGrabacion": "2025-05-10T13:06:23.02"}},
+        {"EstadoGuia": {"IdEstadoGuia": 6, "FechaGrabacion": "10/05/2025"}}
+    ]}`), &resp))
+
+	history, err := adapter.mapResponseToDomain(resp)
+
+	require.NoError(t, err)
+	require.Len(t, history.History, 2)
+	assert.False(t, history.History[0].Date.IsZero())
+	assert.True(t, history.History[1].Date.IsZero())
+	assert.Equal(t, []string{`event 1: invalid date "10/05/2025"`}, history.Warnings)
+}
+
+// TestInterrapidisimoAdapter_normalizeTrackingNumber verifies pasted numbers are reduced to the numeric NumeroGuia.
+func TestInterrapidisimoAdapter_normalizeTrackingNumber(t *testing.T) {
+	adapter := &InterrapidisimoAdapter{}
+
+	tests := map[string]string{
+		"240041234567":      "240041234567",
+		" 240041234567 ":    "240041234567",
+		"000240041234567":   "240041234567",
+		"2400 4123 4567":    "240041234567",
+		"000":               "000",
+		"no tengo la guía ": "no tengo la guía",
+	}
+	for raw, want := range tests {
+		assert.Equal(t, want, adapter.normalizeTrackingNumber(raw), raw)
+	}
+}
+
+// TestInterrapidisimoAdapter_mapResponseToDomain_RawStatus verifies the latest state description is kept as the raw status.
+func TestInterrapidisimoAdapter_mapResponseToDomain_RawStatus(t *testing.T) {
+	adapter := &InterrapidisimoAdapter{logger: zap.NewNop()}
+
+	var resp interResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"EstadosGuia": [
+		{"EstadoGuia": {"IdEstadoGuia": 1, "DescripcionEstadoGuia": "Recibimos tú envío", "FechaGrabacion": "2025-04-30T18:53:15.917"}},
+		{"EstadoGuia": {"IdEstadoGuia": 6, "DescripcionEstadoGuia": "En camino hacia ti ", "FechaGrabacion": "2025-05-02T09:10:00"}}
+	]}`), &resp))
+
+	history, err := adapter.mapResponseToDomain(resp)
+	require.NoError(t, err)
+	assert.Equal(t, "En camino hacia ti", history.RawStatus)
+
+	history, err = adapter.mapResponseToDomain(interResponse{})
+	require.NoError(t, err)
+	assert.Empty(t, history.RawStatus)
+}
+
+// TestInterrapidisimoAdapter_mapResponseToDomain_Timezone verifies dates are read in the configured location.
+func TestInterrapidisimoAdapter_mapResponseToDomain_Timezone(t *testing.T) {
+	var resp interResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"EstadosGuia": [
+		{"EstadoGuia": {"IdEstadoGuia": 1, "FechaGrabacion": "2025-04-30T18:53:15.917"}}
+	]}`), &resp))
+
+	adapter := &InterrapidisimoAdapter{logger: zap.NewNop()}
+	history, err := adapter.mapResponseToDomain(resp)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 4, 30, 23, 53, 15, 917000000, time.UTC), history.History[0].Date.UTC())
+
+	adapter.SetLocation(time.UTC)
+	history, err = adapter.mapResponseToDomain(resp)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 4, 30, 18, 53, 15, 917000000, time.UTC), history.History[0].Date)
+}
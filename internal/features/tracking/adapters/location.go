@@ -0,0 +1,25 @@
+package adapter
+
+import "time"
+
+// bogotaLocation is the timezone the Colombian couriers report their timestamps in, used when an
+// adapter has no location configured. Colombia has no DST, so a fixed UTC-5 zone is used if tzdata
+// is unavailable.
+var bogotaLocation = loadBogotaLocation()
+
+// loadBogotaLocation returns the America/Bogota location, falling back to a fixed UTC-5 zone.
+func loadBogotaLocation() *time.Location {
+	loc, err := time.LoadLocation("America/Bogota")
+	if err != nil {
+		return time.FixedZone("COT", -5*60*60)
+	}
+	return loc
+}
+
+// effectiveLocation returns loc, or bogotaLocation when it is nil.
+func effectiveLocation(loc *time.Location) *time.Location {
+	if loc == nil {
+		return bogotaLocation
+	}
+	return loc
+}
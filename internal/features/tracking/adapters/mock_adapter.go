@@ -0,0 +1,91 @@
+package adapter
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"tracker-scrapper/internal/features/tracking/domain"
+)
+
+// mockShippedAt anchors every canned history so responses are identical across runs.
+var mockShippedAt = time.Date(2024, time.January, 2, 9, 30, 0, 0, bogotaLocation)
+
+// mockProgressStages maps the canned event codes to the shipment's progress.
+var mockProgressStages = progressStages{
+	"mock_received":   10,
+	"mock_in_transit": 50,
+	"mock_delivered":  100,
+}
+
+// MockCourierAdapter returns canned tracking histories without contacting any courier.
+// It is meant for local development where real scrapes are blocked.
+// The last digit of the tracking number picks the outcome:
+//   - 1: delivered (COMPLETED)
+//   - 2: returned to sender (RETURN)
+//   - 3: delivery incidence (INCIDENCE)
+//   - anything else: in transit (PROCESSING)
+type MockCourierAdapter struct {
+	couriers []string
+}
+
+// NewMockCourierAdapter creates a MockCourierAdapter that supports the given couriers.
+// No couriers means every courier in Couriers.
+func NewMockCourierAdapter(couriers ...string) *MockCourierAdapter {
+	if len(couriers) == 0 {
+		couriers = Couriers
+	}
+	return &MockCourierAdapter{couriers: couriers}
+}
+
+// GetTrackingHistory returns the canned history selected by the tracking number suffix.
+func (a *MockCourierAdapter) GetTrackingHistory(trackingNumber string) (*domain.TrackingHistory, error) {
+	if trackingNumber == "" {
+		return nil, errors.New("tracking number is required")
+	}
+
+	events := []domain.TrackingEvent{
+		{Date: mockShippedAt, Text: "ENVIO RECIBIDO", City: "BOGOTA", Code: "mock_received"},
+		{Date: mockShippedAt.Add(6 * time.Hour), Text: "EN TRANSPORTE", City: "BOGOTA", Code: "mock_in_transit"},
+	}
+	history := &domain.TrackingHistory{
+		GlobalStatus: domain.TrackingStatusProcessing,
+		ShippedAt:    mockShippedAt,
+	}
+
+	last := mockShippedAt.Add(30 * time.Hour)
+	switch {
+	case strings.HasSuffix(trackingNumber, "1"):
+		history.GlobalStatus = domain.TrackingStatusCompleted
+		history.DeliveredAt = last
+		events = append(events, domain.TrackingEvent{
+			Date:     last,
+			Text:     "ENTREGADO",
+			City:     "MEDELLIN",
+			Code:     "mock_delivered",
+			ProofURL: "https://example.com/proof/" + trackingNumber + ".jpg",
+			SignedBy: "MOCK RECEIVER",
+		})
+	case strings.HasSuffix(trackingNumber, "2"):
+		history.GlobalStatus = domain.TrackingStatusReturn
+		events = append(events, domain.TrackingEvent{Date: last, Text: "DEVUELTO AL REMITENTE", City: "BOGOTA", Code: "mock_returned"})
+	case strings.HasSuffix(trackingNumber, "3"):
+		history.GlobalStatus = domain.TrackingStatusIncidence
+		events = append(events, domain.TrackingEvent{Date: last, Text: "NOVEDAD: DESTINATARIO AUSENTE", City: "MEDELLIN", Code: "mock_incidence"})
+	}
+
+	history.History = events
+	history.ProgressPct = progressPct(history, mockProgressStages)
+	return history, nil
+}
+
+// SupportsCourier returns true if courierName is one of the couriers the adapter stands in for.
+func (a *MockCourierAdapter) SupportsCourier(courierName string) bool {
+	return slices.Contains(a.couriers, courierName)
+}
+
+// Couriers returns the couriers the adapter stands in for.
+func (a *MockCourierAdapter) Couriers() []string {
+	return a.couriers
+}
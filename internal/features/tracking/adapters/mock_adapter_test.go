@@ -0,0 +1,62 @@
+package adapter
+
+import (
+	"testing"
+
+	"tracker-scrapper/internal/features/tracking/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMockCourierAdapter_GetTrackingHistory verifies the tracking number suffix selects the outcome.
+func TestMockCourierAdapter_GetTrackingHistory(t *testing.T) {
+	tests := []struct {
+		trackingNumber string
+		expected       domain.TrackingStatus
+		progress       int
+	}{
+		{"1000001", domain.TrackingStatusCompleted, 100},
+		{"1000002", domain.TrackingStatusReturn, 50},
+		{"1000003", domain.TrackingStatusIncidence, 50},
+		{"1000004", domain.TrackingStatusProcessing, 50},
+	}
+
+	adapter := NewMockCourierAdapter()
+	for _, tt := range tests {
+		t.Run(tt.trackingNumber, func(t *testing.T) {
+			history, err := adapter.GetTrackingHistory(tt.trackingNumber)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, history.GlobalStatus)
+			assert.Equal(t, tt.progress, history.ProgressPct)
+			assert.NotEmpty(t, history.History)
+			assert.Equal(t, tt.expected == domain.TrackingStatusCompleted, !history.DeliveredAt.IsZero())
+		})
+	}
+}
+
+// TestMockCourierAdapter_Deterministic verifies repeated lookups return the same history.
+func TestMockCourierAdapter_Deterministic(t *testing.T) {
+	adapter := NewMockCourierAdapter()
+
+	first, err := adapter.GetTrackingHistory("2200000001")
+	require.NoError(t, err)
+	second, err := adapter.GetTrackingHistory("2200000001")
+	require.NoError(t, err)
+
+	assert.Equal(t, first, second)
+}
+
+// TestMockCourierAdapter_SupportsCourier verifies the default and explicit courier lists.
+func TestMockCourierAdapter_SupportsCourier(t *testing.T) {
+	assert.True(t, NewMockCourierAdapter().SupportsCourier("servientrega_co"))
+	assert.False(t, NewMockCourierAdapter().SupportsCourier("unknown_co"))
+	assert.False(t, NewMockCourierAdapter("coordinadora_co").SupportsCourier("servientrega_co"))
+}
+
+// TestMockCourierAdapter_Couriers verifies the adapter registers under every courier it stands in for.
+func TestMockCourierAdapter_Couriers(t *testing.T) {
+	assert.Equal(t, Couriers, NewMockCourierAdapter().Couriers())
+	assert.Equal(t, []string{"coordinadora_co"}, NewMockCourierAdapter("coordinadora_co").Couriers())
+}
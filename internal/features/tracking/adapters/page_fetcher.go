@@ -0,0 +1,491 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"tracker-scrapper/internal/core/logger"
+	"tracker-scrapper/internal/core/metrics"
+	"tracker-scrapper/internal/core/proxy"
+	"tracker-scrapper/internal/core/scraper"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/launcher/flags"
+	"github.com/go-rod/rod/lib/proto"
+	"go.uber.org/zap"
+)
+
+// ErrFetcherClosed is returned by RodFetcher.Fetch once the fetcher has been closed.
+var ErrFetcherClosed = errors.New("page fetcher closed")
+
+// PageFetcher opens a courier page and returns the body of the courier API response it triggers.
+// Adapters depend on this interface so their parsing can be tested without a browser.
+type PageFetcher interface {
+	// Fetch navigates to req.URL and returns the body of the first request matching req.Pattern.
+	// It returns when a body is available or ctx is done.
+	Fetch(ctx context.Context, req FetchRequest) ([]byte, error)
+}
+
+// FetchRequest describes a page visit and the API call to intercept.
+type FetchRequest struct {
+	// Courier names the courier being scraped, for logs and metrics.
+	Courier string
+	// URL is the page to open.
+	URL string
+	// Pattern is the hijack URL pattern of the courier API call (e.g., "*/api/Tracking*").
+	Pattern string
+	// ResourceType restricts the hijack to a resource type. Empty matches any type.
+	ResourceType proto.NetworkResourceType
+	// Proxy holds the proxy settings for the browser.
+	Proxy proxy.Settings
+	// ProxyDomains lists the domains routed through the proxy forwarder.
+	ProxyDomains []string
+	// Authorization is sent on the intercepted request when non-empty.
+	Authorization string
+	// Form, when set, is filled and submitted after navigation to trigger the API call.
+	Form *FormInput
+	// Reload reports whether a body should be discarded and the page reloaded.
+	Reload func(body []byte) bool
+	// MaxReloads bounds the number of reloads triggered by Reload.
+	MaxReloads int
+	// UserAgent is the user agent for this scrape. Empty uses the fetcher's pinned user agent or a random one.
+	UserAgent string
+	// ExtraPatterns lists further courier API calls whose responses complete the one matching Pattern.
+	// Fetch waits for one response to each, up to ExtraWait after the Pattern response (zero waits
+	// until ctx is done), and returns without the ones still missing.
+	ExtraPatterns []string
+	// ExtraWait bounds the wait for ExtraPatterns responses once the Pattern response arrived.
+	ExtraWait time.Duration
+	// OnExtras receives the ExtraPatterns responses that arrived, keyed by pattern, before Fetch returns.
+	OnExtras func(extras map[string][]byte)
+}
+
+// interceptedBody is the body of a hijacked courier API response and the pattern it matched.
+type interceptedBody struct {
+	pattern string
+	body    []byte
+}
+
+// FormInput describes a search form to fill in on the courier page.
+type FormInput struct {
+	// InputSelector is the CSS selector of the text input.
+	InputSelector string
+	// Value is typed into the input.
+	Value string
+	// SubmitSelector is the CSS selector of the element clicked to submit.
+	SubmitSelector string
+}
+
+// limitedFetcher is a PageFetcher that holds a limiter slot for the duration of every fetch.
+type limitedFetcher struct {
+	PageFetcher
+	limiter *scraper.Limiter
+}
+
+// WithLimiter wraps fetcher so at most the limiter's number of fetches run at once.
+// A fetch that can't get a slot before ctx is done fails with scraper.ErrCourierBusy.
+func WithLimiter(fetcher PageFetcher, limiter *scraper.Limiter) PageFetcher {
+	return &limitedFetcher{PageFetcher: fetcher, limiter: limiter}
+}
+
+// Close closes the wrapped fetcher when it holds resources.
+func (f *limitedFetcher) Close() error {
+	return closeIfCloser(f.PageFetcher)
+}
+
+// Fetch implements PageFetcher.
+func (f *limitedFetcher) Fetch(ctx context.Context, req FetchRequest) ([]byte, error) {
+	release, err := f.limiter.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return f.PageFetcher.Fetch(ctx, req)
+}
+
+// closeIfCloser closes v when it implements io.Closer.
+func closeIfCloser(v any) error {
+	if c, ok := v.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// RodFetcher implements PageFetcher with a headless Chromium driven by go-rod.
+type RodFetcher struct {
+	logger *zap.Logger
+	// stealth is applied to every page, so all couriers share the same evasion settings.
+	stealth scraper.Stealth
+	// browserBin is the Chromium binary to launch. Empty auto-detects an installed browser.
+	browserBin string
+	// navigationRetries and navigationDelay bound navigation attempts, so a network blip doesn't fail the scrape.
+	navigationRetries int
+	navigationDelay   time.Duration
+	// interceptTimeout bounds the wait for the API call once the page loaded. Zero waits for ctx.
+	interceptTimeout time.Duration
+	// closed is cancelled by Close; it aborts running fetches and rejects new ones.
+	closed context.Context
+	cancel context.CancelFunc
+}
+
+// NewRodFetcher creates a new RodFetcher that applies stealth to every page it opens.
+// browserBin is the Chromium binary path; empty auto-detects it (rod downloads one if none is installed).
+// Failed navigations are attempted up to navigationRetries times, navigationDelay apart. A page that loads
+// but doesn't call the courier API within interceptTimeout (zero disables it) fails with
+// scraper.ErrCourierLayoutChanged.
+func NewRodFetcher(stealth scraper.Stealth, browserBin string, navigationRetries int, navigationDelay, interceptTimeout time.Duration) *RodFetcher {
+	closed, cancel := context.WithCancel(context.Background())
+	return &RodFetcher{
+		logger:            logger.Get(),
+		stealth:           stealth,
+		browserBin:        browserBin,
+		navigationRetries: navigationRetries,
+		navigationDelay:   navigationDelay,
+		interceptTimeout:  interceptTimeout,
+		closed:            closed,
+		cancel:            cancel,
+	}
+}
+
+// Close aborts running fetches, which kills their browsers, and makes later fetches fail
+// with ErrFetcherClosed. It is safe to call more than once.
+func (f *RodFetcher) Close() error {
+	f.cancel()
+	return nil
+}
+
+// lookPath finds an installed Chrome or Chromium; replaced in tests.
+var lookPath = launcher.LookPath
+
+// newLauncher builds the browser launcher shared by every courier.
+// userAgent is applied when stealth is enabled.
+func (f *RodFetcher) newLauncher(ctx context.Context, proxyAddr, userAgent string) *launcher.Launcher {
+	// Use Context(ctx) to ensure launch respects timeout; Docker needs --no-sandbox
+	l := launcher.New().
+		Context(ctx).
+		Headless(true).
+		NoSandbox(true)
+
+	if bin := f.resolveBrowserBin(l.Get(flags.Bin)); bin != "" {
+		l = l.Bin(bin)
+		f.logger.Debug("Using browser binary", zap.String("bin", bin))
+	} else {
+		f.logger.Debug("No browser binary found, rod will download one")
+	}
+
+	if f.stealth.Enabled {
+		l = l.Set("user-agent", userAgent)
+	}
+
+	// Configure proxy - use local forwarder address (no auth needed)
+	if proxyAddr != "" {
+		l = l.Proxy(proxyAddr)
+		f.logger.Debug("Browser configured with proxy", zap.String("proxy", proxyAddr))
+	}
+	return l
+}
+
+// resolveBrowserBin picks the browser binary: the configured path, then the launcher default
+// (the -rod=bin flag), then an installed browser. Empty means none was found.
+func (f *RodFetcher) resolveBrowserBin(launcherDefault string) string {
+	if f.browserBin != "" {
+		return f.browserBin
+	}
+	if launcherDefault != "" {
+		return launcherDefault
+	}
+	if path, ok := lookPath(); ok {
+		return path
+	}
+	return ""
+}
+
+// Fetch launches a browser, hijacks req.Pattern and req.ExtraPatterns and returns the body intercepted
+// for req.Pattern, handing the others to req.OnExtras.
+func (f *RodFetcher) Fetch(ctx context.Context, req FetchRequest) ([]byte, error) {
+	if f.closed.Err() != nil {
+		return nil, ErrFetcherClosed
+	}
+	// Closing the fetcher cancels the fetch so the launcher kills the browser
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer context.AfterFunc(f.closed, cancel)()
+
+	// Start local proxy forwarder if proxy is configured with credentials
+	// This solves Chromium's limitation of not supporting proxy auth via command line
+	var localProxyAddr string
+	if req.Proxy.HasProxy() && req.Proxy.Username != "" && req.Proxy.Password != "" {
+		proxyForwarder, err := proxy.NewForwardingProxy(req.Proxy.FullURL(), req.ProxyDomains...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create proxy forwarder: %w", err)
+		}
+		if pool := req.Proxy.Pool; pool != nil {
+			// Bench the pooled endpoint as soon as it fails to tunnel
+			proxyForwarder.OnTunnelError = func(error) { pool.Bench(req.Proxy) }
+		}
+		localProxyAddr, err = proxyForwarder.Start(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start proxy forwarder: %w", err)
+		}
+		defer proxyForwarder.Stop()
+		f.logger.Debug("Local proxy forwarder started", zap.String("local_addr", localProxyAddr))
+	} else if req.Proxy.HasProxy() {
+		// Proxy without credentials (IP whitelist mode)
+		localProxyAddr = req.Proxy.HostPort()
+	}
+
+	f.logger.Debug("Launching browser...",
+		zap.Bool("proxy_enabled", req.Proxy.HasProxy()),
+		zap.String("proxy_addr", localProxyAddr),
+	)
+
+	// One user agent per scrape, so the launcher flag and the page override agree
+	stealth := f.stealth
+	if req.UserAgent != "" {
+		stealth.UserAgent = req.UserAgent
+	}
+	stealth.UserAgent = stealth.EffectiveUserAgent()
+
+	u, err := f.newLauncher(ctx, localProxyAddr, stealth.UserAgent).Launch()
+	if err != nil {
+		return nil, fmt.Errorf("failed to launch browser: %w", err)
+	}
+
+	browser := rod.New().Context(ctx).ControlURL(u)
+	if err := browser.Connect(); err != nil {
+		return nil, fmt.Errorf("failed to connect to browser: %w", err)
+	}
+	defer browser.Close()
+
+	// Page expects proto.TargetCreateTarget in this version of rod
+	page, err := browser.Page(proto.TargetCreateTarget{URL: ""})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create page: %w", err)
+	}
+	page = page.Context(ctx)
+
+	if err := scraper.ApplyStealth(page, stealth); err != nil {
+		f.logger.Warn("Failed to apply stealth settings", zap.Error(err))
+	}
+
+	router := page.HijackRequests()
+	defer router.Stop()
+
+	done := make(chan interceptedBody)
+	for _, pattern := range append([]string{req.Pattern}, req.ExtraPatterns...) {
+		if err := router.Add(pattern, req.ResourceType, f.hijack(ctx, req, pattern, localProxyAddr, done)); err != nil {
+			return nil, fmt.Errorf("failed to add hijack: %w", err)
+		}
+	}
+
+	go router.Run()
+
+	navErr := f.navigate(page, req)
+	// No API call follows a search form that couldn't be filled in
+	if errors.Is(navErr, scraper.ErrCourierLayoutChanged) {
+		f.reportLayoutChange(req, navErr)
+		return nil, navErr
+	}
+
+	// A loaded page that never calls the API most likely no longer matches req.Pattern
+	var interceptTimer *time.Timer
+	var intercepted <-chan time.Time
+	if navErr == nil && f.interceptTimeout > 0 {
+		interceptTimer = time.NewTimer(f.interceptTimeout)
+		defer interceptTimer.Stop()
+		intercepted = interceptTimer.C
+	}
+
+	// Wait for the Pattern response, then for the extra responses still missing
+	var body []byte
+	accepted, reloads := false, 0
+	extras := make(map[string][]byte, len(req.ExtraPatterns))
+	var extraWait <-chan time.Time
+	for {
+		select {
+		case got := <-done:
+			switch {
+			case got.pattern != req.Pattern:
+				// Keep the first response to each extra pattern
+				if _, ok := extras[got.pattern]; !ok {
+					extras[got.pattern] = got.body
+				}
+			case accepted:
+				// A repeated call after the response was accepted is ignored
+			case req.Reload != nil && reloads < req.MaxReloads && req.Reload(got.body):
+				reloads++
+				f.logger.Warn("Discarding courier response, reloading page",
+					zap.Int("attempt", reloads),
+					zap.Int("max_reloads", req.MaxReloads),
+				)
+				// The reloaded page calls every API again
+				clear(extras)
+				if err := page.Reload(); err != nil {
+					return nil, fmt.Errorf("failed to reload page: %w", err)
+				}
+				if interceptTimer != nil {
+					interceptTimer.Reset(f.interceptTimeout)
+				}
+				continue
+			default:
+				body, accepted = got.body, true
+				intercepted = nil
+				if req.ExtraWait > 0 && len(extras) < len(req.ExtraPatterns) {
+					extraTimer := time.NewTimer(req.ExtraWait)
+					defer extraTimer.Stop()
+					extraWait = extraTimer.C
+				}
+			}
+			if accepted && len(extras) == len(req.ExtraPatterns) {
+				return handExtras(req, body, extras), nil
+			}
+
+		case <-extraWait:
+			f.logger.Debug("Extra courier responses missing, continuing without them",
+				zap.String("courier", req.Courier),
+				zap.Int("expected", len(req.ExtraPatterns)),
+				zap.Int("received", len(extras)),
+			)
+			return handExtras(req, body, extras), nil
+
+		case <-intercepted:
+			err := fmt.Errorf("%w: no request matching %s within %s", scraper.ErrCourierLayoutChanged, req.Pattern, f.interceptTimeout)
+			f.reportLayoutChange(req, err)
+			return nil, err
+
+		case <-ctx.Done():
+			// Extra responses are best effort once the main one arrived
+			if accepted {
+				return handExtras(req, body, extras), nil
+			}
+			if navErr != nil {
+				// Report navigation error as root cause
+				return nil, fmt.Errorf("navigation failed after retries: %w", navErr)
+			}
+			return nil, fmt.Errorf("timeout waiting for courier response: %w", ctx.Err())
+		}
+	}
+}
+
+// hijack returns the handler of requests matching pattern: it replays the request, through the local
+// proxy forwarder when there is one, and hands the response body to done.
+func (f *RodFetcher) hijack(ctx context.Context, req FetchRequest, pattern, localProxyAddr string, done chan<- interceptedBody) func(*rod.Hijack) {
+	return func(h *rod.Hijack) {
+		f.logger.Debug("Intercepted courier request", zap.String("pattern", pattern))
+
+		// Create proxy-aware client if proxy is used
+		client := http.DefaultClient
+		if localProxyAddr != "" {
+			proxyURL, err := url.Parse(localProxyAddr)
+			if err != nil {
+				f.logger.Error("Failed to parse local proxy URL", zap.Error(err))
+			} else {
+				client = &http.Client{
+					Transport: &http.Transport{
+						Proxy: http.ProxyURL(proxyURL),
+					},
+					Timeout: 30 * time.Second,
+				}
+			}
+		}
+
+		if req.Authorization != "" {
+			h.Request.Req().Header.Set("Authorization", req.Authorization)
+		}
+
+		if err := h.LoadResponse(client, true); err != nil {
+			f.logger.Error("Failed to load response", zap.Error(err))
+			return
+		}
+
+		deliver(ctx, done, interceptedBody{pattern: pattern, body: []byte(h.Response.Body())})
+	}
+}
+
+// handExtras passes the extra responses to req.OnExtras, when set, and returns body.
+func handExtras(req FetchRequest, body []byte, extras map[string][]byte) []byte {
+	if req.OnExtras != nil && len(req.ExtraPatterns) > 0 {
+		req.OnExtras(extras)
+	}
+	return body
+}
+
+// reportLayoutChange logs err at error level and counts it, so a courier redesign can be alerted on
+// before failed scrapes pile up.
+func (f *RodFetcher) reportLayoutChange(req FetchRequest, err error) {
+	metrics.LayoutChanged(req.Courier)
+	f.logger.Error("Courier page layout changed",
+		zap.String("courier", req.Courier),
+		zap.String("url", req.URL),
+		zap.String("pattern", req.Pattern),
+		zap.Error(err),
+	)
+}
+
+// deliver hands body to the goroutine waiting in Fetch, giving up once ctx is done so a
+// response intercepted after Fetch returned doesn't block the hijack handler forever.
+// It reports whether body was received.
+func deliver[T any](ctx context.Context, done chan<- T, body T) bool {
+	select {
+	case done <- body:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// navigate opens req.URL with retries and submits req.Form when set. Form elements missing when
+// the lookup deadline expires, or a panic while driving the form, are reported as
+// scraper.ErrCourierLayoutChanged.
+func (f *RodFetcher) navigate(page *rod.Page, req FetchRequest) error {
+	navErr := scraper.NavigateWithRetry(page, req.URL, f.navigationRetries, f.navigationDelay)
+	if navErr != nil || req.Form == nil {
+		return navErr
+	}
+
+	var formErr error
+	if err := rod.Try(func() { formErr = submitForm(page, req.Form) }); err != nil {
+		return fmt.Errorf("%w: search form: %v", scraper.ErrCourierLayoutChanged, err)
+	}
+	return formErr
+}
+
+// submitForm types form.Value into the search input and clicks the submit button.
+func submitForm(page *rod.Page, form *FormInput) error {
+	input, err := page.Element(form.InputSelector)
+	if err != nil {
+		return layoutError("search input", form.InputSelector, err)
+	}
+	if err := input.WaitVisible(); err != nil {
+		return layoutError("search input", form.InputSelector, err)
+	}
+	if err := input.Input(form.Value); err != nil {
+		return fmt.Errorf("failed to type tracking number: %w", err)
+	}
+
+	submit, err := page.Element(form.SubmitSelector)
+	if err != nil {
+		return layoutError("search button", form.SubmitSelector, err)
+	}
+	if err := submit.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		return fmt.Errorf("failed to submit search: %w", err)
+	}
+	return nil
+}
+
+// layoutError reports that the element matching selector didn't show up before err ended the wait.
+// Rod keeps polling for elements until the page context is done, so a deadline means the element never
+// appeared; the deadline is not wrapped, so the lookup isn't reported as a plain timeout. A cancelled
+// fetch is returned as is.
+func layoutError(element, selector string, err error) error {
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+	return fmt.Errorf("%w: %s %s not found: %v", scraper.ErrCourierLayoutChanged, element, selector, err)
+}
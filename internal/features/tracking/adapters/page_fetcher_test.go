@@ -0,0 +1,500 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"tracker-scrapper/internal/core/proxy"
+	"tracker-scrapper/internal/core/scraper"
+	"tracker-scrapper/internal/core/useragent"
+	"tracker-scrapper/internal/features/tracking/domain"
+	"tracker-scrapper/internal/features/tracking/ports"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeFetcher is a PageFetcher that returns canned bodies in order without launching a browser.
+type fakeFetcher struct {
+	bodies []string
+	err    error
+	// extras are handed to OnExtras, keyed by pattern.
+	extras map[string]string
+	// requests records every FetchRequest received.
+	requests []FetchRequest
+}
+
+// Fetch implements PageFetcher, honouring Reload the same way RodFetcher does.
+func (f *fakeFetcher) Fetch(ctx context.Context, req FetchRequest) ([]byte, error) {
+	f.requests = append(f.requests, req)
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	for i, body := range f.bodies {
+		last := i == len(f.bodies)-1
+		if !last && req.Reload != nil && i < req.MaxReloads && req.Reload([]byte(body)) {
+			continue
+		}
+		if req.OnExtras != nil {
+			extras := map[string][]byte{}
+			for pattern, extra := range f.extras {
+				extras[pattern] = []byte(extra)
+			}
+			req.OnExtras(extras)
+		}
+		return []byte(body), nil
+	}
+	return nil, errors.New("no canned body")
+}
+
+// TestCoordinadoraAdapter_GetTrackingHistory_FakeFetcher verifies the scrape flow with a canned response.
+func TestCoordinadoraAdapter_GetTrackingHistory_FakeFetcher(t *testing.T) {
+	fetcher := &fakeFetcher{bodies: []string{`{"history": [{"code": "6", "date": "2024-01-03 13:58:00", "description": "ENTREGADA"}]}`}}
+	settings := proxy.Settings{AllowedDomains: []string{"coordinadora.com"}}
+	adapter := NewCoordinadoraAdapter("https://coordinadora.com/rastreo/?guia=", settings, nil, 0, fetcher)
+
+	history, err := adapter.GetTrackingHistory(" 0433-3004-120 ")
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.TrackingStatusCompleted, history.GlobalStatus)
+	require.Len(t, fetcher.requests, 1)
+	assert.Equal(t, "https://coordinadora.com/rastreo/?guia=04333004120", fetcher.requests[0].URL)
+	assert.Equal(t, "https://coordinadora.com/rastreo/?guia=04333004120", history.PublicURL)
+	assert.Equal(t, "*/wp-json/rgc/v1/detail_tracking*", fetcher.requests[0].Pattern)
+	assert.Equal(t, []string{"coordinadora.com"}, fetcher.requests[0].ProxyDomains)
+}
+
+// TestCoordinadoraAdapter_GetTrackingHistory_StatusResponse verifies events only in the status response are merged in.
+func TestCoordinadoraAdapter_GetTrackingHistory_StatusResponse(t *testing.T) {
+	fetcher := &fakeFetcher{
+		bodies: []string{`{"history": [{"code": "2", "date": "2024-01-01 08:00:00", "description": "EN TERMINAL ORIGEN"}]}`},
+		extras: map[string]string{coordStatusPattern: `{"history": [
+			{"code": "2", "date": "2024-01-01 08:00:00", "description": "EN TERMINAL ORIGEN"},
+			{"code": "6", "date": "2024-01-03 13:58:00", "description": "ENTREGADA"}
+		]}`},
+	}
+	adapter := NewCoordinadoraAdapter("https://coordinadora.com/rastreo/?guia=", proxy.Settings{}, nil, 0, fetcher)
+
+	history, err := adapter.GetTrackingHistory("04333004120")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{coordStatusPattern}, fetcher.requests[0].ExtraPatterns)
+	assert.Equal(t, coordStatusWait, fetcher.requests[0].ExtraWait)
+	assert.Equal(t, domain.TrackingStatusCompleted, history.GlobalStatus)
+	require.Len(t, history.History, 2)
+	assert.Equal(t, "6", history.History[1].Code)
+}
+
+// TestInterrapidisimoAdapter_GetTrackingHistory_FakeFetcher verifies the search form and courier errors.
+func TestInterrapidisimoAdapter_GetTrackingHistory_FakeFetcher(t *testing.T) {
+	fetcher := &fakeFetcher{bodies: []string{`{"Success": false, "Message": "Guia no existe"}`}}
+	adapter := NewInterrapidisimoAdapter("https://www3.interrapidisimo.com/SiguetuEnvio/shipment", proxy.Settings{}, nil, 0, fetcher)
+
+	_, err := adapter.GetTrackingHistory(" 0240041234567")
+
+	assert.ErrorIs(t, err, ports.ErrShipmentNotFound)
+	assert.Contains(t, err.Error(), "Guia no existe")
+	require.NotNil(t, fetcher.requests[0].Form)
+	assert.Equal(t, "240041234567", fetcher.requests[0].Form.Value)
+}
+
+// TestInterrapidisimoAdapter_GetTrackingHistory_PublicURL verifies successful lookups link the courier's search page.
+func TestInterrapidisimoAdapter_GetTrackingHistory_PublicURL(t *testing.T) {
+	fetcher := &fakeFetcher{bodies: []string{`{"Success": true, "EstadosGuia": [{"EstadoGuia": {"IdEstadoGuia": 1, "FechaGrabacion": "2025-04-30T18:53:15.917"}}]}`}}
+	adapter := NewInterrapidisimoAdapter("https://www3.interrapidisimo.com/SiguetuEnvio/shipment", proxy.Settings{}, nil, 0, fetcher)
+
+	history, err := adapter.GetTrackingHistory("240041234567")
+
+	require.NoError(t, err)
+	assert.Equal(t, "https://www3.interrapidisimo.com/SiguetuEnvio/shipment", history.PublicURL)
+}
+
+// TestServientregaAdapter_GetTrackingHistory_FakeFetcherReload verifies empty results are retried.
+func TestServientregaAdapter_GetTrackingHistory_FakeFetcherReload(t *testing.T) {
+	// The connectivity check still performs a plain HTTP request
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	fetcher := &fakeFetcher{bodies: []string{
+		`{"Code": 1, "Results": []}`,
+		`{"Code": 1, "Results": [{"estadoActual": "ENTREGADO", "movimientos": [{"fecha": "21/01/2026 15:44 ", "IdProceso": "21"}]}]}`,
+	}}
+	adapter := NewServientregaAdapter(ts.URL+"/?Guia=", "", proxy.Settings{}, nil, 1, ConnectivityCheckDirect, 0, fetcher)
+
+	history, err := adapter.GetTrackingHistory("2200000000")
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.TrackingStatusCompleted, history.GlobalStatus)
+	assert.Equal(t, 1, fetcher.requests[0].MaxReloads)
+	assert.Equal(t, ts.URL+"/?Guia=2200000000", history.PublicURL)
+}
+
+// TestServientregaAdapter_GetTrackingHistory_UserAgent verifies the connectivity check and the browser share one pooled user agent.
+func TestServientregaAdapter_GetTrackingHistory_UserAgent(t *testing.T) {
+	pool := []string{"agent/1", "agent/2", "agent/3"}
+	useragent.Set(pool)
+	defer useragent.Set(nil)
+
+	var checked string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		checked = r.UserAgent()
+	}))
+	defer ts.Close()
+
+	fetcher := &fakeFetcher{bodies: []string{`{"Code": 1, "Results": [{"estadoActual": "ENTREGADO", "movimientos": [{"fecha": "21/01/2026 15:44 ", "IdProceso": "21"}]}]}`}}
+	adapter := NewServientregaAdapter(ts.URL+"/?Guia=", "", proxy.Settings{}, nil, 0, ConnectivityCheckDirect, 0, fetcher)
+
+	_, err := adapter.GetTrackingHistory("2200000000")
+
+	require.NoError(t, err)
+	assert.Contains(t, pool, checked)
+	assert.Equal(t, checked, fetcher.requests[0].UserAgent)
+}
+
+// patternFetcher is a PageFetcher answering per hijack pattern, recording the URLs it opened.
+type patternFetcher struct {
+	bodies map[string]string
+	errs   map[string]error
+	urls   []string
+}
+
+// Fetch implements PageFetcher.
+func (f *patternFetcher) Fetch(ctx context.Context, req FetchRequest) ([]byte, error) {
+	f.urls = append(f.urls, req.URL)
+	if err, ok := f.errs[req.Pattern]; ok {
+		return nil, err
+	}
+	return []byte(f.bodies[req.Pattern]), nil
+}
+
+// servientregaDelivered is a Servientrega response for a delivered shipment.
+const servientregaDelivered = `{"Code": 1, "Results": [{"estadoActual": "ENTREGADO", "movimientos": [{"fecha": "21/01/2026 15:44 ", "IdProceso": "21"}]}]}`
+
+// TestServientregaAdapter_GetTrackingHistory_DesktopFallback verifies the mobile page is tried first and
+// the desktop page only when it fails.
+func TestServientregaAdapter_GetTrackingHistory_DesktopFallback(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+	mobileURL, desktopURL := ts.URL+"/mobile?Guia=", ts.URL+"/desktop?id="
+
+	t.Run("MobileSucceeds", func(t *testing.T) {
+		fetcher := &patternFetcher{bodies: map[string]string{servientregaMobilePattern: servientregaDelivered}}
+		adapter := NewServientregaAdapter(mobileURL, desktopURL, proxy.Settings{}, nil, 0, ConnectivityCheckDirect, 0, fetcher)
+
+		history, err := adapter.GetTrackingHistory("2200000000")
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.TrackingStatusCompleted, history.GlobalStatus)
+		assert.Equal(t, []string{mobileURL + "2200000000"}, fetcher.urls)
+	})
+
+	t.Run("MobileFails", func(t *testing.T) {
+		fetcher := &patternFetcher{
+			bodies: map[string]string{servientregaDesktopPattern: servientregaDelivered},
+			errs:   map[string]error{servientregaMobilePattern: errors.New("bot challenge")},
+		}
+		adapter := NewServientregaAdapter(mobileURL, desktopURL, proxy.Settings{}, nil, 0, ConnectivityCheckDirect, 0, fetcher)
+
+		history, err := adapter.GetTrackingHistory("2200000000")
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.TrackingStatusCompleted, history.GlobalStatus)
+		assert.Equal(t, []string{mobileURL + "2200000000", desktopURL + "2200000000"}, fetcher.urls)
+	})
+
+	t.Run("BothFail", func(t *testing.T) {
+		fetcher := &patternFetcher{errs: map[string]error{
+			servientregaMobilePattern:  errors.New("bot challenge"),
+			servientregaDesktopPattern: errors.New("navigation failed"),
+		}}
+		adapter := NewServientregaAdapter(mobileURL, desktopURL, proxy.Settings{}, nil, 0, ConnectivityCheckDirect, 0, fetcher)
+
+		_, err := adapter.GetTrackingHistory("2200000000")
+
+		assert.ErrorContains(t, err, "navigation failed")
+		assert.ErrorContains(t, err, "bot challenge")
+	})
+
+	t.Run("BusyDoesNotFallBack", func(t *testing.T) {
+		fetcher := &patternFetcher{errs: map[string]error{servientregaMobilePattern: scraper.ErrCourierBusy}}
+		adapter := NewServientregaAdapter(mobileURL, desktopURL, proxy.Settings{}, nil, 0, ConnectivityCheckDirect, 0, fetcher)
+
+		_, err := adapter.GetTrackingHistory("2200000000")
+
+		assert.ErrorIs(t, err, scraper.ErrCourierBusy)
+		assert.Len(t, fetcher.urls, 1)
+	})
+
+	t.Run("OverrideDisablesFallback", func(t *testing.T) {
+		fetcher := &patternFetcher{errs: map[string]error{servientregaMobilePattern: errors.New("bot challenge")}}
+		adapter := NewServientregaAdapter(mobileURL, desktopURL, proxy.Settings{}, nil, 0, ConnectivityCheckDirect, 0, fetcher)
+
+		_, err := adapter.WithOverrides(ports.Overrides{BaseURL: ts.URL + "/staging?Guia="}).GetTrackingHistory("2200000000")
+
+		assert.ErrorContains(t, err, "bot challenge")
+		assert.Equal(t, []string{ts.URL + "/staging?Guia=2200000000"}, fetcher.urls)
+	})
+}
+
+// TestAdapters_GetTrackingHistory_FetchError verifies fetch errors are returned unchanged.
+func TestAdapters_GetTrackingHistory_FetchError(t *testing.T) {
+	fetchErr := errors.New("failed to launch browser")
+	fetcher := &fakeFetcher{err: fetchErr}
+	adapter := NewCoordinadoraAdapter("https://coordinadora.com/?guia=", proxy.Settings{}, nil, 0, fetcher)
+
+	_, err := adapter.GetTrackingHistory("04333004120")
+
+	assert.ErrorIs(t, err, fetchErr)
+}
+
+// blockingFetcher is a PageFetcher that waits for the adapter's deadline, like a courier that never answers.
+type blockingFetcher struct{}
+
+// Fetch implements PageFetcher.
+func (blockingFetcher) Fetch(ctx context.Context, req FetchRequest) ([]byte, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+// TestAdapters_GetTrackingHistory_Timeout verifies each adapter uses its own timeout and reports it.
+func TestAdapters_GetTrackingHistory_Timeout(t *testing.T) {
+	adapter := NewInterrapidisimoAdapter("https://www3.interrapidisimo.com/SiguetuEnvio/shipment", proxy.Settings{}, nil, 20*time.Millisecond, blockingFetcher{})
+
+	start := time.Now()
+	_, err := adapter.GetTrackingHistory("240041234567")
+
+	assert.Less(t, time.Since(start), DefaultTimeout)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.EqualError(t, err, "interrapidisimo_co lookup timed out after 20ms: context deadline exceeded")
+}
+
+// TestEffectiveTimeout verifies a zero timeout falls back to DefaultTimeout.
+func TestEffectiveTimeout(t *testing.T) {
+	assert.Equal(t, DefaultTimeout, effectiveTimeout(0))
+	assert.Equal(t, 90*time.Second, effectiveTimeout(90*time.Second))
+}
+
+// TestRodFetcher_ResolveBrowserBin verifies the configured binary wins over the launcher default and auto-detection.
+func TestRodFetcher_ResolveBrowserBin(t *testing.T) {
+	original := lookPath
+	defer func() { lookPath = original }()
+	lookPath = func() (string, bool) { return "/Applications/Google Chrome.app/Contents/MacOS/Google Chrome", true }
+
+	assert.Equal(t, "/opt/chromium/chrome", NewRodFetcher(scraper.Stealth{}, "/opt/chromium/chrome", 1, 0, 0).resolveBrowserBin("/usr/bin/chromium"))
+	assert.Equal(t, "/usr/bin/chromium", NewRodFetcher(scraper.Stealth{}, "", 1, 0, 0).resolveBrowserBin("/usr/bin/chromium"))
+	assert.Equal(t, "/Applications/Google Chrome.app/Contents/MacOS/Google Chrome", NewRodFetcher(scraper.Stealth{}, "", 1, 0, 0).resolveBrowserBin(""))
+
+	lookPath = func() (string, bool) { return "", false }
+	assert.Empty(t, NewRodFetcher(scraper.Stealth{}, "", 1, 0, 0).resolveBrowserBin(""))
+}
+
+// TestWithLimiter_Busy verifies a lookup that can't get a browser slot before its timeout fails with ErrCourierBusy.
+func TestWithLimiter_Busy(t *testing.T) {
+	limiter := scraper.NewLimiter(1)
+	release, err := limiter.Acquire(context.Background())
+	require.NoError(t, err)
+
+	fetcher := &fakeFetcher{bodies: []string{`{"history": []}`}}
+	adapter := NewCoordinadoraAdapter("https://coordinadora.com/rastreo/?guia=", proxy.Settings{}, nil, 20*time.Millisecond, WithLimiter(fetcher, limiter))
+
+	_, err = adapter.GetTrackingHistory("04333004120")
+	assert.ErrorIs(t, err, scraper.ErrCourierBusy)
+	assert.Empty(t, fetcher.requests, "the page must not be fetched without a slot")
+
+	release()
+	_, err = adapter.GetTrackingHistory("04333004120")
+	require.NoError(t, err)
+	assert.Len(t, fetcher.requests, 1)
+}
+
+// startedFetcher is a PageFetcher that reports each fetch on started and then blocks until ctx is done.
+type startedFetcher struct {
+	started chan struct{}
+}
+
+// Fetch implements PageFetcher.
+func (f startedFetcher) Fetch(ctx context.Context, req FetchRequest) ([]byte, error) {
+	f.started <- struct{}{}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+// TestCourierFetcher verifies a courier limit rejects scrapes beyond it while the shared cap still has
+// free browsers for other couriers.
+func TestCourierFetcher(t *testing.T) {
+	started := make(chan struct{}, 2)
+	shared := WithLimiter(startedFetcher{started: started}, scraper.NewLimiter(4))
+	servientrega := courierFetcher(shared, 1)
+	coordinadora := courierFetcher(shared, 0)
+	assert.Same(t, shared, coordinadora)
+
+	running, stop := context.WithCancel(context.Background())
+	defer stop()
+	go servientrega.Fetch(running, FetchRequest{})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := servientrega.Fetch(ctx, FetchRequest{})
+	assert.ErrorIs(t, err, scraper.ErrCourierBusy)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = coordinadora.Fetch(ctx, FetchRequest{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NotErrorIs(t, err, scraper.ErrCourierBusy)
+}
+
+// TestLayoutError verifies a form element that never appeared is reported as a layout change rather than
+// a timeout, while a cancelled fetch is passed through.
+func TestLayoutError(t *testing.T) {
+	err := layoutError("search input", "#inputGuide", context.DeadlineExceeded)
+	assert.ErrorIs(t, err, scraper.ErrCourierLayoutChanged)
+	assert.NotErrorIs(t, err, context.DeadlineExceeded)
+	assert.EqualError(t, err, "courier page layout changed: search input #inputGuide not found: context deadline exceeded")
+	assert.EqualError(t, timeoutError("interrapidisimo_co", time.Minute, err), err.Error())
+
+	assert.Equal(t, context.Canceled, layoutError("search input", "#inputGuide", context.Canceled))
+}
+
+// TestDeliver verifies a late hijacked response is dropped once the fetch context is done
+// instead of blocking its handler goroutine on a channel nobody reads.
+func TestDeliver(t *testing.T) {
+	done := make(chan []byte)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	received := make(chan []byte, 1)
+	go func() { received <- <-done }()
+	assert.True(t, deliver(ctx, done, []byte("body")))
+	assert.Equal(t, []byte("body"), <-received)
+
+	cancel()
+	returned := make(chan bool)
+	go func() { returned <- deliver(ctx, done, []byte("late")) }()
+
+	select {
+	case delivered := <-returned:
+		assert.False(t, delivered)
+	case <-time.After(time.Second):
+		t.Fatal("deliver blocked after the context was cancelled")
+	}
+}
+
+// TestRodFetcher_Close verifies a closed fetcher refuses to launch a browser and that Close is idempotent.
+func TestRodFetcher_Close(t *testing.T) {
+	fetcher := NewRodFetcher(scraper.Stealth{}, "", 1, 0, 0)
+
+	require.NoError(t, fetcher.Close())
+	require.NoError(t, fetcher.Close())
+
+	_, err := fetcher.Fetch(context.Background(), FetchRequest{URL: "https://example.com", Pattern: "*"})
+	assert.ErrorIs(t, err, ErrFetcherClosed)
+}
+
+// TestRodFetcher_Fetch_InterceptTimeout verifies a page that loads but never calls the courier API fails as a
+// layout change once the intercept timeout passes, well before the lookup deadline.
+func TestRodFetcher_Fetch_InterceptTimeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><body><h1>Nuevo sitio</h1></body></html>`))
+	}))
+	defer ts.Close()
+
+	fetcher := NewRodFetcher(scraper.Stealth{}, "", 1, 0, time.Second)
+	defer fetcher.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	_, err := fetcher.Fetch(ctx, FetchRequest{Courier: "servientrega_co", URL: ts.URL, Pattern: "*/api/ControlRastreovalidaciones*"})
+
+	assert.ErrorIs(t, err, scraper.ErrCourierLayoutChanged)
+	assert.EqualError(t, err, "courier page layout changed: no request matching */api/ControlRastreovalidaciones* within 1s")
+	assert.NoError(t, ctx.Err(), "the lookup deadline must not be reached")
+}
+
+// multiCallPage serves a page calling /api/detail and, when withStatus is set, /api/status, answering both.
+func multiCallPage(withStatus bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/detail":
+			w.Write([]byte(`{"part": "detail"}`))
+		case "/api/status":
+			w.Write([]byte(`{"part": "status"}`))
+		default:
+			script := `fetch("/api/detail");`
+			if withStatus {
+				script += `setTimeout(() => fetch("/api/status"), 200);`
+			}
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<html><body><script>` + script + `</script></body></html>`))
+		}
+	}))
+}
+
+// TestRodFetcher_Fetch_ExtraPatterns verifies the fetch waits for the extra responses and hands them over by pattern.
+func TestRodFetcher_Fetch_ExtraPatterns(t *testing.T) {
+	ts := multiCallPage(true)
+	defer ts.Close()
+
+	fetcher := NewRodFetcher(scraper.Stealth{}, "", 1, 0, 0)
+	defer fetcher.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	var extras map[string][]byte
+	body, err := fetcher.Fetch(ctx, FetchRequest{
+		URL:           ts.URL,
+		Pattern:       "*/api/detail",
+		ExtraPatterns: []string{"*/api/status"},
+		OnExtras:      func(got map[string][]byte) { extras = got },
+	})
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"part": "detail"}`, string(body))
+	assert.JSONEq(t, `{"part": "status"}`, string(extras["*/api/status"]))
+}
+
+// TestRodFetcher_Fetch_ExtraPatternsMissing verifies a missing extra response only delays the fetch by ExtraWait.
+func TestRodFetcher_Fetch_ExtraPatternsMissing(t *testing.T) {
+	ts := multiCallPage(false)
+	defer ts.Close()
+
+	fetcher := NewRodFetcher(scraper.Stealth{}, "", 1, 0, 0)
+	defer fetcher.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	extras := map[string][]byte{"stale": nil}
+	body, err := fetcher.Fetch(ctx, FetchRequest{
+		URL:           ts.URL,
+		Pattern:       "*/api/detail",
+		ExtraPatterns: []string{"*/api/status"},
+		ExtraWait:     time.Second,
+		OnExtras:      func(got map[string][]byte) { extras = got },
+	})
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"part": "detail"}`, string(body))
+	assert.Empty(t, extras)
+	assert.NoError(t, ctx.Err(), "the lookup deadline must not be reached")
+}
+
+// TestCourierAdapters_Close verifies adapters close their fetcher through the limiter and tolerate fetchers without resources.
+func TestCourierAdapters_Close(t *testing.T) {
+	rod := NewRodFetcher(scraper.Stealth{}, "", 1, 0, 0)
+	shared := WithLimiter(rod, scraper.NewLimiter(1))
+
+	coordinadora := NewCoordinadoraAdapter("https://coordinadora.com/rastreo/?guia=", proxy.Settings{}, nil, 0, shared)
+	servientrega := NewServientregaAdapter("https://servientrega.com", "", proxy.Settings{}, nil, 0, ConnectivityCheckDirect, 0, shared)
+	assert.NoError(t, coordinadora.Close())
+	assert.NoError(t, servientrega.Close())
+	assert.Error(t, rod.closed.Err())
+
+	interrapidisimo := NewInterrapidisimoAdapter("https://interrapidisimo.com", proxy.Settings{}, nil, 0, &fakeFetcher{})
+	assert.NoError(t, interrapidisimo.Close())
+}
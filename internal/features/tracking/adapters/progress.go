@@ -0,0 +1,29 @@
+package adapter
+
+import "tracker-scrapper/internal/features/tracking/domain"
+
+// progressStages maps a courier event code to how far along, in percent, the shipment is once it
+// happens. Codes that don't move a shipment forward (incidences, returns, new guides) are left out.
+type progressStages map[string]int
+
+// progressPct derives TrackingHistory.ProgressPct from the furthest stage its events reached.
+// A completed shipment is always 100. Returns and incidences are not stages, so the shipment keeps
+// the progress it had made before them. Without any staged event the global status decides.
+func progressPct(history *domain.TrackingHistory, stages progressStages) int {
+	if history.IsDelivered() {
+		return 100
+	}
+
+	pct, staged := 0, false
+	for _, event := range history.History {
+		if stage, ok := stages[event.Code]; ok {
+			pct = max(pct, stage)
+			staged = true
+		}
+	}
+
+	if !staged && len(history.History) > 0 {
+		return history.GlobalStatus.Progress()
+	}
+	return pct
+}
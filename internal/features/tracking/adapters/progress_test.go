@@ -0,0 +1,55 @@
+package adapter
+
+import (
+	"testing"
+
+	"tracker-scrapper/internal/features/tracking/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// eventCodes builds a history's events from their codes.
+func eventCodes(codes ...string) []domain.TrackingEvent {
+	out := make([]domain.TrackingEvent, len(codes))
+	for i, code := range codes {
+		out[i] = domain.TrackingEvent{Code: code}
+	}
+	return out
+}
+
+// TestProgressPct verifies progress follows each courier's stages and the special statuses.
+func TestProgressPct(t *testing.T) {
+	tests := []struct {
+		name    string
+		stages  progressStages
+		status  domain.TrackingStatus
+		events  []domain.TrackingEvent
+		wantPct int
+	}{
+		{"coordinadora origin", coordProgressStages, domain.TrackingStatusProcessing, eventCodes("2"), 20},
+		{"coordinadora in transit", coordProgressStages, domain.TrackingStatusProcessing, eventCodes("2", "3"), 50},
+		{"coordinadora out for delivery", coordProgressStages, domain.TrackingStatusProcessing, eventCodes("2", "3", "4", "5"), 80},
+		{"coordinadora delivered", coordProgressStages, domain.TrackingStatusCompleted, eventCodes("2", "3", "5", "6"), 100},
+		{"coordinadora incidence keeps progress", coordProgressStages, domain.TrackingStatusIncidence, eventCodes("2", "3", "5", "701"), 80},
+		{"coordinadora return keeps progress", coordProgressStages, domain.TrackingStatusReturn, eventCodes("2", "3", "8"), 50},
+		{"interrapidisimo received", interProgressStages, domain.TrackingStatusProcessing, eventCodes("1"), 10},
+		{"interrapidisimo on its way", interProgressStages, domain.TrackingStatusProcessing, eventCodes("1", "2", "3", "6"), 80},
+		{"interrapidisimo delivered", interProgressStages, domain.TrackingStatusCompleted, eventCodes("1", "6", "11"), 100},
+		{"interrapidisimo returned", interProgressStages, domain.TrackingStatusReturn, eventCodes("1", "2", "10"), 35},
+		{"servientrega guide created", servProgressStages, domain.TrackingStatusProcessing, eventCodes("1"), 10},
+		{"servientrega at destination", servProgressStages, domain.TrackingStatusProcessing, eventCodes("1", "6", "12", "15"), 65},
+		{"servientrega delivered", servProgressStages, domain.TrackingStatusCompleted, eventCodes("1", "18", "21"), 100},
+		{"servientrega incidence", servProgressStages, domain.TrackingStatusIncidence, eventCodes("1", "6", "27"), 20},
+		{"completed without delivered event", servProgressStages, domain.TrackingStatusCompleted, eventCodes("1"), 100},
+		{"unstaged events use the status", coordProgressStages, domain.TrackingStatusOrigin, eventCodes("custom"), 20},
+		{"unknown status", coordProgressStages, domain.TrackingStatusUnknown, eventCodes("999"), 0},
+		{"no events", coordProgressStages, domain.TrackingStatusProcessing, nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := &domain.TrackingHistory{GlobalStatus: tt.status, History: tt.events}
+			assert.Equal(t, tt.wantPct, progressPct(history, tt.stages))
+		})
+	}
+}
@@ -3,196 +3,216 @@ package adapter
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
 	"time"
 
+	"tracker-scrapper/internal/core/capture"
 	"tracker-scrapper/internal/core/logger"
+	"tracker-scrapper/internal/core/metrics"
 	"tracker-scrapper/internal/core/proxy"
+	"tracker-scrapper/internal/core/scraper"
+	"tracker-scrapper/internal/core/useragent"
 	"tracker-scrapper/internal/features/tracking/domain"
+	"tracker-scrapper/internal/features/tracking/ports"
 
-	"github.com/go-rod/rod"
-	"github.com/go-rod/rod/lib/launcher"
 	"github.com/go-rod/rod/lib/proto"
 	"go.uber.org/zap"
 )
 
+// ConnectivityCheck selects how reachability is checked before a tracking page is opened.
+type ConnectivityCheck string
+
+const (
+	// ConnectivityCheckDirect checks without the proxy, so no paid bandwidth is spent on it.
+	ConnectivityCheckDirect ConnectivityCheck = "direct"
+	// ConnectivityCheckProxy checks through the proxy, for couriers that block non-proxy IPs.
+	ConnectivityCheckProxy ConnectivityCheck = "proxy"
+	// ConnectivityCheckOff skips the check.
+	ConnectivityCheckOff ConnectivityCheck = "off"
+)
+
 // ServientregaAdapter handles tracking for Servientrega courier.
 type ServientregaAdapter struct {
-	baseURL     string
+	// baseURL is the mobile tracking page, tried first.
+	baseURL string
+	// desktopURL is the desktop tracking page used when the mobile lookup fails. Empty disables the fallback.
+	desktopURL  string
 	proxy       proxy.Settings
 	courierName string
 	logger      *zap.Logger
+	// authorization is sent on courier API requests when set through per-call overrides.
+	authorization string
+	// statusCodes holds configured codes that take precedence over servDefaultCodes.
+	statusCodes StatusCodes
+	// emptyRetries is how many times the page is reloaded when the courier returns no results.
+	emptyRetries int
+	// connectivity selects how reachability is checked before the browser is opened.
+	connectivity ConnectivityCheck
+	// timeout bounds a single lookup, including the connectivity check.
+	timeout time.Duration
+	// location is the timezone the courier's dates are parsed in. Nil uses America/Bogota.
+	location *time.Location
+	// fetcher opens the tracking page and intercepts the courier API response.
+	fetcher PageFetcher
 }
 
-// NewServientregaAdapter creates a new ServientregaAdapter with the given base URL and proxy settings.
-func NewServientregaAdapter(baseURL string, proxySettings proxy.Settings) *ServientregaAdapter {
+// NewServientregaAdapter creates a new ServientregaAdapter that tries the mobile page at baseURL and then
+// the desktop page at desktopURL (empty disables the fallback). statusCodes may be nil to use only the built-in codes. emptyRetries bounds page reloads on empty results.
+// connectivity selects the reachability check made before each page; empty uses ConnectivityCheckDirect.
+// A zero timeout uses DefaultTimeout.
+func NewServientregaAdapter(baseURL, desktopURL string, proxySettings proxy.Settings, statusCodes StatusCodes, emptyRetries int, connectivity ConnectivityCheck, timeout time.Duration, fetcher PageFetcher) *ServientregaAdapter {
+	if connectivity == "" {
+		connectivity = ConnectivityCheckDirect
+	}
 	return &ServientregaAdapter{
-		baseURL:     baseURL,
-		proxy:       proxySettings,
-		courierName: "servientrega_co",
-		logger:      logger.Get(),
+		baseURL:      baseURL,
+		desktopURL:   desktopURL,
+		proxy:        proxySettings,
+		courierName:  "servientrega_co",
+		logger:       logger.Get(),
+		statusCodes:  statusCodes,
+		emptyRetries: emptyRetries,
+		connectivity: connectivity,
+		timeout:      effectiveTimeout(timeout),
+		fetcher:      fetcher,
 	}
 }
 
-// GetTrackingHistory retrieves tracking history from Servientrega.
+// SetLocation sets the timezone Servientrega's dates are parsed in, since they carry no offset.
+func (a *ServientregaAdapter) SetLocation(loc *time.Location) {
+	a.location = loc
+}
+
+// Close releases the page fetcher. Adapters share it, so closing it more than once is harmless.
+func (a *ServientregaAdapter) Close() error {
+	return closeIfCloser(a.fetcher)
+}
+
+// GetTrackingHistory retrieves tracking history from Servientrega and records scrape metrics.
 func (a *ServientregaAdapter) GetTrackingHistory(trackingNumber string) (*domain.TrackingHistory, error) {
+	start := time.Now()
+	history, err := a.scrape(a.normalizeTrackingNumber(trackingNumber))
+	err = timeoutError(a.courierName, a.timeout, err)
+	metrics.ObserveScrape(a.courierName, time.Since(start), err)
+	return history, err
+}
+
+// normalizeTrackingNumber keeps only the digits of raw, dropping the letter prefixes (e.g., "SV")
+// some guides are copied with, while preserving leading zeros.
+func (a *ServientregaAdapter) normalizeTrackingNumber(raw string) string {
+	return digitsOnly(raw)
+}
+
+// Hijack patterns of the API calls made by each Servientrega tracking page.
+const (
+	// servientregaMobilePattern matches the API called by the mobile.servientrega.com detail page.
+	servientregaMobilePattern = "*/api/ControlRastreovalidaciones"
+	// servientregaDesktopPattern matches the API called by the www.servientrega.com tracking portal.
+	servientregaDesktopPattern = "*/api/ControlRastreo*"
+)
+
+// scrape retrieves tracking history from Servientrega's mobile page, falling back to the
+// desktop page when the mobile lookup fails. Both pages return the same JSON.
+func (a *ServientregaAdapter) scrape(trackingNumber string) (*domain.TrackingHistory, error) {
 	// Create a master context with timeout to prevent hanging
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
 	defer cancel()
 
 	a.logger.Info("Starting Servientrega tracking",
 		zap.String("tracking_number", trackingNumber),
-		zap.Duration("timeout", 60*time.Second),
+		zap.Duration("timeout", a.timeout),
 	)
 
-	// Use baseURL from config (mockable)
-	trackingURL := fmt.Sprintf("%s%s", a.baseURL, trackingNumber)
-
-	// fast fail: check connectivity first
-	if err := a.checkConnectivity(ctx, trackingURL); err != nil {
-		return nil, fmt.Errorf("connectivity check failed: %w", err)
+	if a.desktopURL == "" {
+		return a.scrapePage(ctx, a.baseURL, servientregaMobilePattern, trackingNumber)
 	}
 
-	// Start local proxy forwarder if proxy is configured with credentials
-	// This solves Chromium's limitation of not supporting proxy auth via command line
-	var localProxyAddr string
-	var proxyForwarder *proxy.ForwardingProxy
-	if a.proxy.HasProxy() && a.proxy.Username != "" && a.proxy.Password != "" {
-		var err error
-		// Whitelist only Servientrega domains to save bandwidth
-		proxyForwarder, err = proxy.NewForwardingProxy(a.proxy.FullURL(), "mobile.servientrega.com", "servientrega.com")
-		if err != nil {
-			return nil, fmt.Errorf("failed to create proxy forwarder: %w", err)
-		}
-		localProxyAddr, err = proxyForwarder.Start(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to start proxy forwarder: %w", err)
-		}
-		defer proxyForwarder.Stop()
-		a.logger.Debug("Local proxy forwarder started", zap.String("local_addr", localProxyAddr))
-	} else if a.proxy.HasProxy() {
-		// Proxy without credentials (IP whitelist mode)
-		localProxyAddr = a.proxy.HostPort()
+	// Leave the desktop fallback half of the budget
+	mobileCtx, mobileCancel := context.WithTimeout(ctx, a.timeout/2)
+	history, err := a.scrapePage(mobileCtx, a.baseURL, servientregaMobilePattern, trackingNumber)
+	mobileCancel()
+	if err == nil {
+		return history, nil
+	}
+	// Without a browser slot or once shutting down the desktop page would fail the same way
+	if errors.Is(err, scraper.ErrCourierBusy) || errors.Is(err, ErrFetcherClosed) {
+		return nil, err
 	}
 
-	a.logger.Debug("Launching browser...",
-		zap.Bool("proxy_enabled", a.proxy.HasProxy()),
-		zap.String("proxy_addr", localProxyAddr),
+	a.logger.Warn("Servientrega mobile lookup failed, falling back to desktop page",
+		zap.String("tracking_number", trackingNumber),
+		zap.Error(err),
 	)
-	// Configure launcher for Docker environment (needs --no-sandbox)
-	// Use Context(ctx) to ensure launch respects timeout
-	l := launcher.New().
-		Context(ctx).
-		Bin("/usr/bin/chromium").
-		Headless(true).
-		NoSandbox(true).
-		Set("user-agent", stealthUA) // Set User-Agent in browser
-
-	// Configure proxy - use local forwarder address (no auth needed)
-	if localProxyAddr != "" {
-		l = l.Proxy(localProxyAddr)
-		a.logger.Debug("Browser configured with proxy", zap.String("proxy", localProxyAddr))
+	history, desktopErr := a.scrapePage(ctx, a.desktopURL, servientregaDesktopPattern, trackingNumber)
+	if desktopErr != nil {
+		return nil, fmt.Errorf("desktop fallback failed: %w (mobile: %v)", desktopErr, err)
 	}
+	return history, nil
+}
+
+// scrapePage opens the tracking page at baseURL and maps the API response matching pattern.
+func (a *ServientregaAdapter) scrapePage(ctx context.Context, baseURL, pattern, trackingNumber string) (*domain.TrackingHistory, error) {
+	trackingURL := fmt.Sprintf("%s%s", baseURL, trackingNumber)
 
-	u, err := l.Launch()
+	// Pick a healthy proxy endpoint when a pool is configured
+	proxySettings, err := a.proxy.Resolve(ctx, trackingURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to launch browser: %w", err)
+		return nil, err
 	}
 
-	a.logger.Debug("Connecting to browser...")
-	browser := rod.New().Context(ctx).ControlURL(u)
-	if err := browser.Connect(); err != nil {
-		return nil, fmt.Errorf("failed to connect to browser: %w", err)
-	}
-	defer browser.Close()
+	// The connectivity check and the browser present the same user agent
+	userAgent := useragent.Random()
 
-	a.logger.Debug("Creating page...")
-	// Page expects proto.TargetCreateTarget in this version of rod
-	page, err := browser.Page(proto.TargetCreateTarget{URL: ""})
-	if err != nil {
-		return nil, fmt.Errorf("failed to create page: %w", err)
+	// fast fail: check connectivity first
+	if err := a.checkConnectivity(ctx, trackingURL, proxySettings, userAgent); err != nil {
+		return nil, fmt.Errorf("connectivity check failed: %w", err)
 	}
-	// Measure page operations with the same context
-	page = page.Context(ctx)
 
-	// Stealth: Hide webdriver property
-	if _, err := page.EvalOnNewDocument("Object.defineProperty(navigator, 'webdriver', {get: () => undefined})"); err != nil {
-		a.logger.Warn("Failed to inject stealth script", zap.Error(err))
+	body, err := a.fetcher.Fetch(ctx, FetchRequest{
+		Courier:      a.courierName,
+		URL:          trackingURL,
+		Pattern:      pattern,
+		ResourceType: proto.NetworkResourceTypeXHR,
+		Proxy:        proxySettings,
+		// Tunnel only the configured Servientrega domains to save bandwidth
+		ProxyDomains:  proxySettings.AllowedDomains,
+		Authorization: a.authorization,
+		// Reload when Servientrega answers with an empty success response
+		Reload: func(body []byte) bool {
+			var servResp servientregaResponse
+			return json.Unmarshal(body, &servResp) == nil && isEmptySuccess(servResp)
+		},
+		MaxReloads: a.emptyRetries,
+		UserAgent:  userAgent,
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	a.logger.Debug("Hijacking requests...")
-	router := page.HijackRequests()
-	defer router.Stop()
-
-	done := make(chan string)
-
-	// Add expects (pattern string, type proto.NetworkResourceType, handler)
-	if err := router.Add("*/api/ControlRastreovalidaciones", proto.NetworkResourceTypeXHR, func(ctx *rod.Hijack) {
-		a.logger.Debug("Intercepted 'ControlRastreovalidaciones' request")
-
-		// Create proxy-aware client if proxy is used
-		client := http.DefaultClient
-		if localProxyAddr != "" {
-			proxyURL, err := url.Parse(localProxyAddr)
-			if err != nil {
-				a.logger.Error("Failed to parse local proxy URL", zap.Error(err))
-				// continue with default client (might fail if direct access blocked)
-			} else {
-				client = &http.Client{
-					Transport: &http.Transport{
-						Proxy: http.ProxyURL(proxyURL),
-					},
-					Timeout: 30 * time.Second,
-				}
-			}
-		}
-
-		if err := ctx.LoadResponse(client, true); err != nil {
-			a.logger.Error("Failed to load response", zap.Error(err))
-			return
-		}
-		done <- ctx.Response.Body()
-	}); err != nil {
-		return nil, fmt.Errorf("failed to add hijack: %w", err)
+	a.logger.Debug("Received response from hijacked request", zap.String("url", trackingURL))
+	capture.Save(a.courierName, trackingNumber, body)
+	var servResp servientregaResponse
+	if err := json.Unmarshal(body, &servResp); err != nil {
+		return nil, fmt.Errorf("failed to parse Servientrega response: %w", err)
 	}
 
-	go router.Run()
-
-	// Navigate with retry
-	const maxRetries = 3
-	var navErr error
-	for i := 1; i <= maxRetries; i++ {
-		a.logger.Debug("Navigating to URL", zap.String("url", trackingURL), zap.Int("attempt", i), zap.Int("max_retries", maxRetries))
-		navErr = page.Navigate(trackingURL)
-		if navErr == nil {
-			break
-		}
-		a.logger.Warn("Navigation failed", zap.Error(navErr), zap.Duration("retry_in", 2*time.Second))
-		time.Sleep(2 * time.Second)
+	history, err := a.mapResponseToDomain(servResp)
+	if err != nil {
+		return nil, err
 	}
+	// Link the page that answered; the desktop portal when the mobile page failed
+	history.PublicURL = trackingURL
+	return history, nil
+}
 
-	// Wait for response
-	select {
-	case body := <-done:
-		a.logger.Debug("Received response from hijacked request")
-		var servResp servientregaResponse
-		err := json.Unmarshal([]byte(body), &servResp)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse Servientrega response: %w", err)
-		}
-
-		return a.mapResponseToDomain(servResp)
-
-	case <-ctx.Done():
-		if navErr != nil {
-			// Report navigation error as root cause
-			return nil, fmt.Errorf("navigation failed after retries: %w", navErr)
-		}
-		return nil, fmt.Errorf("timeout waiting for courier response: %w", ctx.Err())
-	}
+// isEmptySuccess reports whether Servientrega answered successfully but without results,
+// which happens intermittently on the first call even when the shipment exists.
+func isEmptySuccess(resp servientregaResponse) bool {
+	return len(resp.Results) == 0 && resp.Code == servCodeSuccess
 }
 
 // mapResponseToDomain converts servientregaResponse to domain.TrackingHistory.
@@ -204,18 +224,37 @@ func (a *ServientregaAdapter) mapResponseToDomain(resp servientregaResponse) (*d
 
 	// Check for valid response
 	if len(resp.Results) == 0 {
-		return nil, fmt.Errorf("no results in response (Code: %d)", resp.Code)
+		return nil, fmt.Errorf("%w: no results in response (Code: %d)", ports.ErrShipmentNotFound, resp.Code)
 	}
 
 	result := resp.Results[0]
 	history.GlobalStatus = mapServientregaStatus(result.EstadoActual)
+	history.RawStatus = strings.TrimSpace(result.EstadoActual)
 
 	// Process movements (tracking events)
-	// Layout: "31/01/2026 12:51 " (DD/MM/YYYY HH:MM with trailing space)
+	// Layout: "31/01/2026 12:51 " (DD/MM/YYYY HH:MM with trailing space), local time without an offset
 	const dateLayout = "02/01/2006 15:04"
+	location := effectiveLocation(a.location)
 
-	for _, mov := range result.Movimientos {
-		date, _ := time.Parse(dateLayout, strings.TrimSpace(mov.Fecha))
+	if fechaEnvio := strings.TrimSpace(result.FechaEnvio); fechaEnvio != "" {
+		shippedAt, err := time.ParseInLocation(dateLayout, fechaEnvio, location)
+		if err != nil {
+			history.Warnings = append(history.Warnings, fmt.Sprintf("invalid shipped date %q", result.FechaEnvio))
+		}
+		history.ShippedAt = shippedAt
+	}
+
+	// estadoActual is authoritative; the latest movement code is used when it isn't recognized
+	var codeStatus domain.TrackingStatus
+	lastKnown := true
+	for i, mov := range result.Movimientos {
+		date, err := time.ParseInLocation(dateLayout, strings.TrimSpace(mov.Fecha), location)
+		if err != nil {
+			history.Warnings = append(history.Warnings, fmt.Sprintf("event %d: invalid date %q", i, mov.Fecha))
+		}
+		if mov.IdProceso == "" {
+			history.Warnings = append(history.Warnings, fmt.Sprintf("event %d: missing status code", i))
+		}
 
 		event := domain.TrackingEvent{
 			Date: date,
@@ -223,25 +262,73 @@ func (a *ServientregaAdapter) mapResponseToDomain(resp servientregaResponse) (*d
 			City: mov.Ubicacion,
 			Code: mov.IdProceso,
 		}
+		if mov.IdProceso == "21" {
+			event.ProofURL = result.ImagenEntrega
+			event.SignedBy = result.NombreRecibe
+			history.DeliveredAt = date
+		}
 		history.History = append(history.History, event)
 
+		status, isKnown := a.statusCodes.lookup(servDefaultCodes, mov.IdProceso)
+		if status != "" {
+			codeStatus = status
+		}
+
+		lastKnown = isKnown
+
 		// Check if this code is known for analytics purposes
-		if !servKnownCodes[mov.IdProceso] {
+		if !isKnown {
 			a.logger.Warn("Unknown Servientrega movement code encountered",
+				zap.String("courier", a.courierName),
 				zap.String("code", mov.IdProceso),
 				zap.String("description", mov.Movimiento),
 			)
 		}
 	}
 
+	if history.GlobalStatus == domain.TrackingStatusProcessing {
+		switch {
+		case !lastKnown:
+			history.GlobalStatus = domain.TrackingStatusUnknown
+		case codeStatus != "":
+			history.GlobalStatus = codeStatus
+		}
+	}
+
+	history.ProgressPct = progressPct(history, servProgressStages)
+
+	if len(history.Warnings) > 0 {
+		a.logger.Warn("Servientrega response partially parsed",
+			zap.String("courier", a.courierName),
+			zap.Strings("warnings", history.Warnings),
+		)
+	}
+
 	return history, nil
 }
 
+// WithOverrides returns a copy of the adapter using the given per-call overrides.
+func (a *ServientregaAdapter) WithOverrides(overrides ports.Overrides) ports.TrackingProvider {
+	clone := *a
+	if overrides.BaseURL != "" {
+		// An overridden endpoint is tested on its own, without the desktop fallback
+		clone.baseURL = overrides.BaseURL
+		clone.desktopURL = ""
+	}
+	clone.authorization = overrides.Authorization
+	return &clone
+}
+
 // SupportsCourier returns true if this adapter supports servientrega_co.
 func (a *ServientregaAdapter) SupportsCourier(courierName string) bool {
 	return courierName == a.courierName
 }
 
+// Couriers returns servientrega_co.
+func (a *ServientregaAdapter) Couriers() []string {
+	return []string{a.courierName}
+}
+
 // --- Internal types ---
 
 // servientregaResponse represents the JSON structure from Servientrega API.
@@ -253,7 +340,10 @@ type servientregaResponse struct {
 		NumeroGuia   string `json:"numeroGuia"`
 		FechaEnvio   string `json:"fechaEnvio"`
 		EstadoActual string `json:"estadoActual"`
-		Movimientos  []struct {
+		// ImagenEntrega and NombreRecibe are only filled once the shipment is delivered.
+		ImagenEntrega string `json:"imagenEntrega"`
+		NombreRecibe  string `json:"nombreRecibe"`
+		Movimientos   []struct {
 			Estado     string `json:"estado"`
 			Fecha      string `json:"fecha"`
 			Movimiento string `json:"movimiento"`
@@ -264,16 +354,29 @@ type servientregaResponse struct {
 	} `json:"Results"`
 }
 
-// Known movement codes for Servientrega
-var servKnownCodes = map[string]bool{
-	"1":  true, // Guia generada
-	"6":  true, // Ingreso al centro logistico
-	"12": true, // Salio a ciudad destino
-	"15": true, // Llegó a ciudad destino
-	"18": true, // En reparto
-	"21": true, // Entregado
-	"24": true, // Devolución
-	"27": true, // Novedad
+// servCodeSuccess is the response Code Servientrega returns for a successful lookup.
+const servCodeSuccess = 1
+
+// servDefaultCodes are the built-in Servientrega movement codes; configured codes take precedence.
+var servDefaultCodes = StatusCodes{
+	"1":  "",                             // Guia generada
+	"6":  "",                             // Ingreso al centro logistico
+	"12": "",                             // Salio a ciudad destino
+	"15": "",                             // Llegó a ciudad destino
+	"18": "",                             // En reparto
+	"21": domain.TrackingStatusCompleted, // Entregado
+	"24": domain.TrackingStatusReturn,    // Devolución
+	"27": domain.TrackingStatusIncidence, // Novedad
+}
+
+// servProgressStages maps Servientrega movement codes to the shipment's progress.
+var servProgressStages = progressStages{
+	"1":  10,  // Guia generada
+	"6":  20,  // Ingreso al centro logistico
+	"12": 50,  // Salio a ciudad destino
+	"15": 65,  // Llegó a ciudad destino
+	"18": 80,  // En reparto
+	"21": 100, // Entregado
 }
 
 // mapServientregaStatus maps the estado string to our domain status.
@@ -291,45 +394,67 @@ func mapServientregaStatus(estado string) domain.TrackingStatus {
 	}
 }
 
-// stealthUA mimics a real browser to avoid blocking
-const stealthUA = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/121.0.0.0 Safari/537.36"
+// checkConnectivity sends a HEAD request to verify network reachability, falling back to a GET
+// when the server answers 405. Only the status line is needed, so the body is never read.
+// The direct check skips the proxy to save paid bandwidth; the proxy check goes through it,
+// for when Servientrega blocks the server's own IP.
+func (a *ServientregaAdapter) checkConnectivity(ctx context.Context, urlStr string, proxySettings proxy.Settings, userAgent string) error {
+	switch a.connectivity {
+	case ConnectivityCheckOff:
+		return nil
+	case ConnectivityCheckDirect:
+		proxySettings = proxy.Settings{}
+	}
 
-// checkConnectivity performs a simple HTTP request to verify network reachability
-func (a *ServientregaAdapter) checkConnectivity(ctx context.Context, urlStr string) error {
 	a.logger.Debug("Checking connectivity",
 		zap.String("url", urlStr),
-		zap.Bool("proxy_enabled", a.proxy.HasProxy()),
+		zap.Bool("proxy_enabled", proxySettings.HasProxy()),
 	)
 
-	req, err := http.NewRequestWithContext(ctx, "GET", urlStr, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set stealth User-Agent
-	req.Header.Set("User-Agent", stealthUA)
-
 	// Create HTTP client with optional proxy
-	client := a.getHTTPClient()
+	client := a.getHTTPClient(proxySettings)
 
-	resp, err := client.Do(req)
+	status, err := a.probe(ctx, client, http.MethodHead, urlStr, userAgent)
+	if err == nil && status == http.StatusMethodNotAllowed {
+		status, err = a.probe(ctx, client, http.MethodGet, urlStr, userAgent)
+	}
 	if err != nil {
 		a.logger.Debug("Connectivity check FAILED", zap.Error(err))
 		return err
 	}
-	defer resp.Body.Close()
 
-	a.logger.Debug("Connectivity check SUCCESS", zap.String("status", resp.Status))
+	a.logger.Debug("Connectivity check SUCCESS", zap.Int("status", status))
 	return nil
 }
 
+// probe sends a single connectivity request and returns the response status without reading the body.
+func (a *ServientregaAdapter) probe(ctx context.Context, client *http.Client, method, urlStr, userAgent string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, urlStr, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set stealth User-Agent
+	req.Header.Set("User-Agent", userAgent)
+	if a.authorization != "" {
+		req.Header.Set("Authorization", a.authorization)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
 // getHTTPClient returns an HTTP client configured with proxy if enabled.
-func (a *ServientregaAdapter) getHTTPClient() *http.Client {
-	if !a.proxy.HasProxy() {
+func (a *ServientregaAdapter) getHTTPClient(proxySettings proxy.Settings) *http.Client {
+	if !proxySettings.HasProxy() {
 		return http.DefaultClient
 	}
 
-	proxyURL, err := url.Parse(a.proxy.FullURL())
+	proxyURL, err := url.Parse(proxySettings.FullURL())
 	if err != nil {
 		a.logger.Warn("Invalid proxy URL, using default client", zap.Error(err))
 		return http.DefaultClient
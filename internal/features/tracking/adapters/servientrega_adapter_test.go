@@ -1,18 +1,25 @@
 package adapter
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	"tracker-scrapper/internal/core/proxy"
+	"tracker-scrapper/internal/core/scraper"
 	"tracker-scrapper/internal/features/tracking/domain"
+	"tracker-scrapper/internal/features/tracking/ports"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 )
 
 func TestServientregaAdapter_GetTrackingHistory(t *testing.T) {
@@ -87,7 +94,7 @@ func TestServientregaAdapter_GetTrackingHistory(t *testing.T) {
 	// Initialize the adapter with the mock server URL
 	// Append /?Guia= to match the structure expected by the adapter
 	// Empty proxy settings for testing (no proxy needed)
-	adapter := NewServientregaAdapter(ts.URL+"/?Guia=", proxy.Settings{})
+	adapter := NewServientregaAdapter(ts.URL+"/?Guia=", "", proxy.Settings{}, nil, 0, ConnectivityCheckDirect, 0, NewRodFetcher(scraper.Stealth{Enabled: true}, "", 1, 0, 0))
 
 	// Call the method
 	history, err := adapter.GetTrackingHistory("2259200365")
@@ -104,7 +111,336 @@ func TestServientregaAdapter_GetTrackingHistory(t *testing.T) {
 	assert.Equal(t, "Bogota (Cundinamarca)", event1.City)
 	assert.Equal(t, "1", event1.Code)
 
-	// Verify date parsing (31/01/2026 12:51)
-	expectedTime, _ := time.Parse("02/01/2006 15:04", "31/01/2026 12:51")
-	assert.Equal(t, expectedTime, event1.Date)
+	// Verify date parsing (31/01/2026 12:51 Colombia time)
+	expectedTime, _ := time.ParseInLocation("02/01/2006 15:04", "31/01/2026 12:51", bogotaLocation)
+	assert.True(t, expectedTime.Equal(event1.Date))
+}
+
+// TestServientregaAdapter_mapResponseToDomain_DeliveryProof verifies proof fields on the delivered movement.
+func TestServientregaAdapter_mapResponseToDomain_DeliveryProof(t *testing.T) {
+	jsonContent := `{
+		"Code": 1,
+		"Results": [
+			{
+				"numeroGuia": "2200000000",
+				"estadoActual": "ENTREGADO",
+				"imagenEntrega": "https://mobile.servientrega.com/imagenes/2200000000.jpg",
+				"nombreRecibe": "ANA RODRIGUEZ",
+				"movimientos": [
+					{
+						"fecha": "20/01/2026 08:02 ",
+						"movimiento": "En reparto",
+						"ubicacion": "Cali (Valle)",
+						"IdProceso": "18"
+					},
+					{
+						"fecha": "21/01/2026 15:44 ",
+						"movimiento": "Entregado",
+						"ubicacion": "Cali (Valle)",
+						"IdProceso": "21"
+					}
+				]
+			}
+		]
+	}`
+
+	var resp servientregaResponse
+	err := json.Unmarshal([]byte(jsonContent), &resp)
+	require.NoError(t, err)
+
+	adapter := &ServientregaAdapter{
+		logger: zap.NewNop(),
+	}
+	history, err := adapter.mapResponseToDomain(resp)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.TrackingStatusCompleted, history.GlobalStatus)
+	require.Len(t, history.History, 2)
+	assert.Empty(t, history.History[0].ProofURL)
+	assert.Equal(t, "https://mobile.servientrega.com/imagenes/2200000000.jpg", history.History[1].ProofURL)
+	assert.Equal(t, "ANA RODRIGUEZ", history.History[1].SignedBy)
+}
+
+// TestServientregaAdapter_mapResponseToDomain_ShippedAndDeliveredAt verifies history-level dates.
+func TestServientregaAdapter_mapResponseToDomain_ShippedAndDeliveredAt(t *testing.T) {
+	jsonContent := `{
+		"Code": 1,
+		"Results": [
+			{
+				"numeroGuia": "2200000000",
+				"fechaEnvio": "17/01/2026 10:57 ",
+				"estadoActual": "ENTREGADO",
+				"movimientos": [
+					{
+						"fecha": "17/01/2026 10:57 ",
+						"movimiento": "Guia generada",
+						"ubicacion": "Bogota (Cundinamarca)",
+						"IdProceso": "1"
+					},
+					{
+						"fecha": "21/01/2026 15:44 ",
+						"movimiento": "Entregado",
+						"ubicacion": "Cali (Valle)",
+						"IdProceso": "21"
+					}
+				]
+			}
+		]
+	}`
+
+	var resp servientregaResponse
+	err := json.Unmarshal([]byte(jsonContent), &resp)
+	require.NoError(t, err)
+
+	adapter := &ServientregaAdapter{
+		logger: zap.NewNop(),
+	}
+	history, err := adapter.mapResponseToDomain(resp)
+
+	require.NoError(t, err)
+	assert.True(t, time.Date(2026, 1, 17, 15, 57, 0, 0, time.UTC).Equal(history.ShippedAt))
+	assert.True(t, time.Date(2026, 1, 21, 20, 44, 0, 0, time.UTC).Equal(history.DeliveredAt))
+}
+
+// TestServientregaAdapter_mapResponseToDomain_NotDelivered verifies DeliveredAt stays zero in transit.
+func TestServientregaAdapter_mapResponseToDomain_NotDelivered(t *testing.T) {
+	jsonContent := `{
+		"Code": 1,
+		"Results": [
+			{
+				"fechaEnvio": "31/01/2026 12:51 ",
+				"estadoActual": "EN PROCESAMIENTO",
+				"movimientos": [
+					{
+						"fecha": "31/01/2026 12:51 ",
+						"movimiento": "Guia generada",
+						"IdProceso": "1"
+					}
+				]
+			}
+		]
+	}`
+
+	var resp servientregaResponse
+	err := json.Unmarshal([]byte(jsonContent), &resp)
+	require.NoError(t, err)
+
+	adapter := &ServientregaAdapter{
+		logger: zap.NewNop(),
+	}
+	history, err := adapter.mapResponseToDomain(resp)
+
+	require.NoError(t, err)
+	assert.False(t, history.ShippedAt.IsZero())
+	assert.True(t, history.DeliveredAt.IsZero())
+	assert.Empty(t, history.Warnings)
+}
+
+// TestServientregaAdapter_mapResponseToDomain_PartialParse verifies malformed dates are reported as warnings.
+func TestServientregaAdapter_mapResponseToDomain_PartialParse(t *testing.T) {
+	jsonContent := `{
+		"Code": 1,
+		"Results": [
+			{
+				"fechaEnvio": "enero 31",
+				"estadoActual": "EN PROCESAMIENTO",
+				"movimientos": [
+					{"fecha": "31-01-2026", "movimiento": "Guia generada", "IdProceso": "1"},
+					{"fecha": "01/02/2026 09:00 ", "movimiento": "En transito", "IdProceso": "2"}
+				]
+			}
+		]
+	}`
+
+	var resp servientregaResponse
+	require.NoError(t, json.Unmarshal([]byte(jsonContent), &resp))
+
+	adapter := &ServientregaAdapter{
+		logger: zap.NewNop(),
+	}
+	history, err := adapter.mapResponseToDomain(resp)
+
+	require.NoError(t, err)
+	require.Len(t, history.History, 2)
+	assert.True(t, history.ShippedAt.IsZero())
+	assert.False(t, history.History[1].Date.IsZero())
+	assert.Equal(t, []string{
+		`invalid shipped date "enero 31"`,
+		`event 0: invalid date "31-01-2026"`,
+	}, history.Warnings)
+}
+
+// TestServientregaAdapter_mapResponseToDomain_NoResults verifies an empty answer is reported as an unknown shipment.
+func TestServientregaAdapter_mapResponseToDomain_NoResults(t *testing.T) {
+	adapter := &ServientregaAdapter{
+		logger: zap.NewNop(),
+	}
+	_, err := adapter.mapResponseToDomain(servientregaResponse{Code: 2})
+
+	assert.ErrorIs(t, err, ports.ErrShipmentNotFound)
+}
+
+// TestServientregaAdapter_mapResponseToDomain_Timezone verifies timestamps are parsed as Colombia time (UTC-5).
+func TestServientregaAdapter_mapResponseToDomain_Timezone(t *testing.T) {
+	jsonContent := `{
+		"Code": 1,
+		"Results": [
+			{
+				"estadoActual": "EN PROCESAMIENTO",
+				"movimientos": [
+					{
+						"fecha": "31/01/2026 12:51 ",
+						"movimiento": "Guia generada",
+						"IdProceso": "1"
+					}
+				]
+			}
+		]
+	}`
+
+	var resp servientregaResponse
+	err := json.Unmarshal([]byte(jsonContent), &resp)
+	require.NoError(t, err)
+
+	adapter := &ServientregaAdapter{
+		logger: zap.NewNop(),
+	}
+	history, err := adapter.mapResponseToDomain(resp)
+
+	require.NoError(t, err)
+	require.Len(t, history.History, 1)
+
+	date := history.History[0].Date
+	assert.Equal(t, 12, date.Hour())
+	assert.Equal(t, time.Date(2026, 1, 31, 17, 51, 0, 0, time.UTC), date.UTC())
+
+	encoded, err := json.Marshal(date)
+	require.NoError(t, err)
+	assert.Equal(t, `"2026-01-31T12:51:00-05:00"`, string(encoded))
+}
+
+// TestIsEmptySuccess verifies only successful responses without results trigger a reload.
+func TestIsEmptySuccess(t *testing.T) {
+	var resp servientregaResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"Code": 1, "Results": []}`), &resp))
+	assert.True(t, isEmptySuccess(resp))
+
+	require.NoError(t, json.Unmarshal([]byte(`{"Code": 0, "Results": []}`), &resp))
+	assert.False(t, isEmptySuccess(resp))
+
+	require.NoError(t, json.Unmarshal([]byte(`{"Code": 1, "Results": [{"estadoActual": "ENTREGADO"}]}`), &resp))
+	assert.False(t, isEmptySuccess(resp))
+}
+
+// TestServientregaAdapter_normalizeTrackingNumber verifies prefixes and separators are dropped and leading zeros kept.
+func TestServientregaAdapter_normalizeTrackingNumber(t *testing.T) {
+	adapter := &ServientregaAdapter{}
+
+	tests := map[string]string{
+		"2259176775":     "2259176775",
+		"\t2259176775 ":  "2259176775",
+		"SV2259176775":   "2259176775",
+		"Guía: 22591767": "22591767",
+		"0022591767":     "0022591767",
+	}
+	for raw, want := range tests {
+		assert.Equal(t, want, adapter.normalizeTrackingNumber(raw), raw)
+	}
+}
+
+// TestServientregaAdapter_mapResponseToDomain_RawStatus verifies estadoActual is kept as the raw status.
+func TestServientregaAdapter_mapResponseToDomain_RawStatus(t *testing.T) {
+	adapter := &ServientregaAdapter{logger: zap.NewNop()}
+
+	var resp servientregaResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"Code": 1, "Results": [{"estadoActual": " EN REPARTO ", "movimientos": [
+		{"fecha": "20/01/2026 08:02 ", "movimiento": "En reparto", "IdProceso": "18"}
+	]}]}`), &resp))
+
+	history, err := adapter.mapResponseToDomain(resp)
+	require.NoError(t, err)
+	assert.Equal(t, "EN REPARTO", history.RawStatus)
+	assert.Equal(t, domain.TrackingStatusProcessing, history.GlobalStatus)
+
+	var empty servientregaResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"Code": 1, "Results": [{"estadoActual": ""}]}`), &empty))
+	history, err = adapter.mapResponseToDomain(empty)
+	require.NoError(t, err)
+	assert.Empty(t, history.RawStatus)
+}
+
+// TestServientregaAdapter_checkConnectivity verifies each connectivity mode: a HEAD that skips the proxy by default,
+// a HEAD through the proxy, and no request at all when off.
+func TestServientregaAdapter_checkConnectivity(t *testing.T) {
+	var targetMethods, proxiedMethods []string
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		targetMethods = append(targetMethods, r.Method)
+	}))
+	defer target.Close()
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxiedMethods = append(proxiedMethods, r.Method)
+	}))
+	defer upstream.Close()
+
+	proxyURL, err := url.Parse(upstream.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(proxyURL.Port())
+	require.NoError(t, err)
+	proxySettings := proxy.Settings{Enabled: true, Hostname: proxyURL.Hostname(), Port: port}
+
+	tests := []struct {
+		check   ConnectivityCheck
+		direct  []string
+		proxied []string
+	}{
+		{"", []string{http.MethodHead}, nil},
+		{ConnectivityCheckProxy, nil, []string{http.MethodHead}},
+		{ConnectivityCheckOff, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.check), func(t *testing.T) {
+			targetMethods, proxiedMethods = nil, nil
+			adapter := NewServientregaAdapter(target.URL+"/?Guia=", "", proxySettings, nil, 0, tt.check, 0, nil)
+
+			require.NoError(t, adapter.checkConnectivity(context.Background(), target.URL, proxySettings, "test-agent"))
+			assert.Equal(t, tt.direct, targetMethods)
+			assert.Equal(t, tt.proxied, proxiedMethods)
+		})
+	}
+}
+
+// TestServientregaAdapter_checkConnectivity_HeadNotAllowed verifies the check retries with GET when HEAD gets 405.
+func TestServientregaAdapter_checkConnectivity_HeadNotAllowed(t *testing.T) {
+	var methods []string
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer target.Close()
+
+	adapter := NewServientregaAdapter(target.URL+"/?Guia=", "", proxy.Settings{}, nil, 0, ConnectivityCheckDirect, 0, nil)
+
+	require.NoError(t, adapter.checkConnectivity(context.Background(), target.URL, proxy.Settings{}, "test-agent"))
+	assert.Equal(t, []string{http.MethodHead, http.MethodGet}, methods)
+}
+
+// TestServientregaAdapter_SetLocation verifies movement and shipped dates are read in the configured location.
+func TestServientregaAdapter_SetLocation(t *testing.T) {
+	var resp servientregaResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"Results": [{
+		"estadoActual": "EN PROCESAMIENTO",
+		"fechaEnvio": "30/01/2026 09:15",
+		"movimientos": [{"fecha": "31/01/2026 12:51 ", "movimiento": "Guia generada", "IdProceso": "1"}]
+	}]}`), &resp))
+
+	adapter := &ServientregaAdapter{logger: zap.NewNop()}
+	adapter.SetLocation(time.FixedZone("UTC+1", 60*60))
+	history, err := adapter.mapResponseToDomain(resp)
+
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 1, 31, 11, 51, 0, 0, time.UTC), history.History[0].Date.UTC())
+	assert.Equal(t, time.Date(2026, 1, 30, 8, 15, 0, 0, time.UTC), history.ShippedAt.UTC())
 }
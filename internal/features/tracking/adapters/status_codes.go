@@ -0,0 +1,60 @@
+package adapter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"tracker-scrapper/internal/features/tracking/domain"
+)
+
+// StatusCodes maps a courier event code to the global status it implies.
+// An empty status marks the code as known without changing the current global status.
+type StatusCodes map[string]domain.TrackingStatus
+
+// lookup resolves a code against the configured codes first and then the built-in defaults.
+func (s StatusCodes) lookup(defaults StatusCodes, code string) (domain.TrackingStatus, bool) {
+	if status, ok := s[code]; ok {
+		return status, true
+	}
+	status, ok := defaults[code]
+	return status, ok
+}
+
+// validStatuses lists the statuses accepted in a status codes file.
+var validStatuses = map[domain.TrackingStatus]bool{
+	"":                              true,
+	domain.TrackingStatusProcessing: true,
+	domain.TrackingStatusCompleted:  true,
+	domain.TrackingStatusOrigin:     true,
+	domain.TrackingStatusReturn:     true,
+	domain.TrackingStatusIncidence:  true,
+	domain.TrackingStatusUnknown:    true,
+}
+
+// LoadStatusCodes reads per-courier status code maps from a JSON file, e.g.:
+//
+//	{"coordinadora_co": {"9": "RETURN"}, "interrapidisimo_co": {"12": ""}}
+//
+// Codes in the file take precedence over the adapters' built-in defaults.
+func LoadStatusCodes(path string) (map[string]StatusCodes, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read status codes file: %w", err)
+	}
+
+	var codes map[string]StatusCodes
+	if err := json.Unmarshal(data, &codes); err != nil {
+		return nil, fmt.Errorf("failed to parse status codes file: %w", err)
+	}
+
+	for courier, courierCodes := range codes {
+		for code, status := range courierCodes {
+			if !validStatuses[status] {
+				return nil, fmt.Errorf("invalid status %q for %s code %s", status, courier, code)
+			}
+		}
+	}
+
+	return codes, nil
+}
@@ -0,0 +1,59 @@
+package adapter
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"tracker-scrapper/internal/features/tracking/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// TestLoadStatusCodes verifies a valid file is loaded per courier.
+func TestLoadStatusCodes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "codes.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"interrapidisimo_co": {"12": "RETURN", "13": ""}}`), 0o600))
+
+	codes, err := LoadStatusCodes(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.TrackingStatusReturn, codes["interrapidisimo_co"]["12"])
+	assert.Contains(t, codes["interrapidisimo_co"], "13")
+}
+
+// TestLoadStatusCodes_InvalidStatus verifies unknown statuses are rejected.
+func TestLoadStatusCodes_InvalidStatus(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "codes.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"coordinadora_co": {"9": "LOST"}}`), 0o600))
+
+	_, err := LoadStatusCodes(path)
+
+	assert.Error(t, err)
+}
+
+// TestInterrapidisimoAdapter_mapResponseToDomain_ConfiguredCode verifies configured codes override defaults.
+func TestInterrapidisimoAdapter_mapResponseToDomain_ConfiguredCode(t *testing.T) {
+	jsonContent := `{
+    "EstadosGuia": [
+        {"EstadoGuia": {"IdEstadoGuia": 1, "DescripcionEstadoGuia": "Recibimos tú envío"}},
+        {"EstadoGuia": {"IdEstadoGuia": 12, "DescripcionEstadoGuia": "Devolución al remitente"}}
+    ],
+    "Success": true
+}`
+
+	var resp interResponse
+	require.NoError(t, json.Unmarshal([]byte(jsonContent), &resp))
+
+	adapter := &InterrapidisimoAdapter{
+		logger:      zap.NewNop(),
+		statusCodes: StatusCodes{"12": domain.TrackingStatusReturn},
+	}
+	history, err := adapter.mapResponseToDomain(resp)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.TrackingStatusReturn, history.GlobalStatus)
+}
@@ -0,0 +1,27 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultTimeout is the lookup budget used when an adapter is constructed with a zero timeout.
+const DefaultTimeout = 60 * time.Second
+
+// effectiveTimeout returns timeout, or DefaultTimeout when it is not positive.
+func effectiveTimeout(timeout time.Duration) time.Duration {
+	if timeout <= 0 {
+		return DefaultTimeout
+	}
+	return timeout
+}
+
+// timeoutError annotates err with the courier's timeout when the lookup ran out of time.
+func timeoutError(courier string, timeout time.Duration, err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%s lookup timed out after %s: %w", courier, timeout, err)
+	}
+	return err
+}
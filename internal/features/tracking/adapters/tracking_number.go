@@ -0,0 +1,22 @@
+package adapter
+
+import (
+	"strings"
+	"unicode"
+)
+
+// digitsOnly returns the digits of raw, dropping spaces, dashes, letter prefixes and anything
+// else users paste around a guide number. When raw has no digits at all it is returned trimmed,
+// so the courier still answers with its own "not found" instead of being sent an empty number.
+func digitsOnly(raw string) string {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, raw)
+	if digits == "" {
+		return strings.TrimFunc(raw, unicode.IsSpace)
+	}
+	return digits
+}
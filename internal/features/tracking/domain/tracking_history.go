@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"slices"
+	"time"
+)
 
 // TrackingStatus represents the current global status of a shipment.
 type TrackingStatus string
@@ -16,14 +19,89 @@ const (
 	TrackingStatusReturn TrackingStatus = "RETURN"
 	// TrackingStatusIncidence indicates there is an issue with the shipment.
 	TrackingStatusIncidence TrackingStatus = "INCIDENCE"
+	// TrackingStatusUnknown indicates the latest courier event has an unmapped code.
+	TrackingStatusUnknown TrackingStatus = "UNKNOWN"
 )
 
+// Progress returns how far along, in percent, a shipment in status s is when its events don't say
+// anything more specific. Return, incidence and unknown statuses don't tell how far the shipment got
+// and report 0.
+func (s TrackingStatus) Progress() int {
+	switch s {
+	case TrackingStatusOrigin:
+		return 20
+	case TrackingStatusProcessing:
+		return 50
+	case TrackingStatusCompleted:
+		return 100
+	default:
+		return 0
+	}
+}
+
 // TrackingHistory represents the complete tracking information for a shipment.
 type TrackingHistory struct {
+	// Courier is the normalized name of the courier that produced the history (e.g., "servientrega_co").
+	Courier string `json:"courier"`
 	// GlobalStatus is the overall status of the shipment.
 	GlobalStatus TrackingStatus `json:"global_status"`
+	// ProgressPct is how far along the shipment is towards delivery, from 0 to 100, for progress bars.
+	// It is derived from GlobalStatus and the courier's event codes.
+	ProgressPct int `json:"progress_pct"`
+	// RawStatus is the courier's own wording of the current status, as shown on its tracking page.
+	RawStatus string `json:"raw_status,omitempty"`
 	// History contains the chronological events for the shipment.
 	History []TrackingEvent `json:"history"`
+	// ShippedAt is when the courier received the shipment. Zero if the courier doesn't report it.
+	ShippedAt time.Time `json:"shipped_at,omitzero"`
+	// DeliveredAt is when the shipment was delivered. Zero until delivery.
+	DeliveredAt time.Time `json:"delivered_at,omitzero"`
+	// Warnings describes courier events that could only be partially parsed (e.g., a malformed date).
+	// The affected events are still included in History with the unparsable fields left empty.
+	Warnings []string `json:"warnings,omitempty"`
+	// PublicURL is the courier's own tracking page for the shipment, for customers to open.
+	PublicURL string `json:"public_url,omitempty"`
+	// RetrievedAt is when the history was scraped; cached copies keep the original time.
+	RetrievedAt time.Time `json:"retrieved_at,omitzero"`
+}
+
+// Latest returns a copy of h keeping only the n most recent events, in chronological order.
+// GlobalStatus and the other fields are kept as computed from the full history.
+// It returns h unchanged when n is not positive or the history is already short enough.
+func (h *TrackingHistory) Latest(n int) *TrackingHistory {
+	if h == nil || n <= 0 || len(h.History) <= n {
+		return h
+	}
+
+	events := slices.Clone(h.History)
+	slices.SortStableFunc(events, func(a, b TrackingEvent) int {
+		return a.Date.Compare(b.Date)
+	})
+
+	truncated := *h
+	truncated.History = events[len(events)-n:]
+	return &truncated
+}
+
+// LatestEvent returns the most recent event of the history, or nil when it has none. Courier events
+// are not always in chronological order, so the latest date wins; among equal dates the last one listed.
+func (h *TrackingHistory) LatestEvent() *TrackingEvent {
+	if h == nil {
+		return nil
+	}
+
+	var latest *TrackingEvent
+	for i := range h.History {
+		if latest == nil || !h.History[i].Date.Before(latest.Date) {
+			latest = &h.History[i]
+		}
+	}
+	return latest
+}
+
+// IsDelivered reports whether the shipment has been delivered.
+func (h *TrackingHistory) IsDelivered() bool {
+	return h != nil && h.GlobalStatus == TrackingStatusCompleted
 }
 
 // TrackingEvent represents a single event in the shipment's tracking history.
@@ -36,4 +114,8 @@ type TrackingEvent struct {
 	City string `json:"city"`
 	// Code is the courier-specific status code for this event.
 	Code string `json:"code"`
+	// ProofURL is the delivery proof (signature image or photo) URL, only set on delivered events.
+	ProofURL string `json:"proof_url,omitempty"`
+	// SignedBy is the name of the person who received the shipment, only set on delivered events.
+	SignedBy string `json:"signed_by,omitempty"`
 }
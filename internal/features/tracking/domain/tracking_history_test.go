@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// day returns midnight UTC of the given day of January 2024.
+func day(d int) time.Time {
+	return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+}
+
+// TestTrackingHistory_Latest verifies the most recent events are kept in chronological order.
+func TestTrackingHistory_Latest(t *testing.T) {
+	h := &TrackingHistory{
+		GlobalStatus: TrackingStatusReturn,
+		History: []TrackingEvent{
+			{Date: day(3), Code: "3"},
+			{Date: day(1), Code: "1"},
+			{Date: day(4), Code: "4"},
+			{Date: day(2), Code: "2"},
+		},
+	}
+
+	latest := h.Latest(2)
+
+	require.Len(t, latest.History, 2)
+	assert.Equal(t, "3", latest.History[0].Code)
+	assert.Equal(t, "4", latest.History[1].Code)
+	assert.Equal(t, TrackingStatusReturn, latest.GlobalStatus)
+	assert.Len(t, h.History, 4, "the original history must not be modified")
+	assert.Equal(t, "3", h.History[0].Code)
+}
+
+// TestTrackingHistory_Latest_NoTruncation verifies short histories and non-positive limits are returned as-is.
+func TestTrackingHistory_Latest_NoTruncation(t *testing.T) {
+	h := &TrackingHistory{History: []TrackingEvent{{Code: "1"}, {Code: "2"}}}
+
+	assert.Same(t, h, h.Latest(0))
+	assert.Same(t, h, h.Latest(2))
+	assert.Same(t, h, h.Latest(5))
+}
+
+// TestTrackingHistory_LatestEvent verifies the event with the latest date is returned regardless of order.
+func TestTrackingHistory_LatestEvent(t *testing.T) {
+	h := &TrackingHistory{History: []TrackingEvent{
+		{Date: day(2), Code: "2"},
+		{Date: day(4), Code: "4"},
+		{Date: day(1), Code: "1"},
+	}}
+
+	latest := h.LatestEvent()
+
+	require.NotNil(t, latest)
+	assert.Equal(t, "4", latest.Code)
+	assert.Same(t, &h.History[1], latest)
+}
+
+// TestTrackingHistory_LatestEvent_SameDate verifies the last listed event wins among equal dates, as with undated events.
+func TestTrackingHistory_LatestEvent_SameDate(t *testing.T) {
+	h := &TrackingHistory{History: []TrackingEvent{{Code: "1"}, {Code: "2"}}}
+
+	require.NotNil(t, h.LatestEvent())
+	assert.Equal(t, "2", h.LatestEvent().Code)
+}
+
+// TestTrackingHistory_LatestEvent_Empty verifies empty and nil histories have no latest event.
+func TestTrackingHistory_LatestEvent_Empty(t *testing.T) {
+	var missing *TrackingHistory
+
+	assert.Nil(t, (&TrackingHistory{}).LatestEvent())
+	assert.Nil(t, (&TrackingHistory{History: []TrackingEvent{}}).LatestEvent())
+	assert.Nil(t, missing.LatestEvent())
+}
+
+// TestTrackingHistory_IsDelivered verifies only completed shipments count as delivered.
+func TestTrackingHistory_IsDelivered(t *testing.T) {
+	var missing *TrackingHistory
+
+	assert.True(t, (&TrackingHistory{GlobalStatus: TrackingStatusCompleted}).IsDelivered())
+	assert.False(t, (&TrackingHistory{GlobalStatus: TrackingStatusReturn}).IsDelivered())
+	assert.False(t, (&TrackingHistory{}).IsDelivered())
+	assert.False(t, missing.IsDelivered())
+}
+
+// TestTrackingStatus_Progress verifies the progress implied by each status on its own.
+func TestTrackingStatus_Progress(t *testing.T) {
+	tests := []struct {
+		status TrackingStatus
+		want   int
+	}{
+		{TrackingStatusOrigin, 20},
+		{TrackingStatusProcessing, 50},
+		{TrackingStatusCompleted, 100},
+		{TrackingStatusReturn, 0},
+		{TrackingStatusIncidence, 0},
+		{TrackingStatusUnknown, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.status.Progress())
+		})
+	}
+}
@@ -1,29 +1,103 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"tracker-scrapper/internal/core/auth"
+	"tracker-scrapper/internal/core/logger"
+	"tracker-scrapper/internal/core/maintenance"
+	"tracker-scrapper/internal/core/request"
+	"tracker-scrapper/internal/core/scraper"
+	"tracker-scrapper/internal/features/tracking/ports"
 	"tracker-scrapper/internal/features/tracking/service"
 
 	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// trackingNumberPattern restricts tracking numbers to values that are safe to embed in courier URLs.
+var trackingNumberPattern = regexp.MustCompile(`^[A-Za-z0-9-]{4,40}$`)
+
+// maxBatchItems caps the number of shipments accepted in a single batch request.
+const maxBatchItems = 50
+
+// busyRetryAfter is suggested to clients when no browser was free. Browsers free up as running
+// scrapes finish, so there is no reset time to report; this is about how long a scrape takes.
+const busyRetryAfter = 10 * time.Second
+
+// Defaults for watch requests until SetWatchTiming is called.
+const (
+	// defaultWatchTimeout is how long a watch request is held waiting for a change.
+	defaultWatchTimeout = 30 * time.Second
+	// defaultWatchInterval is how often a held watch request looks the tracking up again.
+	defaultWatchInterval = 5 * time.Second
+)
+
+// Per-request courier override headers.
+const (
+	// headerCourierBaseURL overrides the configured courier tracking URL.
+	headerCourierBaseURL = "X-Courier-Base-URL"
+	// headerCourierAuthorization is forwarded as the Authorization header to the courier API.
+	headerCourierAuthorization = "X-Courier-Authorization"
 )
 
 // TrackingHandler handles HTTP requests for tracking operations.
 type TrackingHandler struct {
 	trackingService *service.TrackingService
+	// overrideAllowedHosts lists the hosts accepted in X-Courier-Base-URL. Empty disables overrides.
+	overrideAllowedHosts []string
+	// strictJSON rejects request bodies containing unknown fields.
+	strictJSON bool
+	// autoDetect lets callers omit the courier; candidates are derived from the tracking number.
+	autoDetect bool
+	// watchTimeout bounds how long a watch request is held before answering 304.
+	watchTimeout time.Duration
+	// watchInterval is the pause between lookups while a watch request is held.
+	watchInterval time.Duration
 }
 
 // NewTrackingHandler creates a new TrackingHandler.
-func NewTrackingHandler(trackingService *service.TrackingService) *TrackingHandler {
+// overrideAllowedHosts enables per-request courier overrides for the listed hosts.
+// autoDetect makes the courier query parameter optional.
+func NewTrackingHandler(trackingService *service.TrackingService, overrideAllowedHosts []string, strictJSON, autoDetect bool) *TrackingHandler {
 	return &TrackingHandler{
-		trackingService: trackingService,
+		trackingService:      trackingService,
+		overrideAllowedHosts: overrideAllowedHosts,
+		strictJSON:           strictJSON,
+		autoDetect:           autoDetect,
+		watchTimeout:         defaultWatchTimeout,
+		watchInterval:        defaultWatchInterval,
 	}
 }
 
+// SetWatchTiming sets how long watch requests are held and how often they look the tracking up again.
+// Call it before the handler serves requests.
+func (h *TrackingHandler) SetWatchTiming(timeout, interval time.Duration) {
+	h.watchTimeout = timeout
+	h.watchInterval = interval
+}
+
+// BatchRequest represents the request body for batch tracking lookups.
+type BatchRequest struct {
+	// Items lists the shipments to look up.
+	Items []service.BatchItem `json:"items"`
+}
+
 // ErrorResponse represents an error response with Ray ID.
 type ErrorResponse struct {
 	// Message is the error description.
 	Message string `json:"message"`
 	// RayID is the unique request identifier for tracing.
 	RayID string `json:"ray_id,omitempty"`
+	// Maintenance is true when the error was caused by maintenance mode.
+	Maintenance bool `json:"maintenance,omitempty"`
 }
 
 // GetTrackingHistory godoc
@@ -33,42 +107,353 @@ type ErrorResponse struct {
 // @Accept json
 // @Produce json
 // @Param number path string true "Tracking Number"
-// @Param courier query string true "Courier name (e.g., coordinadora_co, servientrega_co)"
+// @Param courier query string false "Courier name (e.g., coordinadora_co, servientrega_co). Required unless courier auto-detection is enabled"
+// @Param limit query int false "Return only the N most recent events"
+// @Param X-Courier-Base-URL header string false "Override the courier tracking URL (admin key only; host must be allowlisted)"
+// @Param X-Courier-Authorization header string false "Authorization value forwarded to the courier API (admin key only)"
 // @Success 200 {object} domain.TrackingHistory
-// @Failure 400 {object} ErrorResponse
+// @Header 200 {string} X-Cache "HIT when served from cache, MISS otherwise"
+// @Header 200 {string} X-Maintenance-Mode "true when served in maintenance mode"
+// @Header 200 {string} X-Courier "Detected courier when the courier parameter was omitted"
+// @Header 200 {string} ETag "Entity tag of the body; send it back in If-None-Match to get a 304"
+// @Success 304 "Not modified since the ETag in If-None-Match"
+// @Failure 400 {object} request.ValidationErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Failure 502 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Failure 504 {object} ErrorResponse
 // @Router /tracking/{number} [get]
 func (h *TrackingHandler) GetTrackingHistory(c *fiber.Ctx) error {
 	trackingNumber := c.Params("number")
-	if trackingNumber == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Message: "tracking number is required",
-			RayID:   c.Locals("requestid").(string),
+	courier := c.Query("courier")
+	detect := courier == "" && h.autoDetect
+
+	var errs []request.ValidationError
+	switch {
+	case trackingNumber == "":
+		errs = append(errs, request.ValidationError{Field: "number", Message: "required"})
+	case !trackingNumberPattern.MatchString(trackingNumber):
+		errs = append(errs, request.ValidationError{Field: "number", Message: "must be 4-40 characters long and contain only letters, digits or dashes"})
+	default:
+		if err := h.checkNumber(trackingNumber, courier); err != nil {
+			errs = append(errs, request.ValidationError{Field: "number", Message: err.Error()})
+		}
+	}
+	switch {
+	case courier == "" && !detect:
+		errs = append(errs, request.ValidationError{Field: "courier", Message: "required"})
+	case !detect && !h.trackingService.SupportsCourier(courier):
+		errs = append(errs, request.ValidationError{Field: "courier", Message: "not supported: " + courier})
+	}
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			errs = append(errs, request.ValidationError{Field: "limit", Message: "must be a positive integer"})
+		}
+		limit = n
+	}
+	if len(errs) > 0 {
+		return request.Invalid(c, errs...)
+	}
+
+	overrides, hasOverrides := h.parseOverrides(c)
+	if hasOverrides && (!auth.IsAdmin(c) || !h.overridesAllowed(overrides)) {
+		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
+			Message: "courier override not allowed",
+			RayID:   request.RayID(c),
 		})
 	}
+	if hasOverrides && detect {
+		return request.Invalid(c, request.ValidationError{Field: "courier", Message: "required with courier overrides"})
+	}
+
+	// The lookup may outlive the request, so it must not share memory with the Fiber context
+	number, courierName := strings.Clone(trackingNumber), strings.Clone(courier)
+	overrides = ports.Overrides{BaseURL: strings.Clone(overrides.BaseURL), Authorization: strings.Clone(overrides.Authorization)}
+
+	result, err := request.Await(c.UserContext(), func() (*service.TrackingResult, error) {
+		switch {
+		case detect:
+			return h.trackingService.DetectTrackingHistory(number)
+		case hasOverrides:
+			return h.trackingService.GetTrackingHistoryWithOverrides(number, courierName, overrides)
+		default:
+			return h.trackingService.GetTrackingHistory(number, courierName)
+		}
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		// Answered with 504 by the request timeout middleware
+		return err
+	}
+	if err != nil {
+		return h.lookupError(c, err, trackingNumber, courier)
+	}
 
+	c.Set("X-Cache", cacheStatus(result.FromCache))
+	if result.Courier != "" {
+		c.Set("X-Courier", result.Courier)
+	}
+	if result.Maintenance {
+		c.Set("X-Maintenance-Mode", "true")
+	}
+	// The full history stays cached; only the response is truncated
+	history := result.History.Latest(limit)
+	tag := result.ETag
+	if history != result.History {
+		tag = service.HistoryETag(history)
+	}
+	return request.JSONWithETag(c, history, tag)
+}
+
+// WatchTrackingHistory godoc
+// @Summary Wait for a tracking history to change
+// @Description Holds the request until the tracking history no longer matches the ETag in since, looking it up again on an interval. The first lookup may be served from the cache; later ones scrape the courier again (bypassing and refreshing the cache), so changes show up before the cache TTL expires. Answers 304 when nothing changed before the watch timeout.
+// @Tags tracking
+// @Accept json
+// @Produce json
+// @Param number path string true "Tracking Number"
+// @Param courier query string true "Courier name (e.g., coordinadora_co, servientrega_co)"
+// @Param since query string false "ETag of the history the client already has. Empty returns the current history"
+// @Success 200 {object} domain.TrackingHistory
+// @Header 200 {string} X-Cache "HIT when served from cache, MISS otherwise"
+// @Header 200 {string} X-Maintenance-Mode "true when served in maintenance mode"
+// @Header 200 {string} ETag "Entity tag of the body; send it back in since to keep watching"
+// @Success 304 "Not changed before the watch timeout"
+// @Failure 400 {object} request.ValidationErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 502 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /tracking/{number}/watch [get]
+func (h *TrackingHandler) WatchTrackingHistory(c *fiber.Ctx) error {
+	trackingNumber := c.Params("number")
 	courier := c.Query("courier")
-	if courier == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Message: "courier query parameter is required",
-			RayID:   c.Locals("requestid").(string),
-		})
+	since := c.Query("since")
+
+	var errs []request.ValidationError
+	if !trackingNumberPattern.MatchString(trackingNumber) {
+		errs = append(errs, request.ValidationError{Field: "number", Message: "must be 4-40 characters long and contain only letters, digits or dashes"})
+	} else if err := h.checkNumber(trackingNumber, courier); err != nil {
+		errs = append(errs, request.ValidationError{Field: "number", Message: err.Error()})
+	}
+	switch {
+	case courier == "":
+		errs = append(errs, request.ValidationError{Field: "courier", Message: "required"})
+	case !h.trackingService.SupportsCourier(courier):
+		errs = append(errs, request.ValidationError{Field: "courier", Message: "not supported: " + courier})
+	}
+	if len(errs) > 0 {
+		return request.Invalid(c, errs...)
 	}
 
-	history, err := h.trackingService.GetTrackingHistory(trackingNumber, courier)
+	// Watches are bounded by watchTimeout rather than the server's request timeout
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c.UserContext()), h.watchTimeout)
+	defer cancel()
+
+	result, err := h.trackingService.WatchTrackingHistory(ctx, trackingNumber, courier, since, h.watchInterval)
+	if errors.Is(err, service.ErrNotChanged) {
+		c.Set(fiber.HeaderETag, since)
+		return c.SendStatus(fiber.StatusNotModified)
+	}
 	if err != nil {
-		if err == service.ErrCourierNotSupported {
-			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
-				Message: "courier not supported",
-				RayID:   c.Locals("requestid").(string),
-			})
-		}
+		return h.lookupError(c, err, trackingNumber, courier)
+	}
+
+	c.Set("X-Cache", cacheStatus(result.FromCache))
+	if result.Maintenance {
+		c.Set("X-Maintenance-Mode", "true")
+	}
+	return request.JSONWithETag(c, result.History, result.ETag)
+}
 
-		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
-			Message: err.Error(),
-			RayID:   c.Locals("requestid").(string),
+// lookupError answers a failed tracking lookup with the status matching err.
+func (h *TrackingHandler) lookupError(c *fiber.Ctx, err error, trackingNumber, courier string) error {
+	if errors.Is(err, service.ErrOverridesNotSupported) {
+		return request.Invalid(c, request.ValidationError{Field: "courier", Message: "does not support overrides"})
+	}
+
+	if err == service.ErrCourierNotSupported {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+			Message: "courier not supported",
+			RayID:   request.RayID(c),
 		})
 	}
 
-	return c.JSON(history)
+	if errors.Is(err, service.ErrTrackingNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+			Message: "tracking number not found with any matching courier",
+			RayID:   request.RayID(c),
+		})
+	}
+
+	if errors.Is(err, maintenance.ErrCacheMiss) {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(ErrorResponse{
+			Message:     "service under maintenance: tracking not available in cache",
+			RayID:       request.RayID(c),
+			Maintenance: true,
+		})
+	}
+
+	if errors.Is(err, scraper.ErrCourierBusy) {
+		request.SetRetryAfter(c, busyRetryAfter)
+		return c.Status(fiber.StatusServiceUnavailable).JSON(ErrorResponse{
+			Message: "too many concurrent lookups, try again later",
+			RayID:   request.RayID(c),
+		})
+	}
+
+	if errors.Is(err, scraper.ErrCourierLayoutChanged) {
+		logger.With(request.RayID(c)).Error("Courier page layout changed",
+			zap.String("tracking_number", trackingNumber),
+			zap.String("courier", courier),
+			zap.Error(err),
+		)
+		return c.Status(fiber.StatusBadGateway).JSON(ErrorResponse{
+			Message: "courier page changed and could not be read, try again later",
+			RayID:   request.RayID(c),
+		})
+	}
+
+	var unavailable *service.UnavailableError
+	if errors.As(err, &unavailable) {
+		request.SetRetryAfter(c, unavailable.RetryAfter)
+	}
+	if errors.Is(err, service.ErrCourierUnavailable) {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(ErrorResponse{
+			Message: "courier temporarily unavailable, try again later",
+			RayID:   request.RayID(c),
+		})
+	}
+
+	logger.With(request.RayID(c)).Error("Failed to fetch tracking",
+		zap.String("tracking_number", trackingNumber),
+		zap.String("courier", courier),
+		zap.Error(err),
+	)
+	return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+		Message: err.Error(),
+		RayID:   request.RayID(c),
+	})
+}
+
+// cacheStatus returns the X-Cache header value for a response.
+func cacheStatus(fromCache bool) string {
+	if fromCache {
+		return "HIT"
+	}
+	return "MISS"
+}
+
+// GetTrackingHistoryBatch godoc
+// @Summary Get tracking history for several shipments
+// @Description Looks up multiple tracking numbers concurrently. Each item carries either its history or an error.
+// @Tags tracking
+// @Accept json
+// @Produce json
+// @Param request body BatchRequest true "Shipments to track"
+// @Success 200 {array} service.BatchResult
+// @Failure 400 {object} request.ValidationErrorResponse
+// @Failure 504 {object} ErrorResponse
+// @Router /tracking/batch [post]
+func (h *TrackingHandler) GetTrackingHistoryBatch(c *fiber.Ctx) error {
+	var req BatchRequest
+	if err := request.ParseJSON(c, &req, h.strictJSON); err != nil {
+		return request.Invalid(c, request.BodyError(err))
+	}
+
+	if len(req.Items) == 0 || len(req.Items) > maxBatchItems {
+		return request.Invalid(c, request.ValidationError{
+			Field:   "items",
+			Message: fmt.Sprintf("must contain between 1 and %d entries", maxBatchItems),
+		})
+	}
+
+	// Missing fields mean a malformed request; bad values are still reported per item below
+	var errs []request.ValidationError
+	for i, item := range req.Items {
+		if item.Number == "" {
+			errs = append(errs, request.ValidationError{Field: fmt.Sprintf("items[%d].number", i), Message: "required"})
+		}
+		if item.Courier == "" {
+			errs = append(errs, request.ValidationError{Field: fmt.Sprintf("items[%d].courier", i), Message: "required"})
+		}
+	}
+	if len(errs) > 0 {
+		return request.Invalid(c, errs...)
+	}
+
+	// Invalid items are reported individually instead of failing the whole batch
+	results := make([]service.BatchResult, len(req.Items))
+	valid := make([]service.BatchItem, 0, len(req.Items))
+	validIdx := make([]int, 0, len(req.Items))
+	for i, item := range req.Items {
+		switch {
+		case !trackingNumberPattern.MatchString(item.Number):
+			results[i] = service.BatchResult{Number: item.Number, Courier: item.Courier, Error: "invalid tracking number"}
+		case !h.trackingService.SupportsCourier(item.Courier):
+			results[i] = service.BatchResult{Number: item.Number, Courier: item.Courier, Error: "courier not supported"}
+		default:
+			if err := h.trackingService.CheckTrackingNumber(item.Number, item.Courier); err != nil {
+				results[i] = service.BatchResult{Number: item.Number, Courier: item.Courier, Error: "invalid tracking number: " + err.Error()}
+				continue
+			}
+			valid = append(valid, item)
+			validIdx = append(validIdx, i)
+		}
+	}
+
+	ctx := c.UserContext()
+	batch, err := request.Await(ctx, func() ([]service.BatchResult, error) {
+		return h.trackingService.GetTrackingHistoryBatch(ctx, valid), nil
+	})
+	if err != nil {
+		// Answered with 504 by the request timeout middleware
+		return err
+	}
+	for i, res := range batch {
+		results[validIdx[i]] = res
+	}
+
+	return c.JSON(results)
+}
+
+// checkNumber applies the service's sanity checks to a tracking number that matched trackingNumberPattern.
+// Unsupported couriers are reported on their own, so only the checks shared by every courier apply to them.
+func (h *TrackingHandler) checkNumber(trackingNumber, courier string) error {
+	if !h.trackingService.SupportsCourier(courier) {
+		courier = ""
+	}
+	return h.trackingService.CheckTrackingNumber(trackingNumber, courier)
+}
+
+// parseOverrides reads the per-request courier override headers.
+func (h *TrackingHandler) parseOverrides(c *fiber.Ctx) (ports.Overrides, bool) {
+	overrides := ports.Overrides{
+		BaseURL:       c.Get(headerCourierBaseURL),
+		Authorization: c.Get(headerCourierAuthorization),
+	}
+	return overrides, overrides.BaseURL != "" || overrides.Authorization != ""
+}
+
+// overridesAllowed validates overrides against the configured host allowlist.
+func (h *TrackingHandler) overridesAllowed(overrides ports.Overrides) bool {
+	if len(h.overrideAllowedHosts) == 0 {
+		return false
+	}
+	if overrides.BaseURL == "" {
+		return true
+	}
+
+	parsed, err := url.Parse(overrides.BaseURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return false
+	}
+
+	for _, host := range h.overrideAllowedHosts {
+		if parsed.Hostname() == host {
+			return true
+		}
+	}
+	return false
 }
@@ -4,10 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"tracker-scrapper/internal/core/auth"
+	"tracker-scrapper/internal/core/breaker"
+	"tracker-scrapper/internal/core/maintenance"
+	"tracker-scrapper/internal/core/request"
+	"tracker-scrapper/internal/core/scraper"
 	"tracker-scrapper/internal/features/tracking/domain"
 	"tracker-scrapper/internal/features/tracking/ports"
 	"tracker-scrapper/internal/features/tracking/service"
@@ -37,6 +44,21 @@ func (m *mockTrackingProvider) SupportsCourier(courierName string) bool {
 	return courierName == m.supportedCourier
 }
 
+// Couriers implements TrackingProvider.
+func (m *mockTrackingProvider) Couriers() []string {
+	return []string{m.supportedCourier}
+}
+
+// mockOverridableProvider is a mockTrackingProvider that also accepts per-call overrides.
+type mockOverridableProvider struct {
+	mockTrackingProvider
+}
+
+// WithOverrides implements OverridableProvider.
+func (m *mockOverridableProvider) WithOverrides(overrides ports.Overrides) ports.TrackingProvider {
+	return m
+}
+
 // mockCache for testing.
 type mockCache struct{}
 
@@ -46,6 +68,12 @@ func (m *mockCache) Get(ctx context.Context, key string) ([]byte, error) {
 func (m *mockCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
 	return nil
 }
+func (m *mockCache) GetMulti(ctx context.Context, keys []string) (map[string][]byte, error) {
+	return map[string][]byte{}, nil
+}
+func (m *mockCache) SetMulti(ctx context.Context, entries map[string][]byte, ttl time.Duration) error {
+	return nil
+}
 func (m *mockCache) Delete(ctx context.Context, key string) error { return nil }
 func (m *mockCache) Ping(ctx context.Context) error               { return nil }
 func (m *mockCache) Close() error                                 { return nil }
@@ -62,8 +90,9 @@ func TestTrackingHandler_GetTrackingHistory_Success(t *testing.T) {
 		returnHistory:    expectedHistory,
 	}
 
-	trackingSvc := service.NewTrackingService([]ports.TrackingProvider{provider}, &mockCache{}, 30*time.Second)
-	handler := NewTrackingHandler(trackingSvc)
+	trackingSvc, err := service.NewTrackingService([]ports.TrackingProvider{provider}, &mockCache{}, 30*time.Second, nil, 1)
+	require.NoError(t, err)
+	handler := NewTrackingHandler(trackingSvc, nil, false, false)
 
 	app := fiber.New()
 	app.Use(func(c *fiber.Ctx) error {
@@ -72,11 +101,12 @@ func TestTrackingHandler_GetTrackingHistory_Success(t *testing.T) {
 	})
 	app.Get("/tracking/:number", handler.GetTrackingHistory)
 
-	req := httptest.NewRequest("GET", "/tracking/12345?courier=coordinadora_co", nil)
+	req := httptest.NewRequest("GET", "/tracking/04333004120?courier=coordinadora_co", nil)
 	resp, err := app.Test(req)
 
 	require.NoError(t, err)
 	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "MISS", resp.Header.Get("X-Cache"))
 
 	var result domain.TrackingHistory
 	err = json.NewDecoder(resp.Body).Decode(&result)
@@ -84,10 +114,77 @@ func TestTrackingHandler_GetTrackingHistory_Success(t *testing.T) {
 	assert.Equal(t, expectedHistory.GlobalStatus, result.GlobalStatus)
 }
 
+// TestTrackingHandler_GetTrackingHistory_NotModified verifies a matching If-None-Match gets an empty 304.
+func TestTrackingHandler_GetTrackingHistory_NotModified(t *testing.T) {
+	provider := &mockTrackingProvider{
+		supportedCourier: "coordinadora_co",
+		returnHistory:    &domain.TrackingHistory{GlobalStatus: domain.TrackingStatusProcessing},
+	}
+	trackingSvc, err := service.NewTrackingService([]ports.TrackingProvider{provider}, &mockCache{}, 30*time.Second, nil, 1)
+	require.NoError(t, err)
+	handler := NewTrackingHandler(trackingSvc, nil, false, false)
+
+	app := fiber.New()
+	app.Get("/tracking/:number", handler.GetTrackingHistory)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/tracking/04333004120?courier=coordinadora_co", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	etag := resp.Header.Get("ETag")
+	require.NotEmpty(t, etag)
+
+	req := httptest.NewRequest("GET", "/tracking/04333004120?courier=coordinadora_co", nil)
+	req.Header.Set("If-None-Match", etag)
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotModified, resp.StatusCode)
+	assert.Equal(t, etag, resp.Header.Get("ETag"))
+}
+
+// TestTrackingHandler_GetTrackingHistory_Limit verifies ?limit returns only the most recent events.
+func TestTrackingHandler_GetTrackingHistory_Limit(t *testing.T) {
+	provider := &mockTrackingProvider{
+		supportedCourier: "coordinadora_co",
+		returnHistory: &domain.TrackingHistory{
+			GlobalStatus: domain.TrackingStatusCompleted,
+			History: []domain.TrackingEvent{
+				{Date: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Code: "1"},
+				{Date: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), Code: "6"},
+				{Date: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Code: "2"},
+			},
+		},
+	}
+
+	trackingSvc, err := service.NewTrackingService([]ports.TrackingProvider{provider}, &mockCache{}, 30*time.Second, nil, 1)
+	require.NoError(t, err)
+	handler := NewTrackingHandler(trackingSvc, nil, false, false)
+
+	app := fiber.New()
+	app.Get("/tracking/:number", handler.GetTrackingHistory)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/tracking/04333004120?courier=coordinadora_co&limit=2", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var result domain.TrackingHistory
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Equal(t, domain.TrackingStatusCompleted, result.GlobalStatus)
+	require.Len(t, result.History, 2)
+	assert.Equal(t, "2", result.History[0].Code)
+	assert.Equal(t, "6", result.History[1].Code)
+
+	for _, limit := range []string{"0", "-1", "abc"} {
+		resp, err := app.Test(httptest.NewRequest("GET", "/tracking/04333004120?courier=coordinadora_co&limit="+limit, nil))
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode, "limit=%s", limit)
+	}
+}
+
 // TestTrackingHandler_GetTrackingHistory_MissingTrackingNumber verifies tracking number validation.
 func TestTrackingHandler_GetTrackingHistory_MissingTrackingNumber(t *testing.T) {
-	trackingSvc := service.NewTrackingService([]ports.TrackingProvider{}, &mockCache{}, 30*time.Second)
-	handler := NewTrackingHandler(trackingSvc)
+	trackingSvc, err := service.NewTrackingService([]ports.TrackingProvider{}, &mockCache{}, 30*time.Second, nil, 1)
+	require.NoError(t, err)
+	handler := NewTrackingHandler(trackingSvc, nil, false, false)
 
 	app := fiber.New()
 	app.Use(func(c *fiber.Ctx) error {
@@ -105,8 +202,9 @@ func TestTrackingHandler_GetTrackingHistory_MissingTrackingNumber(t *testing.T)
 
 // TestTrackingHandler_GetTrackingHistory_MissingCourier verifies courier parameter validation.
 func TestTrackingHandler_GetTrackingHistory_MissingCourier(t *testing.T) {
-	trackingSvc := service.NewTrackingService([]ports.TrackingProvider{}, &mockCache{}, 30*time.Second)
-	handler := NewTrackingHandler(trackingSvc)
+	trackingSvc, err := service.NewTrackingService([]ports.TrackingProvider{}, &mockCache{}, 30*time.Second, nil, 1)
+	require.NoError(t, err)
+	handler := NewTrackingHandler(trackingSvc, nil, false, false)
 
 	app := fiber.New()
 	app.Use(func(c *fiber.Ctx) error {
@@ -121,21 +219,86 @@ func TestTrackingHandler_GetTrackingHistory_MissingCourier(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
 
-	var errResp ErrorResponse
+	var errResp request.ValidationErrorResponse
 	err = json.NewDecoder(resp.Body).Decode(&errResp)
 	require.NoError(t, err)
-	assert.Contains(t, errResp.Message, "courier query parameter is required")
+	assert.Equal(t, []request.ValidationError{{Field: "courier", Message: "required"}}, errResp.Errors)
 	assert.Equal(t, "test-ray-id", errResp.RayID)
 }
 
+// TestTrackingHandler_GetTrackingHistory_AutoDetect verifies the courier is detected when omitted and auto-detection is on.
+func TestTrackingHandler_GetTrackingHistory_AutoDetect(t *testing.T) {
+	provider := &mockTrackingProvider{
+		supportedCourier: "interrapidisimo_co",
+		returnHistory: &domain.TrackingHistory{
+			GlobalStatus: domain.TrackingStatusCompleted,
+			History:      []domain.TrackingEvent{{Code: "11"}},
+		},
+	}
+	trackingSvc, err := service.NewTrackingService([]ports.TrackingProvider{provider}, &mockCache{}, 30*time.Second, nil, 1)
+	require.NoError(t, err)
+	handler := NewTrackingHandler(trackingSvc, nil, false, true)
+
+	app := fiber.New()
+	app.Get("/tracking/:number", handler.GetTrackingHistory)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/tracking/240041234567", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "interrapidisimo_co", resp.Header.Get("X-Courier"))
+
+	// No courier matches the format of this number
+	resp, err = app.Test(httptest.NewRequest("GET", "/tracking/ABC-123", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+// TestTrackingHandler_GetTrackingHistory_WithoutRequestID verifies a missing or malformed Ray ID does not panic.
+func TestTrackingHandler_GetTrackingHistory_WithoutRequestID(t *testing.T) {
+	trackingSvc, err := service.NewTrackingService([]ports.TrackingProvider{}, &mockCache{}, 30*time.Second, nil, 1)
+	require.NoError(t, err)
+	handler := NewTrackingHandler(trackingSvc, nil, false, false)
+
+	tests := []struct {
+		name  string
+		local interface{}
+	}{
+		{"Missing", nil},
+		{"NotString", 42},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Use(func(c *fiber.Ctx) error {
+				if tt.local != nil {
+					c.Locals("requestid", tt.local)
+				}
+				return c.Next()
+			})
+			app.Get("/tracking/:number", handler.GetTrackingHistory)
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/tracking/12345", nil))
+
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+			var errResp request.ValidationErrorResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&errResp))
+			assert.Equal(t, "unknown", errResp.RayID)
+		})
+	}
+}
+
 // TestTrackingHandler_GetTrackingHistory_CourierNotSupported verifies unsupported courier response.
 func TestTrackingHandler_GetTrackingHistory_CourierNotSupported(t *testing.T) {
 	provider := &mockTrackingProvider{
 		supportedCourier: "coordinadora_co",
 	}
 
-	trackingSvc := service.NewTrackingService([]ports.TrackingProvider{provider}, &mockCache{}, 30*time.Second)
-	handler := NewTrackingHandler(trackingSvc)
+	trackingSvc, err := service.NewTrackingService([]ports.TrackingProvider{provider}, &mockCache{}, 30*time.Second, nil, 1)
+	require.NoError(t, err)
+	handler := NewTrackingHandler(trackingSvc, nil, false, false)
 
 	app := fiber.New()
 	app.Use(func(c *fiber.Ctx) error {
@@ -148,10 +311,465 @@ func TestTrackingHandler_GetTrackingHistory_CourierNotSupported(t *testing.T) {
 	resp, err := app.Test(req)
 
 	require.NoError(t, err)
-	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	var errResp request.ValidationErrorResponse
+	err = json.NewDecoder(resp.Body).Decode(&errResp)
+	require.NoError(t, err)
+	require.Len(t, errResp.Errors, 1)
+	assert.Equal(t, "courier", errResp.Errors[0].Field)
+	assert.Contains(t, errResp.Errors[0].Message, "not supported")
+}
+
+// TestTrackingHandler_GetTrackingHistory_FakeNumber verifies implausible and denylisted numbers get a 400 without a lookup.
+func TestTrackingHandler_GetTrackingHistory_FakeNumber(t *testing.T) {
+	provider := &mockTrackingProvider{
+		supportedCourier: "coordinadora_co",
+		returnError:      errors.New("lookup must not happen"),
+	}
+	trackingSvc, err := service.NewTrackingService([]ports.TrackingProvider{provider}, &mockCache{}, 30*time.Second, nil, 1)
+	require.NoError(t, err)
+	trackingSvc.SetDenylist([]string{"04333004120"})
+	handler := NewTrackingHandler(trackingSvc, nil, false, false)
+
+	app := fiber.New()
+	app.Get("/tracking/:number", handler.GetTrackingHistory)
+
+	tests := []struct {
+		number  string
+		message string
+	}{
+		{"00000", "must not be all zeros"},
+		{"test", "must contain digits"},
+		{"12345", "must contain at least 10 digits for coordinadora_co"},
+		{"04333004120", "not accepted"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.number, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest("GET", "/tracking/"+tt.number+"?courier=coordinadora_co", nil))
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+			var errResp request.ValidationErrorResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&errResp))
+			assert.Equal(t, []request.ValidationError{{Field: "number", Message: tt.message}}, errResp.Errors)
+		})
+	}
+}
+
+// TestTrackingHandler_GetTrackingHistory_MaintenanceCacheMiss verifies 503 on cache miss in maintenance mode.
+func TestTrackingHandler_GetTrackingHistory_MaintenanceCacheMiss(t *testing.T) {
+	provider := &mockTrackingProvider{
+		supportedCourier: "coordinadora_co",
+	}
+
+	trackingSvc, err := service.NewTrackingService([]ports.TrackingProvider{provider}, &mockCache{}, 30*time.Second, maintenance.NewMode(true), 1)
+	require.NoError(t, err)
+	handler := NewTrackingHandler(trackingSvc, nil, false, false)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("requestid", "test-ray-id")
+		return c.Next()
+	})
+	app.Get("/tracking/:number", handler.GetTrackingHistory)
+
+	req := httptest.NewRequest("GET", "/tracking/04333004120?courier=coordinadora_co", nil)
+	resp, err := app.Test(req)
+
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+
+	var errResp ErrorResponse
+	err = json.NewDecoder(resp.Body).Decode(&errResp)
+	require.NoError(t, err)
+	assert.True(t, errResp.Maintenance)
+	assert.Contains(t, errResp.Message, "maintenance")
+}
+
+// TestTrackingHandler_GetTrackingHistory_CourierBusy verifies 503 when no browser slot frees up in time.
+func TestTrackingHandler_GetTrackingHistory_CourierBusy(t *testing.T) {
+	provider := &mockTrackingProvider{
+		supportedCourier: "coordinadora_co",
+		returnError:      fmt.Errorf("coordinadora_co lookup timed out after 1s: %w", scraper.ErrCourierBusy),
+	}
+
+	trackingSvc, err := service.NewTrackingService([]ports.TrackingProvider{provider}, &mockCache{}, 30*time.Second, nil, 1)
+	require.NoError(t, err)
+	handler := NewTrackingHandler(trackingSvc, nil, false, false)
+
+	app := fiber.New()
+	app.Get("/tracking/:number", handler.GetTrackingHistory)
+
+	req := httptest.NewRequest("GET", "/tracking/04333004120?courier=coordinadora_co", nil)
+	resp, err := app.Test(req)
+
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
 
 	var errResp ErrorResponse
 	err = json.NewDecoder(resp.Body).Decode(&errResp)
 	require.NoError(t, err)
-	assert.Contains(t, errResp.Message, "courier not supported")
+	assert.False(t, errResp.Maintenance)
+	assert.Contains(t, errResp.Message, "too many concurrent lookups")
+	assert.Equal(t, "10", resp.Header.Get("Retry-After"))
+}
+
+// TestTrackingHandler_GetTrackingHistory_LayoutChanged verifies 502 when the courier page no longer has the expected form.
+func TestTrackingHandler_GetTrackingHistory_LayoutChanged(t *testing.T) {
+	provider := &mockTrackingProvider{
+		supportedCourier: "interrapidisimo_co",
+		returnError:      fmt.Errorf("%w: search input #inputGuide not found: context deadline exceeded", scraper.ErrCourierLayoutChanged),
+	}
+
+	trackingSvc, err := service.NewTrackingService([]ports.TrackingProvider{provider}, &mockCache{}, 30*time.Second, nil, 1)
+	require.NoError(t, err)
+	handler := NewTrackingHandler(trackingSvc, nil, false, false)
+
+	app := fiber.New()
+	app.Get("/tracking/:number", handler.GetTrackingHistory)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/tracking/240041234567?courier=interrapidisimo_co", nil))
+
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadGateway, resp.StatusCode)
+
+	var errResp ErrorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&errResp))
+	assert.Contains(t, errResp.Message, "courier page changed")
+	assert.NotEmpty(t, errResp.RayID)
+}
+
+// TestTrackingHandler_GetTrackingHistory_BreakerOpen verifies 503 with the remaining breaker cooldown in Retry-After.
+func TestTrackingHandler_GetTrackingHistory_BreakerOpen(t *testing.T) {
+	provider := &mockTrackingProvider{
+		supportedCourier: "coordinadora_co",
+		returnError:      errors.New("timeout waiting for courier response"),
+	}
+	guarded := service.WithCircuitBreaker(provider, "coordinadora_co", breaker.New(1, time.Minute, nil))
+
+	trackingSvc, err := service.NewTrackingService([]ports.TrackingProvider{guarded}, &mockCache{}, 30*time.Second, nil, 1)
+	require.NoError(t, err)
+	handler := NewTrackingHandler(trackingSvc, nil, false, false)
+
+	app := fiber.New()
+	app.Get("/tracking/:number", handler.GetTrackingHistory)
+
+	// The first failure opens the breaker
+	resp, err := app.Test(httptest.NewRequest("GET", "/tracking/04333004120?courier=coordinadora_co", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get("Retry-After"))
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/tracking/04333004120?courier=coordinadora_co", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "60", resp.Header.Get("Retry-After"))
+
+	var errResp ErrorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&errResp))
+	assert.Contains(t, errResp.Message, "courier temporarily unavailable")
+}
+
+// slowTrackingProvider answers after delay.
+type slowTrackingProvider struct {
+	mockTrackingProvider
+	delay time.Duration
+}
+
+// GetTrackingHistory implements TrackingProvider.
+func (m *slowTrackingProvider) GetTrackingHistory(trackingNumber string) (*domain.TrackingHistory, error) {
+	time.Sleep(m.delay)
+	return m.mockTrackingProvider.GetTrackingHistory(trackingNumber)
+}
+
+// signalingCache reports every cached key on stored.
+type signalingCache struct {
+	mockCache
+	stored chan string
+}
+
+func (m *signalingCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.stored <- key
+	return nil
+}
+
+// TestTrackingHandler_GetTrackingHistory_RequestTimeout verifies an expired request context ends the
+// request with context.DeadlineExceeded while the scrape finishes and is cached in the background.
+func TestTrackingHandler_GetTrackingHistory_RequestTimeout(t *testing.T) {
+	provider := &slowTrackingProvider{
+		mockTrackingProvider: mockTrackingProvider{
+			supportedCourier: "coordinadora_co",
+			returnHistory:    &domain.TrackingHistory{GlobalStatus: domain.TrackingStatusProcessing},
+		},
+		delay: 200 * time.Millisecond,
+	}
+	cache := &signalingCache{stored: make(chan string, 1)}
+
+	trackingSvc, err := service.NewTrackingService([]ports.TrackingProvider{provider}, cache, 30*time.Second, nil, 1)
+	require.NoError(t, err)
+	handler := NewTrackingHandler(trackingSvc, nil, false, false)
+
+	var handlerErr error
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithTimeout(c.UserContext(), 20*time.Millisecond)
+		defer cancel()
+		c.SetUserContext(ctx)
+		handlerErr = c.Next()
+		return nil
+	})
+	app.Get("/tracking/:number", handler.GetTrackingHistory)
+
+	_, err = app.Test(httptest.NewRequest("GET", "/tracking/04333004120?courier=coordinadora_co", nil), -1)
+	require.NoError(t, err)
+	assert.ErrorIs(t, handlerErr, context.DeadlineExceeded)
+
+	select {
+	case key := <-cache.stored:
+		assert.Equal(t, "ts_coordinadora_co_04333004120", key)
+	case <-time.After(2 * time.Second):
+		t.Fatal("scrape result was not cached after the request timed out")
+	}
+}
+
+// TestTrackingHandler_GetTrackingHistoryBatch_RequestTimeout verifies a batch outliving the request
+// timeout is answered with 504 instead of waiting for every lookup.
+func TestTrackingHandler_GetTrackingHistoryBatch_RequestTimeout(t *testing.T) {
+	provider := &slowTrackingProvider{
+		mockTrackingProvider: mockTrackingProvider{
+			supportedCourier: "coordinadora_co",
+			returnHistory:    &domain.TrackingHistory{GlobalStatus: domain.TrackingStatusProcessing},
+		},
+		delay: 200 * time.Millisecond,
+	}
+
+	trackingSvc, err := service.NewTrackingService([]ports.TrackingProvider{provider}, &mockCache{}, 30*time.Second, nil, 1)
+	require.NoError(t, err)
+	handler := NewTrackingHandler(trackingSvc, nil, false, false)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithTimeout(c.UserContext(), 20*time.Millisecond)
+		defer cancel()
+		c.SetUserContext(ctx)
+		err := c.Next()
+		if errors.Is(err, context.DeadlineExceeded) {
+			return c.SendStatus(fiber.StatusGatewayTimeout)
+		}
+		return err
+	})
+	app.Post("/tracking/batch", handler.GetTrackingHistoryBatch)
+
+	body := `{"items": [{"number": "04333004120", "courier": "coordinadora_co"}, {"number": "04333004121", "courier": "coordinadora_co"}]}`
+	req := httptest.NewRequest("POST", "/tracking/batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	start := time.Now()
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusGatewayTimeout, resp.StatusCode)
+	assert.Less(t, time.Since(start), 150*time.Millisecond)
+}
+
+// TestTrackingHandler_GetTrackingHistory_InvalidTrackingNumber verifies tracking number format validation.
+func TestTrackingHandler_GetTrackingHistory_InvalidTrackingNumber(t *testing.T) {
+	provider := &mockTrackingProvider{
+		supportedCourier: "coordinadora_co",
+	}
+
+	trackingSvc, err := service.NewTrackingService([]ports.TrackingProvider{provider}, &mockCache{}, 30*time.Second, nil, 1)
+	require.NoError(t, err)
+	handler := NewTrackingHandler(trackingSvc, nil, false, false)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("requestid", "test-ray-id")
+		return c.Next()
+	})
+	app.Get("/tracking/:number", handler.GetTrackingHistory)
+
+	for _, number := range []string{"123", "12%2034", "..%2F..%2Fetc", "abc_123"} {
+		req := httptest.NewRequest("GET", "/tracking/"+number+"?courier=coordinadora_co", nil)
+		resp, err := app.Test(req)
+
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode, number)
+
+		var errResp request.ValidationErrorResponse
+		err = json.NewDecoder(resp.Body).Decode(&errResp)
+		require.NoError(t, err)
+		require.Len(t, errResp.Errors, 1)
+		assert.Equal(t, "number", errResp.Errors[0].Field)
+		assert.Contains(t, errResp.Errors[0].Message, "must be 4-40 characters")
+		assert.Equal(t, "test-ray-id", errResp.RayID)
+	}
+}
+
+// TestTrackingHandler_GetTrackingHistory_OverrideHostNotAllowed verifies override hosts are checked against the allowlist.
+func TestTrackingHandler_GetTrackingHistory_OverrideHostNotAllowed(t *testing.T) {
+	provider := &mockTrackingProvider{
+		supportedCourier: "coordinadora_co",
+	}
+
+	trackingSvc, err := service.NewTrackingService([]ports.TrackingProvider{provider}, &mockCache{}, 30*time.Second, nil, 1)
+	require.NoError(t, err)
+	handler := NewTrackingHandler(trackingSvc, []string{"staging.coordinadora.com"}, false, false)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("requestid", "test-ray-id")
+		return c.Next()
+	})
+	app.Get("/tracking/:number", auth.MarkAdmin(true, []string{"admin-key"}), handler.GetTrackingHistory)
+
+	req := httptest.NewRequest("GET", "/tracking/04333004120?courier=coordinadora_co", nil)
+	req.Header.Set("Authorization", "Bearer admin-key")
+	req.Header.Set("X-Courier-Base-URL", "https://evil.example.com/rastreo")
+	resp, err := app.Test(req)
+
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+}
+
+// TestTrackingHandler_GetTrackingHistory_OverridesRequireAdmin verifies only admin keys may send courier overrides.
+func TestTrackingHandler_GetTrackingHistory_OverridesRequireAdmin(t *testing.T) {
+	provider := &mockOverridableProvider{mockTrackingProvider{
+		supportedCourier: "coordinadora_co",
+		returnHistory: &domain.TrackingHistory{
+			GlobalStatus: domain.TrackingStatusCompleted,
+			History:      []domain.TrackingEvent{},
+		},
+	}}
+
+	trackingSvc, err := service.NewTrackingService([]ports.TrackingProvider{provider}, &mockCache{}, 30*time.Second, nil, 1)
+	require.NoError(t, err)
+	handler := NewTrackingHandler(trackingSvc, []string{"staging.coordinadora.com"}, false, false)
+
+	app := fiber.New()
+	app.Get("/tracking/:number", auth.MarkAdmin(true, []string{"admin-key"}), handler.GetTrackingHistory)
+
+	tests := []struct {
+		name    string
+		key     string
+		baseURL string
+		status  int
+	}{
+		{"ordinary key with base URL", "api-key", "https://staging.coordinadora.com/rastreo", fiber.StatusForbidden},
+		{"ordinary key with authorization only", "api-key", "", fiber.StatusForbidden},
+		{"admin key with base URL", "admin-key", "https://staging.coordinadora.com/rastreo", fiber.StatusOK},
+		{"admin key with authorization only", "admin-key", "", fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/tracking/04333004120?courier=coordinadora_co", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.key)
+			req.Header.Set("X-Courier-Authorization", "Bearer courier-token")
+			if tt.baseURL != "" {
+				req.Header.Set("X-Courier-Base-URL", tt.baseURL)
+			}
+			resp, err := app.Test(req)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+		})
+	}
+}
+
+// TestTrackingHandler_GetTrackingHistoryBatch verifies per-item results and validation errors.
+func TestTrackingHandler_GetTrackingHistoryBatch(t *testing.T) {
+	provider := &mockTrackingProvider{
+		supportedCourier: "coordinadora_co",
+		returnHistory: &domain.TrackingHistory{
+			GlobalStatus: domain.TrackingStatusCompleted,
+			History:      []domain.TrackingEvent{},
+		},
+	}
+
+	trackingSvc, err := service.NewTrackingService([]ports.TrackingProvider{provider}, &mockCache{}, 30*time.Second, nil, 2)
+	require.NoError(t, err)
+	handler := NewTrackingHandler(trackingSvc, nil, false, false)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("requestid", "test-ray-id")
+		return c.Next()
+	})
+	app.Post("/tracking/batch", handler.GetTrackingHistoryBatch)
+
+	body := `{"items":[
+		{"number":"04333004120","courier":"coordinadora_co"},
+		{"number":"../..","courier":"coordinadora_co"},
+		{"number":"67890","courier":"unknown_co"}
+	]}`
+	req := httptest.NewRequest("POST", "/tracking/batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var results []service.BatchResult
+	err = json.NewDecoder(resp.Body).Decode(&results)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	require.NotNil(t, results[0].History)
+	assert.Equal(t, domain.TrackingStatusCompleted, results[0].History.GlobalStatus)
+	assert.Equal(t, "invalid tracking number", results[1].Error)
+	assert.Equal(t, "courier not supported", results[2].Error)
+}
+
+// TestTrackingHandler_GetTrackingHistoryBatch_Empty verifies an empty batch is rejected.
+func TestTrackingHandler_GetTrackingHistoryBatch_Empty(t *testing.T) {
+	trackingSvc, err := service.NewTrackingService([]ports.TrackingProvider{}, &mockCache{}, 30*time.Second, nil, 2)
+	require.NoError(t, err)
+	handler := NewTrackingHandler(trackingSvc, nil, false, false)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("requestid", "test-ray-id")
+		return c.Next()
+	})
+	app.Post("/tracking/batch", handler.GetTrackingHistoryBatch)
+
+	req := httptest.NewRequest("POST", "/tracking/batch", strings.NewReader(`{"items":[]}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+// TestTrackingHandler_GetTrackingHistoryBatch_MissingFields verifies every missing item field is reported at once.
+func TestTrackingHandler_GetTrackingHistoryBatch_MissingFields(t *testing.T) {
+	trackingSvc, err := service.NewTrackingService([]ports.TrackingProvider{}, &mockCache{}, 30*time.Second, nil, 2)
+	require.NoError(t, err)
+	handler := NewTrackingHandler(trackingSvc, nil, false, false)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("requestid", "test-ray-id")
+		return c.Next()
+	})
+	app.Post("/tracking/batch", handler.GetTrackingHistoryBatch)
+
+	body := `{"items":[{"number":"12345"},{"number":"67890","courier":"coordinadora_co"},{}]}`
+	req := httptest.NewRequest("POST", "/tracking/batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	var errResp request.ValidationErrorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&errResp))
+	assert.Equal(t, []request.ValidationError{
+		{Field: "items[0].courier", Message: "required"},
+		{Field: "items[2].number", Message: "required"},
+		{Field: "items[2].courier", Message: "required"},
+	}, errResp.Errors)
+	assert.Equal(t, "test-ray-id", errResp.RayID)
 }
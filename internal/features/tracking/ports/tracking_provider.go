@@ -1,6 +1,14 @@
 package ports
 
-import "tracker-scrapper/internal/features/tracking/domain"
+import (
+	"errors"
+
+	"tracker-scrapper/internal/features/tracking/domain"
+)
+
+// ErrShipmentNotFound is returned by GetTrackingHistory when the courier answered but knows no
+// shipment for the number. It describes the number, not the courier's health.
+var ErrShipmentNotFound = errors.New("shipment not found")
 
 // TrackingProvider defines the interface for courier tracking implementations.
 type TrackingProvider interface {
@@ -8,4 +16,22 @@ type TrackingProvider interface {
 	GetTrackingHistory(trackingNumber string) (*domain.TrackingHistory, error)
 	// SupportsCourier returns true if this provider supports the given courier name.
 	SupportsCourier(courierName string) bool
+	// Couriers returns the courier names this provider is registered under.
+	Couriers() []string
+}
+
+// Overrides holds per-call provider configuration that replaces the global courier settings.
+type Overrides struct {
+	// BaseURL replaces the configured courier tracking URL when non-empty.
+	BaseURL string
+	// Authorization is sent as the Authorization header on courier API requests when non-empty.
+	Authorization string
+}
+
+// OverridableProvider is implemented by providers that accept per-call configuration overrides.
+type OverridableProvider interface {
+	TrackingProvider
+	// WithOverrides returns a copy of the provider configured with the given overrides.
+	// The receiver must not be modified.
+	WithOverrides(overrides Overrides) TrackingProvider
 }
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"tracker-scrapper/internal/core/breaker"
+	"tracker-scrapper/internal/core/logger"
+	"tracker-scrapper/internal/core/metrics"
+	"tracker-scrapper/internal/core/scraper"
+	"tracker-scrapper/internal/features/tracking/domain"
+	"tracker-scrapper/internal/features/tracking/ports"
+
+	"go.uber.org/zap"
+)
+
+// ErrCourierUnavailable is returned without calling the courier while its circuit breaker is open.
+var ErrCourierUnavailable = errors.New("courier temporarily unavailable")
+
+// UnavailableError is the ErrCourierUnavailable returned for a courier, with when to try again.
+type UnavailableError struct {
+	// Courier is the courier whose circuit breaker is open.
+	Courier string
+	// RetryAfter is how long until the breaker lets a probe through. Zero when a probe is already running.
+	RetryAfter time.Duration
+}
+
+// Error implements error.
+func (e *UnavailableError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrCourierUnavailable, e.Courier)
+}
+
+// Unwrap makes UnavailableError match ErrCourierUnavailable.
+func (e *UnavailableError) Unwrap() error {
+	return ErrCourierUnavailable
+}
+
+// breakerProvider fast-fails lookups for a courier whose recent scrapes kept failing.
+type breakerProvider struct {
+	ports.TrackingProvider
+	courier string
+	breaker *breaker.Breaker
+}
+
+// overridableBreakerProvider is a breakerProvider whose wrapped provider accepts overrides.
+type overridableBreakerProvider struct {
+	*breakerProvider
+	overridable ports.OverridableProvider
+}
+
+// WithCircuitBreaker wraps provider so lookups for courier fail with ErrCourierUnavailable
+// after threshold consecutive failures, until cooldown elapses and a probe succeeds.
+// Lookups failing with ports.ErrShipmentNotFound count as successes: the courier did answer.
+// The breaker state is exported as the tracker_courier_breaker_state metric.
+func WithCircuitBreaker(provider ports.TrackingProvider, courier string, b *breaker.Breaker) ports.TrackingProvider {
+	p := &breakerProvider{
+		TrackingProvider: provider,
+		courier:          courier,
+		breaker:          b,
+	}
+
+	if overridable, ok := provider.(ports.OverridableProvider); ok {
+		return &overridableBreakerProvider{breakerProvider: p, overridable: overridable}
+	}
+	return p
+}
+
+// NewCourierBreaker creates a breaker for courier that logs transitions and updates its metric.
+func NewCourierBreaker(courier string, threshold int, cooldown time.Duration) *breaker.Breaker {
+	metrics.SetBreakerState(courier, int(breaker.StateClosed))
+	return breaker.New(threshold, cooldown, func(s breaker.State) {
+		metrics.SetBreakerState(courier, int(s))
+		logger.Get().Warn("Courier circuit breaker state changed",
+			zap.String("courier", courier),
+			zap.String("state", s.String()),
+		)
+	})
+}
+
+// GetTrackingHistory implements TrackingProvider.
+func (p *breakerProvider) GetTrackingHistory(trackingNumber string) (*domain.TrackingHistory, error) {
+	if err := p.breaker.Allow(); err != nil {
+		return nil, &UnavailableError{Courier: p.courier, RetryAfter: p.breaker.RetryAfter()}
+	}
+
+	history, err := p.TrackingProvider.GetTrackingHistory(trackingNumber)
+	if errors.Is(err, scraper.ErrCourierBusy) {
+		// Local browser contention says nothing about the courier's health
+		p.breaker.Skip()
+		return history, err
+	}
+	if errors.Is(err, ports.ErrShipmentNotFound) {
+		// The courier answered; an unknown number says nothing against its health
+		p.breaker.Record(nil)
+		return history, err
+	}
+	p.breaker.Record(err)
+	return history, err
+}
+
+// Close closes the wrapped provider when it holds resources.
+func (p *breakerProvider) Close() error {
+	if c, ok := p.TrackingProvider.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// WithOverrides implements OverridableProvider.
+// Override lookups target a different endpoint, so they bypass the breaker.
+func (p *overridableBreakerProvider) WithOverrides(overrides ports.Overrides) ports.TrackingProvider {
+	return p.overridable.WithOverrides(overrides)
+}
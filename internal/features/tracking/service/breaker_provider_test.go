@@ -0,0 +1,103 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"tracker-scrapper/internal/core/breaker"
+	"tracker-scrapper/internal/core/scraper"
+	"tracker-scrapper/internal/features/tracking/domain"
+	"tracker-scrapper/internal/features/tracking/ports"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithCircuitBreaker_FastFailsWhenOpen verifies the courier is not called once the breaker opens.
+func TestWithCircuitBreaker_FastFailsWhenOpen(t *testing.T) {
+	mock := &mockTrackingProvider{supportedCourier: "coordinadora_co", returnError: errors.New("timeout waiting for courier response")}
+	svc, err := NewTrackingService([]ports.TrackingProvider{
+		WithCircuitBreaker(mock, "coordinadora_co", NewCourierBreaker("coordinadora_co", 2, time.Hour)),
+	}, newMockCache(), time.Minute, nil, 1)
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, err := svc.GetTrackingHistory("123", "coordinadora_co")
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrCourierUnavailable)
+	}
+
+	_, err = svc.GetTrackingHistory("123", "coordinadora_co")
+
+	assert.ErrorIs(t, err, ErrCourierUnavailable)
+	assert.Equal(t, 2, mock.calls)
+
+	var unavailable *UnavailableError
+	require.ErrorAs(t, err, &unavailable)
+	assert.Equal(t, "coordinadora_co", unavailable.Courier)
+	assert.InDelta(t, time.Hour, unavailable.RetryAfter, float64(time.Minute))
+}
+
+// TestWithCircuitBreaker_ClosesAfterProbe verifies a successful probe after the cooldown closes the breaker.
+func TestWithCircuitBreaker_ClosesAfterProbe(t *testing.T) {
+	mock := &mockTrackingProvider{supportedCourier: "coordinadora_co", returnError: errors.New("boom")}
+	b := breaker.New(1, 0, nil)
+	provider := WithCircuitBreaker(mock, "coordinadora_co", b)
+
+	_, err := provider.GetTrackingHistory("123")
+	require.Error(t, err)
+	require.Equal(t, breaker.StateOpen, b.State())
+
+	mock.returnError = nil
+	mock.returnHistory = &domain.TrackingHistory{GlobalStatus: domain.TrackingStatusProcessing}
+
+	history, err := provider.GetTrackingHistory("123")
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.TrackingStatusProcessing, history.GlobalStatus)
+	assert.Equal(t, breaker.StateClosed, b.State())
+}
+
+// TestWithCircuitBreaker_IgnoresBusy verifies lookups rejected for lack of a browser slot don't open the breaker.
+func TestWithCircuitBreaker_IgnoresBusy(t *testing.T) {
+	mock := &mockTrackingProvider{supportedCourier: "coordinadora_co", returnError: fmt.Errorf("lookup failed: %w", scraper.ErrCourierBusy)}
+	b := breaker.New(1, time.Hour, nil)
+	provider := WithCircuitBreaker(mock, "coordinadora_co", b)
+
+	for i := 0; i < 3; i++ {
+		_, err := provider.GetTrackingHistory("123")
+		assert.ErrorIs(t, err, scraper.ErrCourierBusy)
+	}
+
+	assert.Equal(t, breaker.StateClosed, b.State())
+	assert.Equal(t, 3, mock.calls)
+}
+
+// TestWithCircuitBreaker_IgnoresNotFound verifies unknown tracking numbers don't open the breaker.
+func TestWithCircuitBreaker_IgnoresNotFound(t *testing.T) {
+	mock := &mockTrackingProvider{supportedCourier: "coordinadora_co", returnError: fmt.Errorf("%w: no results in response (Code: 1)", ports.ErrShipmentNotFound)}
+	b := breaker.New(2, time.Hour, nil)
+	provider := WithCircuitBreaker(mock, "coordinadora_co", b)
+
+	for i := 0; i < 5; i++ {
+		_, err := provider.GetTrackingHistory("123")
+		assert.ErrorIs(t, err, ports.ErrShipmentNotFound)
+	}
+
+	assert.Equal(t, breaker.StateClosed, b.State())
+	assert.Equal(t, 5, mock.calls)
+}
+
+// TestWithCircuitBreaker_PreservesOverrides verifies overridable providers stay overridable.
+func TestWithCircuitBreaker_PreservesOverrides(t *testing.T) {
+	var used []string
+	overridable := &mockOverridableProvider{baseURL: "https://default.test", usedURLs: &used}
+
+	_, ok := WithCircuitBreaker(overridable, "coordinadora_co", breaker.New(1, time.Hour, nil)).(ports.OverridableProvider)
+	assert.True(t, ok)
+
+	_, ok = WithCircuitBreaker(&mockTrackingProvider{}, "coordinadora_co", breaker.New(1, time.Hour, nil)).(ports.OverridableProvider)
+	assert.False(t, ok)
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+
+	"tracker-scrapper/internal/core/logger"
+	"tracker-scrapper/internal/core/maintenance"
+
+	"go.uber.org/zap"
+)
+
+// courierRule matches guide numbers that a courier is known to issue.
+type courierRule struct {
+	courier string
+	pattern *regexp.Regexp
+}
+
+// courierRules are ordered by likelihood: prefix rules first, then bare length rules.
+// A courier may appear several times; DetectCourier keeps its first position.
+var courierRules = []courierRule{
+	// Interrapidisimo guides are 12 digits starting with 24
+	{"interrapidisimo_co", regexp.MustCompile(`^24\d{10}$`)},
+	// Coordinadora guides are 11 digits with a leading zero
+	{"coordinadora_co", regexp.MustCompile(`^0\d{10}$`)},
+	// Servientrega guides are 10 or 11 digits starting with 2
+	{"servientrega_co", regexp.MustCompile(`^2\d{9,10}$`)},
+	{"coordinadora_co", regexp.MustCompile(`^\d{11}$`)},
+	{"servientrega_co", regexp.MustCompile(`^\d{9,11}$`)},
+	{"interrapidisimo_co", regexp.MustCompile(`^\d{12,13}$`)},
+}
+
+// DetectCourier returns the couriers whose guide number format matches trackingNumber,
+// most likely first. It returns nil when no format matches.
+func DetectCourier(trackingNumber string) []string {
+	var candidates []string
+	for _, rule := range courierRules {
+		if rule.pattern.MatchString(trackingNumber) && !slices.Contains(candidates, rule.courier) {
+			candidates = append(candidates, rule.courier)
+		}
+	}
+	return candidates
+}
+
+// DetectTrackingHistory looks trackingNumber up with each supported courier returned by DetectCourier,
+// in order, and returns the first result with at least one event. The result's Courier is set.
+// It returns ErrTrackingNotFound when no candidate resolves, or maintenance.ErrCacheMiss when
+// maintenance mode prevented a live lookup.
+func (s *TrackingService) DetectTrackingHistory(trackingNumber string) (*TrackingResult, error) {
+	cacheMiss := false
+	for _, courier := range DetectCourier(trackingNumber) {
+		if !s.SupportsCourier(courier) {
+			continue
+		}
+
+		result, err := s.GetTrackingHistory(trackingNumber, courier)
+		if err != nil {
+			cacheMiss = cacheMiss || errors.Is(err, maintenance.ErrCacheMiss)
+			logger.Get().Debug("Courier candidate did not resolve",
+				zap.String("courier", courier),
+				zap.String("tracking_number", trackingNumber),
+				zap.Error(err),
+			)
+			continue
+		}
+		if result.History == nil || len(result.History.History) == 0 {
+			continue
+		}
+
+		result.Courier = courier
+		return result, nil
+	}
+
+	if cacheMiss {
+		return nil, maintenance.ErrCacheMiss
+	}
+	return nil, ErrTrackingNotFound
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"tracker-scrapper/internal/core/maintenance"
+	"tracker-scrapper/internal/features/tracking/domain"
+	"tracker-scrapper/internal/features/tracking/ports"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDetectCourier verifies candidates are ordered by likelihood and unknown formats yield none.
+func TestDetectCourier(t *testing.T) {
+	tests := []struct {
+		trackingNumber string
+		expected       []string
+	}{
+		{"240041234567", []string{"interrapidisimo_co"}},
+		{"04333004120", []string{"coordinadora_co", "servientrega_co"}},
+		{"2200000000", []string{"servientrega_co"}},
+		{"22000000000", []string{"servientrega_co", "coordinadora_co"}},
+		{"999999999999", []string{"interrapidisimo_co"}},
+		{"ABC-123", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.trackingNumber, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DetectCourier(tt.trackingNumber))
+		})
+	}
+}
+
+// TestTrackingService_DetectTrackingHistory verifies candidates are tried in order until one resolves.
+func TestTrackingService_DetectTrackingHistory(t *testing.T) {
+	coordinadora := &mockTrackingProvider{supportedCourier: "coordinadora_co", returnError: errors.New("guia no existe")}
+	servientrega := &mockTrackingProvider{
+		supportedCourier: "servientrega_co",
+		returnHistory: &domain.TrackingHistory{
+			GlobalStatus: domain.TrackingStatusCompleted,
+			History:      []domain.TrackingEvent{{Code: "21"}},
+		},
+	}
+	interrapidisimo := &mockTrackingProvider{supportedCourier: "interrapidisimo_co"}
+	svc, err := NewTrackingService([]ports.TrackingProvider{coordinadora, servientrega, interrapidisimo}, newMockCache(), 30*time.Second, nil, 1)
+	require.NoError(t, err)
+
+	result, err := svc.DetectTrackingHistory("04333004120")
+
+	require.NoError(t, err)
+	assert.Equal(t, "servientrega_co", result.Courier)
+	assert.Equal(t, domain.TrackingStatusCompleted, result.History.GlobalStatus)
+	assert.Equal(t, 1, coordinadora.calls)
+	assert.Zero(t, interrapidisimo.calls, "non-matching couriers must not be tried")
+}
+
+// TestTrackingService_DetectTrackingHistory_NotFound verifies empty histories and errors are not a match.
+func TestTrackingService_DetectTrackingHistory_NotFound(t *testing.T) {
+	provider := &mockTrackingProvider{
+		supportedCourier: "interrapidisimo_co",
+		returnHistory:    &domain.TrackingHistory{GlobalStatus: domain.TrackingStatusProcessing},
+	}
+	svc, err := NewTrackingService([]ports.TrackingProvider{provider}, newMockCache(), 30*time.Second, nil, 1)
+	require.NoError(t, err)
+
+	_, err = svc.DetectTrackingHistory("240041234567")
+	assert.ErrorIs(t, err, ErrTrackingNotFound)
+
+	_, err = svc.DetectTrackingHistory("ABC-123")
+	assert.ErrorIs(t, err, ErrTrackingNotFound)
+}
+
+// TestTrackingService_DetectTrackingHistory_Maintenance verifies a cache miss in maintenance mode is not reported as not found.
+func TestTrackingService_DetectTrackingHistory_Maintenance(t *testing.T) {
+	provider := &mockTrackingProvider{supportedCourier: "interrapidisimo_co"}
+	svc, err := NewTrackingService([]ports.TrackingProvider{provider}, newMockCache(), 30*time.Second, maintenance.NewMode(true), 1)
+	require.NoError(t, err)
+
+	_, err = svc.DetectTrackingHistory("240041234567")
+
+	assert.ErrorIs(t, err, maintenance.ErrCacheMiss)
+	assert.Zero(t, provider.calls)
+}
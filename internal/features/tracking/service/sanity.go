@@ -0,0 +1,68 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// minGuideDigits is the fewest digits a courier's guide numbers can have. The couriers issue
+// longer guides (see courierRules); the minimums leave room for numbers pasted without their
+// leading zeros.
+var minGuideDigits = map[string]int{
+	"coordinadora_co":    10,
+	"servientrega_co":    9,
+	"interrapidisimo_co": 9,
+}
+
+var (
+	// errNoDigits rejects numbers such as "test" that no courier could have issued.
+	errNoDigits = errors.New("must contain digits")
+	// errAllZeros rejects placeholder numbers such as "00000".
+	errAllZeros = errors.New("must not be all zeros")
+	// errDenied rejects numbers listed in the denylist.
+	errDenied = errors.New("not accepted")
+)
+
+// SetDenylist replaces the tracking numbers CheckTrackingNumber rejects. Entries are compared
+// ignoring case and, for numbers, ignoring the separators users paste around them.
+func (s *TrackingService) SetDenylist(numbers []string) {
+	denied := make(map[string]bool, len(numbers))
+	for _, number := range numbers {
+		if number = strings.ToLower(strings.TrimSpace(number)); number != "" {
+			denied[number] = true
+		}
+	}
+	s.denylist.Store(&denied)
+}
+
+// CheckTrackingNumber rejects tracking numbers that can't be a real guide of courier before any
+// lookup is made, so obviously fake numbers don't cost a scrape. An empty courier, as in
+// auto-detection, only applies the checks shared by every courier. The error message says what is
+// wrong with the number and is meant for validation responses.
+func (s *TrackingService) CheckTrackingNumber(trackingNumber, courier string) error {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, trackingNumber)
+
+	if denied := s.denylist.Load(); denied != nil {
+		if (*denied)[strings.ToLower(trackingNumber)] || (digits != "" && (*denied)[digits]) {
+			return errDenied
+		}
+	}
+
+	switch {
+	case digits == "":
+		return errNoDigits
+	case strings.Trim(digits, "0") == "":
+		return errAllZeros
+	}
+
+	if minDigits := minGuideDigits[courier]; len(digits) < minDigits {
+		return fmt.Errorf("must contain at least %d digits for %s", minDigits, courier)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"tracker-scrapper/internal/features/tracking/ports"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTrackingService_CheckTrackingNumber verifies the per-courier sanity checks and the denylist.
+func TestTrackingService_CheckTrackingNumber(t *testing.T) {
+	svc, err := NewTrackingService([]ports.TrackingProvider{}, newMockCache(), 30*time.Second, nil, 1)
+	require.NoError(t, err)
+	svc.SetDenylist([]string{" TEST-123 ", "2200000001", ""})
+
+	tests := []struct {
+		name           string
+		trackingNumber string
+		courier        string
+		wantErr        string
+	}{
+		{"coordinadora guide", "04333004120", "coordinadora_co", ""},
+		{"servientrega guide", "2200000000", "servientrega_co", ""},
+		{"interrapidisimo guide", "240041234567", "interrapidisimo_co", ""},
+		{"separators are ignored", "0433-300-4120", "coordinadora_co", ""},
+		{"no digits", "test", "coordinadora_co", "must contain digits"},
+		{"all zeros", "00000000000", "servientrega_co", "must not be all zeros"},
+		{"too short for coordinadora", "123456789", "coordinadora_co", "must contain at least 10 digits for coordinadora_co"},
+		{"too short for interrapidisimo", "12345678", "interrapidisimo_co", "must contain at least 9 digits for interrapidisimo_co"},
+		{"short without courier", "12345", "", ""},
+		{"all zeros without courier", "0000", "", "must not be all zeros"},
+		{"denylisted ignoring case", "test-123", "", "not accepted"},
+		{"denylisted ignoring separators", "220-000-0001", "servientrega_co", "not accepted"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := svc.CheckTrackingNumber(tt.trackingNumber, tt.courier)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+// TestTrackingService_SetDenylist verifies a new denylist replaces the previous one.
+func TestTrackingService_SetDenylist(t *testing.T) {
+	svc, err := NewTrackingService([]ports.TrackingProvider{}, newMockCache(), 30*time.Second, nil, 1)
+	require.NoError(t, err)
+
+	svc.SetDenylist([]string{"04333004120"})
+	assert.EqualError(t, svc.CheckTrackingNumber("04333004120", "coordinadora_co"), "not accepted")
+
+	svc.SetDenylist(nil)
+	assert.NoError(t, svc.CheckTrackingNumber("04333004120", "coordinadora_co"))
+}
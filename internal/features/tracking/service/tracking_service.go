@@ -5,11 +5,21 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"tracker-scrapper/internal/core/cache"
+	"tracker-scrapper/internal/core/clock"
+	"tracker-scrapper/internal/core/etag"
+	"tracker-scrapper/internal/core/logger"
+	"tracker-scrapper/internal/core/maintenance"
+	"tracker-scrapper/internal/core/metrics"
 	"tracker-scrapper/internal/features/tracking/domain"
 	"tracker-scrapper/internal/features/tracking/ports"
+
+	"go.uber.org/zap"
 )
 
 var (
@@ -17,60 +27,329 @@ var (
 	ErrCourierNotSupported = errors.New("courier not supported")
 	// ErrTrackingNotFound is returned when the tracking number is not found.
 	ErrTrackingNotFound = errors.New("tracking not found")
+	// ErrOverridesNotSupported is returned when the courier's provider does not accept per-call overrides.
+	ErrOverridesNotSupported = errors.New("courier does not support overrides")
+	// ErrNotChanged is returned by WatchTrackingHistory when the history still matches the caller's ETag.
+	ErrNotChanged = errors.New("tracking not changed")
 )
 
+// TrackingResult wraps a tracking history with metadata about how it was obtained.
+type TrackingResult struct {
+	// History is the tracking history for the shipment.
+	History *domain.TrackingHistory
+	// FromCache is true when History was served from the cache instead of a live scrape.
+	FromCache bool
+	// Maintenance is true when the result was produced while maintenance mode was active.
+	Maintenance bool
+	// ETag identifies History's content as computed by HistoryETag. Empty when unknown.
+	ETag string
+	// Courier is the courier that resolved the lookup when it was auto-detected. Empty otherwise.
+	Courier string
+}
+
+// BatchItem identifies a single shipment in a batch tracking request.
+type BatchItem struct {
+	// Number is the courier tracking number.
+	Number string `json:"number"`
+	// Courier is the courier name (e.g., coordinadora_co).
+	Courier string `json:"courier"`
+}
+
+// BatchResult holds the outcome of a single batch item. Exactly one of History or Error is set.
+type BatchResult struct {
+	// Number is the courier tracking number.
+	Number string `json:"number"`
+	// Courier is the courier name.
+	Courier string `json:"courier"`
+	// History is the tracking history when the lookup succeeded.
+	History *domain.TrackingHistory `json:"history,omitempty"`
+	// Error describes why the lookup failed.
+	Error string `json:"error,omitempty"`
+}
+
 // TrackingService orchestrates tracking requests across multiple courier providers.
 type TrackingService struct {
+	// providers holds the registered providers in registration order, for Close.
 	providers []ports.TrackingProvider
+	// registry maps each courier name to the provider that serves it.
+	registry map[string]ports.TrackingProvider
 	// cache is the caching layer for storing tracking results.
 	cache cache.Cache
-	// cacheTTL is the duration for which tracking data is cached.
-	cacheTTL time.Duration
+	// cacheTTL is the duration (in nanoseconds) for which tracking data is cached. Updated on config reload.
+	cacheTTL atomic.Int64
+	// maintenance restricts lookups to the cache while enabled.
+	maintenance *maintenance.Mode
+	// batchWorkers bounds the number of concurrent lookups in a batch request.
+	batchWorkers int
+	// denylist holds the lowercased tracking numbers rejected by CheckTrackingNumber. Updated on config reload.
+	denylist atomic.Pointer[map[string]bool]
+	// clock stamps RetrievedAt on scraped histories.
+	clock clock.Clock
+}
+
+// NewTrackingService creates a new TrackingService with cache support, registering each provider
+// under the couriers it reports. It fails when two providers claim the same courier.
+// A nil maintenance mode is treated as disabled; batchWorkers below 1 is treated as 1.
+func NewTrackingService(providers []ports.TrackingProvider, cache cache.Cache, cacheTTL time.Duration, maintenance *maintenance.Mode, batchWorkers int) (*TrackingService, error) {
+	registry := make(map[string]ports.TrackingProvider, len(providers))
+	for _, provider := range providers {
+		for _, courier := range provider.Couriers() {
+			if _, ok := registry[courier]; ok {
+				return nil, fmt.Errorf("courier %s is registered by more than one provider", courier)
+			}
+			registry[courier] = provider
+		}
+	}
+
+	if batchWorkers < 1 {
+		batchWorkers = 1
+	}
+	s := &TrackingService{
+		providers:    providers,
+		registry:     registry,
+		cache:        cache,
+		maintenance:  maintenance,
+		batchWorkers: batchWorkers,
+		clock:        clock.Real,
+	}
+	s.SetCacheTTL(cacheTTL)
+	return s, nil
 }
 
-// NewTrackingService creates a new TrackingService with cache support.
-func NewTrackingService(providers []ports.TrackingProvider, cache cache.Cache, cacheTTL time.Duration) *TrackingService {
-	return &TrackingService{
-		providers: providers,
-		cache:     cache,
-		cacheTTL:  cacheTTL,
+// SetCacheTTL changes the TTL used for tracking data cached from now on.
+func (s *TrackingService) SetCacheTTL(ttl time.Duration) {
+	s.cacheTTL.Store(int64(ttl))
+}
+
+// SetClock replaces the clock used for retrieval times, for tests.
+func (s *TrackingService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// Close closes every provider that implements io.Closer, such as the scraping adapters and
+// their browsers. All providers are closed even if some fail; the errors are joined.
+// It is safe to call more than once.
+func (s *TrackingService) Close() error {
+	var errs []error
+	for _, provider := range s.providers {
+		if c, ok := provider.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
 	}
+	return errors.Join(errs...)
+}
+
+// SupportsCourier returns true if a provider is registered for the given courier.
+func (s *TrackingService) SupportsCourier(courier string) bool {
+	_, ok := s.registry[courier]
+	return ok
 }
 
 // GetTrackingHistory retrieves tracking history for a given tracking number and courier.
 // Uses cache with key format: ts_{courier}_{trackingNumber}
-func (s *TrackingService) GetTrackingHistory(trackingNumber, courier string) (*domain.TrackingHistory, error) {
+// In maintenance mode only the cache is consulted and maintenance.ErrCacheMiss is returned on a miss.
+func (s *TrackingService) GetTrackingHistory(trackingNumber, courier string) (*TrackingResult, error) {
 	ctx := context.Background()
-	cacheKey := fmt.Sprintf("ts_%s_%s", courier, trackingNumber)
+	cacheKey := trackingCacheKey(courier, trackingNumber)
+	inMaintenance := s.maintenance.Enabled()
 
-	// Try to get from cache first
+	// Try to get from cache first; a miss wraps cache.ErrNotFound, anything else is a cache failure
 	cachedData, err := s.cache.Get(ctx, cacheKey)
+	if err != nil && !errors.Is(err, cache.ErrNotFound) {
+		logger.Get().Warn("Tracking cache read failed", zap.String("key", cacheKey), zap.Error(err))
+	}
 	if err == nil {
-		var history domain.TrackingHistory
-		if err := json.Unmarshal(cachedData, &history); err == nil {
-			return &history, nil
+		var entry cachedHistory
+		if err := json.Unmarshal(cachedData, &entry); err == nil {
+			history := &entry.TrackingHistory
+			// Entries cached before the courier or the ETag was recorded still report them
+			if history.Courier == "" {
+				history.Courier = courier
+			}
+			if entry.ETag == "" {
+				entry.ETag = HistoryETag(history)
+			}
+			metrics.CacheHit("tracking")
+			logger.Get().Debug("Tracking cache hit", zap.String("key", cacheKey))
+			return &TrackingResult{History: history, FromCache: true, Maintenance: inMaintenance, ETag: entry.ETag}, nil
 		}
 		// If unmarshal fails, continue to fetch from provider
 	}
+	metrics.CacheMiss("tracking")
+	logger.Get().Debug("Tracking cache miss", zap.String("key", cacheKey))
+
+	if inMaintenance {
+		return nil, maintenance.ErrCacheMiss
+	}
 
 	// Cache miss or error - fetch from provider
-	for _, provider := range s.providers {
-		if provider.SupportsCourier(courier) {
-			history, err := provider.GetTrackingHistory(trackingNumber)
-			if err != nil {
-				return nil, fmt.Errorf("failed to get tracking from provider: %w", err)
-			}
+	return s.fetchTrackingHistory(ctx, cacheKey, trackingNumber, courier)
+}
 
-			// Cache the result
-			historyData, err := json.Marshal(history)
-			if err == nil {
-				// Fire and forget - don't fail if cache write fails
-				_ = s.cache.Set(ctx, cacheKey, historyData, s.cacheTTL)
-			}
+// refreshTrackingHistory looks the tracking up with the courier, skipping the cached copy, and caches
+// the result. In maintenance mode, where couriers aren't called, it is served from the cache instead.
+func (s *TrackingService) refreshTrackingHistory(trackingNumber, courier string) (*TrackingResult, error) {
+	if s.maintenance.Enabled() {
+		return s.GetTrackingHistory(trackingNumber, courier)
+	}
+	return s.fetchTrackingHistory(context.Background(), trackingCacheKey(courier, trackingNumber), trackingNumber, courier)
+}
 
-			return history, nil
+// fetchTrackingHistory looks the tracking up with the courier and caches the result under cacheKey.
+func (s *TrackingService) fetchTrackingHistory(ctx context.Context, cacheKey, trackingNumber, courier string) (*TrackingResult, error) {
+	provider, ok := s.registry[courier]
+	if !ok {
+		return nil, ErrCourierNotSupported
+	}
+	history, err := provider.GetTrackingHistory(trackingNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tracking from provider: %w", err)
+	}
+	history.Courier = courier
+	history.RetrievedAt = s.clock.Now().UTC()
+
+	// Cache the result
+	result := &TrackingResult{History: history, ETag: HistoryETag(history)}
+	historyData, err := json.Marshal(cachedHistory{TrackingHistory: *history, ETag: result.ETag})
+	if err == nil {
+		// Fire and forget - don't fail if cache write fails
+		_ = s.cache.Set(ctx, cacheKey, historyData, time.Duration(s.cacheTTL.Load()))
+	}
+
+	return result, nil
+}
+
+// trackingCacheKey returns the cache key of a tracking history: ts_{courier}_{trackingNumber}.
+func trackingCacheKey(courier, trackingNumber string) string {
+	return fmt.Sprintf("ts_%s_%s", courier, trackingNumber)
+}
+
+// cachedHistory is the cache entry of a tracking history, holding the ETag computed when it was cached.
+type cachedHistory struct {
+	domain.TrackingHistory
+	// ETag is HistoryETag of the history. Empty in entries cached before it was recorded.
+	ETag string `json:"etag,omitempty"`
+}
+
+// HistoryETag returns the weak ETag of history's JSON encoding without RetrievedAt, which changes on
+// every scrape, so a refetch with the same events keeps its ETag for If-None-Match and watches.
+// It returns "" when history can't be encoded.
+func HistoryETag(history *domain.TrackingHistory) string {
+	unstamped := *history
+	unstamped.RetrievedAt = time.Time{}
+	data, err := json.Marshal(unstamped)
+	if err != nil {
+		return ""
+	}
+	return etag.Weak(data)
+}
+
+// GetTrackingHistoryWithOverrides retrieves tracking history using per-call provider overrides.
+// The cache is bypassed in both directions so override results never leak into regular lookups.
+func (s *TrackingService) GetTrackingHistoryWithOverrides(trackingNumber, courier string, overrides ports.Overrides) (*TrackingResult, error) {
+	if s.maintenance.Enabled() {
+		return nil, maintenance.ErrCacheMiss
+	}
+
+	provider, ok := s.registry[courier]
+	if !ok {
+		return nil, ErrCourierNotSupported
+	}
+	overridable, ok := provider.(ports.OverridableProvider)
+	if !ok {
+		return nil, ErrOverridesNotSupported
+	}
+
+	logger.Get().Info("Fetching tracking with courier overrides",
+		zap.String("courier", courier),
+		zap.String("base_url", overrides.BaseURL),
+		zap.Bool("authorization", overrides.Authorization != ""),
+	)
+
+	history, err := overridable.WithOverrides(overrides).GetTrackingHistory(trackingNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tracking from provider: %w", err)
+	}
+	history.Courier = courier
+	history.RetrievedAt = s.clock.Now().UTC()
+
+	return &TrackingResult{History: history}, nil
+}
+
+// WatchTrackingHistory looks the tracking up every interval until its ETag differs from since and
+// returns that result. The first lookup may be served from the cache; later ones scrape the courier
+// again and refresh the cached copy, so a change shows up without waiting for the cache TTL.
+// An empty since returns the first lookup. ErrNotChanged is returned when ctx is done before the
+// history changes; lookup errors end the watch.
+func (s *TrackingService) WatchTrackingHistory(ctx context.Context, trackingNumber, courier, since string, interval time.Duration) (*TrackingResult, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lookup := s.GetTrackingHistory
+	for {
+		result, err := lookup(trackingNumber, courier)
+		if err != nil {
+			return nil, err
+		}
+		if since == "" || result.ETag != since {
+			return result, nil
 		}
+		lookup = s.refreshTrackingHistory
+
+		select {
+		case <-ctx.Done():
+			return nil, ErrNotChanged
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetTrackingHistoryBatch retrieves tracking histories for several shipments concurrently.
+// At most batchWorkers lookups run at once. Items not yet started when ctx is cancelled
+// are reported with the context error. Results keep the order of items.
+func (s *TrackingService) GetTrackingHistoryBatch(ctx context.Context, items []BatchItem) []BatchResult {
+	results := make([]BatchResult, len(items))
+	jobs := make(chan int)
+
+	workers := min(s.batchWorkers, len(items))
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = s.lookupBatchItem(ctx, items[i])
+			}
+		}()
+	}
+
+	for i := range items {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// lookupBatchItem resolves a single batch item, honoring cancellation before starting the lookup.
+func (s *TrackingService) lookupBatchItem(ctx context.Context, item BatchItem) BatchResult {
+	res := BatchResult{Number: item.Number, Courier: item.Courier}
+
+	if err := ctx.Err(); err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	result, err := s.GetTrackingHistory(item.Number, item.Courier)
+	if err != nil {
+		res.Error = err.Error()
+		return res
 	}
 
-	return nil, ErrCourierNotSupported
+	res.History = result.History
+	return res
 }
@@ -3,9 +3,15 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"tracker-scrapper/internal/core/cache"
+	"tracker-scrapper/internal/core/clock"
+	"tracker-scrapper/internal/core/maintenance"
 	"tracker-scrapper/internal/features/tracking/domain"
 	"tracker-scrapper/internal/features/tracking/ports"
 
@@ -18,10 +24,12 @@ type mockTrackingProvider struct {
 	supportedCourier string
 	returnHistory    *domain.TrackingHistory
 	returnError      error
+	calls            int
 }
 
 // GetTrackingHistory implements TrackingProvider.
 func (m *mockTrackingProvider) GetTrackingHistory(trackingNumber string) (*domain.TrackingHistory, error) {
+	m.calls++
 	if m.returnError != nil {
 		return nil, m.returnError
 	}
@@ -33,8 +41,78 @@ func (m *mockTrackingProvider) SupportsCourier(courierName string) bool {
 	return courierName == m.supportedCourier
 }
 
+// Couriers implements TrackingProvider.
+func (m *mockTrackingProvider) Couriers() []string {
+	return []string{m.supportedCourier}
+}
+
+// mockOverridableProvider records the base URL used for each call.
+type mockOverridableProvider struct {
+	baseURL  string
+	usedURLs *[]string
+}
+
+// GetTrackingHistory implements TrackingProvider.
+func (m *mockOverridableProvider) GetTrackingHistory(trackingNumber string) (*domain.TrackingHistory, error) {
+	*m.usedURLs = append(*m.usedURLs, m.baseURL)
+	return &domain.TrackingHistory{GlobalStatus: domain.TrackingStatusProcessing}, nil
+}
+
+// SupportsCourier implements TrackingProvider.
+func (m *mockOverridableProvider) SupportsCourier(courierName string) bool {
+	return courierName == "coordinadora_co"
+}
+
+// Couriers implements TrackingProvider.
+func (m *mockOverridableProvider) Couriers() []string {
+	return []string{"coordinadora_co"}
+}
+
+// WithOverrides implements OverridableProvider.
+func (m *mockOverridableProvider) WithOverrides(overrides ports.Overrides) ports.TrackingProvider {
+	clone := *m
+	if overrides.BaseURL != "" {
+		clone.baseURL = overrides.BaseURL
+	}
+	return &clone
+}
+
+// slowProvider simulates a scrape and records the peak number of concurrent calls.
+type slowProvider struct {
+	inFlight atomic.Int32
+	peak     atomic.Int32
+}
+
+// GetTrackingHistory implements TrackingProvider.
+func (p *slowProvider) GetTrackingHistory(trackingNumber string) (*domain.TrackingHistory, error) {
+	n := p.inFlight.Add(1)
+	defer p.inFlight.Add(-1)
+	for {
+		peak := p.peak.Load()
+		if n <= peak || p.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	if trackingNumber == "FAIL" {
+		return nil, errors.New("scrape failed")
+	}
+	return &domain.TrackingHistory{GlobalStatus: domain.TrackingStatusProcessing}, nil
+}
+
+// SupportsCourier implements TrackingProvider.
+func (p *slowProvider) SupportsCourier(courierName string) bool {
+	return courierName == "coordinadora_co"
+}
+
+// Couriers implements TrackingProvider.
+func (p *slowProvider) Couriers() []string {
+	return []string{"coordinadora_co"}
+}
+
 // mockCache is a simple in-memory cache for testing.
 type mockCache struct {
+	mu   sync.Mutex
 	data map[string][]byte
 }
 
@@ -43,18 +121,45 @@ func newMockCache() *mockCache {
 }
 
 func (m *mockCache) Get(ctx context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if val, ok := m.data[key]; ok {
 		return val, nil
 	}
-	return nil, errors.New("key not found")
+	return nil, fmt.Errorf("%w: %s", cache.ErrNotFound, key)
 }
 
 func (m *mockCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.data[key] = value
 	return nil
 }
 
+func (m *mockCache) GetMulti(ctx context.Context, keys []string) (map[string][]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result := make(map[string][]byte, len(keys))
+	for _, key := range keys {
+		if val, ok := m.data[key]; ok {
+			result[key] = val
+		}
+	}
+	return result, nil
+}
+
+func (m *mockCache) SetMulti(ctx context.Context, entries map[string][]byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, value := range entries {
+		m.data[key] = value
+	}
+	return nil
+}
+
 func (m *mockCache) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	delete(m.data, key)
 	return nil
 }
@@ -81,12 +186,148 @@ func TestTrackingService_GetTrackingHistory_Success(t *testing.T) {
 
 	mockCache := newMockCache()
 
-	svc := NewTrackingService([]ports.TrackingProvider{provider}, mockCache, 30*time.Second)
+	svc, err := NewTrackingService([]ports.TrackingProvider{provider}, mockCache, 30*time.Second, nil, 1)
+	require.NoError(t, err)
+
+	result, err := svc.GetTrackingHistory("12345", "coordinadora_co")
+
+	require.NoError(t, err)
+	assert.Equal(t, expectedHistory, result.History)
+	assert.False(t, result.FromCache)
+}
+
+// TestTrackingService_GetTrackingHistory_CacheHit verifies a second lookup is served from cache.
+func TestTrackingService_GetTrackingHistory_CacheHit(t *testing.T) {
+	provider := &mockTrackingProvider{
+		supportedCourier: "coordinadora_co",
+		returnHistory: &domain.TrackingHistory{
+			GlobalStatus: domain.TrackingStatusCompleted,
+			History:      []domain.TrackingEvent{},
+		},
+	}
+
+	svc, err := NewTrackingService([]ports.TrackingProvider{provider}, newMockCache(), 30*time.Second, nil, 1)
+	require.NoError(t, err)
+
+	first, err := svc.GetTrackingHistory("12345", "coordinadora_co")
+	require.NoError(t, err)
+	assert.False(t, first.FromCache)
+
+	second, err := svc.GetTrackingHistory("12345", "coordinadora_co")
+	require.NoError(t, err)
+	assert.True(t, second.FromCache)
+	assert.Equal(t, domain.TrackingStatusCompleted, second.History.GlobalStatus)
+	assert.Equal(t, "coordinadora_co", second.History.Courier)
+}
+
+// TestTrackingService_GetTrackingHistory_RetrievedAt verifies cache hits report the original scrape time.
+func TestTrackingService_GetTrackingHistory_RetrievedAt(t *testing.T) {
+	scrapedAt := time.Date(2025, 3, 4, 10, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(scrapedAt)
+
+	provider := &mockTrackingProvider{
+		supportedCourier: "coordinadora_co",
+		returnHistory:    &domain.TrackingHistory{GlobalStatus: domain.TrackingStatusCompleted},
+	}
+	svc, err := NewTrackingService([]ports.TrackingProvider{provider}, newMockCache(), 30*time.Second, nil, 1)
+	require.NoError(t, err)
+	svc.SetClock(clk)
+
+	live, err := svc.GetTrackingHistory("12345", "coordinadora_co")
+	require.NoError(t, err)
+	assert.Equal(t, scrapedAt, live.History.RetrievedAt)
+
+	clk.Advance(10 * time.Minute)
+	cached, err := svc.GetTrackingHistory("12345", "coordinadora_co")
+	require.NoError(t, err)
+	assert.True(t, cached.FromCache)
+	assert.Equal(t, scrapedAt, cached.History.RetrievedAt)
+}
+
+// TestTrackingService_GetTrackingHistory_Courier verifies the resolving courier is recorded, including on
+// entries cached before it was.
+func TestTrackingService_GetTrackingHistory_Courier(t *testing.T) {
+	provider := &mockTrackingProvider{
+		supportedCourier: "servientrega_co",
+		returnHistory:    &domain.TrackingHistory{GlobalStatus: domain.TrackingStatusCompleted},
+	}
+	cache := newMockCache()
+	svc, err := NewTrackingService([]ports.TrackingProvider{provider}, cache, 30*time.Second, nil, 1)
+	require.NoError(t, err)
+
+	live, err := svc.GetTrackingHistory("2259176774", "servientrega_co")
+	require.NoError(t, err)
+	assert.Equal(t, "servientrega_co", live.History.Courier)
+
+	require.NoError(t, cache.Set(context.Background(), "ts_servientrega_co_2259176775", []byte(`{"global_status":"COMPLETED","history":[]}`), time.Minute))
+	cached, err := svc.GetTrackingHistory("2259176775", "servientrega_co")
+	require.NoError(t, err)
+	assert.True(t, cached.FromCache)
+	assert.Equal(t, "servientrega_co", cached.History.Courier)
+}
 
-	history, err := svc.GetTrackingHistory("12345", "coordinadora_co")
+// TestTrackingService_GetTrackingHistory_ETag verifies the ETag is the same for the live result and the cache hit.
+func TestTrackingService_GetTrackingHistory_ETag(t *testing.T) {
+	provider := &mockTrackingProvider{
+		supportedCourier: "coordinadora_co",
+		returnHistory:    &domain.TrackingHistory{GlobalStatus: domain.TrackingStatusProcessing},
+	}
+	svc, err := NewTrackingService([]ports.TrackingProvider{provider}, newMockCache(), 30*time.Second, nil, 1)
+	require.NoError(t, err)
 
+	live, err := svc.GetTrackingHistory("12345", "coordinadora_co")
+	require.NoError(t, err)
+	cached, err := svc.GetTrackingHistory("12345", "coordinadora_co")
 	require.NoError(t, err)
-	assert.Equal(t, expectedHistory, history)
+
+	assert.NotEmpty(t, live.ETag)
+	assert.True(t, cached.FromCache)
+	assert.Equal(t, live.ETag, cached.ETag)
+}
+
+// TestTrackingService_GetTrackingHistory_CachedETag verifies cache hits serve the weak ETag stored with
+// the entry, and entries cached without one still get it.
+func TestTrackingService_GetTrackingHistory_CachedETag(t *testing.T) {
+	cache := newMockCache()
+	svc, err := NewTrackingService([]ports.TrackingProvider{&mockTrackingProvider{supportedCourier: "coordinadora_co"}}, cache, 30*time.Second, nil, 1)
+	require.NoError(t, err)
+
+	require.NoError(t, cache.Set(context.Background(), "ts_coordinadora_co_12345", []byte(`{"global_status":"PROCESSING","history":[],"etag":"W/\"stored\""}`), time.Minute))
+	cached, err := svc.GetTrackingHistory("12345", "coordinadora_co")
+	require.NoError(t, err)
+	assert.Equal(t, `W/"stored"`, cached.ETag)
+
+	require.NoError(t, cache.Set(context.Background(), "ts_coordinadora_co_12345", []byte(`{"global_status":"PROCESSING","history":[]}`), time.Minute))
+	legacy, err := svc.GetTrackingHistory("12345", "coordinadora_co")
+	require.NoError(t, err)
+	assert.Regexp(t, `^W/"[0-9a-f]{32}"$`, legacy.ETag)
+	assert.Equal(t, HistoryETag(legacy.History), legacy.ETag)
+}
+
+// TestTrackingService_GetTrackingHistory_ETagIgnoresRetrievedAt verifies a later scrape of the same events keeps
+// the ETag, so If-None-Match and watches don't see a change on every cache refresh.
+func TestTrackingService_GetTrackingHistory_ETagIgnoresRetrievedAt(t *testing.T) {
+	scrapedAt := time.Date(2025, 3, 4, 10, 0, 0, 0, time.UTC)
+	provider := &mockTrackingProvider{
+		supportedCourier: "coordinadora_co",
+		returnHistory:    &domain.TrackingHistory{GlobalStatus: domain.TrackingStatusProcessing},
+	}
+
+	lookup := func(at time.Time) *TrackingResult {
+		svc, err := NewTrackingService([]ports.TrackingProvider{provider}, newMockCache(), 30*time.Second, nil, 1)
+		require.NoError(t, err)
+		svc.SetClock(clock.NewFake(at))
+		result, err := svc.GetTrackingHistory("12345", "coordinadora_co")
+		require.NoError(t, err)
+		return result
+	}
+	firstETag := lookup(scrapedAt).ETag
+	later := lookup(scrapedAt.Add(time.Hour))
+
+	assert.False(t, later.FromCache)
+	assert.Equal(t, scrapedAt.Add(time.Hour), later.History.RetrievedAt)
+	assert.NotEmpty(t, firstETag)
+	assert.Equal(t, firstETag, later.ETag)
 }
 
 // TestTrackingService_GetTrackingHistory_CourierNotSupported verifies unsupported courier handling.
@@ -97,11 +338,12 @@ func TestTrackingService_GetTrackingHistory_CourierNotSupported(t *testing.T) {
 
 	mockCache := newMockCache()
 
-	svc := NewTrackingService([]ports.TrackingProvider{provider}, mockCache, 30*time.Second)
+	svc, err := NewTrackingService([]ports.TrackingProvider{provider}, mockCache, 30*time.Second, nil, 1)
+	require.NoError(t, err)
 
-	history, err := svc.GetTrackingHistory("12345", "unknown_courier")
+	result, err := svc.GetTrackingHistory("12345", "unknown_courier")
 
-	assert.Nil(t, history)
+	assert.Nil(t, result)
 	assert.ErrorIs(t, err, ErrCourierNotSupported)
 }
 
@@ -115,15 +357,27 @@ func TestTrackingService_GetTrackingHistory_ProviderError(t *testing.T) {
 
 	mockCache := newMockCache()
 
-	svc := NewTrackingService([]ports.TrackingProvider{provider}, mockCache, 30*time.Second)
+	svc, err := NewTrackingService([]ports.TrackingProvider{provider}, mockCache, 30*time.Second, nil, 1)
+	require.NoError(t, err)
 
-	history, err := svc.GetTrackingHistory("12345", "coordinadora_co")
+	result, err := svc.GetTrackingHistory("12345", "coordinadora_co")
 
-	assert.Nil(t, history)
+	assert.Nil(t, result)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to get tracking from provider")
 }
 
+// TestNewTrackingService_DuplicateCourier verifies two providers can't claim the same courier.
+func TestNewTrackingService_DuplicateCourier(t *testing.T) {
+	_, err := NewTrackingService([]ports.TrackingProvider{
+		&mockTrackingProvider{supportedCourier: "coordinadora_co"},
+		&mockTrackingProvider{supportedCourier: "servientrega_co"},
+		&mockTrackingProvider{supportedCourier: "coordinadora_co"},
+	}, newMockCache(), 30*time.Second, nil, 1)
+
+	assert.EqualError(t, err, "courier coordinadora_co is registered by more than one provider")
+}
+
 // TestTrackingService_GetTrackingHistory_MultipleProviders verifies routing to correct provider.
 func TestTrackingService_GetTrackingHistory_MultipleProviders(t *testing.T) {
 	provider1 := &mockTrackingProvider{
@@ -144,10 +398,236 @@ func TestTrackingService_GetTrackingHistory_MultipleProviders(t *testing.T) {
 
 	mockCache := newMockCache()
 
-	svc := NewTrackingService([]ports.TrackingProvider{provider1, provider2}, mockCache, 30*time.Second)
+	svc, err := NewTrackingService([]ports.TrackingProvider{provider1, provider2}, mockCache, 30*time.Second, nil, 1)
+	require.NoError(t, err)
+
+	result, err := svc.GetTrackingHistory("67890", "servientrega_co")
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.TrackingStatusCompleted, result.History.GlobalStatus)
+}
+
+// TestTrackingService_GetTrackingHistory_MaintenanceCacheHit verifies cached data is served in maintenance mode.
+func TestTrackingService_GetTrackingHistory_MaintenanceCacheHit(t *testing.T) {
+	provider := &mockTrackingProvider{supportedCourier: "coordinadora_co"}
+
+	mockCache := newMockCache()
+	mockCache.data["ts_coordinadora_co_12345"] = []byte(`{"global_status":"COMPLETED","history":[]}`)
+
+	svc, err := NewTrackingService([]ports.TrackingProvider{provider}, mockCache, 30*time.Second, maintenance.NewMode(true), 1)
+	require.NoError(t, err)
+
+	result, err := svc.GetTrackingHistory("12345", "coordinadora_co")
+
+	require.NoError(t, err)
+	assert.True(t, result.FromCache)
+	assert.True(t, result.Maintenance)
+	assert.Equal(t, domain.TrackingStatusCompleted, result.History.GlobalStatus)
+	assert.Zero(t, provider.calls)
+}
+
+// TestTrackingService_GetTrackingHistory_MaintenanceCacheMiss verifies providers are not called in maintenance mode.
+func TestTrackingService_GetTrackingHistory_MaintenanceCacheMiss(t *testing.T) {
+	provider := &mockTrackingProvider{
+		supportedCourier: "coordinadora_co",
+		returnHistory:    &domain.TrackingHistory{GlobalStatus: domain.TrackingStatusProcessing},
+	}
+
+	svc, err := NewTrackingService([]ports.TrackingProvider{provider}, newMockCache(), 30*time.Second, maintenance.NewMode(true), 1)
+	require.NoError(t, err)
+
+	result, err := svc.GetTrackingHistory("12345", "coordinadora_co")
+
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, maintenance.ErrCacheMiss)
+	assert.Zero(t, provider.calls)
+}
+
+// TestTrackingService_GetTrackingHistoryWithOverrides verifies the override URL is only used for that call.
+func TestTrackingService_GetTrackingHistoryWithOverrides(t *testing.T) {
+	var usedURLs []string
+	provider := &mockOverridableProvider{
+		baseURL:  "https://coordinadora.com/rastreo",
+		usedURLs: &usedURLs,
+	}
+
+	mockCache := newMockCache()
+	svc, err := NewTrackingService([]ports.TrackingProvider{provider}, mockCache, 30*time.Second, nil, 1)
+	require.NoError(t, err)
+
+	_, err = svc.GetTrackingHistoryWithOverrides("12345", "coordinadora_co", ports.Overrides{
+		BaseURL: "https://staging.coordinadora.com/rastreo",
+	})
+	require.NoError(t, err)
+	assert.Empty(t, mockCache.data, "override results must not be cached")
+
+	_, err = svc.GetTrackingHistory("12345", "coordinadora_co")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"https://staging.coordinadora.com/rastreo",
+		"https://coordinadora.com/rastreo",
+	}, usedURLs)
+}
+
+// TestTrackingService_GetTrackingHistoryWithOverrides_NotSupported verifies providers without override support are rejected.
+func TestTrackingService_GetTrackingHistoryWithOverrides_NotSupported(t *testing.T) {
+	provider := &mockTrackingProvider{supportedCourier: "coordinadora_co"}
+
+	svc, err := NewTrackingService([]ports.TrackingProvider{provider}, newMockCache(), 30*time.Second, nil, 1)
+	require.NoError(t, err)
+
+	_, err = svc.GetTrackingHistoryWithOverrides("12345", "coordinadora_co", ports.Overrides{BaseURL: "https://staging.test"})
+
+	assert.ErrorIs(t, err, ErrOverridesNotSupported)
+	assert.Zero(t, provider.calls)
+}
+
+// TestTrackingService_GetTrackingHistoryBatch verifies bounded concurrency, ordering and per-item errors.
+func TestTrackingService_GetTrackingHistoryBatch(t *testing.T) {
+	provider := &slowProvider{}
+	svc, err := NewTrackingService([]ports.TrackingProvider{provider}, newMockCache(), 30*time.Second, nil, 2)
+	require.NoError(t, err)
+
+	items := []BatchItem{
+		{Number: "1001", Courier: "coordinadora_co"},
+		{Number: "FAIL", Courier: "coordinadora_co"},
+		{Number: "1003", Courier: "coordinadora_co"},
+		{Number: "1004", Courier: "coordinadora_co"},
+		{Number: "1005", Courier: "unknown_co"},
+	}
+
+	results := svc.GetTrackingHistoryBatch(context.Background(), items)
+
+	require.Len(t, results, len(items))
+	for i, item := range items {
+		assert.Equal(t, item.Number, results[i].Number)
+	}
+	assert.NotNil(t, results[0].History)
+	assert.Empty(t, results[0].Error)
+	assert.Nil(t, results[1].History)
+	assert.Contains(t, results[1].Error, "scrape failed")
+	assert.Contains(t, results[4].Error, ErrCourierNotSupported.Error())
+	assert.LessOrEqual(t, provider.peak.Load(), int32(2))
+}
+
+// TestTrackingService_GetTrackingHistoryBatch_Cancelled verifies items are skipped after cancellation.
+func TestTrackingService_GetTrackingHistoryBatch_Cancelled(t *testing.T) {
+	provider := &slowProvider{}
+	svc, err := NewTrackingService([]ports.TrackingProvider{provider}, newMockCache(), 30*time.Second, nil, 2)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := svc.GetTrackingHistoryBatch(ctx, []BatchItem{{Number: "1001", Courier: "coordinadora_co"}})
+
+	require.Len(t, results, 1)
+	assert.Nil(t, results[0].History)
+	assert.Equal(t, context.Canceled.Error(), results[0].Error)
+	assert.Zero(t, provider.peak.Load())
+}
+
+// TestTrackingService_WatchTrackingHistory verifies a watch returns once the ETag differs from since
+// and reports ErrNotChanged when the context ends first.
+func TestTrackingService_WatchTrackingHistory(t *testing.T) {
+	provider := &mockTrackingProvider{
+		supportedCourier: "coordinadora_co",
+		returnHistory:    &domain.TrackingHistory{GlobalStatus: domain.TrackingStatusProcessing},
+	}
+	mc := newMockCache()
+	svc, err := NewTrackingService([]ports.TrackingProvider{provider}, mc, 30*time.Second, nil, 1)
+	require.NoError(t, err)
+
+	current, err := svc.WatchTrackingHistory(context.Background(), "12345", "coordinadora_co", "", time.Millisecond)
+	require.NoError(t, err)
+	require.NotEmpty(t, current.ETag)
+
+	t.Run("NotChanged", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+		defer cancel()
+
+		result, err := svc.WatchTrackingHistory(ctx, "12345", "coordinadora_co", current.ETag, 5*time.Millisecond)
+
+		assert.ErrorIs(t, err, ErrNotChanged)
+		assert.Nil(t, result)
+	})
+
+	t.Run("Changed", func(t *testing.T) {
+		provider.returnHistory = &domain.TrackingHistory{GlobalStatus: domain.TrackingStatusCompleted}
+		require.NoError(t, mc.Delete(context.Background(), "ts_coordinadora_co_12345"))
+
+		result, err := svc.WatchTrackingHistory(context.Background(), "12345", "coordinadora_co", current.ETag, time.Millisecond)
 
-	history, err := svc.GetTrackingHistory("67890", "servientrega_co")
+		require.NoError(t, err)
+		assert.NotEqual(t, current.ETag, result.ETag)
+		assert.Equal(t, domain.TrackingStatusCompleted, result.History.GlobalStatus)
+	})
+
+	t.Run("ChangedWhileCached", func(t *testing.T) {
+		cached, err := svc.GetTrackingHistory("12345", "coordinadora_co")
+		require.NoError(t, err)
+		require.True(t, cached.FromCache)
+		provider.returnHistory = &domain.TrackingHistory{GlobalStatus: domain.TrackingStatusReturn}
+
+		result, err := svc.WatchTrackingHistory(context.Background(), "12345", "coordinadora_co", cached.ETag, time.Millisecond)
+
+		require.NoError(t, err)
+		assert.False(t, result.FromCache)
+		assert.Equal(t, domain.TrackingStatusReturn, result.History.GlobalStatus)
+
+		refreshed, err := svc.GetTrackingHistory("12345", "coordinadora_co")
+		require.NoError(t, err)
+		assert.True(t, refreshed.FromCache)
+		assert.Equal(t, result.ETag, refreshed.ETag)
+	})
+
+	t.Run("LookupError", func(t *testing.T) {
+		_, err := svc.WatchTrackingHistory(context.Background(), "12345", "unknown_co", current.ETag, time.Millisecond)
+		assert.ErrorIs(t, err, ErrCourierNotSupported)
+	})
+}
 
+// closingProvider is a mockTrackingProvider that counts Close calls.
+type closingProvider struct {
+	mockTrackingProvider
+	closes   int
+	closeErr error
+}
+
+// Close implements io.Closer.
+func (p *closingProvider) Close() error {
+	p.closes++
+	return p.closeErr
+}
+
+// TestTrackingService_Close verifies every closable provider is closed, including behind a circuit breaker,
+// and that a failing provider doesn't stop the others from closing.
+func TestTrackingService_Close(t *testing.T) {
+	failing := &closingProvider{mockTrackingProvider: mockTrackingProvider{supportedCourier: "servientrega_co"}, closeErr: errors.New("browser still running")}
+	wrapped := &closingProvider{mockTrackingProvider: mockTrackingProvider{supportedCourier: "coordinadora_co"}}
+	svc, err := NewTrackingService([]ports.TrackingProvider{
+		failing,
+		&mockTrackingProvider{supportedCourier: "interrapidisimo_co"},
+		WithCircuitBreaker(wrapped, "coordinadora_co", NewCourierBreaker("coordinadora_co", 1, time.Minute)),
+	}, newMockCache(), time.Minute, nil, 1)
 	require.NoError(t, err)
-	assert.Equal(t, domain.TrackingStatusCompleted, history.GlobalStatus)
+
+	err = svc.Close()
+
+	assert.ErrorIs(t, err, failing.closeErr)
+	assert.Equal(t, 1, failing.closes)
+	assert.Equal(t, 1, wrapped.closes)
+
+	failing.closeErr = nil
+	assert.NoError(t, svc.Close())
+}
+
+// TestTrackingService_Close_NoProviders verifies closing is safe when nothing was allocated.
+func TestTrackingService_Close_NoProviders(t *testing.T) {
+	svc, err := NewTrackingService(nil, newMockCache(), time.Minute, nil, 1)
+	require.NoError(t, err)
+
+	assert.NoError(t, svc.Close())
+	assert.NoError(t, svc.Close())
 }